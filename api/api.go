@@ -0,0 +1,13 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package api provides the meta-schemas of the JSON Schema drafts.
+package api
+
+import "embed"
+
+// FS contains the meta-schema files of the JSON Schema drafts, such as "draft-07/schema.json".
+//
+//go:embed draft-04 draft-06 draft-07 2019-09
+var FS embed.FS
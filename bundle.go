@@ -0,0 +1,413 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/zchee/go-jsonschema/pkg/jsonpointer"
+)
+
+// Bundle returns the copy of root which contains all the schemas referenced by its external "$ref"s.
+//
+// The documents of the external schemas are resolved by reg, and are copied into the "definitions" of the root,
+// or the "$defs" if the root is the draft 2019-09 schema. The "$ref"s of the root resource are rewritten to
+// the local JSON Pointers of the copies. The copied documents keep their "$id"s, which are made absolute,
+// so the "$ref"s in them are resolved to the same schemas as before.
+//
+// The base URI of root is its "$id", or the URI which root is added to reg with. The definition names are
+// derived from the last path segments of the document URIs, and are suffixed by the sequence number if
+// they collide with the other definitions, in the depth-first order of the "$ref"s.
+//
+// Bundle does not modify root and the documents of reg.
+func Bundle(root *Schema, reg *Registry) (*Schema, error) {
+	if reg == nil {
+		reg = NewRegistry()
+	}
+	base, ok := reg.uriOf(root)
+	if !ok {
+		base = &url.URL{}
+	}
+
+	out := root.clone()
+
+	b := &bundler{
+		reg:     reg,
+		root:    out,
+		defsKey: keyDefinitions,
+		local:   make(map[string]string),
+		used:    make(map[string]bool),
+		docs:    make(map[string]bool),
+	}
+	if strings.TrimSuffix(out.Schema, "#") == Draft201909SchemaURL {
+		b.defsKey = keyDefs
+	}
+	for name := range b.defs() {
+		b.used[name] = true
+	}
+
+	// the root is identified by both the URI of reg and its "$id".
+	b.local[base.String()] = ""
+	b.index(out, base, "")
+	rootBase := base
+	if out.ID != "" {
+		if id, err := url.Parse(out.ID); err == nil && id.Fragment == "" {
+			rootBase = base.ResolveReference(id)
+		}
+	}
+	if err := b.rewrite(out, base, rootBase); err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(b.queue); i++ {
+		doc := b.queue[i]
+		if err := b.rewrite(doc.schema, doc.uri, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(b.queue) > 0 {
+		defs := b.defs()
+		if defs == nil {
+			defs = make(Definitions)
+		}
+		for _, doc := range b.queue {
+			defs[doc.name] = doc.schema
+		}
+		if b.defsKey == keyDefs {
+			out.Defs = defs
+		} else {
+			out.Definitions = defs
+		}
+	}
+
+	return out, nil
+}
+
+// bundledDoc represents a copy of the external document which is bundled into the root.
+type bundledDoc struct {
+	name   string
+	uri    *url.URL
+	schema *Schema
+}
+
+// bundler represents a state of the Bundle.
+type bundler struct {
+	reg     *Registry
+	root    *Schema
+	defsKey string
+
+	// local is the map of the absolute URI of the resources in the root, including the bundled documents,
+	// to their JSON Pointers.
+	local map[string]string
+
+	// used is the set of the definition names.
+	used map[string]bool
+
+	// docs is the set of the URIs of the bundled documents.
+	docs map[string]bool
+
+	queue []*bundledDoc
+}
+
+// defs returns the definitions of the root which the documents are bundled into.
+func (b *bundler) defs() Definitions {
+	if b.defsKey == keyDefs {
+		return b.root.Defs
+	}
+
+	return b.root.Definitions
+}
+
+// index records the resources of s, which is located at prefix in the root.
+func (b *bundler) index(s *Schema, base *url.URL, prefix string) {
+	walkResources(s, base, func(loc string, _ *Schema, _, id *url.URL) {
+		if id != nil {
+			b.local[id.String()] = prefix + loc
+		}
+	})
+}
+
+// rewrite bundles the documents referenced by the "$ref"s in s, whose base URI is base.
+//
+// The "$ref"s of the schemas whose base URI is rootBase are rewritten to the local JSON Pointers,
+// and the others are kept since they are resolved by the "$id"s of the bundled documents.
+func (b *bundler) rewrite(s *Schema, base, rootBase *url.URL) error {
+	var err error
+	walkResources(s, base, func(loc string, sub *Schema, subBase, _ *url.URL) {
+		if err != nil || sub.Ref == "" {
+			return
+		}
+		ref, perr := url.Parse(sub.Ref)
+		if perr != nil {
+			err = fmt.Errorf("jsonschema: invalid $ref %q: %v", sub.Ref, perr)
+			return
+		}
+
+		abs := subBase.ResolveReference(ref)
+		ptr, external, rerr := b.resolve(abs)
+		if rerr != nil {
+			err = fmt.Errorf("jsonschema: unresolvable $ref %q: %v", sub.Ref, rerr)
+			return
+		}
+		if external && rootBase != nil && subBase.String() == rootBase.String() {
+			u := url.URL{Fragment: ptr}
+			sub.Ref = "#" + u.EscapedFragment()
+		}
+	})
+
+	return err
+}
+
+// resolve returns the JSON Pointer in the root of the schema identified by abs, and whether the schema is
+// in the bundled document. The document is bundled if it is not yet.
+func (b *bundler) resolve(abs *url.URL) (string, bool, error) {
+	key := abs.String()
+	frag := ""
+	if abs.Fragment == "" || strings.HasPrefix(abs.Fragment, "/") {
+		key = stripFragment(abs).String()
+		frag = abs.Fragment
+	}
+	if _, err := jsonpointer.Parse(frag); err != nil {
+		return "", false, err
+	}
+
+	if loc, ok := b.local[key]; ok {
+		return loc + frag, b.isBundled(loc), nil
+	}
+
+	res, _, err := b.reg.resolve(abs)
+	if err != nil {
+		return "", false, err
+	}
+	if err := b.bundle(res); err != nil {
+		return "", false, err
+	}
+	loc, ok := b.local[key]
+	if !ok {
+		return "", false, fmt.Errorf("unknown schema %q", abs)
+	}
+
+	return loc + frag, true, nil
+}
+
+// isBundled reports whether the loc JSON Pointer in the root is in the bundled documents.
+func (b *bundler) isBundled(loc string) bool {
+	for _, doc := range b.queue {
+		prefix := appendLocation("", b.defsKey, doc.name)
+		if loc == prefix || strings.HasPrefix(loc, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bundle copies the document of res into the root if it is not bundled yet.
+func (b *bundler) bundle(res *resource) error {
+	uri := res.docURI.String()
+	if b.docs[uri] {
+		return nil
+	}
+	b.docs[uri] = true
+
+	s := res.doc.clone()
+	// the "$id" makes the base URI of the copy same as the original.
+	s.ID = res.docURI.String()
+	if res.doc.ID != "" {
+		if id, err := url.Parse(res.doc.ID); err == nil {
+			s.ID = res.docURI.ResolveReference(id).String()
+		}
+	}
+
+	doc := &bundledDoc{name: b.defName(res.docURI), uri: res.docURI, schema: s}
+	b.queue = append(b.queue, doc)
+	prefix := appendLocation("", b.defsKey, doc.name)
+	b.local[uri] = prefix
+	b.index(s, &url.URL{}, prefix)
+
+	return nil
+}
+
+// defName returns the unused definition name derived from the last path segment of uri.
+func (b *bundler) defName(uri *url.URL) string {
+	name := strings.TrimSuffix(path.Base(uri.Path), path.Ext(uri.Path))
+	if name == "" || name == "." || name == "/" {
+		name = uri.Hostname()
+	}
+	if name == "" {
+		name = "schema"
+	}
+
+	cand := name
+	for i := 2; b.used[cand]; i++ {
+		cand = name + strconv.Itoa(i)
+	}
+	b.used[cand] = true
+
+	return cand
+}
+
+// clone returns the deep copy of s.
+//
+// The regular expressions are shared because they are immutable.
+func (s *Schema) clone() *Schema {
+	if s == nil {
+		return nil
+	}
+
+	c := *s
+	if s.Bool != nil {
+		b := *s.Bool
+		c.Bool = &b
+	}
+	c.Default = copyValue(s.Default)
+	if s.Examples != nil {
+		c.Examples = make(Interfaces, len(s.Examples))
+		for i, v := range s.Examples {
+			c.Examples[i] = copyValue(v)
+		}
+	}
+	c.Maximum = cloneFloat(s.Maximum)
+	c.ExclusiveMaximum = cloneFloat(s.ExclusiveMaximum)
+	c.Minimum = cloneFloat(s.Minimum)
+	c.ExclusiveMinimum = cloneFloat(s.ExclusiveMinimum)
+	c.MaxLength = cloneInt(s.MaxLength)
+	c.MaxItems = cloneInt(s.MaxItems)
+	c.MaxProperties = cloneInt(s.MaxProperties)
+	if s.AdditionalItems != nil {
+		c.AdditionalItems = &AdditionalItems{Schema: s.AdditionalItems.Schema.clone()}
+	}
+	if s.Items != nil {
+		c.Items = &Items{Schemas: s.Items.Schemas.clone(), HasMultiple: s.Items.HasMultiple}
+	}
+	if s.Contains != nil {
+		c.Contains = &AdditionalProperties{Schema: s.Contains.Schema.clone()}
+	}
+	if s.Required != nil {
+		c.Required = append(StringArray{}, s.Required...)
+	}
+	if s.AdditionalProperties != nil {
+		c.AdditionalProperties = &AdditionalProperties{Schema: s.AdditionalProperties.Schema.clone()}
+	}
+	c.Definitions = s.Definitions.clone()
+	c.Defs = s.Defs.clone()
+	if s.Properties != nil {
+		c.Properties = make(Properties, len(s.Properties))
+		for name, sub := range s.Properties {
+			c.Properties[name] = sub.clone()
+		}
+	}
+	if s.PatternProperties != nil {
+		c.PatternProperties = make(PatternProperties, len(s.PatternProperties))
+		for expr, pp := range s.PatternProperties {
+			if pp == nil {
+				c.PatternProperties[expr] = nil
+				continue
+			}
+			c.PatternProperties[expr] = &PatternProperty{Regexp: pp.Regexp, Schema: pp.Schema.clone()}
+		}
+	}
+	if s.Dependencies != nil {
+		c.Dependencies = &DependencyMap{}
+		if s.Dependencies.Names != nil {
+			c.Dependencies.Names = make(map[string][]string, len(s.Dependencies.Names))
+			for name, names := range s.Dependencies.Names {
+				c.Dependencies.Names[name] = append([]string(nil), names...)
+			}
+		}
+		if s.Dependencies.Schemas != nil {
+			c.Dependencies.Schemas = make(map[string]*Schema, len(s.Dependencies.Schemas))
+			for name, sub := range s.Dependencies.Schemas {
+				c.Dependencies.Schemas[name] = sub.clone()
+			}
+		}
+	}
+	c.PropertyNames = s.PropertyNames.clone()
+	c.Const = s.Const.clone()
+	if s.Enum != nil {
+		c.Enum = make(Enum, len(s.Enum))
+		for i, e := range s.Enum {
+			c.Enum[i] = e.clone()
+		}
+	}
+	if s.Type != nil {
+		c.Type = append(Types{}, s.Type...)
+	}
+	c.If = s.If.clone()
+	c.Then = s.Then.clone()
+	c.Else = s.Else.clone()
+	c.AllOf = s.AllOf.clone()
+	c.AnyOf = s.AnyOf.clone()
+	c.OneOf = s.OneOf.clone()
+	c.Not = s.Not.clone()
+
+	return &c
+}
+
+// clone returns the deep copy of l.
+func (l SchemaList) clone() SchemaList {
+	if l == nil {
+		return nil
+	}
+	c := make(SchemaList, len(l))
+	for i, s := range l {
+		c[i] = s.clone()
+	}
+
+	return c
+}
+
+// clone returns the deep copy of d.
+func (d Definitions) clone() Definitions {
+	if d == nil {
+		return nil
+	}
+	c := make(Definitions, len(d))
+	for name, s := range d {
+		c[name] = s.clone()
+	}
+
+	return c
+}
+
+// clone returns the deep copy of c.
+func (c *Const) clone() *Const {
+	if c == nil {
+		return nil
+	}
+	v := &Const{Type: c.Type}
+	if c.Value != nil {
+		v.Value = make([]interface{}, len(c.Value))
+		for i, x := range c.Value {
+			v.Value[i] = copyValue(x)
+		}
+	}
+
+	return v
+}
+
+// cloneFloat returns the copy of the pointer p to the number.
+func cloneFloat(p *float64) *float64 {
+	if p == nil {
+		return nil
+	}
+	v := *p
+
+	return &v
+}
+
+// cloneInt returns the copy of the pointer p to the integer.
+func cloneInt(p *int64) *int64 {
+	if p == nil {
+		return nil
+	}
+	v := *p
+
+	return &v
+}
@@ -0,0 +1,236 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// mustJSON returns the JSON value of s decoded into the interface{}, which is compared by reflect.DeepEqual.
+func mustJSON(tb testing.TB, s *Schema) interface{} {
+	tb.Helper()
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		tb.Fatal(err)
+	}
+
+	return v
+}
+
+func TestBundle(t *testing.T) {
+	tests := []struct {
+		name string
+		root string
+		docs map[string]string
+		want string
+	}{
+		{
+			name: "no external",
+			root: `{"properties": {"a": {"$ref": "#/definitions/a"}}, "definitions": {"a": {"type": "string"}}}`,
+			want: `{"properties": {"a": {"$ref": "#/definitions/a"}}, "definitions": {"a": {"type": "string"}}}`,
+		},
+		{
+			name: "external",
+			root: `{"properties": {"a": {"$ref": "item.json#/definitions/a"}, "b": {"$ref": "item.json"}}}`,
+			docs: map[string]string{
+				"http://example.com/item.json": `{"type": "object", "definitions": {"a": {"type": "integer"}}}`,
+			},
+			want: `{
+				"properties": {"a": {"$ref": "#/definitions/item/definitions/a"}, "b": {"$ref": "#/definitions/item"}},
+				"definitions": {"item": {"$id": "http://example.com/item.json", "type": "object", "definitions": {"a": {"type": "integer"}}}}
+			}`,
+		},
+		{
+			name: "name collision",
+			root: `{"properties": {"a": {"$ref": "a/item.json"}, "b": {"$ref": "b/item.json"}}, "definitions": {"item": {}}}`,
+			docs: map[string]string{
+				"http://example.com/a/item.json": `{"type": "string"}`,
+				"http://example.com/b/item.json": `{"type": "integer"}`,
+			},
+			want: `{
+				"properties": {"a": {"$ref": "#/definitions/item2"}, "b": {"$ref": "#/definitions/item3"}},
+				"definitions": {
+					"item": {},
+					"item2": {"$id": "http://example.com/a/item.json", "type": "string"},
+					"item3": {"$id": "http://example.com/b/item.json", "type": "integer"}
+				}
+			}`,
+		},
+		{
+			name: "2019-09",
+			root: `{"$schema": "https://json-schema.org/draft/2019-09/schema", "items": {"$ref": "item.json"}}`,
+			docs: map[string]string{
+				"http://example.com/item.json": `{"type": "string"}`,
+			},
+			want: `{
+				"$schema": "https://json-schema.org/draft/2019-09/schema",
+				"items": {"$ref": "#/$defs/item"},
+				"$defs": {"item": {"$id": "http://example.com/item.json", "type": "string"}}
+			}`,
+		},
+		{
+			name: "refs in the bundled documents",
+			root: `{"$ref": "a.json"}`,
+			docs: map[string]string{
+				"http://example.com/a.json": `{"items": {"$ref": "b.json"}}`,
+				"http://example.com/b.json": `{"type": "null"}`,
+			},
+			want: `{
+				"$ref": "#/definitions/a",
+				"definitions": {
+					"a": {"$id": "http://example.com/a.json", "items": {"$ref": "b.json"}},
+					"b": {"$id": "http://example.com/b.json", "type": "null"}
+				}
+			}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reg := NewRegistry()
+			for uri, doc := range tt.docs {
+				if err := reg.Add(uri, mustSchema(t, doc)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			root := mustSchema(t, tt.root)
+			if err := reg.Add("http://example.com/root.json", root); err != nil {
+				t.Fatal(err)
+			}
+			before := mustJSON(t, root)
+
+			s, err := Bundle(root, reg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want interface{}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if got := mustJSON(t, s); !reflect.DeepEqual(got, want) {
+				b, _ := json.Marshal(got)
+				t.Errorf("Bundle() = %s, want %s", b, tt.want)
+			}
+			if after := mustJSON(t, root); !reflect.DeepEqual(after, before) {
+				t.Errorf("Bundle() modified the root: %v, want %v", after, before)
+			}
+		})
+	}
+}
+
+func TestBundleError(t *testing.T) {
+	tests := []struct {
+		name    string
+		root    string
+		wantErr string
+	}{
+		{name: "unresolvable", root: `{"$ref": "missing.json"}`, wantErr: "unresolvable $ref"},
+		{name: "invalid pointer", root: `{"$ref": "#/definitions/~2"}`, wantErr: "unresolvable $ref"},
+		{name: "invalid URI", root: `{"$ref": "%zz"}`, wantErr: "invalid $ref"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reg := NewRegistry()
+			root := mustSchema(t, tt.root)
+			if err := reg.Add("http://example.com/root.json", root); err != nil {
+				t.Fatal(err)
+			}
+			if s, err := Bundle(root, reg); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Bundle() = %v, %v, want %q", s, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchemaClone(t *testing.T) {
+	s := mustSchema(t, `{
+		"$id": "http://example.com/root.json",
+		"title": "root",
+		"default": {"a": [1, {"b": null}]},
+		"examples": [[1], {"a": 2}],
+		"multipleOf": 2,
+		"maximum": 10,
+		"exclusiveMinimum": 0,
+		"maxLength": 3,
+		"pattern": "^a",
+		"additionalItems": false,
+		"items": [{"type": "string"}, true],
+		"maxItems": 4,
+		"contains": {"const": [1, {"a": 2}]},
+		"maxProperties": 5,
+		"required": ["a"],
+		"additionalProperties": {"not": {}},
+		"definitions": {"a": {"enum": [1, "b", [2], {"c": 3}]}},
+		"$defs": {"b": false},
+		"properties": {"a": {"$ref": "#/definitions/a"}},
+		"patternProperties": {"^x": {"type": "integer"}},
+		"dependencies": {"a": ["b"], "c": {"required": ["d"]}},
+		"propertyNames": {"maxLength": 2},
+		"type": ["object", "null"],
+		"format": "uri",
+		"if": {"minProperties": 1},
+		"then": {"maxProperties": 3},
+		"else": true,
+		"allOf": [{"minProperties": 0}],
+		"anyOf": [{"type": "object"}],
+		"oneOf": [{"type": "object"}, {"type": "null"}],
+		"not": {"type": "string"}
+	}`)
+	before := mustJSON(t, s)
+
+	c := s.clone()
+	if !reflect.DeepEqual(c, s) {
+		t.Fatalf("clone() = %+v, want %+v", c, s)
+	}
+
+	// modifies every part of the copy, which must not be shared with s.
+	c.Default.(map[string]interface{})["a"].([]interface{})[1].(map[string]interface{})["b"] = 1
+	c.Examples[0].([]interface{})[0] = 2
+	*c.Maximum = 0
+	*c.ExclusiveMinimum = 1
+	*c.MaxLength = 0
+	*c.AdditionalItems.Bool = true
+	c.Items.Schemas[0].Type = Types{IntegerType}
+	*c.MaxItems = 0
+	c.Contains.Const.Value[1].(map[string]interface{})["a"] = 3
+	*c.MaxProperties = 0
+	c.Required[0].Value = "b"
+	c.AdditionalProperties.Not.Type = Types{NullType}
+	c.Definitions["a"].Enum[3].Value[0].(map[string]interface{})["c"] = 4
+	*c.Defs["b"].Bool = true
+	c.Properties["a"].Ref = "#"
+	c.PatternProperties["^x"].Schema.Type = nil
+	c.Dependencies.Names["a"][0] = "c"
+	c.Dependencies.Schemas["c"].Required = nil
+	*c.PropertyNames.MaxLength = 0
+	c.Type[0] = StringType
+	c.If.MinProperties = 2
+	c.Then.MaxProperties = nil
+	*c.Else.Bool = false
+	c.AllOf[0].MinProperties = 1
+	c.AnyOf[0].Type = nil
+	c.OneOf[1].Type = nil
+	c.Not.Type = nil
+
+	if after := mustJSON(t, s); !reflect.DeepEqual(after, before) {
+		t.Errorf("modifying the clone changed the original: %v, want %v", after, before)
+	}
+	if (*Schema)(nil).clone() != nil {
+		t.Error("clone() of nil is not nil")
+	}
+}
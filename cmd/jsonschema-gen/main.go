@@ -0,0 +1,107 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command jsonschema-gen generates the Go types from the JSON Schema.
+//
+// Usage:
+//  jsonschema-gen [flags] [schema.json]
+//
+// The schema is read from the standard input if the file is not given.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+	"github.com/zchee/go-jsonschema/pkg/codegen"
+)
+
+// defaultBoilerplate is the boilerplate file used if it exists and the -boilerplate flag is not given.
+const defaultBoilerplate = "hack/boilerplate/boilerplate.go.txt"
+
+var (
+	flagOutput      = flag.String("o", "", "write the generated source to `file` instead of the standard output")
+	flagPackage     = flag.String("package", codegen.DefaultPackageName, "package `name` of the generated source")
+	flagRoot        = flag.String("root", "", "type `name` of the root schema (default derived from the title)")
+	flagGojay       = flag.Bool("gojay", false, "generate the gojay method set, the pool and the Stream type of the structs")
+	flagBoilerplate = flag.String("boilerplate", "", "header boilerplate `file`, whose YEAR is replaced by the current year (default "+defaultBoilerplate+" if exists)")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: jsonschema-gen [flags] [schema.json]\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "jsonschema-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	var data []byte
+	var err error
+	switch len(args) {
+	case 0:
+		data, err = ioutil.ReadAll(os.Stdin)
+	case 1:
+		data, err = ioutil.ReadFile(args[0])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		return err
+	}
+
+	var schema jsonschema.Schema
+	if err := schema.UnmarshalJSON(data); err != nil {
+		return fmt.Errorf("decode schema: %v", err)
+	}
+
+	opts := []codegen.Option{
+		codegen.WithPackageName(*flagPackage),
+		codegen.WithRootName(*flagRoot),
+	}
+	if *flagGojay {
+		opts = append(opts, codegen.WithGojay())
+	}
+	header, err := boilerplate(*flagBoilerplate)
+	if err != nil {
+		return err
+	}
+	if header != nil {
+		opts = append(opts, codegen.WithHeader(header))
+	}
+
+	src, err := codegen.Generate(&schema, opts...)
+	if err != nil {
+		return err
+	}
+
+	if *flagOutput == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+
+	return ioutil.WriteFile(*flagOutput, src, 0644)
+}
+
+// boilerplate returns the header boilerplate of path, or the defaultBoilerplate if path is empty.
+func boilerplate(path string) ([]byte, error) {
+	if path == "" {
+		if _, err := os.Stat(defaultBoilerplate); err != nil {
+			return nil, nil
+		}
+		path = defaultBoilerplate
+	}
+
+	return codegen.Boilerplate(path, time.Now().Year())
+}
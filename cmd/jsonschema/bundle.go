@@ -0,0 +1,138 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+)
+
+func runBundle(fs *flag.FlagSet, args []string) int {
+	output := fs.String("o", "", "write the bundled schema to `file` instead of the standard output")
+	if code, exit := parseFlags(fs, args); exit {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	s, err := loadSchema(fs.Arg(0))
+	if err != nil {
+		errorf("bundle", "%v", err)
+		return exitError
+	}
+	data, err := s.MarshalJSON()
+	if err != nil {
+		errorf("bundle", "%v", err)
+		return exitError
+	}
+	data, err = formatSchema(data)
+	if err != nil {
+		errorf("bundle", "%v", err)
+		return exitError
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = ioutil.WriteFile(*output, data, 0644)
+	}
+	if err != nil {
+		errorf("bundle", "%v", err)
+		return exitError
+	}
+
+	return exitValid
+}
+
+// readSchema reads and decodes the schema file of name.
+func readSchema(name string) (*jsonschema.Schema, error) {
+	data, err := readInput(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSchema(name, data)
+}
+
+// decodeSchema decodes the schema data of the file name.
+func decodeSchema(name string, data []byte) (*jsonschema.Schema, error) {
+	s := &jsonschema.Schema{}
+	if err := s.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("%s: decode schema: %v", name, err)
+	}
+
+	return s, nil
+}
+
+// loadSchema reads the schema file of name, and inlines the schema files referenced by its "$ref"s
+// into its "definitions".
+func loadSchema(name string) (*jsonschema.Schema, error) {
+	data, err := readInput(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return bundleSchema(name, data)
+}
+
+// bundleSchema decodes the schema data of the file name, and inlines the schemas referenced by its external "$ref"s
+// into its "definitions".
+//
+// The "$ref"s are resolved against the "$id" of the schema, or the path of the file, and only the local files are loaded.
+func bundleSchema(name string, data []byte) (*jsonschema.Schema, error) {
+	root, err := decodeSchema(name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	path := name
+	if name == "-" {
+		path = "stdin"
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reg := jsonschema.NewRegistry()
+	reg.SetLoader(loadFile)
+	if err := reg.Add(fileURI(path), root); err != nil {
+		return nil, err
+	}
+	s, err := jsonschema.Bundle(root, reg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return s, nil
+}
+
+// fileURI returns the file URI of the absolute path.
+func fileURI(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+
+	return u.String()
+}
+
+// loadFile loads the schema file of the file uri.
+func loadFile(uri string) (*jsonschema.Schema, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") {
+		return nil, fmt.Errorf("only local files are supported")
+	}
+
+	return readSchema(filepath.FromSlash(u.Path))
+}
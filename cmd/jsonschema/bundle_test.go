@@ -0,0 +1,54 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+)
+
+func TestLoadSchema(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"schema.json": `{"properties": {"a": {"$ref": "item.json"}, "b": {"$ref": "defs.json#/definitions/b"}}}`,
+		"item.json":   `{"type": "object", "properties": {"next": {"$ref": "item.json"}}}`,
+		"defs.json":   `{"definitions": {"b": {"type": "integer"}}}`,
+	})
+
+	s, err := loadSchema(filepath.Join(dir, "schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Definitions) != 2 {
+		t.Errorf("loadSchema() definitions = %v, want item and defs", s.Definitions)
+	}
+	if ref := s.Properties["b"].Ref; !strings.HasPrefix(ref, "#/definitions/") {
+		t.Errorf("loadSchema() $ref = %q, want the local JSON Pointer", ref)
+	}
+
+	v := jsonschema.MustCompile(s)
+	tests := []struct {
+		inst  interface{}
+		valid bool
+	}{
+		{inst: map[string]interface{}{"a": map[string]interface{}{"next": map[string]interface{}{}}, "b": 1.0}, valid: true},
+		{inst: map[string]interface{}{"a": map[string]interface{}{"next": 1.0}}},
+		{inst: map[string]interface{}{"b": "1"}},
+	}
+	for _, tt := range tests {
+		if got := v.IsValid(tt.inst); got != tt.valid {
+			t.Errorf("IsValid(%v) = %t, want %t", tt.inst, got, tt.valid)
+		}
+	}
+
+	if _, err := loadSchema(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("loadSchema(missing.json) error = nil")
+	}
+	if _, err := loadFile("http://example.com/schema.json"); err == nil || !strings.Contains(err.Error(), "only local files") {
+		t.Errorf("loadFile(http) error = %v, want only local files", err)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+	"github.com/zchee/go-jsonschema/api"
+)
+
+// metaSchemas is the map of the "$schema" URI without the empty fragment to the meta-schema file in api.FS.
+//
+// The draft 2019-09 meta-schema is the one which the vocabulary meta-schemas are expanded into.
+var metaSchemas = map[string]string{
+	"http://json-schema.org/draft-04/schema":       "draft-04/schema.json",
+	"http://json-schema.org/draft-06/schema":       "draft-06/schema.json",
+	"http://json-schema.org/draft-07/schema":       "draft-07/schema.json",
+	"https://json-schema.org/draft/2019-09/schema": "2019-09/schema-expand.json",
+}
+
+// recursiveRef is the "$recursiveRef" of the draft 2019-09 meta-schema, which always refers to the meta-schema itself
+// because its root has the "$recursiveAnchor".
+var recursiveRef = []byte(`"$recursiveRef": "#"`)
+
+// metaValidators caches the compiled meta-schemas by the "$schema" URI.
+var metaValidators = make(map[string]*jsonschema.Validator)
+
+// metaValidator returns the Validator of the meta-schema of the "$schema" URI, which is draft-07 if uri is empty.
+func metaValidator(uri string) (*jsonschema.Validator, error) {
+	if uri == "" {
+		uri = jsonschema.Draft7SchemaURL
+	}
+	uri = strings.TrimSuffix(uri, "#")
+	if v, ok := metaValidators[uri]; ok {
+		return v, nil
+	}
+
+	name, ok := metaSchemas[uri]
+	if !ok {
+		return nil, fmt.Errorf("unsupported $schema %q", uri)
+	}
+	src, err := api.FS.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	// the "$recursiveRef" is not supported, so it is replaced with the "$ref" to the meta-schema.
+	src = bytes.ReplaceAll(src, recursiveRef, []byte(`"$ref": "`+uri+`"`))
+
+	var s jsonschema.Schema
+	if err := s.UnmarshalJSON(src); err != nil {
+		return nil, fmt.Errorf("decode meta-schema of %q: %v", uri, err)
+	}
+	v, err := jsonschema.Compile(&s)
+	if err != nil {
+		return nil, fmt.Errorf("compile meta-schema of %q: %v", uri, err)
+	}
+	metaValidators[uri] = v
+
+	return v, nil
+}
+
+func runCheck(fs *flag.FlagSet, args []string) int {
+	output := fs.String("output", outputText, "output `format` of the errors: "+strings.Join(outputFormats, ", "))
+	if code, exit := parseFlags(fs, args); exit {
+		return code
+	}
+
+	rp, err := newReporter(os.Stdout, *output)
+	if err != nil {
+		errorf("check", "%v", err)
+		return exitUsage
+	}
+	names, err := expandInputs(fs.Args())
+	if err != nil {
+		errorf("check", "%v", err)
+		return exitUsage
+	}
+
+	for _, name := range names {
+		if err := rp.report(&result{name: name, err: checkSchema(name)}); err != nil {
+			errorf("check", "%v", err)
+			return exitError
+		}
+	}
+
+	return rp.code
+}
+
+// checkSchema validates the schema file of name against its meta-schema, and checks whether it can be compiled.
+//
+// checkSchema returns jsonschema.ValidationErrors if the schema is invalid.
+func checkSchema(name string) error {
+	data, err := readInput(name)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var inst interface{}
+	if err := dec.Decode(&inst); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("invalid character after the schema")
+	}
+
+	var uri string
+	if obj, ok := inst.(map[string]interface{}); ok {
+		if s, ok := obj["$schema"].(string); ok {
+			uri = s
+		}
+	}
+	meta, err := metaValidator(uri)
+	if err != nil {
+		return err
+	}
+	if err := meta.Validate(inst); err != nil {
+		return err
+	}
+
+	s, err := bundleSchema(name, data)
+	if err != nil {
+		return invalidSchema(err)
+	}
+	if _, err := jsonschema.Compile(s); err != nil {
+		return invalidSchema(err)
+	}
+
+	return nil
+}
+
+// invalidSchema returns the jsonschema.ValidationErrors of err which makes the schema unusable.
+func invalidSchema(err error) jsonschema.ValidationErrors {
+	e := &jsonschema.ValidationError{Message: err.Error()}
+	if se, ok := err.(*jsonschema.SchemaError); ok {
+		e.InstanceLocation = se.Location
+		e.Message = se.Err.Error()
+	}
+
+	return jsonschema.ValidationErrors{e}
+}
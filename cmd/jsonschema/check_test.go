@@ -0,0 +1,179 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes the files to the temporary directory, and returns the path of the directory.
+func writeFiles(tb testing.TB, files map[string]string) string {
+	tb.Helper()
+
+	dir := tb.TempDir()
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestMetaValidator(t *testing.T) {
+	for uri := range metaSchemas {
+		v, err := metaValidator(uri + "#")
+		if err != nil {
+			t.Fatalf("metaValidator(%q) error = %v", uri, err)
+		}
+		if !v.IsValid(map[string]interface{}{"$schema": uri}) {
+			t.Errorf("metaValidator(%q) rejects the empty schema", uri)
+		}
+	}
+
+	if _, err := metaValidator("http://example.com/schema"); err == nil || !strings.Contains(err.Error(), "unsupported $schema") {
+		t.Errorf("metaValidator() error = %v, want unsupported $schema", err)
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    int
+		wantErr string
+	}{
+		{
+			name:  "draft-07",
+			files: map[string]string{"schema.json": `{"type": "object", "properties": {"a": {"type": "string"}}}`},
+			want:  exitValid,
+		},
+		{
+			name:    "draft-07 invalid",
+			files:   map[string]string{"schema.json": `{"type": "object", "properties": {"a": {"type": "foo"}}}`},
+			want:    exitInvalid,
+			wantErr: "/properties/a/type",
+		},
+		{
+			name:  "draft-04 boolean exclusiveMinimum",
+			files: map[string]string{"schema.json": `{"$schema": "http://json-schema.org/draft-04/schema#", "minimum": 1, "exclusiveMinimum": true}`},
+			want:  exitValid,
+		},
+		{
+			name:  "draft-04",
+			files: map[string]string{"schema.json": `{"$schema": "http://json-schema.org/draft-04/schema#", "id": "http://example.com/a.json", "minimum": 1}`},
+			want:  exitValid,
+		},
+		{
+			name:    "draft-04 zero multipleOf",
+			files:   map[string]string{"schema.json": `{"$schema": "http://json-schema.org/draft-04/schema#", "multipleOf": 0}`},
+			want:    exitInvalid,
+			wantErr: "/multipleOf",
+		},
+		{
+			name:    "draft-06 boolean exclusiveMinimum",
+			files:   map[string]string{"schema.json": `{"$schema": "http://json-schema.org/draft-06/schema#", "exclusiveMinimum": true}`},
+			want:    exitInvalid,
+			wantErr: "/exclusiveMinimum",
+		},
+		{
+			name:  "2019-09",
+			files: map[string]string{"schema.json": `{"$schema": "https://json-schema.org/draft/2019-09/schema", "$defs": {"a": {"minimum": 1}}, "$ref": "#/$defs/a"}`},
+			want:  exitValid,
+		},
+		{
+			name:    "2019-09 invalid nested",
+			files:   map[string]string{"schema.json": `{"$schema": "https://json-schema.org/draft/2019-09/schema", "$defs": {"a": {"not": {"minimum": "x"}}}}`},
+			want:    exitInvalid,
+			wantErr: "/$defs/a/not/minimum",
+		},
+		{
+			name:    "unsupported $schema",
+			files:   map[string]string{"schema.json": `{"$schema": "http://example.com/schema"}`},
+			want:    exitError,
+			wantErr: "unsupported $schema",
+		},
+		{
+			name:    "trailing data",
+			files:   map[string]string{"schema.json": `{} {}`},
+			want:    exitError,
+			wantErr: "invalid character after the schema",
+		},
+		{
+			name: "external $ref",
+			files: map[string]string{
+				"schema.json": `{"properties": {"a": {"$ref": "defs.json#/definitions/a"}}}`,
+				"defs.json":   `{"definitions": {"a": {"type": "string"}}}`,
+			},
+			want: exitValid,
+		},
+		{
+			name:    "missing $ref",
+			files:   map[string]string{"schema.json": `{"properties": {"a": {"$ref": "#/definitions/a"}}}`},
+			want:    exitInvalid,
+			wantErr: "#/definitions/a",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := writeFiles(t, tt.files)
+			r := &result{name: filepath.Join(dir, "schema.json")}
+			r.err = checkSchema(r.name)
+			if got := r.code(); got != tt.want {
+				t.Errorf("checkSchema() = %v, want the exit code %d", r.err, tt.want)
+			}
+			if tt.wantErr != "" && (r.err == nil || !strings.Contains(r.err.Error(), tt.wantErr)) {
+				t.Errorf("checkSchema() = %v, want %q", r.err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReporter(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"schema.json": `{"type": "object", "properties": {"a": {"type": "foo"}}}`})
+	name := filepath.Join(dir, "schema.json")
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: outputText, want: name + ": /properties/a/type: "},
+		{format: outputJSON, want: `{"name":"` + name + `","valid":false,"errors":[{"keywordLocation":`},
+		{format: outputFlag, want: `{"valid":false}` + "\n"},
+		{format: outputBasic, want: `{"valid":false,"errors":[{"keywordLocation":"#/properties/properties/additionalProperties`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.format, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			rp, err := newReporter(&buf, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := rp.report(&result{name: name, err: checkSchema(name)}); err != nil {
+				t.Fatal(err)
+			}
+			if rp.code != exitInvalid {
+				t.Errorf("code = %d, want %d", rp.code, exitInvalid)
+			}
+			if got := buf.String(); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("report() = %s, want the prefix %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := newReporter(ioutil.Discard, "xml"); err == nil {
+		t.Error("newReporter(xml) error = nil")
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/francoispqt/gojay"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+)
+
+// The list of the sides of the -breaking flag.
+const (
+	breakingAny       = "any"
+	breakingProducers = "producers"
+	breakingConsumers = "consumers"
+)
+
+func runDiff(fs *flag.FlagSet, args []string) int {
+	output := fs.String("output", outputText, "output `format` of the changes: "+outputText+", "+outputJSON)
+	breaking := fs.String("breaking", breakingAny, "exit with 1 on the changes breaking the `side`: "+
+		breakingProducers+", "+breakingConsumers+", or "+breakingAny)
+	if code, exit := parseFlags(fs, args); exit {
+		return code
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	var breaks func(c *jsonschema.Change) bool
+	switch *breaking {
+	case breakingAny:
+		breaks = (*jsonschema.Change).Breaking
+	case breakingProducers:
+		breaks = func(c *jsonschema.Change) bool { return c.BreaksProducers }
+	case breakingConsumers:
+		breaks = func(c *jsonschema.Change) bool { return c.BreaksConsumers }
+	default:
+		errorf("diff", "unknown side %q, must be one of %q", *breaking, []string{breakingProducers, breakingConsumers, breakingAny})
+		return exitUsage
+	}
+	if *output != outputText && *output != outputJSON {
+		errorf("diff", "unknown output format %q, must be one of %q", *output, []string{outputText, outputJSON})
+		return exitUsage
+	}
+
+	old, err := loadSchema(fs.Arg(0))
+	if err != nil {
+		errorf("diff", "%v", err)
+		return exitError
+	}
+	new, err := loadSchema(fs.Arg(1))
+	if err != nil {
+		errorf("diff", "%v", err)
+		return exitError
+	}
+	changes, err := jsonschema.Diff(old, new)
+	if err != nil {
+		errorf("diff", "%v", err)
+		return exitError
+	}
+
+	code := exitValid
+	for _, c := range changes {
+		if breaks(c) {
+			code = exitInvalid
+		}
+		if err := writeChange(*output, c); err != nil {
+			errorf("diff", "%v", err)
+			return exitError
+		}
+	}
+
+	return code
+}
+
+// writeChange writes c to the standard output in the output format.
+func writeChange(output string, c *jsonschema.Change) error {
+	if output == outputText {
+		_, err := fmt.Println(c)
+		return err
+	}
+
+	b, err := gojay.MarshalJSONObject(c)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(append(b, '\n'))
+
+	return err
+}
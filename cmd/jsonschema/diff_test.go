@@ -0,0 +1,106 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunDiff is not parallel, because it replaces the standard output and the standard error.
+func TestRunDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		args    []string
+		want    int
+		wantOut string
+	}{
+		{
+			name:    "compatible",
+			old:     `{"type": "string", "description": "a"}`,
+			new:     `{"type": "string", "description": "b"}`,
+			want:    exitValid,
+			wantOut: "/description: description changed (compatible)",
+		},
+		{
+			name:    "breaking",
+			old:     `{"properties": {"a": {"type": "string"}}}`,
+			new:     `{"properties": {"a": {"type": "string"}}, "additionalProperties": false}`,
+			want:    exitInvalid,
+			wantOut: "/additionalProperties: additional properties are no longer allowed (breaking for producers)",
+		},
+		{
+			name:    "breaking for the other side",
+			old:     `{"maximum": 5}`,
+			new:     `{"maximum": 4}`,
+			args:    []string{"-breaking", breakingConsumers},
+			want:    exitValid,
+			wantOut: "/maximum: maximum decreased from 5 to 4 (breaking for producers)",
+		},
+		{
+			name:    "json",
+			old:     `{"minimum": 1}`,
+			new:     `{"exclusiveMinimum": 1}`,
+			args:    []string{"-output", outputJSON},
+			want:    exitInvalid,
+			wantOut: `{"keywordLocation":"/exclusiveMinimum","keyword":"exclusiveMinimum",`,
+		},
+		{
+			name: "missing file",
+			old:  `{}`,
+			want: exitError,
+		},
+		{
+			name: "unresolvable $ref",
+			old:  `{}`,
+			new:  `{"$ref": "#/definitions/a"}`,
+			want: exitError,
+		},
+		{
+			name: "unknown side",
+			old:  `{}`,
+			new:  `{}`,
+			args: []string{"-breaking", "both"},
+			want: exitUsage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{"old.json": tt.old}
+			if tt.new != "" {
+				files["new.json"] = tt.new
+			}
+			dir := writeFiles(t, files)
+
+			stdout, stderr := os.Stdout, os.Stderr
+			out, err := os.Create(filepath.Join(dir, "stdout"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer out.Close()
+			os.Stdout, os.Stderr = out, out
+			args := append(append([]string{}, tt.args...), filepath.Join(dir, "old.json"), filepath.Join(dir, "new.json"))
+			code := runDiff(flag.NewFlagSet("jsonschema diff", flag.ContinueOnError), args)
+			os.Stdout, os.Stderr = stdout, stderr
+
+			b, err := ioutil.ReadFile(out.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code != tt.want {
+				t.Errorf("runDiff() = %d, want %d: %s", code, tt.want, b)
+			}
+			if !strings.Contains(string(b), tt.wantOut) {
+				t.Errorf("runDiff() output = %s, want %q", b, tt.wantOut)
+			}
+		})
+	}
+}
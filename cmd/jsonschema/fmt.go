@@ -0,0 +1,283 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+)
+
+// fmtIndent is the indentation of the formatted schema.
+const fmtIndent = "  "
+
+// keywordOrder is the order of the keywords in the formatted schema, which is the order of the Schema encoding.
+//
+// The unknown keywords follow the known keywords in the lexical order.
+var keywordOrder = []string{
+	"$schema", "$id", "title", "$ref", "$comment", "description", "default", "readOnly", "writeOnly", "examples",
+	"multipleOf", "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum", "maxLength", "minLength", "pattern",
+	"additionalItems", "items", "maxItems", "minItems", "uniqueItems", "contains",
+	"maxProperties", "minProperties", "required", "additionalProperties", "definitions", "$defs", "properties", "patternProperties",
+	"dependencies", "propertyNames", "const", "enum", "type", "format", "contentMediaType", "contentEncoding",
+	"if", "then", "else", "allOf", "anyOf", "oneOf", "not",
+}
+
+// keywordRanks is the map of the keyword to its index of the keywordOrder.
+var keywordRanks = func() map[string]int {
+	m := make(map[string]int, len(keywordOrder))
+	for i, k := range keywordOrder {
+		m[k] = i
+	}
+	return m
+}()
+
+// valueKind represents a kind of the JSON value in the schema, which decides the order of the object keys.
+type valueKind int
+
+// The list of valueKind.
+const (
+	// plainValue is the value which is not a schema, whose object keys are sorted.
+	plainValue valueKind = iota
+
+	// schemaValue is the schema, whose keywords are ordered by the keywordOrder.
+	schemaValue
+
+	// schemaMapValue is the object of the schemas, such as the "properties".
+	schemaMapValue
+
+	// schemaListValue is the schema or the array of the schemas, such as the "items" and the "allOf".
+	schemaListValue
+
+	// dependencyMapValue is the object of the schemas or the property name arrays of the "dependencies".
+	dependencyMapValue
+)
+
+// keywordKinds is the map of the keyword to the kind of its value.
+var keywordKinds = map[string]valueKind{
+	"additionalItems":      schemaValue,
+	"items":                schemaListValue,
+	"contains":             schemaValue,
+	"additionalProperties": schemaValue,
+	"definitions":          schemaMapValue,
+	"$defs":                schemaMapValue,
+	"properties":           schemaMapValue,
+	"patternProperties":    schemaMapValue,
+	"dependencies":         dependencyMapValue,
+	"propertyNames":        schemaValue,
+	"if":                   schemaValue,
+	"then":                 schemaValue,
+	"else":                 schemaValue,
+	"allOf":                schemaListValue,
+	"anyOf":                schemaListValue,
+	"oneOf":                schemaListValue,
+	"not":                  schemaValue,
+}
+
+func runFmt(fs *flag.FlagSet, args []string) int {
+	write := fs.Bool("w", false, "write the result to the source file instead of the standard output")
+	list := fs.Bool("l", false, "list the files whose formatting differs from the result, and exit with 1 if any")
+	if code, exit := parseFlags(fs, args); exit {
+		return code
+	}
+
+	names, err := expandInputs(fs.Args())
+	if err != nil {
+		errorf("fmt", "%v", err)
+		return exitUsage
+	}
+
+	code := exitValid
+	for _, name := range names {
+		if name == "-" && *write {
+			errorf("fmt", "cannot write the result to the standard input")
+			return exitUsage
+		}
+
+		data, err := readInput(name)
+		if err != nil {
+			errorf("fmt", "%v", err)
+			code = exitError
+			continue
+		}
+		out, err := formatSchema(data)
+		if err != nil {
+			errorf("fmt", "%s: %v", name, err)
+			code = exitError
+			continue
+		}
+
+		changed := !bytes.Equal(data, out)
+		if *list && changed {
+			fmt.Println(name)
+			if code == exitValid {
+				code = exitInvalid
+			}
+		}
+		if *write && changed {
+			if err := ioutil.WriteFile(name, out, 0644); err != nil {
+				errorf("fmt", "%v", err)
+				code = exitError
+			}
+		}
+		if !*list && !*write {
+			os.Stdout.Write(out)
+		}
+	}
+
+	return code
+}
+
+// formatSchema returns the canonically formatted schema of data.
+//
+// The keywords are ordered as the Schema encoding, and the other object keys are sorted.
+// The objects and arrays are indented by the fmtIndent, and the numbers are kept as they are.
+func formatSchema(data []byte) ([]byte, error) {
+	var s jsonschema.Schema
+	if err := s.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("decode schema: %v", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid character after the schema")
+	}
+
+	var buf bytes.Buffer
+	if err := writeValue(&buf, v, schemaValue, ""); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+// writeValue writes the JSON value v of the kind to buf, whose nested lines are prefixed by indent.
+func writeValue(buf *bytes.Buffer, v interface{}, kind valueKind, indent string) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		keys, kinds := objectKeys(v, kind)
+		buf.WriteString("{\n")
+		for i, k := range keys {
+			buf.WriteString(indent + fmtIndent)
+			if err := writeString(buf, k); err != nil {
+				return err
+			}
+			buf.WriteString(": ")
+			if err := writeValue(buf, v[k], kinds[i], indent+fmtIndent); err != nil {
+				return err
+			}
+			if i < len(keys)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "}")
+
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		elem := plainValue
+		if kind == schemaListValue {
+			elem = schemaValue
+		}
+		buf.WriteString("[\n")
+		for i, x := range v {
+			buf.WriteString(indent + fmtIndent)
+			if err := writeValue(buf, x, elem, indent+fmtIndent); err != nil {
+				return err
+			}
+			if i < len(v)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "]")
+
+	case string:
+		return writeString(buf, v)
+
+	case json.Number:
+		buf.WriteString(v.String())
+
+	case bool:
+		fmt.Fprint(buf, v)
+
+	case nil:
+		buf.WriteString("null")
+	}
+
+	return nil
+}
+
+// objectKeys returns the ordered keys of the object v of the kind, and the kinds of their values.
+func objectKeys(v map[string]interface{}, kind valueKind) ([]string, []valueKind) {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kinds := make([]valueKind, len(keys))
+	switch kind {
+	case schemaValue, schemaListValue:
+		sort.SliceStable(keys, func(i, j int) bool {
+			ri, oki := keywordRanks[keys[i]]
+			rj, okj := keywordRanks[keys[j]]
+			if oki != okj {
+				return oki
+			}
+			return oki && ri < rj
+		})
+		for i, k := range keys {
+			kinds[i] = keywordKinds[k]
+		}
+
+	case schemaMapValue:
+		for i := range kinds {
+			kinds[i] = schemaValue
+		}
+
+	case dependencyMapValue:
+		for i, k := range keys {
+			if _, ok := v[k].(map[string]interface{}); ok {
+				kinds[i] = schemaValue
+			}
+		}
+	}
+
+	return keys, kinds
+}
+
+// writeString writes the JSON string of s to buf without escaping the HTML characters.
+func writeString(buf *bytes.Buffer, s string) error {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	buf.WriteString(strings.TrimSuffix(b.String(), "\n"))
+
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// expandInputs expands the glob patterns of args into the file names.
+//
+// The "-" is the standard input, which is also returned if args is empty.
+func expandInputs(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{"-"}, nil
+	}
+
+	var names []string
+	for _, arg := range args {
+		if arg == "-" || !strings.ContainsAny(arg, `*?[\`) {
+			names = append(names, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %q", arg)
+		}
+		names = append(names, matches...)
+	}
+
+	return names, nil
+}
+
+// openInput opens the file of name, or returns the standard input if name is "-".
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+
+	return os.Open(name)
+}
+
+// readInput reads the file of name, or the standard input if name is "-".
+func readInput(name string) ([]byte, error) {
+	if name == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+
+	return ioutil.ReadFile(name)
+}
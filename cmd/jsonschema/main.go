@@ -0,0 +1,127 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command jsonschema validates the JSON instances and the JSON Schemas.
+//
+// Usage:
+//  jsonschema validate [flags] -schema schema.json [instance.json ...]
+//  jsonschema check [flags] [schema.json ...]
+//  jsonschema bundle [flags] schema.json
+//  jsonschema fmt [flags] [schema.json ...]
+//  jsonschema simplify [flags] [schema.json ...]
+//  jsonschema diff [flags] old.json new.json
+//
+// The validate command validates the instances against the schema. The instances are the files,
+// the glob patterns of the files, or "-" for the standard input, which is also read if no instance is given.
+// The -ndjson flag validates each line of the instances as the newline-delimited JSON.
+//
+// The check command validates the schemas against the meta-schema of their "$schema", which is draft-07
+// if omitted, and checks whether the schemas can be compiled.
+//
+// The bundle command inlines the schemas of the external "$ref"s into the "definitions" of the schema.
+//
+// The fmt command reformats the schemas canonically.
+//
+// The simplify command reports the schema locations which can be simplified, such as the "anyOf" branches
+// which accept nothing, as the validation errors of the schemas and the files referenced by their external "$ref"s.
+// The -o flag writes the simplified schema, which is bundled if it has the external "$ref"s.
+//
+// The diff command reports the changes from the old schema to the new schema, and whether they break
+// the producers or the consumers of the instances. The -breaking flag selects which breaking changes
+// make the exit code 1.
+//
+// The validation errors and the simplifications are reported in the format of the -output flag,
+// which is "text", "json", or the standard output format "flag" or "basic".
+//
+// The exit code is 0 if all the instances or the schemas are valid, 1 if some of them are invalid,
+// can be simplified, or have the breaking changes, 2 if the command line is invalid, and 3 if the files
+// cannot be read or decoded.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// The list of exit codes.
+const (
+	exitValid   = 0
+	exitInvalid = 1
+	exitUsage   = 2
+	exitError   = 3
+)
+
+// command represents a subcommand of the jsonschema.
+type command struct {
+	name  string
+	usage string
+	run   func(fs *flag.FlagSet, args []string) int
+}
+
+var commands = []*command{
+	{name: "validate", usage: "validate [flags] -schema schema.json [instance.json ...]", run: runValidate},
+	{name: "check", usage: "check [flags] [schema.json ...]", run: runCheck},
+	{name: "bundle", usage: "bundle [flags] schema.json", run: runBundle},
+	{name: "fmt", usage: "fmt [flags] [schema.json ...]", run: runFmt},
+	{name: "simplify", usage: "simplify [flags] [schema.json ...]", run: runSimplify},
+	{name: "diff", usage: "diff [flags] old.json new.json", run: runDiff},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitUsage
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		fs := flag.NewFlagSet("jsonschema "+cmd.name, flag.ContinueOnError)
+		fs.Usage = func() {
+			fmt.Fprintf(fs.Output(), "usage: jsonschema %s\n\n", cmd.usage)
+			fs.PrintDefaults()
+		}
+		return cmd.run(fs, args[1:])
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage()
+		return exitValid
+	}
+	fmt.Fprintf(os.Stderr, "jsonschema: unknown command %q\n", args[0])
+	usage()
+
+	return exitUsage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "\tjsonschema %s\n", cmd.usage)
+	}
+}
+
+// parseFlags parses args by fs, and returns the exit code if the command should exit.
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitValid, true
+		}
+		return exitUsage, true
+	}
+
+	return 0, false
+}
+
+// errorf prints the error message of the command to the standard error.
+func errorf(cmd, format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "jsonschema %s: %s\n", cmd, fmt.Sprintf(format, args...))
+}
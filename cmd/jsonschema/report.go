@@ -0,0 +1,243 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/francoispqt/gojay"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+)
+
+// The list of output formats.
+const (
+	// outputText writes the validation errors as the lines of the text.
+	outputText = "text"
+
+	// outputJSON writes the results as the newline-delimited JSON objects.
+	outputJSON = "json"
+
+	// outputFlag writes the results in the "flag" standard output format.
+	outputFlag = "flag"
+
+	// outputBasic writes the results in the "basic" standard output format.
+	outputBasic = "basic"
+)
+
+// outputFormats is the list of the supported output formats.
+var outputFormats = []string{outputText, outputJSON, outputFlag, outputBasic}
+
+// result represents a validation result of an instance or a schema.
+type result struct {
+	// name is the file name, or "-" for the standard input.
+	name string
+
+	// line is the 1-based line number of the newline-delimited JSON instance, or 0 if the whole file is the instance.
+	line int
+
+	// err is nil if valid, jsonschema.ValidationErrors if invalid, or the other error if the file cannot be read or decoded.
+	err error
+}
+
+// location returns the printable location of the instance.
+func (r *result) location() string {
+	if r.line == 0 {
+		return r.name
+	}
+
+	return r.name + ":" + strconv.Itoa(r.line)
+}
+
+// code returns the exit code of r.
+func (r *result) code() int {
+	switch r.err.(type) {
+	case nil:
+		return exitValid
+	case jsonschema.ValidationErrors:
+		return exitInvalid
+	}
+
+	return exitError
+}
+
+// reporter writes the results in the output format, and keeps the exit code of the worst result.
+type reporter struct {
+	w      io.Writer
+	format string
+	code   int
+}
+
+// newReporter returns the reporter which writes the results in the format to w.
+func newReporter(w io.Writer, format string) (*reporter, error) {
+	for _, f := range outputFormats {
+		if f == format {
+			return &reporter{w: w, format: format}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown output format %q, must be one of %q", format, outputFormats)
+}
+
+// report writes r.
+func (rp *reporter) report(r *result) error {
+	if c := r.code(); c > rp.code {
+		rp.code = c
+	}
+
+	switch rp.format {
+	case outputText:
+		switch err := r.err.(type) {
+		case nil:
+		case jsonschema.ValidationErrors:
+			for _, e := range err {
+				if _, err := fmt.Fprintf(rp.w, "%s: %v\n", r.location(), e); err != nil {
+					return err
+				}
+			}
+		default:
+			if _, err := fmt.Fprintf(rp.w, "%s: %v\n", r.location(), err); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case outputJSON:
+		return rp.writeObject((*jsonResult)(r))
+
+	case outputFlag:
+		return rp.writeObject((*flagResult)(r))
+
+	default:
+		return rp.writeObject((*basicResult)(r))
+	}
+}
+
+// writeObject writes o as a line of the JSON object.
+func (rp *reporter) writeObject(o gojay.MarshalerJSONObject) error {
+	b, err := gojay.MarshalJSONObject(o)
+	if err != nil {
+		return err
+	}
+	_, err = rp.w.Write(append(b, '\n'))
+
+	return err
+}
+
+// jsonResult represents a result in the json output format.
+type jsonResult result
+
+// MarshalJSONObject implements gojay.MarshalerJSONObject.
+func (r *jsonResult) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey("name", r.name)
+	enc.IntKeyOmitEmpty("line", r.line)
+	enc.BoolKey("valid", r.err == nil)
+	switch err := r.err.(type) {
+	case nil:
+	case jsonschema.ValidationErrors:
+		enc.ArrayKey("errors", err)
+	default:
+		enc.StringKey("error", err.Error())
+	}
+}
+
+// IsNil implements gojay.MarshalerJSONObject.
+//
+// IsNil checks if instance is nil.
+func (r *jsonResult) IsNil() bool {
+	return r == nil
+}
+
+// flagResult represents a result in the "flag" standard output format.
+type flagResult result
+
+// MarshalJSONObject implements gojay.MarshalerJSONObject.
+func (r *flagResult) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.BoolKey("valid", r.err == nil)
+}
+
+// IsNil implements gojay.MarshalerJSONObject.
+//
+// IsNil checks if instance is nil.
+func (r *flagResult) IsNil() bool {
+	return r == nil
+}
+
+// basicResult represents a result in the "basic" standard output format.
+//
+// The error which is not the validation error is reported as the error of the root location.
+type basicResult result
+
+// MarshalJSONObject implements gojay.MarshalerJSONObject.
+func (r *basicResult) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.BoolKey("valid", r.err == nil)
+	if r.err == nil {
+		return
+	}
+
+	units := basicUnits{{message: r.err.Error()}}
+	if errs, ok := r.err.(jsonschema.ValidationErrors); ok {
+		units = make(basicUnits, len(errs))
+		for i, e := range errs {
+			units[i] = basicUnit{keywordLocation: e.KeywordLocation, instanceLocation: e.InstanceLocation, message: e.Message}
+		}
+	}
+	enc.ArrayKey("errors", units)
+}
+
+// IsNil implements gojay.MarshalerJSONObject.
+//
+// IsNil checks if instance is nil.
+func (r *basicResult) IsNil() bool {
+	return r == nil
+}
+
+// basicUnit represents an output unit of the "basic" standard output format.
+type basicUnit struct {
+	keywordLocation  string
+	instanceLocation string
+	message          string
+}
+
+// MarshalJSONObject implements gojay.MarshalerJSONObject.
+func (u *basicUnit) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey("keywordLocation", fragment(u.keywordLocation))
+	enc.StringKey("instanceLocation", fragment(u.instanceLocation))
+	enc.StringKey("error", u.message)
+}
+
+// IsNil implements gojay.MarshalerJSONObject.
+//
+// IsNil checks if instance is nil.
+func (u *basicUnit) IsNil() bool {
+	return u == nil
+}
+
+// basicUnits represents a list of basicUnit.
+type basicUnits []basicUnit
+
+// MarshalJSONArray implements gojay.MarshalerJSONArray.
+func (us basicUnits) MarshalJSONArray(enc *gojay.Encoder) {
+	for i := range us {
+		enc.Object(&us[i])
+	}
+}
+
+// IsNil implements gojay.MarshalerJSONArray.
+//
+// IsNil checks if instance is nil.
+func (us basicUnits) IsNil() bool {
+	return len(us) == 0
+}
+
+// fragment returns the URI fragment representation of the JSON Pointer ptr.
+func fragment(ptr string) string {
+	u := url.URL{Fragment: ptr}
+
+	return "#" + u.EscapedFragment()
+}
@@ -0,0 +1,161 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+	"github.com/zchee/go-jsonschema/pkg/jsonpointer"
+)
+
+func runSimplify(fs *flag.FlagSet, args []string) int {
+	output := fs.String("output", outputText, "output `format` of the simplifications: "+strings.Join(outputFormats, ", "))
+	out := fs.String("o", "", "write the simplified schema to `file`, which requires a single schema")
+	if code, exit := parseFlags(fs, args); exit {
+		return code
+	}
+
+	rp, err := newReporter(os.Stdout, *output)
+	if err != nil {
+		errorf("simplify", "%v", err)
+		return exitUsage
+	}
+	names, err := expandInputs(fs.Args())
+	if err != nil {
+		errorf("simplify", "%v", err)
+		return exitUsage
+	}
+	if *out != "" && len(names) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	for _, name := range names {
+		s, results, err := simplifySchema(name)
+		if s != nil && *out != "" {
+			if werr := writeSchema(*out, s); werr != nil {
+				err = werr
+			}
+		}
+		if err != nil {
+			results = []*result{{name: name, err: err}}
+		}
+		for _, r := range results {
+			if err := rp.report(r); err != nil {
+				errorf("simplify", "%v", err)
+				return exitError
+			}
+		}
+	}
+
+	return rp.code
+}
+
+// simplifySchema simplifies the schema file of name, and returns the results of the schema file and the files
+// referenced by its external "$ref"s.
+//
+// The schema is bundled only if it has the external "$ref"s, and the simplifications in the bundled schemas are
+// reported as jsonschema.ValidationErrors of their files at the locations in the files.
+func simplifySchema(name string) (*jsonschema.Schema, []*result, error) {
+	data, err := readInput(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	orig, err := decodeSchema(name, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, notes, err := jsonschema.Simplify(orig)
+	var files map[string]string
+	if err != nil {
+		// the external "$ref"s are resolved by the bundled schemas.
+		bundled, berr := bundleSchema(name, data)
+		if berr != nil {
+			return nil, nil, berr
+		}
+		if s, notes, err = jsonschema.Simplify(bundled); err != nil {
+			return nil, nil, err
+		}
+		files = bundledFiles(name, orig, bundled)
+	}
+
+	results := []*result{{name: name}}
+	byName := map[string]*result{name: results[0]}
+	for _, n := range notes {
+		file, loc := name, n.KeywordLocation
+		for prefix, f := range files {
+			if loc == prefix || strings.HasPrefix(loc, prefix+"/") {
+				file, loc = f, loc[len(prefix):]
+				break
+			}
+		}
+		r, ok := byName[file]
+		if !ok {
+			r = &result{name: file}
+			byName[file] = r
+			results = append(results, r)
+		}
+		errs, _ := r.err.(jsonschema.ValidationErrors)
+		r.err = append(errs, &jsonschema.ValidationError{InstanceLocation: loc, Message: n.Message})
+	}
+
+	return s, results, nil
+}
+
+// bundledFiles returns the map of the locations of the definitions which are bundled into the schema file of name
+// to the names of their files.
+//
+// The file names are relative to the directory of name as name is, and the schemas which are not the local files
+// are not mapped.
+func bundledFiles(name string, orig, bundled *jsonschema.Schema) map[string]string {
+	key, origDefs, defs := "definitions", orig.Definitions, bundled.Definitions
+	if strings.TrimSuffix(orig.Schema, "#") == jsonschema.Draft201909SchemaURL {
+		key, origDefs, defs = "$defs", orig.Defs, bundled.Defs
+	}
+	dir := filepath.Dir(name)
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+
+	files := make(map[string]string)
+	for def, sub := range defs {
+		if _, ok := origDefs[def]; ok {
+			continue
+		}
+		u, err := url.Parse(sub.ID)
+		if err != nil || u.Scheme != "file" {
+			continue
+		}
+		path := filepath.FromSlash(u.Path)
+		if rel, err := filepath.Rel(absDir, path); err == nil {
+			path = filepath.Join(dir, rel)
+		}
+		files["/"+key+"/"+jsonpointer.Escape(def)] = path
+	}
+
+	return files
+}
+
+// writeSchema writes the formatted s to the file of name.
+func writeSchema(name string, s *jsonschema.Schema) error {
+	data, err := s.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	data, err = formatSchema(data)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(name, data, 0644)
+}
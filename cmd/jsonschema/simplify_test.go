@@ -0,0 +1,87 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+)
+
+func TestSimplifySchema(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  map[string][]string // file name to the locations of the simplifications
+	}{
+		{
+			name:  "simple",
+			files: map[string]string{"schema.json": `{"type": "string"}`},
+			want:  map[string][]string{"schema.json": nil},
+		},
+		{
+			name:  "local",
+			files: map[string]string{"schema.json": `{"properties": {"a": {"anyOf": [{"type": "string"}, false]}}}`},
+			want:  map[string][]string{"schema.json": {"/properties/a/anyOf/1"}},
+		},
+		{
+			name: "external",
+			files: map[string]string{
+				"schema.json": `{"properties": {"a": {"$ref": "defs.json#/definitions/a"}, "b": {"allOf": [true]}}}`,
+				"defs.json":   `{"definitions": {"a": {"oneOf": [false, {"type": "integer"}]}}}`,
+			},
+			want: map[string][]string{
+				"schema.json": {"/properties/b/allOf/0"},
+				"defs.json":   {"/definitions/a/oneOf/0"},
+			},
+		},
+		{
+			name: "external 2019-09",
+			files: map[string]string{
+				"schema.json": `{"$schema": "https://json-schema.org/draft/2019-09/schema", "items": {"$ref": "item.json"}}`,
+				"item.json":   `{"if": {"type": "string"}}`,
+			},
+			want: map[string][]string{
+				"schema.json": nil,
+				"item.json":   {"/if"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := writeFiles(t, tt.files)
+			s, results, err := simplifySchema(filepath.Join(dir, "schema.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s == nil {
+				t.Fatal("simplifySchema() returns no schema")
+			}
+
+			got := make(map[string][]string)
+			for _, r := range results {
+				rel, err := filepath.Rel(dir, r.name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var locs []string
+				if r.err != nil {
+					for _, e := range r.err.(jsonschema.ValidationErrors) {
+						locs = append(locs, e.InstanceLocation)
+					}
+				}
+				got[filepath.ToSlash(rel)] = locs
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("simplifySchema() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"strings"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+)
+
+func runValidate(fs *flag.FlagSet, args []string) int {
+	schema := fs.String("schema", "", "schema `file` of the instances")
+	output := fs.String("output", outputText, "output `format` of the errors: "+strings.Join(outputFormats, ", "))
+	ndjson := fs.Bool("ndjson", false, "validate each line of the instances as the newline-delimited JSON")
+	if code, exit := parseFlags(fs, args); exit {
+		return code
+	}
+	if *schema == "" {
+		errorf("validate", "-schema is required")
+		fs.Usage()
+		return exitUsage
+	}
+
+	rp, err := newReporter(os.Stdout, *output)
+	if err != nil {
+		errorf("validate", "%v", err)
+		return exitUsage
+	}
+	names, err := expandInputs(fs.Args())
+	if err != nil {
+		errorf("validate", "%v", err)
+		return exitUsage
+	}
+
+	s, err := loadSchema(*schema)
+	if err != nil {
+		errorf("validate", "%v", err)
+		return exitError
+	}
+	v, err := jsonschema.Compile(s)
+	if err != nil {
+		errorf("validate", "%s: %v", *schema, err)
+		return exitError
+	}
+
+	for _, name := range names {
+		if err := validateInput(rp, v, name, *ndjson); err != nil {
+			errorf("validate", "%v", err)
+			return exitError
+		}
+	}
+
+	return rp.code
+}
+
+// validateInput validates the instance file of name against v, and reports the results to rp.
+//
+// The returned error is the error of the report.
+func validateInput(rp *reporter, v *jsonschema.Validator, name string, ndjson bool) error {
+	r, err := openInput(name)
+	if err != nil {
+		return rp.report(&result{name: name, err: err})
+	}
+	defer r.Close()
+
+	if !ndjson {
+		return rp.report(&result{name: name, err: v.ValidateReader(r)})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for res := range jsonschema.ValidateStream(ctx, r, v) {
+		if err := rp.report(&result{name: name, line: res.Line, err: res.Err}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
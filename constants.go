@@ -144,4 +144,20 @@ const (
 	//
 	// New in draft 7.
 	FormatRegex Format = "regex"
+
+	// FormatUUID a Universally Unique Identifier, according to RFC4122.
+	//
+	// RFC4122:
+	//   https://tools.ietf.org/html/rfc4122
+	//
+	// New in draft 2019-09.
+	FormatUUID Format = "uuid"
+
+	// FormatDuration a duration, according to the ISO 8601 ABNF in RFC3339 Appendix A.
+	//
+	// RFC3339:
+	//   https://tools.ietf.org/html/rfc3339#appendix-A
+	//
+	// New in draft 2019-09.
+	FormatDuration Format = "duration"
 )
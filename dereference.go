@@ -0,0 +1,144 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Dereference replaces every "$ref" of s with the referenced Schema in-place.
+//
+// The schema which has the "$ref" is overwritten by the shallow copy of the referenced Schema, so both of them
+// share the same sub schemas. The keywords adjacent to the "$ref" are dropped since they are ignored by the validation.
+// The recursive "$ref" makes the cycle of the pointers, which is still walked safely by Walk but cannot be encoded
+// as JSON. Use DereferenceDepth to get the tree instead.
+//
+// The copies do not have the "$id" of the referenced Schema, which identifies only the original.
+//
+// The "$ref"s must be resolved within s, so the external "$ref"s should be bundled by the Bundle in advance.
+func Dereference(s *Schema) error {
+	d, err := newDereferencer(s)
+	if err != nil {
+		return err
+	}
+
+	// the targets never have the "$ref", so they are not overwritten regardless of the order.
+	for x, t := range d.targets {
+		*x = *t
+		x.ID = ""
+	}
+
+	return nil
+}
+
+// DereferenceDepth is like Dereference, but expands the "$ref"s into the tree of the copies which can be encoded as JSON.
+//
+// A schema is expanded inside itself at most depth times through the recursive "$ref"s. The deeper recursive "$ref"s
+// are replaced with the "$ref" to the JSON Pointer of the innermost expanded copy of the referenced Schema in the result,
+// so the depth 0 keeps all the recursive "$ref"s and expands the others, as api/2019-09/schema-expand.json does.
+//
+// The "$id"s of the sub schemas are removed, so the "$ref"s are resolved against the root.
+func DereferenceDepth(s *Schema, depth int) error {
+	if depth < 0 {
+		return fmt.Errorf("jsonschema: negative depth %d", depth)
+	}
+	d, err := newDereferencer(s)
+	if err != nil {
+		return err
+	}
+	d.depth = depth
+	d.stack = make(map[*Schema][]string)
+
+	id := s.ID
+	*s = *d.expand(s, "")
+	s.ID = id
+
+	return nil
+}
+
+// dereferencer represents a state of the Dereference and the DereferenceDepth.
+type dereferencer struct {
+	// targets is the map of the schema which has the "$ref" to the final referenced Schema which does not have the "$ref".
+	targets map[*Schema]*Schema
+
+	// refs is the map of the schema which has the "$ref" to the Schema which it directly refers to.
+	refs map[*Schema]*Schema
+
+	// locs is the map of the schema to its JSON Pointer location in the root.
+	locs map[*Schema]string
+
+	// bases is the map of the schema to its base URI.
+	bases map[*Schema]*url.URL
+
+	depth int
+	stack map[*Schema][]string // locations of the expanded copies of the schema in the current path
+}
+
+// newDereferencer resolves the "$ref"s of s.
+func newDereferencer(s *Schema) (*dereferencer, error) {
+	v := &Validator{
+		root:      s,
+		resources: make(map[string]*Schema),
+		bases:     make(map[*Schema]*url.URL),
+		refs:      make(map[*Schema]*Schema),
+	}
+	v.indexResources(s, &url.URL{}, func(string, *Schema) bool { return true })
+	if err := v.resolveRefs(); err != nil {
+		return nil, err
+	}
+
+	d := &dereferencer{
+		targets: make(map[*Schema]*Schema, len(v.refs)),
+		refs:    v.refs,
+		locs:    make(map[*Schema]string),
+		bases:   v.bases,
+	}
+	var refs []*Schema
+	walk(s, func(loc string, sub *Schema) bool {
+		d.locs[sub] = loc
+		if sub.Ref != "" {
+			refs = append(refs, sub)
+		}
+		return true
+	})
+	for _, x := range refs {
+		t := x
+		for seen := map[*Schema]bool{x: true}; t.Ref != ""; {
+			t = v.refs[t]
+			if seen[t] {
+				return nil, fmt.Errorf("%s: circular $ref %q", appendLocation(d.locs[x], keyRef), x.Ref)
+			}
+			seen[t] = true
+		}
+		d.targets[x] = t
+	}
+
+	return d, nil
+}
+
+// expand returns the copy of x located at loc in the result, whose "$ref"s are expanded.
+func (d *dereferencer) expand(x *Schema, loc string) *Schema {
+	if t, ok := d.targets[x]; ok {
+		if locs := d.stack[t]; len(locs) > d.depth {
+			u := url.URL{Fragment: locs[len(locs)-1]}
+			return &Schema{Ref: "#" + u.EscapedFragment()}
+		}
+		x = t
+	}
+
+	locs := make(map[*Schema]string)
+	for _, sub := range x.subschemas() {
+		locs[sub.schema] = appendLocation(loc, sub.tokens...)
+	}
+	d.stack[x] = append(d.stack[x], loc)
+	c := x.mapSubschemas(func(sub *Schema) *Schema {
+		return d.expand(sub, locs[sub])
+	})
+	d.stack[x] = d.stack[x][:len(d.stack[x])-1]
+	c.ID = ""
+
+	return c
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// dereferenceFiles is the list of the draft-07 files of the JSON-Schema-Test-Suite whose schemas are dereferenced.
+var dereferenceFiles = []string{"definitions.json", "items.json", "properties.json", "ref.json", "refRemote.json"}
+
+// TestDereferenceSuite checks that the dereferenced schemas of the JSON-Schema-Test-Suite validate the cases
+// as the original schemas do.
+func TestDereferenceSuite(t *testing.T) {
+	srv := httptest.NewServer(suiteHandler())
+	defer srv.Close()
+
+	reg := NewRegistry()
+	reg.SetLoader(suiteLoader(srv))
+
+	tests := []struct {
+		name  string
+		deref func(s *Schema) (*Schema, error)
+	}{
+		{
+			name: "Dereference",
+			deref: func(s *Schema) (*Schema, error) {
+				return s, Dereference(s)
+			},
+		},
+		{
+			name: "DereferenceDepth 0",
+			deref: func(s *Schema) (*Schema, error) {
+				return s, DereferenceDepth(s, 0)
+			},
+		},
+		{
+			name: "DereferenceDepth 2",
+			deref: func(s *Schema) (*Schema, error) {
+				return s, DereferenceDepth(s, 2)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, file := range dereferenceFiles {
+				for _, g := range readSuiteFile(t, filepath.Join(suiteDir, "tests", "draft7", file)) {
+					name := strings.Join([]string{"draft7", file, g.Description}, "/")
+					if _, _, skip := suiteSkip(name); skip {
+						continue
+					}
+					t.Run(file+"/"+g.Description, func(t *testing.T) {
+						s := mustSchema(t, string(g.Schema))
+						s, err := Bundle(s, reg)
+						if err != nil {
+							t.Fatal(err)
+						}
+						s, err = tt.deref(s)
+						if err != nil {
+							t.Fatal(err)
+						}
+						if tt.name != "Dereference" {
+							// the tree must be encoded and decoded as the same schema.
+							data, err := s.MarshalJSON()
+							if err != nil {
+								t.Fatal(err)
+							}
+							s = mustSchema(t, string(data))
+						}
+						v, err := Compile(s, WithRegexpEngine(RegexpECMA262))
+						if err != nil {
+							t.Fatal(err)
+						}
+						for _, c := range g.Tests {
+							if err := runSuiteCase(v, c); err != nil {
+								t.Errorf("%s: %v", c.Description, err)
+							}
+						}
+					})
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,817 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/francoispqt/gojay"
+)
+
+// Change represents a difference of a keyword between the old and the new Schema.
+type Change struct {
+	// KeywordLocation is the JSON Pointer of the changed keyword from the root of the new Schema, which passes
+	// through the "$ref"s, or the old Schema if the keyword is removed.
+	KeywordLocation string
+
+	// Keyword is the changed keyword, or the empty string if the whole root schema is changed.
+	Keyword string
+
+	// Message is the human readable description of the change.
+	Message string
+
+	// BreaksProducers reports whether an instance valid against the old Schema may be invalid against the new Schema,
+	// which breaks the producers of the instances.
+	BreaksProducers bool
+
+	// BreaksConsumers reports whether an instance valid against the new Schema may be invalid against the old Schema,
+	// which breaks the consumers of the instances.
+	BreaksConsumers bool
+}
+
+// Breaking reports whether c breaks either the producers or the consumers.
+func (c *Change) Breaking() bool {
+	return c.BreaksProducers || c.BreaksConsumers
+}
+
+// String implements fmt.Stringer.
+func (c *Change) String() string {
+	compat := "compatible"
+	switch {
+	case c.BreaksProducers && c.BreaksConsumers:
+		compat = "breaking"
+	case c.BreaksProducers:
+		compat = "breaking for producers"
+	case c.BreaksConsumers:
+		compat = "breaking for consumers"
+	}
+
+	return fmt.Sprintf("%s: %s (%s)", instanceLocationString(c.KeywordLocation), c.Message, compat)
+}
+
+// Changes represents a list of Change.
+type Changes []*Change
+
+// Breaking reports whether any of cs is breaking.
+func (cs Changes) Breaking() bool {
+	for _, c := range cs {
+		if c.Breaking() {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	// compile time check whether the Change implements gojay.MarshalerJSONObject interface.
+	_ gojay.MarshalerJSONObject = &Change{}
+	// compile time check whether the Changes implements gojay.MarshalerJSONArray interface.
+	_ gojay.MarshalerJSONArray = Changes{}
+)
+
+// MarshalJSONObject implements gojay.MarshalerJSONObject.
+func (c *Change) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey(keyKeywordLocation, c.KeywordLocation)
+	enc.StringKey(keyKeyword, c.Keyword)
+	enc.StringKey(keyMessage, c.Message)
+	enc.BoolKey(keyBreaksProducers, c.BreaksProducers)
+	enc.BoolKey(keyBreaksConsumers, c.BreaksConsumers)
+}
+
+// IsNil implements gojay.MarshalerJSONObject.
+//
+// IsNil checks if instance is nil.
+func (c *Change) IsNil() bool {
+	return c == nil
+}
+
+// MarshalJSONArray implements gojay.MarshalerJSONArray.
+func (cs Changes) MarshalJSONArray(enc *gojay.Encoder) {
+	for _, c := range cs {
+		enc.Object(c)
+	}
+}
+
+// IsNil implements gojay.MarshalerJSONArray.
+//
+// IsNil checks if instance is nil.
+func (cs Changes) IsNil() bool {
+	return len(cs) == 0
+}
+
+// Diff returns the Changes from the old Schema to the new Schema.
+//
+// Diff walks both schemas in parallel, following the "$ref"s, and compares their keywords. The change which makes
+// the schema accept less instances, such as a new "required" property, a tightened "maximum", a removed "enum" value
+// or the "additionalProperties" becoming false, breaks the producers. The change which makes the schema accept more
+// instances breaks the consumers, and the change whose effect is unknown, such as a changed "pattern", breaks both.
+// The changes of the annotations, such as the "description", are compatible.
+//
+// The property which is defined only in either schema is compared with the "patternProperties" or the
+// "additionalProperties" of the other schema. The "$ref"s must be resolved within the schemas, so the external
+// "$ref"s should be bundled by the Bundle in advance.
+func Diff(old, new *Schema) (Changes, error) {
+	od, err := newDereferencer(old)
+	if err != nil {
+		return nil, fmt.Errorf("old schema: %v", err)
+	}
+	nd, err := newDereferencer(new)
+	if err != nil {
+		return nil, fmt.Errorf("new schema: %v", err)
+	}
+
+	d := &differ{
+		oldRefs: od.refs,
+		newRefs: nd.refs,
+		empty:   &Schema{},
+		seen:    make(map[schemaPair]bool),
+	}
+	d.compare("", "", "", old, new, covariant)
+
+	return d.changes, nil
+}
+
+// variance represents how the narrowing of a sub schema affects the root Schema.
+type variance int
+
+// The list of variance.
+const (
+	// covariant narrows the root Schema by narrowing the sub schema.
+	covariant variance = iota
+
+	// contravariant widens the root Schema by narrowing the sub schema, such as the "not".
+	contravariant
+
+	// invariant may both narrow and widen the root Schema by any change of the sub schema, such as the "if".
+	invariant
+)
+
+// flip returns the variance of the sub schema under the "not".
+func (v variance) flip() variance {
+	switch v {
+	case covariant:
+		return contravariant
+	case contravariant:
+		return covariant
+	}
+
+	return invariant
+}
+
+// schemaPair represents a pair of the compared schemas.
+type schemaPair struct {
+	old, new *Schema
+	v        variance
+}
+
+// differ represents a state of the Diff.
+type differ struct {
+	// oldRefs and newRefs are the maps of the schema which has the "$ref" to the Schema which it directly refers to.
+	oldRefs map[*Schema]*Schema
+	newRefs map[*Schema]*Schema
+
+	// empty is the schema which is compared with the missing sub schema.
+	empty *Schema
+
+	seen    map[schemaPair]bool
+	changes Changes
+}
+
+// record records the change of the keyword at loc, which narrows the schema if narrow is true, or widens it.
+func (d *differ) record(loc, keyword string, v variance, narrow bool, format string, args ...interface{}) {
+	c := &Change{KeywordLocation: loc, Keyword: keyword, Message: fmt.Sprintf(format, args...)}
+	switch {
+	case v == invariant:
+		c.BreaksProducers, c.BreaksConsumers = true, true
+	case narrow == (v == covariant):
+		c.BreaksProducers = true
+	default:
+		c.BreaksConsumers = true
+	}
+	d.changes = append(d.changes, c)
+}
+
+// narrowed records the change which narrows the schema.
+func (d *differ) narrowed(loc, keyword string, v variance, format string, args ...interface{}) {
+	d.record(appendLocation(loc, keyword), keyword, v, true, format, args...)
+}
+
+// widened records the change which widens the schema.
+func (d *differ) widened(loc, keyword string, v variance, format string, args ...interface{}) {
+	d.record(appendLocation(loc, keyword), keyword, v, false, format, args...)
+}
+
+// changed records the change which may both narrow and widen the schema.
+func (d *differ) changed(loc, keyword string, format string, args ...interface{}) {
+	d.record(appendLocation(loc, keyword), keyword, invariant, true, format, args...)
+}
+
+// annotated records the compatible change of the annotation keyword.
+func (d *differ) annotated(loc, keyword string, format string, args ...interface{}) {
+	d.changes = append(d.changes, &Change{
+		KeywordLocation: appendLocation(loc, keyword),
+		Keyword:         keyword,
+		Message:         fmt.Sprintf(format, args...),
+	})
+}
+
+// resolve returns the Schema referenced by s through the "$ref"s and its location, which passes through the "$ref"s
+// from loc as the Validator does, or the empty schema if s is nil or true.
+func (d *differ) resolve(loc string, s *Schema, refs map[*Schema]*Schema) (*Schema, string) {
+	for s != nil && s.Ref != "" {
+		s, loc = refs[s], appendLocation(loc, keyRef)
+	}
+	if s == nil || (s.Bool != nil && *s.Bool) {
+		return d.empty, loc
+	}
+
+	return s, loc
+}
+
+// compare compares the old schema o and the new schema n at loc, whose variance in the root is v.
+//
+// o and n are the sub schemas of the keyword, or the root schemas if keyword is empty. subject names the instances
+// which the sub schemas apply to with its verb, such as "additional properties are", or is empty if they have no
+// concise name.
+func (d *differ) compare(loc, keyword, subject string, o, n *Schema, v variance) {
+	// the location of the missing new schema is the one of the old schema.
+	nloc, missing := loc, n == nil
+	o, loc = d.resolve(loc, o, d.oldRefs)
+	n, nloc = d.resolve(nloc, n, d.newRefs)
+	if !missing {
+		loc = nloc
+	}
+	key := schemaPair{old: o, new: n, v: v}
+	if d.seen[key] {
+		return
+	}
+	d.seen[key] = true
+
+	oFalse, nFalse := o.Bool != nil, n.Bool != nil
+	switch {
+	case oFalse && nFalse:
+		return
+	case nFalse && subject != "":
+		d.record(loc, keyword, v, true, "%s no longer allowed", subject)
+		return
+	case nFalse:
+		d.record(loc, keyword, v, true, "schema no longer accepts any instance")
+		return
+	case oFalse && subject != "":
+		d.record(loc, keyword, v, false, "%s now allowed", subject)
+		return
+	case oFalse:
+		d.record(loc, keyword, v, false, "schema now accepts instances")
+		return
+	}
+
+	d.compareAnnotations(loc, o, n)
+	d.compareTypes(loc, o, n, v)
+	d.compareValues(loc, o, n, v)
+	d.compareNumbers(loc, o, n, v)
+	d.compareStrings(loc, o, n, v)
+	d.compareArrays(loc, o, n, v)
+	d.compareObjects(loc, o, n, v)
+	d.compareApplicators(loc, o, n, v)
+}
+
+// compareAnnotations compares the annotation keywords, whose changes are compatible.
+func (d *differ) compareAnnotations(loc string, o, n *Schema) {
+	if o.Title != n.Title {
+		d.annotated(loc, keyTitle, "title changed from %q to %q", o.Title, n.Title)
+	}
+	if o.Description != n.Description {
+		d.annotated(loc, keyDescription, "description changed")
+	}
+	if !equal(o.Default, n.Default) {
+		d.annotated(loc, keyDefault, "default changed from %s to %s", jsonString(o.Default), jsonString(n.Default))
+	}
+	if o.ReadOnly != n.ReadOnly {
+		d.annotated(loc, keyReadOnly, "readOnly changed to %t", n.ReadOnly)
+	}
+	if o.WriteOnly != n.WriteOnly {
+		d.annotated(loc, keyWriteOnly, "writeOnly changed to %t", n.WriteOnly)
+	}
+}
+
+// compareTypes compares the "type"s.
+func (d *differ) compareTypes(loc string, o, n *Schema, v variance) {
+	ot, nt := typeSet(o.Type), typeSet(n.Type)
+	var removed, added []string
+	for _, t := range allTypes {
+		switch {
+		case t == IntegerType && ot[NumberType] != nt[NumberType]:
+			// the integer follows the number.
+		case ot[t] && !nt[t]:
+			removed = append(removed, t.String())
+		case !ot[t] && nt[t]:
+			added = append(added, t.String())
+		}
+	}
+
+	switch {
+	case len(removed) > 0 && len(added) > 0:
+		d.changed(loc, keyType, "type no longer accepts %s, and now accepts %s", strings.Join(removed, ", "), strings.Join(added, ", "))
+	case len(removed) > 0:
+		d.narrowed(loc, keyType, v, "type no longer accepts %s", strings.Join(removed, ", "))
+	case len(added) > 0:
+		d.widened(loc, keyType, v, "type now accepts %s", strings.Join(added, ", "))
+	}
+}
+
+// allTypes is the list of the primitive types of the instances.
+var allTypes = []Type{NullType, BooleanType, ObjectType, ArrayType, NumberType, IntegerType, StringType}
+
+// typeSet returns the set of the primitive types accepted by types, which is all of them if types is nil.
+func typeSet(types Types) map[Type]bool {
+	set := make(map[Type]bool, len(allTypes))
+	if types == nil {
+		types = allTypes
+	}
+	for _, t := range types {
+		set[t] = true
+		if t == NumberType {
+			set[IntegerType] = true
+		}
+	}
+
+	return set
+}
+
+// compareValues compares the "const"s and the "enum"s.
+func (d *differ) compareValues(loc string, o, n *Schema, v variance) {
+	switch {
+	case o.Const == nil && n.Const == nil:
+	case o.Const == nil:
+		d.narrowed(loc, keyConst, v, "const %s added", jsonString(n.Const.Interface()))
+	case n.Const == nil:
+		d.widened(loc, keyConst, v, "const %s removed", jsonString(o.Const.Interface()))
+	case !equal(o.Const.Interface(), n.Const.Interface()):
+		d.changed(loc, keyConst, "const changed from %s to %s", jsonString(o.Const.Interface()), jsonString(n.Const.Interface()))
+	}
+
+	switch {
+	case o.Enum == nil && n.Enum == nil:
+	case o.Enum == nil:
+		d.narrowed(loc, keyEnum, v, "enum added")
+	case n.Enum == nil:
+		d.widened(loc, keyEnum, v, "enum removed")
+	default:
+		for _, c := range o.Enum {
+			if len(intersectEnum(n.Enum, Enum{c})) == 0 {
+				d.narrowed(loc, keyEnum, v, "enum value %s removed", jsonString(c.Interface()))
+			}
+		}
+		for _, c := range n.Enum {
+			if len(intersectEnum(o.Enum, Enum{c})) == 0 {
+				d.widened(loc, keyEnum, v, "enum value %s added", jsonString(c.Interface()))
+			}
+		}
+	}
+}
+
+// compareNumbers compares the numeric keywords.
+func (d *differ) compareNumbers(loc string, o, n *Schema, v variance) {
+	d.compareBound(loc, upperBound(o), upperBound(n), true, v)
+	d.compareBound(loc, lowerBound(o), lowerBound(n), false, v)
+
+	switch om, nm := o.MultipleOf, n.MultipleOf; {
+	case om == nm:
+	case om == 0:
+		d.narrowed(loc, keyMultipleOf, v, "multipleOf %v added", nm)
+	case nm == 0:
+		d.widened(loc, keyMultipleOf, v, "multipleOf %v removed", om)
+	case isMultipleOf(nm, om):
+		d.narrowed(loc, keyMultipleOf, v, "multipleOf changed from %v to %v", om, nm)
+	case isMultipleOf(om, nm):
+		d.widened(loc, keyMultipleOf, v, "multipleOf changed from %v to %v", om, nm)
+	default:
+		d.changed(loc, keyMultipleOf, "multipleOf changed from %v to %v", om, nm)
+	}
+}
+
+// bound represents the effective numeric bound of the "maximum" and the "exclusiveMaximum", or of the "minimum" and
+// the "exclusiveMinimum", which is given by the keyword.
+type bound struct {
+	keyword   string
+	value     float64
+	exclusive bool
+}
+
+// upperBound returns the effective upper bound of s, or nil if it is unbounded.
+func upperBound(s *Schema) *bound {
+	var b *bound
+	if s.Maximum != nil {
+		b = &bound{keyword: keyMaximum, value: *s.Maximum}
+	}
+	if x := s.ExclusiveMaximum; x != nil && (b == nil || *x <= b.value) {
+		b = &bound{keyword: keyExclusiveMaximum, value: *x, exclusive: true}
+	}
+
+	return b
+}
+
+// lowerBound returns the effective lower bound of s, or nil if it is unbounded.
+func lowerBound(s *Schema) *bound {
+	var b *bound
+	if s.Minimum != nil {
+		b = &bound{keyword: keyMinimum, value: *s.Minimum}
+	}
+	if x := s.ExclusiveMinimum; x != nil && (b == nil || *x >= b.value) {
+		b = &bound{keyword: keyExclusiveMinimum, value: *x, exclusive: true}
+	}
+
+	return b
+}
+
+// compareBound compares the effective upper bounds if upper is true, or the lower bounds, which are unbounded if nil.
+func (d *differ) compareBound(loc string, o, n *bound, upper bool, v variance) {
+	switch {
+	case o == nil && n == nil:
+		return
+	case o == nil:
+		d.narrowed(loc, n.keyword, v, "%s %v added", n.keyword, n.value)
+		return
+	case n == nil:
+		d.widened(loc, o.keyword, v, "%s %v removed", o.keyword, o.value)
+		return
+	}
+
+	// narrow is whether n accepts fewer numbers than o, by its value or by its exclusiveness.
+	var narrow bool
+	switch {
+	case n.value != o.value:
+		narrow = (n.value < o.value) == upper
+	case n.exclusive != o.exclusive:
+		narrow = n.exclusive
+	default:
+		return
+	}
+
+	var msg string
+	switch {
+	case o.keyword != n.keyword:
+		msg = fmt.Sprintf("%s %v changed to %s %v", o.keyword, o.value, n.keyword, n.value)
+	case n.value < o.value:
+		msg = fmt.Sprintf("%s decreased from %v to %v", n.keyword, o.value, n.value)
+	default:
+		msg = fmt.Sprintf("%s increased from %v to %v", n.keyword, o.value, n.value)
+	}
+	d.record(appendLocation(loc, n.keyword), n.keyword, v, narrow, "%s", msg)
+}
+
+// compareMax compares the upper bound keywords, which is unbounded if nil.
+func (d *differ) compareMax(loc, keyword string, o, n *float64, v variance) {
+	switch {
+	case o == nil && n == nil:
+	case o == nil:
+		d.narrowed(loc, keyword, v, "%s %v added", keyword, *n)
+	case n == nil:
+		d.widened(loc, keyword, v, "%s %v removed", keyword, *o)
+	case *n < *o:
+		d.narrowed(loc, keyword, v, "%s decreased from %v to %v", keyword, *o, *n)
+	case *n > *o:
+		d.widened(loc, keyword, v, "%s increased from %v to %v", keyword, *o, *n)
+	}
+}
+
+// compareMin compares the lower bound keywords, which is unbounded if nil.
+func (d *differ) compareMin(loc, keyword string, o, n *float64, v variance) {
+	switch {
+	case o == nil && n == nil:
+	case o == nil:
+		d.narrowed(loc, keyword, v, "%s %v added", keyword, *n)
+	case n == nil:
+		d.widened(loc, keyword, v, "%s %v removed", keyword, *o)
+	case *n > *o:
+		d.narrowed(loc, keyword, v, "%s increased from %v to %v", keyword, *o, *n)
+	case *n < *o:
+		d.widened(loc, keyword, v, "%s decreased from %v to %v", keyword, *o, *n)
+	}
+}
+
+// intBound returns the count bound i as the float, or nil if it is unbounded.
+func intBound(i *int64) *float64 {
+	if i == nil {
+		return nil
+	}
+	f := float64(*i)
+
+	return &f
+}
+
+// minBound returns the count lower bound i as the float, or nil if it is 0.
+func minBound(i int64) *float64 {
+	if i == 0 {
+		return nil
+	}
+
+	return intBound(&i)
+}
+
+// compareText compares the string valued keywords such as the "pattern".
+func (d *differ) compareText(loc, keyword, o, n string, v variance) {
+	switch {
+	case o == n:
+	case o == "":
+		d.narrowed(loc, keyword, v, "%s %q added", keyword, n)
+	case n == "":
+		d.widened(loc, keyword, v, "%s %q removed", keyword, o)
+	default:
+		d.changed(loc, keyword, "%s changed from %q to %q", keyword, o, n)
+	}
+}
+
+// compareStrings compares the string keywords.
+func (d *differ) compareStrings(loc string, o, n *Schema, v variance) {
+	d.compareMax(loc, keyMaxLength, intBound(o.MaxLength), intBound(n.MaxLength), v)
+	d.compareMin(loc, keyMinLength, minBound(o.MinLength), minBound(n.MinLength), v)
+
+	var op, np string
+	if o.Pattern != nil {
+		op = o.Pattern.String()
+	}
+	if n.Pattern != nil {
+		np = n.Pattern.String()
+	}
+	d.compareText(loc, keyPattern, op, np, v)
+	d.compareText(loc, keyFormat, string(o.Format), string(n.Format), v)
+	d.compareText(loc, keyContentMediaType, o.ContentMediaType, n.ContentMediaType, v)
+	d.compareText(loc, keyContentEncoding, o.ContentEncoding, n.ContentEncoding, v)
+}
+
+// compareArrays compares the array keywords.
+func (d *differ) compareArrays(loc string, o, n *Schema, v variance) {
+	d.compareMax(loc, keyMaxItems, intBound(o.MaxItems), intBound(n.MaxItems), v)
+	d.compareMin(loc, keyMinItems, minBound(o.MinItems), minBound(n.MinItems), v)
+	switch {
+	case !o.UniqueItems && n.UniqueItems:
+		d.narrowed(loc, keyUniqueItems, v, "uniqueItems added")
+	case o.UniqueItems && !n.UniqueItems:
+		d.widened(loc, keyUniqueItems, v, "uniqueItems removed")
+	}
+
+	tuple := 0
+	for _, s := range []*Schema{o, n} {
+		if s.Items != nil && s.Items.HasMultiple && len(s.Items.Schemas) > tuple {
+			tuple = len(s.Items.Schemas)
+		}
+	}
+	for i := 0; i < tuple; i++ {
+		d.compare(appendLocation(loc, keyItems, strconv.Itoa(i)), keyItems, fmt.Sprintf("item %d is", i), itemAt(o, i), itemAt(n, i), v)
+	}
+	restKeyword, restSubject := keyItems, "items are"
+	if n.Items != nil && n.Items.HasMultiple {
+		restKeyword, restSubject = keyAdditionalItems, "additional items are"
+	}
+	d.compare(appendLocation(loc, restKeyword), restKeyword, restSubject, itemAt(o, tuple), itemAt(n, tuple), v)
+
+	switch {
+	case o.Contains == nil && n.Contains == nil:
+	case o.Contains == nil:
+		d.narrowed(loc, keyContains, v, "contains added")
+	case n.Contains == nil:
+		d.widened(loc, keyContains, v, "contains removed")
+	default:
+		d.compare(appendLocation(loc, keyContains), keyContains, "", o.Contains.Schema, n.Contains.Schema, v)
+	}
+}
+
+// itemAt returns the schema of the i-th array item of s, or nil if it is not constrained.
+func itemAt(s *Schema, i int) *Schema {
+	switch {
+	case s.Items == nil || len(s.Items.Schemas) == 0:
+		return nil
+	case !s.Items.HasMultiple:
+		return s.Items.Schemas[0]
+	case i < len(s.Items.Schemas):
+		return s.Items.Schemas[i]
+	case s.AdditionalItems != nil:
+		return s.AdditionalItems.Schema
+	}
+
+	return nil
+}
+
+// compareObjects compares the object keywords.
+func (d *differ) compareObjects(loc string, o, n *Schema, v variance) {
+	d.compareMax(loc, keyMaxProperties, intBound(o.MaxProperties), intBound(n.MaxProperties), v)
+	d.compareMin(loc, keyMinProperties, minBound(o.MinProperties), minBound(n.MinProperties), v)
+
+	oreq, nreq := stringSet(o.Required), stringSet(n.Required)
+	for _, r := range n.Required {
+		if !oreq[r.Value] {
+			d.narrowed(loc, keyRequired, v, "property %q is now required", r.Value)
+		}
+	}
+	for _, r := range o.Required {
+		if !nreq[r.Value] {
+			d.widened(loc, keyRequired, v, "property %q is no longer required", r.Value)
+		}
+	}
+
+	names := make(map[string]*Schema, len(o.Properties)+len(n.Properties))
+	for k, p := range o.Properties {
+		names[k] = p
+	}
+	for k, p := range n.Properties {
+		names[k] = p
+	}
+	for _, k := range sortedKeys(names) {
+		d.compare(appendLocation(loc, keyProperties, k), keyProperties, fmt.Sprintf("property %q is", k), propertyOf(o, k), propertyOf(n, k), v)
+	}
+
+	patterns := make(map[string]*Schema, len(o.PatternProperties)+len(n.PatternProperties))
+	for k, pp := range o.PatternProperties {
+		patterns[k] = pp.Schema
+	}
+	for k, pp := range n.PatternProperties {
+		patterns[k] = pp.Schema
+	}
+	for _, k := range sortedKeys(patterns) {
+		var op, np *Schema
+		if pp, ok := o.PatternProperties[k]; ok {
+			op = pp.Schema
+		}
+		if pp, ok := n.PatternProperties[k]; ok {
+			np = pp.Schema
+		}
+		d.compare(appendLocation(loc, keyPatternProperties, k), keyPatternProperties, fmt.Sprintf("properties matching %q are", k), op, np, v)
+	}
+
+	var oap, nap *Schema
+	if o.AdditionalProperties != nil {
+		oap = o.AdditionalProperties.Schema
+	}
+	if n.AdditionalProperties != nil {
+		nap = n.AdditionalProperties.Schema
+	}
+	d.compare(appendLocation(loc, keyAdditionalProperties), keyAdditionalProperties, "additional properties are", oap, nap, v)
+	d.compare(appendLocation(loc, keyPropertyNames), keyPropertyNames, "properties are", o.PropertyNames, n.PropertyNames, v)
+	d.compareDependencies(loc, o.Dependencies, n.Dependencies, v)
+}
+
+// stringSet returns the set of the values of a.
+func stringSet(a StringArray) map[string]bool {
+	set := make(map[string]bool, len(a))
+	for _, s := range a {
+		set[s.Value] = true
+	}
+
+	return set
+}
+
+// propertyOf returns the schema of the k property of s, which is the "properties", the matched "patternProperties",
+// or the "additionalProperties", or nil if it is not constrained.
+func propertyOf(s *Schema, k string) *Schema {
+	if p, ok := s.Properties[k]; ok {
+		return p
+	}
+
+	var matched SchemaList
+	for _, expr := range sortedPatterns(s.PatternProperties) {
+		if pp := s.PatternProperties[expr]; pp.Regexp != nil && pp.Regexp.MatchString(k) {
+			matched = append(matched, pp.Schema)
+		}
+	}
+	switch {
+	case len(matched) == 1:
+		return matched[0]
+	case len(matched) > 1:
+		return &Schema{AllOf: matched}
+	case s.AdditionalProperties != nil:
+		return s.AdditionalProperties.Schema
+	}
+
+	return nil
+}
+
+// sortedPatterns returns the sorted keys of pp.
+func sortedPatterns(pp PatternProperties) []string {
+	keys := make([]string, 0, len(pp))
+	for k := range pp {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// compareDependencies compares the "dependencies".
+func (d *differ) compareDependencies(loc string, o, n *DependencyMap, v variance) {
+	if o == nil {
+		o = &DependencyMap{}
+	}
+	if n == nil {
+		n = &DependencyMap{}
+	}
+
+	keys := map[string]*Schema{}
+	for _, m := range []*DependencyMap{o, n} {
+		for k := range m.Names {
+			keys[k] = nil
+		}
+		for k := range m.Schemas {
+			keys[k] = nil
+		}
+	}
+	depLoc := appendLocation(loc, keyDependencies)
+	for _, k := range sortedKeys(keys) {
+		onames, oname := o.Names[k]
+		nnames, nname := n.Names[k]
+		if oname || nname {
+			oset, nset := make(map[string]bool), make(map[string]bool)
+			for _, s := range onames {
+				oset[s] = true
+			}
+			for _, s := range nnames {
+				nset[s] = true
+			}
+			for _, s := range nnames {
+				if !oset[s] {
+					d.narrowed(depLoc, k, v, "property %q now depends on %q", k, s)
+				}
+			}
+			for _, s := range onames {
+				if !nset[s] {
+					d.widened(depLoc, k, v, "property %q no longer depends on %q", k, s)
+				}
+			}
+		}
+		_, oschema := o.Schemas[k]
+		_, nschema := n.Schemas[k]
+		if oschema || nschema {
+			d.compare(appendLocation(depLoc, k), keyDependencies, fmt.Sprintf("property %q is", k), o.Schemas[k], n.Schemas[k], v)
+		}
+	}
+}
+
+// compareApplicators compares the conditional and the boolean logic keywords.
+func (d *differ) compareApplicators(loc string, o, n *Schema, v variance) {
+	for i := 0; i < len(o.AllOf) || i < len(n.AllOf); i++ {
+		var os, ns *Schema
+		if i < len(o.AllOf) {
+			os = o.AllOf[i]
+		}
+		if i < len(n.AllOf) {
+			ns = n.AllOf[i]
+		}
+		d.compare(appendLocation(loc, keyAllOf, strconv.Itoa(i)), keyAllOf, "", os, ns, v)
+	}
+
+	d.compareBranches(loc, keyAnyOf, o.AnyOf, n.AnyOf, v)
+	d.compareBranches(loc, keyOneOf, o.OneOf, n.OneOf, invariant)
+
+	switch {
+	case o.Not == nil && n.Not == nil:
+	case o.Not == nil:
+		d.narrowed(loc, keyNot, v, "not added")
+	case n.Not == nil:
+		d.widened(loc, keyNot, v, "not removed")
+	default:
+		d.compare(appendLocation(loc, keyNot), keyNot, "", o.Not, n.Not, v.flip())
+	}
+
+	switch {
+	case o.If == nil && n.If == nil:
+	case o.If == nil:
+		d.narrowed(loc, keyIf, v, "if added")
+	case n.If == nil:
+		d.widened(loc, keyIf, v, "if removed")
+	default:
+		d.compare(appendLocation(loc, keyIf), keyIf, "", o.If, n.If, invariant)
+		d.compare(appendLocation(loc, keyThen), keyThen, "", o.Then, n.Then, v)
+		d.compare(appendLocation(loc, keyElse), keyElse, "", o.Else, n.Else, v)
+	}
+}
+
+// compareBranches compares the branches of the "anyOf" or the "oneOf" by their indexes. The added branch widens
+// the schema by the variance v.
+func (d *differ) compareBranches(loc, keyword string, o, n SchemaList, v variance) {
+	switch {
+	case o == nil && n == nil:
+		return
+	case o == nil:
+		d.narrowed(loc, keyword, v, "%s added", keyword)
+		return
+	case n == nil:
+		d.widened(loc, keyword, v, "%s removed", keyword)
+		return
+	}
+
+	for i := 0; i < len(o) && i < len(n); i++ {
+		d.compare(appendLocation(loc, keyword, strconv.Itoa(i)), keyword, "", o[i], n[i], v)
+	}
+	for i := len(o); i < len(n); i++ {
+		d.record(appendLocation(loc, keyword, strconv.Itoa(i)), keyword, v, false, "%s branch added", keyword)
+	}
+	for i := len(n); i < len(o); i++ {
+		d.record(appendLocation(loc, keyword, strconv.Itoa(i)), keyword, v, true, "%s branch removed", keyword)
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/francoispqt/gojay"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want []string
+	}{
+		{
+			name: "same",
+			old:  `{"type": "object", "properties": {"a": {"type": "string"}}}`,
+			new:  `{"properties": {"a": {"type": "string"}}, "type": "object"}`,
+		},
+		{
+			name: "annotations",
+			old:  `{"title": "a", "description": "x", "default": 1}`,
+			new:  `{"title": "b", "description": "y", "default": 2, "readOnly": true}`,
+			want: []string{
+				`/title: title changed from "a" to "b" (compatible)`,
+				`/description: description changed (compatible)`,
+				`/default: default changed from 1 to 2 (compatible)`,
+				`/readOnly: readOnly changed to true (compatible)`,
+			},
+		},
+		{
+			name: "types",
+			old:  `{"type": ["string", "null"]}`,
+			new:  `{"type": ["string", "integer"]}`,
+			want: []string{
+				`/type: type no longer accepts null, and now accepts integer (breaking)`,
+			},
+		},
+		{
+			name: "numbers",
+			old:  `{"maximum": 10, "minimum": 0, "multipleOf": 2}`,
+			new:  `{"maximum": 5, "exclusiveMinimum": 0, "multipleOf": 1}`,
+			want: []string{
+				`/maximum: maximum decreased from 10 to 5 (breaking for producers)`,
+				`/exclusiveMinimum: minimum 0 changed to exclusiveMinimum 0 (breaking for producers)`,
+				`/multipleOf: multipleOf changed from 2 to 1 (breaking for consumers)`,
+			},
+		},
+		{
+			name: "enum",
+			old:  `{"enum": ["a", "b"]}`,
+			new:  `{"enum": ["b", "c"]}`,
+			want: []string{
+				`/enum: enum value "a" removed (breaking for producers)`,
+				`/enum: enum value "c" added (breaking for consumers)`,
+			},
+		},
+		{
+			name: "strings",
+			old:  `{"maxLength": 3, "pattern": "^a"}`,
+			new:  `{"maxLength": 4, "minLength": 1, "pattern": "^b"}`,
+			want: []string{
+				`/maxLength: maxLength increased from 3 to 4 (breaking for consumers)`,
+				`/minLength: minLength 1 added (breaking for producers)`,
+				`/pattern: pattern changed from "^a" to "^b" (breaking)`,
+			},
+		},
+		{
+			name: "objects",
+			old:  `{"properties": {"a": {"type": "string"}, "b": {}}, "required": ["a"]}`,
+			new:  `{"properties": {"a": {"type": "string"}, "c": {"type": "integer"}}, "required": ["c"], "additionalProperties": false}`,
+			want: []string{
+				`/required: property "c" is now required (breaking for producers)`,
+				`/required: property "a" is no longer required (breaking for consumers)`,
+				`/properties/b: property "b" is no longer allowed (breaking for producers)`,
+				`/properties/c/type: type no longer accepts null, boolean, object, array, number, string (breaking for producers)`,
+				`/additionalProperties: additional properties are no longer allowed (breaking for producers)`,
+			},
+		},
+		{
+			name: "arrays",
+			old:  `{"items": [{"type": "string"}], "additionalItems": {"type": "integer"}}`,
+			new:  `{"items": {"type": "integer"}, "uniqueItems": true}`,
+			want: []string{
+				`/uniqueItems: uniqueItems added (breaking for producers)`,
+				`/items/0/type: type no longer accepts string, and now accepts integer (breaking)`,
+			},
+		},
+		{
+			name: "additionalItems",
+			old:  `{"items": {"type": "string"}}`,
+			new:  `{"items": [{"type": "string"}], "additionalItems": false}`,
+			want: []string{
+				`/additionalItems: additional items are no longer allowed (breaking for producers)`,
+			},
+		},
+		{
+			name: "exclusive bounds",
+			old:  `{"exclusiveMaximum": 5, "minimum": 1, "exclusiveMinimum": 0}`,
+			new:  `{"maximum": 5, "exclusiveMinimum": 1}`,
+			want: []string{
+				`/maximum: exclusiveMaximum 5 changed to maximum 5 (breaking for consumers)`,
+				`/exclusiveMinimum: minimum 1 changed to exclusiveMinimum 1 (breaking for producers)`,
+			},
+		},
+		{
+			name: "wider exclusive bound",
+			old:  `{"maximum": 5}`,
+			new:  `{"exclusiveMaximum": 6}`,
+			want: []string{
+				`/exclusiveMaximum: maximum 5 changed to exclusiveMaximum 6 (breaking for consumers)`,
+			},
+		},
+		{
+			name: "patternProperties",
+			old:  `{"properties": {"a": {"type": "string"}}}`,
+			new:  `{"patternProperties": {"^a$": {"type": "string"}}}`,
+			want: []string{
+				`/patternProperties/^a$/type: type no longer accepts null, boolean, object, array, number (breaking for producers)`,
+			},
+		},
+		{
+			name: "not",
+			old:  `{"not": {"type": "string"}}`,
+			new:  `{"not": {"type": ["string", "null"]}}`,
+			want: []string{
+				`/not/type: type now accepts null (breaking for producers)`,
+			},
+		},
+		{
+			name: "anyOf and oneOf",
+			old:  `{"anyOf": [{"type": "string"}], "oneOf": [{"minimum": 1}, {"maximum": 0}]}`,
+			new:  `{"anyOf": [{"type": "string"}, {"type": "null"}], "oneOf": [{"minimum": 2}]}`,
+			want: []string{
+				`/anyOf/1: anyOf branch added (breaking for consumers)`,
+				`/oneOf/0/minimum: minimum increased from 1 to 2 (breaking)`,
+				`/oneOf/1: oneOf branch removed (breaking)`,
+			},
+		},
+		{
+			name: "$ref",
+			old:  `{"definitions": {"a": {"type": "string"}}, "properties": {"x": {"$ref": "#/definitions/a"}}}`,
+			new:  `{"definitions": {"b": {"type": "string", "maxLength": 1}}, "properties": {"x": {"$ref": "#/definitions/b"}}}`,
+			want: []string{
+				`/properties/x/$ref/maxLength: maxLength 1 added (breaking for producers)`,
+			},
+		},
+		{
+			name: "recursive $ref",
+			old:  `{"properties": {"next": {"$ref": "#"}}}`,
+			new:  `{"properties": {"next": {"$ref": "#"}}, "required": ["next"]}`,
+			want: []string{
+				`/required: property "next" is now required (breaking for producers)`,
+			},
+		},
+		{
+			name: "dependencies",
+			old:  `{"dependencies": {"a": ["b"]}}`,
+			new:  `{"dependencies": {"a": ["c"], "d": {"required": ["e"]}}}`,
+			want: []string{
+				`/dependencies/a: property "a" now depends on "c" (breaking for producers)`,
+				`/dependencies/a: property "a" no longer depends on "b" (breaking for consumers)`,
+				`/dependencies/d/required: property "e" is now required (breaking for producers)`,
+			},
+		},
+		{
+			name: "if",
+			old:  `{"if": {"type": "string"}, "then": {"minLength": 1}}`,
+			new:  `{"if": {"type": "integer"}, "then": {"minLength": 1}}`,
+			want: []string{
+				`/if/type: type no longer accepts string, and now accepts integer (breaking)`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			changes, err := Diff(mustSchema(t, tt.old), mustSchema(t, tt.new))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range changes {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %q, want %q", got, tt.want)
+			}
+
+			breaking := false
+			for _, w := range tt.want {
+				breaking = breaking || strings.HasSuffix(w, "breaking)") || strings.Contains(w, "(breaking ")
+			}
+			if changes.Breaking() != breaking {
+				t.Errorf("Breaking() = %t, want %t", changes.Breaking(), breaking)
+			}
+		})
+	}
+}
+
+func TestDiffError(t *testing.T) {
+	ok := mustSchema(t, `{}`)
+	bad := mustSchema(t, `{"$ref": "#/definitions/missing"}`)
+	if _, err := Diff(bad, ok); err == nil || !strings.HasPrefix(err.Error(), "old schema: ") {
+		t.Errorf("Diff(bad, ok) error = %v, want the error of the old schema", err)
+	}
+	if _, err := Diff(ok, bad); err == nil || !strings.HasPrefix(err.Error(), "new schema: ") {
+		t.Errorf("Diff(ok, bad) error = %v, want the error of the new schema", err)
+	}
+}
+
+func TestChangesMarshalJSONArray(t *testing.T) {
+	changes := Changes{
+		{KeywordLocation: "/type", Keyword: "type", Message: "type no longer accepts null", BreaksProducers: true},
+		{KeywordLocation: "/title", Keyword: "title", Message: "title changed"},
+	}
+	b, err := gojay.MarshalJSONArray(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `[{"keywordLocation":"/type","keyword":"type","message":"type no longer accepts null","breaksProducers":true,"breaksConsumers":false},` +
+		`{"keywordLocation":"/title","keyword":"title","message":"title changed","breaksProducers":false,"breaksConsumers":false}]`
+	if string(b) != want {
+		t.Errorf("MarshalJSONArray() = %s, want %s", b, want)
+	}
+}
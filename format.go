@@ -0,0 +1,177 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FormatChecker represents a checker of the "format" keyword.
+type FormatChecker interface {
+	// IsFormat reports whether the input conforms to the format.
+	IsFormat(input string) bool
+}
+
+// FormatCheckerFunc is an adapter to allow the use of ordinary functions as FormatChecker.
+type FormatCheckerFunc func(input string) bool
+
+// compile time check whether the FormatCheckerFunc implements FormatChecker interface.
+var _ FormatChecker = FormatCheckerFunc(nil)
+
+// IsFormat implements FormatChecker.
+func (f FormatCheckerFunc) IsFormat(input string) bool { return f(input) }
+
+// FormatMode represents a behavior of the "format" keyword.
+type FormatMode int
+
+// The list of FormatMode.
+const (
+	// FormatAnnotation only collects the "format" keyword as an annotation and never fails the validation.
+	//
+	// This is the default behavior of draft 2019-09.
+	FormatAnnotation FormatMode = iota
+
+	// FormatAssertion fails the validation if the instance does not conform to the format.
+	FormatAssertion
+)
+
+// String implements fmt.Stringer.
+func (m FormatMode) String() string {
+	switch m {
+	case FormatAnnotation:
+		return "annotation"
+	case FormatAssertion:
+		return "assertion"
+	default:
+		return fmt.Sprintf("FormatMode(%d)", int(m))
+	}
+}
+
+// DefaultFormatMode returns the default FormatMode of the v Draft version.
+func DefaultFormatMode(v DraftVersion) FormatMode {
+	if v == DraftVersion201909 {
+		return FormatAnnotation
+	}
+
+	return FormatAssertion
+}
+
+// FormatError represents an error that input does not conform to the Format.
+type FormatError struct {
+	Format Format
+	Input  string
+}
+
+// Error implements error.
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("%q is not valid %q", e.Input, e.Format)
+}
+
+// FormatRegistry represents a registry of FormatChecker keyed by Format.
+//
+// FormatRegistry is safe for concurrent use by multiple goroutines.
+type FormatRegistry struct {
+	mu       sync.RWMutex
+	checkers map[Format]FormatChecker
+	mode     FormatMode
+}
+
+// NewFormatRegistry returns a new FormatRegistry which has the built-in format checkers.
+func NewFormatRegistry(mode FormatMode) *FormatRegistry {
+	r := &FormatRegistry{
+		checkers: make(map[Format]FormatChecker, len(builtinFormatCheckers)),
+		mode:     mode,
+	}
+	for f, c := range builtinFormatCheckers {
+		r.checkers[f] = c
+	}
+
+	return r
+}
+
+// DefaultFormatRegistry is the default FormatRegistry.
+//
+// The Compile takes the copy of DefaultFormatRegistry with the DefaultFormatMode of the "$schema" of the compiled
+// Schema, so the compiled Validators are not affected by the later changes of it, nor by its FormatMode. Use the
+// WithFormatChecker and the WithFormatMode to change the formats of a Validator instead of changing
+// DefaultFormatRegistry, which is shared by all packages in the process.
+var DefaultFormatRegistry = NewFormatRegistry(FormatAssertion)
+
+// Clone returns a copy of r.
+func (r *FormatRegistry) Clone() *FormatRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c := &FormatRegistry{
+		checkers: make(map[Format]FormatChecker, len(r.checkers)),
+		mode:     r.mode,
+	}
+	for f, checker := range r.checkers {
+		c.checkers[f] = checker
+	}
+
+	return c
+}
+
+// Register registers the c FormatChecker as the f Format.
+//
+// Register replaces the existing FormatChecker if f is already registered.
+func (r *FormatRegistry) Register(f Format, c FormatChecker) {
+	r.mu.Lock()
+	r.checkers[f] = c
+	r.mu.Unlock()
+}
+
+// Unregister removes the FormatChecker of f Format.
+func (r *FormatRegistry) Unregister(f Format) {
+	r.mu.Lock()
+	delete(r.checkers, f)
+	r.mu.Unlock()
+}
+
+// Lookup returns the FormatChecker registered as f.
+func (r *FormatRegistry) Lookup(f Format) (FormatChecker, bool) {
+	r.mu.RLock()
+	c, ok := r.checkers[f]
+	r.mu.RUnlock()
+
+	return c, ok
+}
+
+// Mode returns the FormatMode of r.
+func (r *FormatRegistry) Mode() FormatMode {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.mode
+}
+
+// SetMode sets the FormatMode of r.
+func (r *FormatRegistry) SetMode(mode FormatMode) {
+	r.mu.Lock()
+	r.mode = mode
+	r.mu.Unlock()
+}
+
+// Check checks whether the input conforms to the f Format.
+//
+// Check always returns nil if r is in the FormatAnnotation mode, or f is unknown format.
+// Otherwise returns the *FormatError if the input does not conform to f.
+func (r *FormatRegistry) Check(f Format, input string) error {
+	r.mu.RLock()
+	c, ok := r.checkers[f]
+	mode := r.mode
+	r.mu.RUnlock()
+
+	if mode == FormatAnnotation || !ok {
+		return nil
+	}
+	if !c.IsFormat(input) {
+		return &FormatError{Format: f, Input: input}
+	}
+
+	return nil
+}
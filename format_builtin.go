@@ -0,0 +1,456 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zchee/go-jsonschema/internal/lazyregexp"
+	"github.com/zchee/go-jsonschema/pkg/jsonpointer"
+	"github.com/zchee/go-jsonschema/pkg/uritemplate"
+)
+
+// builtinFormatCheckers is the list of built-in format checkers.
+var builtinFormatCheckers = map[Format]FormatChecker{
+	FormatDateTime:            FormatCheckerFunc(isDateTime),
+	FormatDate:                FormatCheckerFunc(isDate),
+	FormatTime:                FormatCheckerFunc(isTime),
+	FormatEmail:               FormatCheckerFunc(isEmail),
+	FormatHostname:            FormatCheckerFunc(isHostname),
+	FormatIPv4:                FormatCheckerFunc(isIPv4),
+	FormatIPv6:                FormatCheckerFunc(isIPv6),
+	FormatURI:                 FormatCheckerFunc(isURI),
+	FormatURIReference:        FormatCheckerFunc(isURIReference),
+	FormatJSONPointer:         FormatCheckerFunc(isJSONPointer),
+	FormatRelativeJSONPointer: FormatCheckerFunc(isRelativeJSONPointer),
+	FormatRegex:               FormatCheckerFunc(isRegex),
+	FormatIDNEmail:            FormatCheckerFunc(isIDNEmail),
+	FormatIDNHostname:         FormatCheckerFunc(isIDNHostname),
+	FormatIRI:                 FormatCheckerFunc(isIRI),
+	FormatIRIReference:        FormatCheckerFunc(isIRIReference),
+	FormatURITemplate:         FormatCheckerFunc(isURITemplate),
+	FormatUUID:                FormatCheckerFunc(isUUID),
+	FormatDuration:            FormatCheckerFunc(isDuration),
+}
+
+// isDateTime reports whether s is a RFC 3339 date-time.
+//
+// RFC 3339, section 5.6:
+//  https://tools.ietf.org/html/rfc3339#section-5.6
+func isDateTime(s string) bool {
+	// date-time = full-date "T" full-time
+	if len(s) < len("2006-01-02T15:04:05Z") {
+		return false
+	}
+	if s[10] != 'T' && s[10] != 't' {
+		return false
+	}
+
+	return isDate(s[:10]) && isTime(s[11:])
+}
+
+// isDate reports whether s is a RFC 3339 full-date.
+func isDate(s string) bool {
+	// full-date = date-fullyear "-" date-month "-" date-mday
+	if len(s) != len("2006-01-02") || s[4] != '-' || s[7] != '-' {
+		return false
+	}
+	if !isDigits(s[:4]) || !isDigits(s[5:7]) || !isDigits(s[8:]) {
+		return false
+	}
+	_, err := time.Parse("2006-01-02", s)
+
+	return err == nil
+}
+
+// isTime reports whether s is a RFC 3339 full-time.
+func isTime(s string) bool {
+	// full-time = partial-time time-offset
+	// partial-time = time-hour ":" time-minute ":" time-second [time-secfrac]
+	if len(s) < len("15:04:05Z") || s[2] != ':' || s[5] != ':' {
+		return false
+	}
+	if !isDigits(s[:2]) || !isDigits(s[3:5]) || !isDigits(s[6:8]) {
+		return false
+	}
+	hour, _ := strconv.Atoi(s[:2])
+	minute, _ := strconv.Atoi(s[3:5])
+	second, _ := strconv.Atoi(s[6:8])
+	if hour > 23 || minute > 59 || second > 60 {
+		return false
+	}
+
+	rest := s[8:]
+	if rest[0] == '.' {
+		i := 1
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 1 {
+			return false
+		}
+		rest = rest[i:]
+	}
+
+	// time-offset = "Z" / time-numoffset
+	var offset int
+	switch {
+	case rest == "Z" || rest == "z":
+	case len(rest) == len("+07:00") && (rest[0] == '+' || rest[0] == '-') && rest[3] == ':':
+		if !isDigits(rest[1:3]) || !isDigits(rest[4:]) {
+			return false
+		}
+		oh, _ := strconv.Atoi(rest[1:3])
+		om, _ := strconv.Atoi(rest[4:])
+		if oh > 23 || om > 59 {
+			return false
+		}
+		offset = oh*60 + om
+		if rest[0] == '-' {
+			offset = -offset
+		}
+	default:
+		return false
+	}
+
+	// the leap second is only allowed at the end of the day in UTC.
+	if second == 60 {
+		utc := ((hour*60+minute-offset)%(24*60) + 24*60) % (24 * 60)
+		return utc == 23*60+59
+	}
+
+	return true
+}
+
+// isDigits reports whether s consists only of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isEmail reports whether s is a RFC 5322 addr-spec.
+//
+// RFC 5322, section 3.4.1:
+//  http://tools.ietf.org/html/rfc5322#section-3.4.1
+func isEmail(s string) bool {
+	at := strings.LastIndexByte(s, '@')
+	if at <= 0 || at == len(s)-1 {
+		return false
+	}
+	local, domain := s[:at], s[at+1:]
+
+	return isEmailLocalPart(local, isAtext) && isEmailDomain(domain, isHostname)
+}
+
+// isEmailLocalPart reports whether s is a dot-atom or quoted-string local-part whose atoms consist of the characters accepted by atext.
+func isEmailLocalPart(s string, atext func(r rune) bool) bool {
+	if len(s) > 64 {
+		return false
+	}
+
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		q := s[1 : len(s)-1]
+		for i := 0; i < len(q); i++ {
+			switch c := q[i]; {
+			case c == '\\':
+				if i+1 >= len(q) {
+					return false
+				}
+				i++
+			case c == '"' || c < 0x20 || c == 0x7f:
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, atom := range strings.Split(s, ".") {
+		if atom == "" {
+			return false
+		}
+		for _, r := range atom {
+			if !atext(r) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isEmailDomain reports whether s is a hostname accepted by isHost, or a domain-literal.
+func isEmailDomain(s string, isHost func(string) bool) bool {
+	if len(s) >= 2 && s[0] == '[' && s[len(s)-1] == ']' {
+		lit := s[1 : len(s)-1]
+		if strings.HasPrefix(lit, "IPv6:") {
+			return isIPv6(lit[len("IPv6:"):])
+		}
+		return isIPv4(lit)
+	}
+
+	return isHost(s)
+}
+
+// isAtext reports whether r is a RFC 5322 atext character.
+func isAtext(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+
+	return strings.ContainsRune("!#$%&'*+-/=?^_`{|}~", r)
+}
+
+// isHostname reports whether s is a RFC 1123 host name.
+//
+// RFC 1034, section 3.1:
+//  http://tools.ietf.org/html/rfc1034#section-3.1
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 {
+			return false
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			switch c := label[i]; {
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-':
+			default:
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isIPv4 reports whether s is an IPv4 address in the dotted-quad form.
+//
+// RFC 2673, section 3.2:
+//  http://tools.ietf.org/html/rfc2673#section-3.2
+func isIPv4(s string) bool {
+	parts := strings.Split(s, ".")
+	if len(parts) != 4 {
+		return false
+	}
+
+	for _, p := range parts {
+		if !isDigits(p) || len(p) > 3 || (len(p) > 1 && p[0] == '0') {
+			return false
+		}
+		if n, _ := strconv.Atoi(p); n > 255 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isIPv6 reports whether s is an IPv6 address.
+//
+// RFC 2373, section 2.2:
+//  http://tools.ietf.org/html/rfc2373#section-2.2
+func isIPv6(s string) bool {
+	if !strings.Contains(s, ":") || strings.ContainsAny(s, "%/") {
+		return false
+	}
+
+	return net.ParseIP(s) != nil
+}
+
+// isURI reports whether s is an absolute RFC 3986 URI.
+//
+// RFC 3986:
+//  http://tools.ietf.org/html/rfc3986
+func isURI(s string) bool {
+	if !isURIChars(s) {
+		return false
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+
+	return u.Scheme != "" && isURIScheme(u.Scheme)
+}
+
+// isURIReference reports whether s is a RFC 3986 URI-reference.
+//
+// RFC 3986, section 4.1:
+//  http://tools.ietf.org/html/rfc3986#section-4.1
+func isURIReference(s string) bool {
+	if !isURIChars(s) {
+		return false
+	}
+	_, err := url.Parse(s)
+
+	return err == nil
+}
+
+// isURIScheme reports whether s is a valid URI scheme.
+func isURIScheme(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+// isURIChars reports whether s consists only of characters allowed in a RFC 3986 URI-reference,
+// and whether all percent-encodings are well-formed.
+func isURIChars(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("-._~:/?#[]@!$&'()*+,;=", c) >= 0:
+		case c == '%':
+			if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+				return false
+			}
+			i += 2
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isHex reports whether c is a hexadecimal digit.
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// isJSONPointer reports whether s is a RFC 6901 JSON Pointer.
+//
+// RFC 6901:
+//  https://tools.ietf.org/html/rfc6901
+func isJSONPointer(s string) bool {
+	_, err := jsonpointer.Parse(s)
+
+	return err == nil
+}
+
+// isRelativeJSONPointer reports whether s is a Relative JSON Pointer.
+func isRelativeJSONPointer(s string) bool {
+	_, err := jsonpointer.ParseRelative(s)
+
+	return err == nil
+}
+
+// isRegex reports whether s is a valid ECMA-262 regular expression.
+func isRegex(s string) bool {
+	err := lazyregexp.DefaultCache.Check(s, lazyregexp.ECMA262)
+
+	return err == nil
+}
+
+// isURITemplate reports whether s is a RFC 6570 URI Template.
+func isURITemplate(s string) bool {
+	return uritemplate.Validate(s) == nil
+}
+
+// isUUID reports whether s is a RFC 4122 UUID.
+//
+// RFC 4122, section 3:
+//  https://tools.ietf.org/html/rfc4122#section-3
+func isUUID(s string) bool {
+	// UUID = time-low "-" time-mid "-" time-high-and-version "-" clock-seq-and-reserved clock-seq-low "-" node
+	if len(s) != len("01234567-89ab-cdef-0123-456789abcdef") {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isDuration reports whether s is a ISO 8601 duration.
+//
+// RFC 3339, Appendix A:
+//  https://tools.ietf.org/html/rfc3339#appendix-A
+func isDuration(s string) bool {
+	// duration = "P" (dur-date / dur-time / dur-week)
+	if len(s) < 3 || s[0] != 'P' {
+		return false
+	}
+	s = s[1:]
+
+	// dur-week = 1*DIGIT "W"
+	if s[len(s)-1] == 'W' {
+		return isDigits(s[:len(s)-1])
+	}
+
+	date, tm := s, ""
+	if i := strings.IndexByte(s, 'T'); i >= 0 {
+		date, tm = s[:i], s[i+1:]
+		// dur-time = "T" (dur-hour / dur-minute / dur-second)
+		if tm == "" {
+			return false
+		}
+	}
+
+	// dur-date = (dur-day / dur-month / dur-year) [dur-time]
+	// dur-year = 1*DIGIT "Y" [dur-month], dur-month = 1*DIGIT "M" [dur-day], dur-day = 1*DIGIT "D"
+	// dur-hour = 1*DIGIT "H" [dur-minute], dur-minute = 1*DIGIT "M" [dur-second], dur-second = 1*DIGIT "S"
+	return isDurationUnits(date, "YMD") && isDurationUnits(tm, "HMS") && (date != "" || tm != "")
+}
+
+// isDurationUnits reports whether s is a sequence of the 1*DIGIT and unit pairs, where the units are
+// consecutive elements of the units.
+func isDurationUnits(s, units string) bool {
+	next := -1
+	for s != "" {
+		i := 0
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(s) {
+			return false
+		}
+		u := strings.IndexByte(units, s[i])
+		if u < 0 || (next >= 0 && u != next) {
+			return false
+		}
+		next = u + 1
+		s = s[i+1:]
+	}
+
+	return true
+}
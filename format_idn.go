@@ -0,0 +1,476 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zchee/go-jsonschema/internal/punycode"
+)
+
+// acePrefix is the ACE prefix of the IDNA A-label.
+const acePrefix = "xn--"
+
+// isIDNEmail reports whether s is a RFC 6531 internationalized email address.
+//
+// RFC 6531, section 3.3:
+//  https://tools.ietf.org/html/rfc6531#section-3.3
+func isIDNEmail(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	at := strings.LastIndexByte(s, '@')
+	if at <= 0 || at == len(s)-1 {
+		return false
+	}
+	local, domain := s[:at], s[at+1:]
+
+	return isEmailLocalPart(local, isUTF8Atext) && isEmailDomain(domain, isIDNHostname)
+}
+
+// isUTF8Atext reports whether r is a RFC 6531 atext character, which is extended by UTF8-non-ascii.
+func isUTF8Atext(r rune) bool {
+	if r >= utf8.RuneSelf {
+		return unicode.IsGraphic(r)
+	}
+
+	return isAtext(r)
+}
+
+// isIDNHostname reports whether s is an internationalized host name which conforms to the IDNA2008.
+//
+// RFC 5890, section 2.3.2.3:
+//  https://tools.ietf.org/html/rfc5890#section-2.3.2.3
+// RFC 5891, section 5.4:
+//  https://tools.ietf.org/html/rfc5891#section-5.4
+func isIDNHostname(s string) bool {
+	if s == "" || !utf8.ValidString(s) {
+		return false
+	}
+
+	// RFC 3490, section 3.1 label separators.
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '。', '．', '｡':
+			return '.'
+		}
+		return r
+	}, s)
+	s = strings.TrimSuffix(s, ".")
+
+	labels := strings.Split(s, ".")
+	ascii := 0
+	rtl := false
+	for _, label := range labels {
+		ulabel, alabel, ok := idnaLabel(label)
+		if !ok {
+			return false
+		}
+		ascii += len(alabel) + 1
+		if isRTLLabel(ulabel) {
+			rtl = true
+		}
+	}
+	if ascii-1 > 253 {
+		return false
+	}
+
+	// RFC 5893, section 2: the Bidi rule applies to all labels of the domain name if any label is an RTL label.
+	if rtl {
+		for _, label := range labels {
+			ulabel, _, _ := idnaLabel(label)
+			if !isBidiLabel(ulabel) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// idnaLabel validates the label as an IDNA2008 label and returns its U-label and A-label form.
+func idnaLabel(label string) (ulabel, alabel string, ok bool) {
+	if label == "" {
+		return "", "", false
+	}
+
+	if isASCII(label) {
+		lower := strings.ToLower(label)
+		if !strings.HasPrefix(lower, acePrefix) {
+			return label, label, len(label) <= 63 && isLDHLabel(label)
+		}
+
+		// A-label: it must decode to a valid U-label which encodes back to the same A-label.
+		u, err := punycode.Decode(lower[len(acePrefix):])
+		if err != nil || u == "" || isASCII(u) {
+			return "", "", false
+		}
+		if enc, err := punycode.Encode(u); err != nil || acePrefix+enc != lower {
+			return "", "", false
+		}
+		label = u
+	}
+
+	if !isULabel(label) {
+		return "", "", false
+	}
+	enc, err := punycode.Encode(label)
+	if err != nil {
+		return "", "", false
+	}
+	alabel = acePrefix + enc
+
+	return label, alabel, len(alabel) <= 63
+}
+
+// isASCII reports whether s consists only of ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isLDHLabel reports whether s is a letter-digit-hyphen label.
+func isLDHLabel(s string) bool {
+	if s[0] == '-' || s[len(s)-1] == '-' {
+		return false
+	}
+	// RFC 5891, section 4.2.3.1: reserved for the "xn--" and future ACE prefixes.
+	if len(s) >= 4 && s[2] == '-' && s[3] == '-' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isULabel reports whether s is a valid U-label.
+//
+// RFC 5891, section 5.4:
+//  https://tools.ietf.org/html/rfc5891#section-5.4
+func isULabel(s string) bool {
+	rs := []rune(s)
+
+	// RFC 5891, section 4.2.3.1: hyphen restrictions.
+	if rs[0] == '-' || rs[len(rs)-1] == '-' {
+		return false
+	}
+	if len(rs) >= 4 && rs[2] == '-' && rs[3] == '-' {
+		return false
+	}
+
+	// RFC 5891, section 4.2.3.2: leading combining marks.
+	if unicode.Is(unicode.M, rs[0]) {
+		return false
+	}
+
+	for i, r := range rs {
+		switch idnaProperty(r) {
+		case idnaPValid:
+		case idnaContextJ, idnaContextO:
+			if !idnaContextRule(rs, i) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// idnaDerivedProperty represents a derived property value of the RFC 5892.
+type idnaDerivedProperty int
+
+// The list of idnaDerivedProperty.
+const (
+	idnaDisallowed idnaDerivedProperty = iota
+	idnaPValid
+	idnaContextJ
+	idnaContextO
+)
+
+// idnaExceptions is the list of exceptions of the RFC 5892, section 2.6.
+var idnaExceptions = map[rune]idnaDerivedProperty{
+	'ß': idnaPValid, // LATIN SMALL LETTER SHARP S
+	'ς': idnaPValid, // GREEK SMALL LETTER FINAL SIGMA
+	'۽': idnaPValid, // ARABIC SIGN SINDHI AMPERSAND
+	'۾': idnaPValid, // ARABIC SIGN SINDHI POSTPOSITION MEN
+	'་': idnaPValid, // TIBETAN MARK INTERSYLLABIC TSHEG
+	'〇': idnaPValid, // IDEOGRAPHIC NUMBER ZERO
+
+	'·': idnaContextO, // MIDDLE DOT
+	'͵': idnaContextO, // GREEK LOWER NUMERAL SIGN (KERAIA)
+	'׳': idnaContextO, // HEBREW PUNCTUATION GERESH
+	'״': idnaContextO, // HEBREW PUNCTUATION GERSHAYIM
+	'・': idnaContextO, // KATAKANA MIDDLE DOT
+
+	'ـ': idnaDisallowed, // ARABIC TATWEEL
+	'ߺ': idnaDisallowed, // NKO LAJANYALAN
+	'〮': idnaDisallowed, // HANGUL SINGLE DOT TONE MARK
+	'〯': idnaDisallowed, // HANGUL DOUBLE DOT TONE MARK
+	'〱': idnaDisallowed, // VERTICAL KANA REPEAT MARK
+	'〲': idnaDisallowed, // VERTICAL KANA REPEAT WITH VOICED SOUND MARK
+	'〳': idnaDisallowed, // VERTICAL KANA REPEAT MARK UPPER HALF
+	'〴': idnaDisallowed, // VERTICAL KANA REPEAT WITH VOICED SOUND MARK UPPER HA
+	'〵': idnaDisallowed, // VERTICAL KANA REPEAT MARK LOWER HALF
+	'〻': idnaDisallowed, // VERTICAL IDEOGRAPHIC ITERATION MARK
+}
+
+// idnaProperty returns the derived property value of r.
+//
+// This approximates the RFC 5892 algorithm by the general categories of the unicode package,
+// because the NFKC_Casefold data is not available in the standard library.
+func idnaProperty(r rune) idnaDerivedProperty {
+	if p, ok := idnaExceptions[r]; ok {
+		return p
+	}
+
+	switch {
+	case r == '‌' || r == '‍': // ZERO WIDTH NON-JOINER and JOINER
+		return idnaContextJ
+	case r >= '٠' && r <= '٩', r >= '۰' && r <= '۹': // ARABIC-INDIC DIGITS
+		return idnaContextO
+	case r == '-':
+		return idnaPValid
+	case r < utf8.RuneSelf:
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return idnaPValid
+		}
+		return idnaDisallowed
+	case r >= 0x1100 && r <= 0x11ff: // Hangul Jamo
+		return idnaDisallowed
+	case unicode.In(r, unicode.Ll, unicode.Lo, unicode.Lm, unicode.Mn, unicode.Mc, unicode.Nd):
+		// unstable characters: the character changes under the case folding.
+		if unicode.ToLower(r) != r {
+			return idnaDisallowed
+		}
+		return idnaPValid
+	}
+
+	return idnaDisallowed
+}
+
+// idnaViramas is the list of characters whose Canonical_Combining_Class is Virama.
+var idnaViramas = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x094d, Hi: 0x094d, Stride: 1},
+		{Lo: 0x09cd, Hi: 0x09cd, Stride: 1},
+		{Lo: 0x0a4d, Hi: 0x0a4d, Stride: 1},
+		{Lo: 0x0acd, Hi: 0x0acd, Stride: 1},
+		{Lo: 0x0b4d, Hi: 0x0b4d, Stride: 1},
+		{Lo: 0x0bcd, Hi: 0x0bcd, Stride: 1},
+		{Lo: 0x0c4d, Hi: 0x0c4d, Stride: 1},
+		{Lo: 0x0ccd, Hi: 0x0ccd, Stride: 1},
+		{Lo: 0x0d3b, Hi: 0x0d3c, Stride: 1},
+		{Lo: 0x0d4d, Hi: 0x0d4d, Stride: 1},
+		{Lo: 0x0dca, Hi: 0x0dca, Stride: 1},
+		{Lo: 0x0e3a, Hi: 0x0e3a, Stride: 1},
+		{Lo: 0x0eba, Hi: 0x0eba, Stride: 1},
+		{Lo: 0x0f84, Hi: 0x0f84, Stride: 1},
+		{Lo: 0x1039, Hi: 0x103a, Stride: 1},
+		{Lo: 0x1714, Hi: 0x1715, Stride: 1},
+		{Lo: 0x1734, Hi: 0x1734, Stride: 1},
+		{Lo: 0x17d2, Hi: 0x17d2, Stride: 1},
+		{Lo: 0x1a60, Hi: 0x1a60, Stride: 1},
+		{Lo: 0x1b44, Hi: 0x1b44, Stride: 1},
+		{Lo: 0x1baa, Hi: 0x1bab, Stride: 1},
+		{Lo: 0x1bf2, Hi: 0x1bf3, Stride: 1},
+		{Lo: 0x2d7f, Hi: 0x2d7f, Stride: 1},
+		{Lo: 0xa806, Hi: 0xa806, Stride: 1},
+		{Lo: 0xa82c, Hi: 0xa82c, Stride: 1},
+		{Lo: 0xa8c4, Hi: 0xa8c4, Stride: 1},
+		{Lo: 0xa953, Hi: 0xa953, Stride: 1},
+		{Lo: 0xa9c0, Hi: 0xa9c0, Stride: 1},
+		{Lo: 0xaaf6, Hi: 0xaaf6, Stride: 1},
+		{Lo: 0xabed, Hi: 0xabed, Stride: 1},
+	},
+}
+
+// idnaContextRule reports whether the CONTEXTJ or CONTEXTO rule of rs[i] is satisfied.
+//
+// RFC 5892, Appendix A:
+//  https://tools.ietf.org/html/rfc5892#appendix-A
+func idnaContextRule(rs []rune, i int) bool {
+	r := rs[i]
+	switch {
+	case r == '‌': // ZERO WIDTH NON-JOINER
+		if i > 0 && unicode.Is(idnaViramas, rs[i-1]) {
+			return true
+		}
+		// approximates the Joining_Type rule by the Arabic script letters.
+		return i > 0 && i < len(rs)-1 && isArabicLetter(rs[i-1]) && isArabicLetter(rs[i+1])
+
+	case r == '‍': // ZERO WIDTH JOINER
+		return i > 0 && unicode.Is(idnaViramas, rs[i-1])
+
+	case r == '·': // MIDDLE DOT
+		return i > 0 && i < len(rs)-1 && rs[i-1] == 'l' && rs[i+1] == 'l'
+
+	case r == '͵': // GREEK LOWER NUMERAL SIGN (KERAIA)
+		return i < len(rs)-1 && unicode.Is(unicode.Greek, rs[i+1])
+
+	case r == '׳' || r == '״': // HEBREW PUNCTUATION GERESH and GERSHAYIM
+		return i > 0 && unicode.Is(unicode.Hebrew, rs[i-1])
+
+	case r == '・': // KATAKANA MIDDLE DOT
+		for _, c := range rs {
+			if c != '・' && unicode.In(c, unicode.Hiragana, unicode.Katakana, unicode.Han) {
+				return true
+			}
+		}
+		return false
+
+	case r >= '٠' && r <= '٩': // ARABIC-INDIC DIGITS
+		for _, c := range rs {
+			if c >= '۰' && c <= '۹' {
+				return false
+			}
+		}
+		return true
+
+	case r >= '۰' && r <= '۹': // EXTENDED ARABIC-INDIC DIGITS
+		for _, c := range rs {
+			if c >= '٠' && c <= '٩' {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// isArabicLetter reports whether r is a letter of the Arabic script.
+func isArabicLetter(r rune) bool {
+	return unicode.Is(unicode.Arabic, r) && unicode.IsLetter(r)
+}
+
+// isRTL reports whether r has the right-to-left bidirectional property.
+func isRTL(r rune) bool {
+	return unicode.In(r, unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko, unicode.Samaritan, unicode.Mandaic)
+}
+
+// isRTLLabel reports whether s contains any right-to-left characters.
+func isRTLLabel(s string) bool {
+	for _, r := range s {
+		if isRTL(r) && !unicode.IsDigit(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isBidiLabel reports whether s satisfies the Bidi rule.
+//
+// This approximates the bidirectional properties by the scripts and the general categories of the unicode package.
+//
+// RFC 5893, section 2:
+//  https://tools.ietf.org/html/rfc5893#section-2
+func isBidiLabel(s string) bool {
+	rs := []rune(s)
+	if !isRTLLabel(s) {
+		// LTR label: it must start with a letter and must not contain the Arabic digits.
+		if !unicode.IsLetter(rs[0]) {
+			return false
+		}
+		for _, r := range rs {
+			if isRTL(r) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// RTL label.
+	if !isRTL(rs[0]) || !unicode.IsLetter(rs[0]) {
+		return false
+	}
+	for _, r := range rs {
+		if unicode.IsLetter(r) && !isRTL(r) {
+			return false
+		}
+	}
+	last := len(rs) - 1
+	for last > 0 && unicode.Is(unicode.Mn, rs[last]) {
+		last--
+	}
+
+	return isRTL(rs[last]) || unicode.IsDigit(rs[last])
+}
+
+// isIRI reports whether s is an absolute RFC 3987 IRI.
+//
+// RFC 3987:
+//  https://tools.ietf.org/html/rfc3987
+func isIRI(s string) bool {
+	u, ok := iriToURI(s)
+	return ok && isURI(u)
+}
+
+// isIRIReference reports whether s is a RFC 3987 IRI-reference.
+func isIRIReference(s string) bool {
+	u, ok := iriToURI(s)
+	return ok && isURIReference(u)
+}
+
+// iriToURI maps s IRI to URI by the percent-encoding of the non-ASCII characters.
+//
+// RFC 3987, section 3.1:
+//  https://tools.ietf.org/html/rfc3987#section-3.1
+func iriToURI(s string) (string, bool) {
+	if !utf8.ValidString(s) {
+		return "", false
+	}
+
+	query := strings.IndexByte(s, '?')
+	fragment := strings.IndexByte(s, '#')
+	var b strings.Builder
+	for i, r := range s {
+		if r < utf8.RuneSelf {
+			b.WriteByte(byte(r))
+			continue
+		}
+		// iprivate is only allowed in the query component.
+		inQuery := query >= 0 && i > query && (fragment < 0 || i < fragment)
+		if !isUcschar(r) && !(inQuery && isIprivate(r)) {
+			return "", false
+		}
+		b.WriteString(url.QueryEscape(string(r)))
+	}
+
+	return b.String(), true
+}
+
+// isUcschar reports whether r is a RFC 3987 ucschar.
+func isUcschar(r rune) bool {
+	switch {
+	case r >= 0xa0 && r <= 0xd7ff, r >= 0xf900 && r <= 0xfdcf, r >= 0xfdf0 && r <= 0xffef:
+		return true
+	case r >= 0x10000 && r <= 0xeffff:
+		return r&0xfffe != 0xfffe && r < 0xe0000 || r >= 0xe1000 && r <= 0xefffd
+	}
+
+	return false
+}
+
+// isIprivate reports whether r is a RFC 3987 iprivate.
+func isIprivate(r rune) bool {
+	return r >= 0xe000 && r <= 0xf8ff || r >= 0xf0000 && r <= 0xffffd || r >= 0x100000 && r <= 0x10fffd
+}
@@ -0,0 +1,49 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import "testing"
+
+func TestIDNFormatCheckers(t *testing.T) {
+	tests := []struct {
+		format Format
+		input  string
+		want   bool
+	}{
+		{format: FormatIDNEmail, input: "실례@실례.테스트", want: true},
+		{format: FormatIDNEmail, input: "joe.bloggs@example.com", want: true},
+		{format: FormatIDNEmail, input: "2962", want: false},
+		{format: FormatIDNHostname, input: "실례.테스트", want: true},
+		{format: FormatIDNHostname, input: "xn--ihqwcrb4cv8a8dqg056pqjye", want: true},
+		{format: FormatIDNHostname, input: "xn--X", want: false},
+		{format: FormatIDNHostname, input: "〮실례.테스트", want: false},
+		{format: FormatIDNHostname, input: "-> $1.00 <--", want: false},
+		{format: FormatIDNHostname, input: "l·l", want: true},
+		{format: FormatIDNHostname, input: "a·l", want: false},
+		{format: FormatIDNHostname, input: "א׳ב", want: true},
+		{format: FormatIDNHostname, input: "a׳ב", want: false},
+		{format: FormatIRI, input: "http://ƒøø.ßår/?∂éœ=πîx#πîüx", want: true},
+		{format: FormatIRI, input: "http://[2001:0db8:85a3:0000:0000:8a2e:0370:7334]", want: true},
+		{format: FormatIRI, input: "/abc", want: false},
+		{format: FormatIRI, input: "http:// ƒøø.com", want: false},
+		{format: FormatIRIReference, input: "//ƒøø.ßår/?∂éœ=πîx#πîüx", want: true},
+		{format: FormatIRIReference, input: "#ƒrägmênt", want: true},
+		{format: FormatIRIReference, input: "\\\\WINDOWS\\filëßåré", want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.format)+"/"+tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			c, ok := DefaultFormatRegistry.Lookup(tt.format)
+			if !ok {
+				t.Fatalf("format %q is not registered", tt.format)
+			}
+			if got := c.IsFormat(tt.input); got != tt.want {
+				t.Errorf("IsFormat(%q) = %t, want %t", tt.input, got, tt.want)
+			}
+		})
+	}
+}
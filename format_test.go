@@ -0,0 +1,295 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuiltinFormatCheckers(t *testing.T) {
+	tests := []struct {
+		format Format
+		input  string
+		want   bool
+	}{
+		{format: FormatDateTime, input: "2018-11-13T20:20:39+00:00", want: true},
+		{format: FormatDateTime, input: "1963-06-19T08:30:06.283185Z", want: true},
+		{format: FormatDateTime, input: "1990-12-31T23:59:60Z", want: true},
+		{format: FormatDateTime, input: "2018-11-13 20:20:39Z", want: false},
+		{format: FormatDateTime, input: "2018-02-30T20:20:39Z", want: false},
+		{format: FormatDateTime, input: "2018-11-13T20:20:39", want: false},
+		{format: FormatDate, input: "2020-02-29", want: true},
+		{format: FormatDate, input: "2019-02-29", want: false},
+		{format: FormatDate, input: "2018-1-13", want: false},
+		{format: FormatTime, input: "20:20:39+00:00", want: true},
+		{format: FormatTime, input: "08:30:06.283185Z", want: true},
+		{format: FormatTime, input: "24:00:00Z", want: false},
+		{format: FormatTime, input: "08:30:06", want: false},
+		{format: FormatEmail, input: "joe.bloggs@example.com", want: true},
+		{format: FormatEmail, input: "te~st@example.com", want: true},
+		{format: FormatEmail, input: "2962", want: false},
+		{format: FormatEmail, input: ".test@example.com", want: false},
+		{format: FormatEmail, input: "te..st@example.com", want: false},
+		{format: FormatHostname, input: "www.example.com", want: true},
+		{format: FormatHostname, input: "xn--4gbwdl.xn--wgbh1c", want: true},
+		{format: FormatHostname, input: "-a-host-name-that-starts-with--", want: false},
+		{format: FormatHostname, input: "not_a_valid_host_name", want: false},
+		{format: FormatIPv4, input: "192.168.0.1", want: true},
+		{format: FormatIPv4, input: "127.0.0.0.1", want: false},
+		{format: FormatIPv4, input: "256.256.256.256", want: false},
+		{format: FormatIPv4, input: "087.10.0.1", want: false},
+		{format: FormatIPv6, input: "::1", want: true},
+		{format: FormatIPv6, input: "::abef", want: true},
+		{format: FormatIPv6, input: "12345::", want: false},
+		{format: FormatIPv6, input: "1::d6::42", want: false},
+		{format: FormatURI, input: "http://foo.bar/?baz=qux#quux", want: true},
+		{format: FormatURI, input: "urn:oasis:names:specification:docbook:dtd:xml:4.1.2", want: true},
+		{format: FormatURI, input: "//foo.bar/?baz=qux#quux", want: false},
+		{format: FormatURI, input: "http:// shouldfail.com", want: false},
+		{format: FormatURIReference, input: "/abc", want: true},
+		{format: FormatURIReference, input: "#fragment", want: true},
+		{format: FormatURIReference, input: "\\\\WINDOWS\\fileshare", want: false},
+		{format: FormatJSONPointer, input: "/foo/bar~0/baz~1/%a", want: true},
+		{format: FormatJSONPointer, input: "", want: true},
+		{format: FormatJSONPointer, input: "/foo/bar~", want: false},
+		{format: FormatJSONPointer, input: "foo", want: false},
+		{format: FormatRelativeJSONPointer, input: "1/foo", want: true},
+		{format: FormatRelativeJSONPointer, input: "0#", want: true},
+		{format: FormatRelativeJSONPointer, input: "/foo/bar", want: false},
+		{format: FormatRelativeJSONPointer, input: "01/a", want: false},
+		{format: FormatRegex, input: "([abc])+\\s+$", want: true},
+		{format: FormatRegex, input: "^(abc]", want: false},
+		{format: FormatURITemplate, input: "http://example.com/dictionary/{term:1}/{term}", want: true},
+		{format: FormatURITemplate, input: "http://example.com/dictionary/{term:1}/{term", want: false},
+		{format: FormatUUID, input: "2eb8aa08-aa98-11ea-b4aa-73b441d16380", want: true},
+		{format: FormatUUID, input: "2eb8aa08-aa98-11ea-b4aa-73b441d1638", want: false},
+		{format: FormatUUID, input: "2eb8aa08aa9811eab4aa73b441d16380", want: false},
+		{format: FormatDuration, input: "P4DT12H30M5S", want: true},
+		{format: FormatDuration, input: "P2W", want: true},
+		{format: FormatDuration, input: "PT1D", want: false},
+		{format: FormatDuration, input: "P", want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.format)+"/"+tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			c, ok := DefaultFormatRegistry.Lookup(tt.format)
+			if !ok {
+				t.Fatalf("format %q is not registered", tt.format)
+			}
+			if got := c.IsFormat(tt.input); got != tt.want {
+				t.Errorf("IsFormat(%q) = %t, want %t", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatRegistry(t *testing.T) {
+	even := FormatCheckerFunc(func(s string) bool { return len(s)%2 == 0 })
+
+	tests := []struct {
+		name    string
+		setup   func(r *FormatRegistry)
+		format  Format
+		input   string
+		wantErr bool
+	}{
+		{
+			name:    "assertion",
+			format:  FormatIPv4,
+			input:   "256.0.0.1",
+			wantErr: true,
+		},
+		{
+			name:   "annotation",
+			setup:  func(r *FormatRegistry) { r.SetMode(FormatAnnotation) },
+			format: FormatIPv4,
+			input:  "256.0.0.1",
+		},
+		{
+			name:   "unknown format",
+			format: Format("unknown"),
+			input:  "anything",
+		},
+		{
+			name:    "registered",
+			setup:   func(r *FormatRegistry) { r.Register("even", even) },
+			format:  "even",
+			input:   "odd",
+			wantErr: true,
+		},
+		{
+			name:   "unregistered",
+			setup:  func(r *FormatRegistry) { r.Unregister(FormatIPv4) },
+			format: FormatIPv4,
+			input:  "256.0.0.1",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := NewFormatRegistry(FormatAssertion)
+			if tt.setup != nil {
+				tt.setup(r)
+			}
+			err := r.Check(tt.format, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check(%q, %q) = %v, wantErr %t", tt.format, tt.input, err, tt.wantErr)
+			}
+			var ferr *FormatError
+			if err != nil && !errors.As(err, &ferr) {
+				t.Errorf("Check(%q, %q) = %T, want *FormatError", tt.format, tt.input, err)
+			}
+		})
+	}
+}
+
+func TestFormatRegistryClone(t *testing.T) {
+	r := NewFormatRegistry(FormatAssertion)
+	c := r.Clone()
+	c.SetMode(FormatAnnotation)
+	c.Unregister(FormatIPv4)
+
+	if r.Mode() != FormatAssertion {
+		t.Errorf("Mode() = %v after changing the clone, want %v", r.Mode(), FormatAssertion)
+	}
+	if _, ok := r.Lookup(FormatIPv4); !ok {
+		t.Errorf("%q is unregistered by unregistering it from the clone", FormatIPv4)
+	}
+}
+
+func TestFormatOptions(t *testing.T) {
+	even := FormatCheckerFunc(func(s string) bool { return len(s)%2 == 0 })
+	schema := &Schema{Format: "even"}
+
+	tests := []struct {
+		name  string
+		opts  []Option
+		input string
+		want  bool
+	}{
+		{
+			name:  "checker",
+			opts:  []Option{WithFormatChecker("even", even)},
+			input: "odd",
+			want:  false,
+		},
+		{
+			name:  "checker before registry",
+			opts:  []Option{WithFormatChecker("even", even), WithFormatRegistry(NewFormatRegistry(FormatAssertion))},
+			input: "odd",
+			want:  false,
+		},
+		{
+			name:  "checker after registry",
+			opts:  []Option{WithFormatRegistry(NewFormatRegistry(FormatAssertion)), WithFormatChecker("even", even)},
+			input: "odd",
+			want:  false,
+		},
+		{
+			name: "mode before registry",
+			opts: []Option{
+				WithFormatChecker("even", even),
+				WithFormatMode(FormatAnnotation),
+				WithFormatRegistry(NewFormatRegistry(FormatAssertion)),
+			},
+			input: "odd",
+			want:  true,
+		},
+		{
+			name:  "mode of registry",
+			opts:  []Option{WithFormatRegistry(NewFormatRegistry(FormatAnnotation)), WithFormatChecker("even", even)},
+			input: "odd",
+			want:  true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			v, err := Compile(schema, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := v.IsValid(tt.input); got != tt.want {
+				t.Errorf("IsValid(%q) = %t, want %t", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileDefaultFormatMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		opts   []Option
+		want   bool
+	}{
+		{
+			name:   "draft-07",
+			schema: `{"$schema": "http://json-schema.org/draft-07/schema#", "format": "email"}`,
+			want:   false,
+		},
+		{
+			name:   "no $schema",
+			schema: `{"format": "email"}`,
+			want:   false,
+		},
+		{
+			name:   "2019-09",
+			schema: `{"$schema": "https://json-schema.org/draft/2019-09/schema", "format": "email"}`,
+			want:   true,
+		},
+		{
+			name:   "2019-09 with mode",
+			schema: `{"$schema": "https://json-schema.org/draft/2019-09/schema", "format": "email"}`,
+			opts:   []Option{WithFormatMode(FormatAssertion)},
+			want:   false,
+		},
+		{
+			name:   "2019-09 with registry",
+			schema: `{"$schema": "https://json-schema.org/draft/2019-09/schema", "format": "email"}`,
+			opts:   []Option{WithFormatRegistry(NewFormatRegistry(FormatAssertion))},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			v, err := Compile(mustSchema(t, tt.schema), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := v.IsValid("x"); got != tt.want {
+				t.Errorf("IsValid(%q) = %t, want %t", "x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileCopiesFormatRegistry(t *testing.T) {
+	r := NewFormatRegistry(FormatAssertion)
+	v := MustCompile(&Schema{Format: FormatIPv4}, WithFormatRegistry(r))
+	r.SetMode(FormatAnnotation)
+
+	if v.IsValid("256.0.0.1") {
+		t.Error("the Validator is changed by the FormatRegistry after the Compile")
+	}
+
+	w := MustCompile(&Schema{Format: "even"}, WithFormatChecker("even", FormatCheckerFunc(func(string) bool { return false })))
+	if w.IsValid("a") {
+		t.Error("WithFormatChecker is not applied")
+	}
+	if _, ok := DefaultFormatRegistry.Lookup("even"); ok {
+		t.Error("WithFormatChecker registers the checker to DefaultFormatRegistry")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// apiSchemas returns the contents of all files under the api directory, which seed the fuzz targets.
+func apiSchemas(tb testing.TB) [][]byte {
+	tb.Helper()
+
+	var seeds [][]byte
+	err := filepath.Walk("api", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) == ".go" {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		seeds = append(seeds, data)
+		return nil
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return seeds
+}
+
+// FuzzUnmarshalJSON checks that decoding any input and resetting the decoded schema do not panic.
+func FuzzUnmarshalJSON(f *testing.F) {
+	for _, seed := range apiSchemas(f) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s Schema
+		if err := s.UnmarshalJSON(data); err != nil {
+			return
+		}
+		if s.AdditionalItems != nil {
+			s.AdditionalItems.Reset()
+		}
+		if s.AdditionalProperties != nil {
+			s.AdditionalProperties.Reset()
+		}
+		s.Reset()
+	})
+}
+
+// FuzzRoundTrip checks that the encoding of the decoded schema is decoded again, and is stable.
+func FuzzRoundTrip(f *testing.F) {
+	for _, seed := range apiSchemas(f) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s Schema
+		if err := s.UnmarshalJSON(data); err != nil {
+			return
+		}
+		b1, err := s.MarshalJSON()
+		if err != nil {
+			t.Fatalf("marshal decoded schema: %v", err)
+		}
+
+		var s2 Schema
+		if err := s2.UnmarshalJSON(b1); err != nil {
+			t.Fatalf("unmarshal encoded schema %s: %v", b1, err)
+		}
+		b2, err := s2.MarshalJSON()
+		if err != nil {
+			t.Fatalf("marshal round-tripped schema: %v", err)
+		}
+		if !bytes.Equal(b1, b2) {
+			t.Fatalf("round trip is not stable:\n%s\n%s", b1, b2)
+		}
+	})
+}
+
+// The maximum sizes of the schema and the instance of the FuzzValidate, beyond which the fuzzing stalls
+// minimizing the interesting inputs.
+const (
+	maxFuzzSchemaSize   = 1 << 10
+	maxFuzzInstanceSize = 256
+)
+
+// fuzzSchemas is the list of the small schemas which seed the FuzzValidate with the small api schemas.
+var fuzzSchemas = []string{
+	`{"type": "object", "properties": {"a": {"type": "integer", "minimum": 0}}, "required": ["a"], "additionalProperties": false}`,
+	`{"type": "array", "items": [{"type": "string", "maxLength": 2}], "additionalItems": {"type": "null"}, "uniqueItems": true}`,
+	`{"anyOf": [{"type": "number", "multipleOf": 0.5}, {"pattern": "^a+$"}], "not": {"const": 0}}`,
+	`{"definitions": {"n": {"items": {"$ref": "#/definitions/n"}, "maxItems": 2}}, "$ref": "#/definitions/n"}`,
+	`{"if": {"type": "string"}, "then": {"format": "date"}, "else": {"enum": [1, true, null]}}`,
+}
+
+// fuzzInstances is the list of the small instances of each type, which seed the FuzzValidate.
+var fuzzInstances = []string{`null`, `true`, `0`, `-1.5`, `"a"`, `[1, "a", null]`, `{"a": {"b": []}}`}
+
+// FuzzValidate checks that the validation of any instance against any compilable schema does not panic,
+// and that IsValid agrees with Validate.
+func FuzzValidate(f *testing.F) {
+	schemas := apiSchemas(f)
+	for _, schema := range fuzzSchemas {
+		schemas = append(schemas, []byte(schema))
+	}
+	for _, schema := range schemas {
+		if len(schema) > maxFuzzSchemaSize {
+			continue
+		}
+		for _, instance := range fuzzInstances {
+			f.Add(schema, []byte(instance))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, schema, instance []byte) {
+		if len(schema) > maxFuzzSchemaSize || len(instance) > maxFuzzInstanceSize {
+			return
+		}
+
+		var s Schema
+		if err := s.UnmarshalJSON(schema); err != nil {
+			return
+		}
+		v, err := Compile(&s)
+		if err != nil {
+			return
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(instance))
+		dec.UseNumber()
+		var inst interface{}
+		if err := dec.Decode(&inst); err != nil {
+			return
+		}
+		err = v.Validate(inst)
+		if valid := v.IsValid(inst); valid != (err == nil) {
+			t.Fatalf("IsValid reports %t, but Validate returns %v", valid, err)
+		}
+		_ = v.ValidateReader(bytes.NewReader(instance))
+	})
+}
@@ -0,0 +1,840 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The list of limits of the Generate.
+const (
+	// maxGenerateDepth is the number of times a schema is generated inside itself through the recursive "$ref"s,
+	// beyond which only the required properties and the minimum items are generated.
+	maxGenerateDepth = 3
+
+	// maxGenerateNesting is the maximum nesting of the generated values.
+	maxGenerateNesting = 64
+
+	// generateAttempts is the number of the candidates generated for a schema until one of them is valid.
+	generateAttempts = 8
+
+	// generateBudget is the maximum number of the candidate validations in a Generate.
+	generateBudget = 10000
+
+	// generateSpan is the width of the numeric range used when it is not bounded.
+	generateSpan = 100
+
+	// stringSpan and arraySpan are the widths of the length ranges of the strings and the arrays used
+	// when they are not bounded.
+	stringSpan = 10
+	arraySpan  = 4
+)
+
+// Generate returns a random instance which is valid against s, which is chosen by rand.
+//
+// The instance respects the "type", the numeric bounds and the "multipleOf", the string lengths, the "pattern",
+// which is generated from its regular expression syntax, the "format", the "enum" and the "const", the "required"
+// properties, and the array bounds and the "uniqueItems". The "allOf" members are merged by the Merge, a branch of
+// the "anyOf" and the "oneOf" is chosen at random, and the "$ref"s are followed until a schema is nested in itself
+// maxGenerateDepth times, beyond which the optional properties and items are omitted.
+//
+// Each candidate is validated against s, and the candidate is regenerated if it is invalid, so the keywords such as
+// the "not" are also satisfied in most cases. Generate returns the last candidate if no valid one is found, and nil
+// if s is nil or the false schema.
+//
+// The instance is composed of nil, bool, int64, float64, string, []interface{} and map[string]interface{}, and is
+// the same for the same seed of rand.
+func Generate(s *Schema, rand *rand.Rand) interface{} {
+	if s == nil {
+		return nil
+	}
+	g := &generator{
+		rand:   rand,
+		depth:  make(map[*Schema]int),
+		budget: generateBudget,
+	}
+	if v, err := Compile(s); err == nil {
+		g.v = v
+	}
+
+	return g.generate(s)
+}
+
+// generator represents a state of the Generate.
+type generator struct {
+	rand *rand.Rand
+
+	// v validates the candidates, or nil if the schema cannot be compiled.
+	v *Validator
+
+	// depth is the number of the expansions of the schema in the current path.
+	depth map[*Schema]int
+
+	// nesting is the nesting of the current value.
+	nesting int
+
+	// minimal is the number of the schemas in the current path which are expanded more than maxGenerateDepth times.
+	minimal int
+
+	// budget is the remaining number of the candidate validations.
+	budget int
+}
+
+// valid reports whether inst is valid against s.
+func (g *generator) valid(s *Schema, inst interface{}) bool {
+	if g.v == nil || g.budget <= 0 {
+		return true
+	}
+	g.budget--
+	st := &state{v: g.v, failFast: true}
+	st.validate(s, inst, "", "")
+
+	return len(st.errs) == 0
+}
+
+// generate returns a random instance of s.
+func (g *generator) generate(s *Schema) interface{} {
+	if s == nil || g.nesting > maxGenerateNesting {
+		return nil
+	}
+	t := g.resolve(s)
+	if t == nil {
+		return nil
+	}
+
+	g.depth[t]++
+	if g.depth[t] == maxGenerateDepth+1 {
+		g.minimal++
+	}
+	g.nesting++
+	defer func() {
+		g.nesting--
+		if g.depth[t] == maxGenerateDepth+1 {
+			g.minimal--
+		}
+		g.depth[t]--
+	}()
+
+	var inst interface{}
+	for i := 0; i < generateAttempts; i++ {
+		inst = g.candidate(t)
+		if g.valid(s, inst) {
+			break
+		}
+	}
+
+	return inst
+}
+
+// resolve returns the schema referenced by s through the "$ref"s, or nil if it cannot be resolved.
+func (g *generator) resolve(s *Schema) *Schema {
+	for i := 0; s != nil && s.Ref != ""; i++ {
+		if g.v == nil || i > maxGenerateNesting {
+			return nil
+		}
+		s = g.v.refs[s]
+	}
+
+	return s
+}
+
+// candidate returns a random instance of s, which does not have the "$ref", without the validation.
+func (g *generator) candidate(s *Schema) interface{} {
+	if s.Bool != nil {
+		if !*s.Bool {
+			return nil
+		}
+		return g.scalar()
+	}
+
+	m := g.merge(s)
+	if m.Bool != nil {
+		return nil
+	}
+	if m.Const != nil {
+		return copyValue(m.Const.Interface())
+	}
+	if len(m.Enum) > 0 {
+		return copyValue(m.Enum[g.rand.Intn(len(m.Enum))].Interface())
+	}
+
+	switch g.chooseType(m) {
+	case NullType:
+		return nil
+	case BooleanType:
+		return g.rand.Intn(2) == 1
+	case IntegerType:
+		return g.integer(m)
+	case NumberType:
+		return g.number(m)
+	case StringType:
+		return g.string(m)
+	case ArrayType:
+		return g.array(m)
+	case ObjectType:
+		return g.object(m)
+	}
+
+	return g.scalar()
+}
+
+// merge returns the Merge of s with its "allOf" members and a random branch of its "anyOf", "oneOf" and "if".
+func (g *generator) merge(s *Schema) *Schema {
+	return g.mergeNested(s, 0)
+}
+
+// mergeNested is like merge, but the members are merged recursively up to maxGenerateNesting levels, since the Merge
+// does not combine the members which have the "$ref" or the "$id".
+func (g *generator) mergeNested(s *Schema, level int) *Schema {
+	if len(s.AllOf) == 0 && len(s.AnyOf) == 0 && len(s.OneOf) == 0 && s.If == nil {
+		return s
+	}
+
+	base := *s
+	base.AllOf, base.AnyOf, base.OneOf = nil, nil, nil
+	base.If, base.Then, base.Else = nil, nil, nil
+	members := []*Schema{&base}
+	add := func(sub *Schema) {
+		t := g.resolve(sub)
+		if t == nil || level >= maxGenerateNesting {
+			return
+		}
+		t = g.mergeNested(t, level+1)
+		if t.ID != "" {
+			c := *t
+			c.ID = ""
+			t = &c
+		}
+		members = append(members, t)
+	}
+	for _, sub := range s.AllOf {
+		add(sub)
+	}
+	if len(s.AnyOf) > 0 {
+		add(s.AnyOf[g.rand.Intn(len(s.AnyOf))])
+	}
+	if len(s.OneOf) > 0 {
+		add(s.OneOf[g.rand.Intn(len(s.OneOf))])
+	}
+	if s.If != nil {
+		cond := g.resolve(s.If)
+		if cond != nil && cond.Bool != nil && *cond.Bool || !g.isFalse(cond) && g.rand.Intn(2) == 0 {
+			add(s.If)
+			add(s.Then)
+		} else {
+			add(s.Else)
+		}
+	}
+
+	m := Merge(members...)
+	if m.Bool == nil && len(m.AllOf) > 0 {
+		// the members which cannot be merged are satisfied by the validation of the candidates, except the
+		// different "multipleOf"s which are rarely satisfied at random.
+		c := *m
+		c.AllOf = nil
+		for _, sub := range m.AllOf {
+			if sub.Ref == "" && sub.MultipleOf != 0 {
+				c.MultipleOf = lcm(c.MultipleOf, sub.MultipleOf)
+			}
+		}
+		m = &c
+	}
+
+	return m
+}
+
+// lcm returns the least common multiple of the positive numbers a and b, or b if a is 0.
+func lcm(a, b float64) float64 {
+	if a == 0 {
+		return b
+	}
+	x, y := floatRat(a), floatRat(b)
+	num := new(big.Int).Mul(x.Num(), y.Num())
+	num.Quo(num, new(big.Int).GCD(nil, nil, x.Num(), y.Num()))
+	den := new(big.Int).GCD(nil, nil, x.Denom(), y.Denom())
+	f, _ := new(big.Rat).SetFrac(num, den).Float64()
+
+	return f
+}
+
+// isFalse reports whether s is the false schema through the "$ref"s.
+func (g *generator) isFalse(s *Schema) bool {
+	t := g.resolve(s)
+
+	return t != nil && t.Bool != nil && !*t.Bool
+}
+
+// chooseType returns a random type of the instance of s.
+//
+// The type is inferred from the keywords if s does not have the "type".
+func (g *generator) chooseType(s *Schema) Type {
+	types := s.Type
+	if len(types) == 0 {
+		switch {
+		case len(s.Properties) > 0 || len(s.Required) > 0 || s.AdditionalProperties != nil ||
+			len(s.PatternProperties) > 0 || s.MinProperties > 0 || s.MaxProperties != nil || s.Dependencies != nil:
+			types = Types{ObjectType}
+		case (s.Items != nil || s.Contains != nil || s.MinItems > 0 || s.MaxItems != nil || s.UniqueItems) &&
+			(s.Contains == nil || !g.isFalse(s.Contains.Schema)):
+			types = Types{ArrayType}
+		case s.Pattern != nil || s.Format != "" || s.MinLength > 0 || s.MaxLength != nil:
+			types = Types{StringType}
+		case s.MultipleOf != 0 || s.Maximum != nil || s.Minimum != nil || s.ExclusiveMaximum != nil ||
+			s.ExclusiveMinimum != nil:
+			types = Types{NumberType}
+		default:
+			types = Types{NullType, BooleanType, IntegerType, NumberType, StringType}
+			if g.minimal == 0 {
+				types = append(types, ArrayType, ObjectType)
+			}
+		}
+	}
+	if s.Contains != nil && g.isFalse(s.Contains.Schema) && len(types) > 1 {
+		// no array contains the item of the false schema.
+		filtered := make(Types, 0, len(types))
+		for _, t := range types {
+			if t != ArrayType {
+				filtered = append(filtered, t)
+			}
+		}
+		types = filtered
+	}
+
+	return types[g.rand.Intn(len(types))]
+}
+
+// scalar returns a random value of a scalar type.
+func (g *generator) scalar() interface{} {
+	switch g.rand.Intn(5) {
+	case 0:
+		return nil
+	case 1:
+		return g.rand.Intn(2) == 1
+	case 2:
+		return int64(g.rand.Intn(2*generateSpan+1) - generateSpan)
+	case 3:
+		return math.Round((g.rand.Float64()*2-1)*generateSpan*1000) / 1000
+	}
+
+	return g.letters(1 + g.rand.Intn(10))
+}
+
+// bounds returns the inclusive numeric range of s, and whether the bounds are exclusive.
+func bounds(s *Schema) (lo, hi float64, loExcl, hiExcl bool) {
+	lo, hi = math.Inf(-1), math.Inf(1)
+	if s.Minimum != nil {
+		lo = *s.Minimum
+	}
+	if s.ExclusiveMinimum != nil && *s.ExclusiveMinimum >= lo {
+		lo, loExcl = *s.ExclusiveMinimum, true
+	}
+	if s.Maximum != nil {
+		hi = *s.Maximum
+	}
+	if s.ExclusiveMaximum != nil && *s.ExclusiveMaximum <= hi {
+		hi, hiExcl = *s.ExclusiveMaximum, true
+	}
+	switch {
+	case math.IsInf(lo, -1) && math.IsInf(hi, 1):
+		lo, hi = -generateSpan, generateSpan
+	case math.IsInf(lo, -1):
+		lo = hi - 2*generateSpan
+	case math.IsInf(hi, 1):
+		hi = lo + 2*generateSpan
+	}
+
+	return lo, hi, loExcl, hiExcl
+}
+
+// integer returns a random integer instance of s.
+func (g *generator) integer(s *Schema) interface{} {
+	lo, hi, loExcl, hiExcl := bounds(s)
+	min, max := math.Ceil(lo), math.Floor(hi)
+	if loExcl && min == lo {
+		min++
+	}
+	if hiExcl && max == hi {
+		max--
+	}
+
+	step := big.NewRat(1, 1)
+	if s.MultipleOf > 0 {
+		// the integer multiples of p/q are the multiples of p.
+		step = new(big.Rat).SetInt(floatRat(s.MultipleOf).Num())
+	}
+	if r, ok := g.multiple(step, min, max); ok {
+		return r.Num().Int64()
+	}
+
+	return int64(min)
+}
+
+// number returns a random number instance of s.
+func (g *generator) number(s *Schema) interface{} {
+	lo, hi, loExcl, hiExcl := bounds(s)
+	if s.MultipleOf > 0 {
+		min, max := lo, hi
+		if loExcl {
+			min = math.Nextafter(lo, math.Inf(1))
+		}
+		if hiExcl {
+			max = math.Nextafter(hi, math.Inf(-1))
+		}
+		if r, ok := g.multiple(floatRat(s.MultipleOf), min, max); ok {
+			f, _ := r.Float64()
+			return f
+		}
+		return lo
+	}
+
+	for i := 0; i < generateAttempts; i++ {
+		f := math.Round((lo+g.rand.Float64()*(hi-lo))*1000) / 1000
+		if (f > lo || (!loExcl && f == lo)) && (f < hi || (!hiExcl && f == hi)) {
+			return f
+		}
+	}
+
+	return (lo + hi) / 2
+}
+
+// multiple returns a random multiple of step in the [min, max] range.
+func (g *generator) multiple(step *big.Rat, min, max float64) (*big.Rat, bool) {
+	if min > max || step.Sign() <= 0 {
+		return nil, false
+	}
+	lo, hi := new(big.Rat), new(big.Rat)
+	lo.SetFloat64(min)
+	hi.SetFloat64(max)
+
+	// k ranges from ceil(min/step) to floor(max/step).
+	kmin := ratCeil(new(big.Rat).Quo(lo, step))
+	kmax := ratFloor(new(big.Rat).Quo(hi, step))
+	if kmin.Cmp(kmax) > 0 {
+		return nil, false
+	}
+	span := new(big.Int).Sub(kmax, kmin)
+	if span.IsInt64() && span.Int64() < math.MaxInt32 {
+		kmin.Add(kmin, big.NewInt(g.rand.Int63n(span.Int64()+1)))
+	}
+
+	return new(big.Rat).Mul(new(big.Rat).SetInt(kmin), step), true
+}
+
+// ratFloor returns the floor of r.
+func ratFloor(r *big.Rat) *big.Int {
+	// the denominator is positive, so the Euclidean division is the floor division.
+	return new(big.Int).Div(r.Num(), r.Denom())
+}
+
+// ratCeil returns the ceiling of r.
+func ratCeil(r *big.Rat) *big.Int {
+	q := ratFloor(r)
+	if !r.IsInt() {
+		q.Add(q, big.NewInt(1))
+	}
+
+	return q
+}
+
+// lengthRange returns the random length in the [min, max] range, where max is min+span if nil.
+func (g *generator) lengthRange(min int64, max *int64, span int64) int {
+	hi := min + span
+	if max != nil && *max < hi {
+		hi = *max
+	}
+	if hi <= min {
+		return int(min)
+	}
+
+	return int(min) + g.rand.Intn(int(hi-min)+1)
+}
+
+// string returns a random string instance of s.
+func (g *generator) string(s *Schema) interface{} {
+	if s.Format != "" {
+		if str, ok := g.format(s.Format); ok {
+			return str
+		}
+	}
+	if s.Pattern != nil {
+		if re, err := syntax.Parse(s.Pattern.String(), syntax.Perl); err == nil {
+			str := g.regexp(re.Simplify())
+			n := int64(utf8.RuneCountInString(str))
+			if n < s.MinLength {
+				// the unanchored pattern matches the substring.
+				str += g.letters(int(s.MinLength - n))
+			}
+			return str
+		}
+	}
+
+	return g.letters(g.lengthRange(s.MinLength, s.MaxLength, stringSpan))
+}
+
+// letters returns a random string of the n lowercase letters.
+func (g *generator) letters(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('a' + g.rand.Intn(26))
+	}
+
+	return string(b)
+}
+
+// digits returns a random string of the n digits.
+func (g *generator) digits(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + g.rand.Intn(10))
+	}
+
+	return string(b)
+}
+
+// format returns a random string of the format f, and whether f is known.
+func (g *generator) format(f Format) (string, bool) {
+	switch f {
+	case FormatDateTime:
+		return g.date() + "T" + g.time(), true
+	case FormatDate:
+		return g.date(), true
+	case FormatTime:
+		return g.time(), true
+	case FormatEmail, FormatIDNEmail:
+		return g.letters(1+g.rand.Intn(8)) + "@" + g.hostname(), true
+	case FormatHostname, FormatIDNHostname:
+		return g.hostname(), true
+	case FormatIPv4:
+		return fmt.Sprintf("%d.%d.%d.%d", g.rand.Intn(256), g.rand.Intn(256), g.rand.Intn(256), g.rand.Intn(256)), true
+	case FormatIPv6:
+		groups := make([]string, 8)
+		for i := range groups {
+			groups[i] = fmt.Sprintf("%x", g.rand.Intn(0x10000))
+		}
+		return strings.Join(groups, ":"), true
+	case FormatURI, FormatIRI:
+		return "https://" + g.hostname() + "/" + g.letters(1+g.rand.Intn(8)), true
+	case FormatURIReference, FormatIRIReference:
+		return "/" + g.letters(1+g.rand.Intn(8)), true
+	case FormatURITemplate:
+		return "https://" + g.hostname() + "/{" + g.letters(1+g.rand.Intn(8)) + "}", true
+	case FormatJSONPointer:
+		return "/" + g.letters(1+g.rand.Intn(8)), true
+	case FormatRelativeJSONPointer:
+		return fmt.Sprintf("%d/%s", g.rand.Intn(3), g.letters(1+g.rand.Intn(8))), true
+	case FormatRegex:
+		return "^" + g.letters(1+g.rand.Intn(8)) + "$", true
+	case FormatUUID:
+		const hex = "0123456789abcdef"
+		b := []byte("xxxxxxxx-xxxx-4xxx-8xxx-xxxxxxxxxxxx")
+		for i, c := range b {
+			if c == 'x' {
+				b[i] = hex[g.rand.Intn(16)]
+			}
+		}
+		return string(b), true
+	case FormatDuration:
+		return fmt.Sprintf("P%dDT%dH", 1+g.rand.Intn(30), g.rand.Intn(24)), true
+	}
+
+	return "", false
+}
+
+// date returns a random full-date of RFC 3339.
+func (g *generator) date() string {
+	return fmt.Sprintf("%04d-%02d-%02d", 1970+g.rand.Intn(100), 1+g.rand.Intn(12), 1+g.rand.Intn(28))
+}
+
+// time returns a random full-time of RFC 3339.
+func (g *generator) time() string {
+	return fmt.Sprintf("%02d:%02d:%02dZ", g.rand.Intn(24), g.rand.Intn(60), g.rand.Intn(60))
+}
+
+// hostname returns a random host name.
+func (g *generator) hostname() string {
+	return g.letters(1+g.rand.Intn(8)) + ".example.com"
+}
+
+// regexp returns a random string matched by re.
+func (g *generator) regexp(re *syntax.Regexp) string {
+	var b strings.Builder
+	g.writeRegexp(&b, re)
+
+	return b.String()
+}
+
+// writeRegexp writes a random string matched by re to b.
+func (g *generator) writeRegexp(b *strings.Builder, re *syntax.Regexp) {
+	repeat := func(min, max int) {
+		if max < 0 {
+			max = min + 3
+		}
+		n := min
+		if max > min {
+			n += g.rand.Intn(max - min + 1)
+		}
+		for i := 0; i < n; i++ {
+			g.writeRegexp(b, re.Sub[0])
+		}
+	}
+
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && g.rand.Intn(2) == 0 {
+				r = unicode.SimpleFold(r)
+			}
+			b.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		b.WriteRune(g.classRune(re.Rune))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		b.WriteByte(byte('a' + g.rand.Intn(26)))
+	case syntax.OpCapture:
+		g.writeRegexp(b, re.Sub[0])
+	case syntax.OpStar:
+		repeat(0, -1)
+	case syntax.OpPlus:
+		repeat(1, -1)
+	case syntax.OpQuest:
+		repeat(0, 1)
+	case syntax.OpRepeat:
+		repeat(re.Min, re.Max)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.writeRegexp(b, sub)
+		}
+	case syntax.OpAlternate:
+		g.writeRegexp(b, re.Sub[g.rand.Intn(len(re.Sub))])
+	}
+}
+
+// classRune returns a random rune in the ranges of the character class, preferring the printable ASCII characters.
+func (g *generator) classRune(ranges []rune) rune {
+	if len(ranges) == 0 {
+		return 'a'
+	}
+	in := func(r rune) bool {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= r && r <= ranges[i+1] {
+				return true
+			}
+		}
+		return false
+	}
+	for i := 0; i < generateAttempts; i++ {
+		if r := rune(' ' + g.rand.Intn('~'-' '+1)); in(r) {
+			return r
+		}
+	}
+
+	i := 2 * g.rand.Intn(len(ranges)/2)
+	lo, hi := ranges[i], ranges[i+1]
+	if hi > unicode.MaxRune {
+		hi = unicode.MaxRune
+	}
+	r := lo + rune(g.rand.Int63n(int64(hi-lo)+1))
+	if !utf8.ValidRune(r) {
+		return lo
+	}
+
+	return r
+}
+
+// array returns a random array instance of s.
+func (g *generator) array(s *Schema) interface{} {
+	var span int64 = arraySpan
+	if g.minimal > 0 {
+		span = 0
+	}
+	n := g.lengthRange(s.MinItems, s.MaxItems, span)
+	if s.Contains != nil && n == 0 && (s.MaxItems == nil || *s.MaxItems > 0) {
+		n = 1
+	}
+	// the array ends before the first item which must not exist.
+	for i := 0; i < n; i++ {
+		if g.isFalse(itemAt(s, i)) {
+			n = i
+		}
+	}
+
+	// the item at the random position satisfies the "contains".
+	at := -1
+	if s.Contains != nil && n > 0 {
+		at = g.rand.Intn(n)
+	}
+
+	arr := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		item := itemAt(s, i)
+		if i == at {
+			item = &Schema{AllOf: SchemaList{s.Contains.Schema}}
+			if sub := itemAt(s, i); sub != nil {
+				item.AllOf = append(item.AllOf, sub)
+			}
+		}
+
+		var v interface{}
+		unique := false
+		for j := 0; j < generateAttempts && !unique; j++ {
+			if item == nil {
+				v = g.scalar()
+			} else {
+				v = g.generate(item)
+			}
+			unique = !s.UniqueItems || !containsValue(arr, v)
+		}
+		if !unique && int64(len(arr)) >= s.MinItems {
+			// the items may run out of the unique values.
+			break
+		}
+		arr = append(arr, v)
+	}
+	return arr
+}
+
+// containsValue reports whether arr contains the value equal to v.
+func containsValue(arr []interface{}, v interface{}) bool {
+	for _, x := range arr {
+		if equal(x, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// object returns a random object instance of s.
+func (g *generator) object(s *Schema) interface{} {
+	obj := make(map[string]interface{})
+	var names []string
+	has := make(map[string]bool)
+	add := func(name string) {
+		if !has[name] {
+			has[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, r := range s.Required {
+		add(r.Value)
+	}
+	var max int64 = math.MaxInt64
+	if s.MaxProperties != nil {
+		max = *s.MaxProperties
+	}
+	if g.minimal == 0 {
+		// the optional properties become rarer as the object is nested deeper.
+		for _, k := range sortedKeys(s.Properties) {
+			if int64(len(names)) < max && g.rand.Intn(1+g.nesting) == 0 && !g.isFalse(s.Properties[k]) {
+				add(k)
+			}
+		}
+	}
+	for _, k := range sortedKeys(s.Properties) {
+		if int64(len(names)) >= s.MinProperties {
+			break
+		}
+		add(k)
+	}
+	if int64(len(names)) < s.MinProperties {
+		nameSchema := propertyNameSchema(s)
+		for i := 0; int64(len(names)) < s.MinProperties && i < generateAttempts*int(s.MinProperties); i++ {
+			add(g.propertyName(s, nameSchema, has))
+		}
+	}
+
+	// the properties depended on by the added properties are also added.
+	for i := 0; i < len(names); i++ {
+		if s.Dependencies != nil {
+			for _, dep := range s.Dependencies.Names[names[i]] {
+				add(dep)
+			}
+		}
+	}
+
+	for _, name := range names {
+		if sub := propertyOf(s, name); sub != nil {
+			obj[name] = g.generate(sub)
+		} else {
+			obj[name] = g.scalar()
+		}
+	}
+
+	return obj
+}
+
+// propertyNameSchema returns the "propertyNames" of s merged with the string type, or nil if s has no
+// "propertyNames".
+func propertyNameSchema(s *Schema) *Schema {
+	if s.PropertyNames == nil {
+		return nil
+	}
+
+	return Merge(s.PropertyNames, &Schema{Type: Types{StringType}})
+}
+
+// propertyName returns a random name of the additional property of s, which is generated from the nameSchema of
+// the "propertyNames" if it is not nil, and is not in has if possible.
+func (g *generator) propertyName(s *Schema, nameSchema *Schema, has map[string]bool) string {
+	if nameSchema != nil {
+		for i := 0; i < generateAttempts; i++ {
+			name, ok := g.generate(nameSchema).(string)
+			if !ok {
+				break
+			}
+			if has[name] {
+				// the unanchored pattern also matches the longer name.
+				name += g.letters(1 + g.rand.Intn(8))
+			}
+			if !has[name] && g.valid(nameSchema, name) {
+				return name
+			}
+		}
+	}
+	// the names of the additional properties are invalid if the "additionalProperties" is false.
+	closed := s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil &&
+		s.AdditionalProperties.Schema.Bool != nil && !*s.AdditionalProperties.Schema.Bool
+	if len(s.PatternProperties) > 0 && (closed || g.rand.Intn(2) == 0) {
+		exprs := make([]string, 0, len(s.PatternProperties))
+		for expr := range s.PatternProperties {
+			exprs = append(exprs, expr)
+		}
+		sort.Strings(exprs)
+		if re, err := syntax.Parse(exprs[g.rand.Intn(len(exprs))], syntax.Perl); err == nil {
+			return g.regexp(re.Simplify())
+		}
+	}
+
+	return g.letters(1 + g.rand.Intn(8))
+}
+
+// copyValue returns the deep copy of the JSON value v, so the instances do not share the values of the schema.
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, x := range v {
+			c[i] = copyValue(x)
+		}
+		return c
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, x := range v {
+			c[k] = copyValue(x)
+		}
+		return c
+	}
+
+	return v
+}
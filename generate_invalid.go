@@ -0,0 +1,619 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Violation represents an instance which violates exactly one keyword of the schema.
+type Violation struct {
+	// KeywordLocation is the JSON Pointer of the violated keyword, which is the same as the KeywordLocation of
+	// the ValidationError of the Instance.
+	KeywordLocation string
+
+	// InstanceLocation is the JSON Pointer of the violating value in the Instance.
+	InstanceLocation string
+
+	// Message is the error message of the violation.
+	Message string
+
+	// Instance is the whole invalid instance.
+	Instance interface{}
+}
+
+// GenerateInvalid returns the random instances which are invalid against s, each of which violates exactly one
+// keyword of s, that is, every ValidationError of the instance has the same KeywordLocation.
+//
+// GenerateInvalid generates a valid instance by the Generate, and walks it with s to break each keyword which
+// applies to it, such as the number one past the "maximum", the object missing a "required" property, the value
+// of the wrong "type", and the string which does not match the "pattern". The branches of the "anyOf" and the
+// "oneOf" are not walked, but the "anyOf" and the "oneOf" themselves are broken.
+//
+// The Violations are ordered by the walk, and at most one Violation is returned for each KeywordLocation.
+// The keywords which cannot be broken alone, such as the "minimum" also implied by the "exclusiveMinimum",
+// have no Violation. GenerateInvalid returns nil if s cannot be compiled or no valid instance is generated.
+func GenerateInvalid(s *Schema, rand *rand.Rand) []*Violation {
+	if s == nil {
+		return nil
+	}
+	v, err := Compile(s)
+	if err != nil {
+		return nil
+	}
+	inst := Generate(s, rand)
+	if !v.IsValid(inst) {
+		return nil
+	}
+
+	vi := &violator{
+		g: &generator{
+			rand:   rand,
+			v:      v,
+			depth:  make(map[*Schema]int),
+			budget: generateBudget,
+		},
+		v:    v,
+		root: inst,
+		seen: make(map[string]bool),
+	}
+	vi.visit(s, "", inst, nil, 0)
+
+	return vi.violations
+}
+
+// violator represents a state of the GenerateInvalid.
+type violator struct {
+	g *generator
+	v *Validator
+
+	// root is the valid instance.
+	root interface{}
+
+	// seen is the set of the keyword locations which have the Violation.
+	seen map[string]bool
+
+	violations []*Violation
+}
+
+// visit breaks the keywords of s at kloc which apply to the inst value at path, and visits the sub schemas.
+//
+// hops is the number of the sub schemas visited for the same value, which bounds the cyclic "$ref"s.
+func (vi *violator) visit(s *Schema, kloc string, inst interface{}, path []string, hops int) {
+	if s == nil || s.Bool != nil || hops > maxRefDepth {
+		return
+	}
+	if s.Ref != "" {
+		if target, ok := vi.v.refs[s]; ok {
+			vi.visit(target, appendLocation(kloc, keyRef), inst, path, hops+1)
+		}
+		return
+	}
+
+	vi.breakGeneric(s, kloc, inst, path)
+	switch x := normalizeInstance(inst).(type) {
+	case string:
+		vi.breakString(s, kloc, x, path)
+	case []interface{}:
+		vi.breakArray(s, kloc, inst.([]interface{}), path)
+	case map[string]interface{}:
+		vi.breakObject(s, kloc, inst.(map[string]interface{}), path)
+	default:
+		if _, ok := numberValue(inst); ok {
+			vi.breakNumber(s, kloc, inst, path)
+		}
+	}
+
+	for i, sub := range s.AllOf {
+		vi.visit(sub, appendLocation(kloc, keyAllOf, strconv.Itoa(i)), inst, path, hops+1)
+	}
+	if s.If != nil {
+		st := &state{v: vi.v}
+		if st.valid(s.If, inst, "", "") {
+			vi.visit(s.Then, appendLocation(kloc, keyThen), inst, path, hops+1)
+		} else {
+			vi.visit(s.Else, appendLocation(kloc, keyElse), inst, path, hops+1)
+		}
+	}
+
+	switch x := inst.(type) {
+	case []interface{}:
+		for i, item := range x {
+			sub, loc := vi.itemSchema(s, kloc, i)
+			vi.visit(sub, loc, item, append(path[:len(path):len(path)], strconv.Itoa(i)), 0)
+		}
+	case map[string]interface{}:
+		for _, name := range sortedInstanceKeys(x) {
+			ipath := append(path[:len(path):len(path)], name)
+			matched := false
+			if sub, ok := s.Properties[name]; ok {
+				matched = true
+				vi.visit(sub, appendLocation(kloc, keyProperties, name), x[name], ipath, 0)
+			}
+			for _, expr := range sortedPatterns(s.PatternProperties) {
+				pp := s.PatternProperties[expr]
+				if pp.Regexp != nil && pp.Regexp.MatchString(name) {
+					matched = true
+					vi.visit(pp.Schema, appendLocation(kloc, keyPatternProperties, expr), x[name], ipath, 0)
+				}
+			}
+			if !matched && s.AdditionalProperties != nil {
+				vi.visit(s.AdditionalProperties.Schema, appendLocation(kloc, keyAdditionalProperties), x[name], ipath, 0)
+			}
+			if s.Dependencies != nil {
+				if dep, ok := s.Dependencies.Schemas[name]; ok {
+					vi.visit(dep, appendLocation(kloc, keyDependencies, name), inst, path, hops+1)
+				}
+			}
+		}
+	}
+}
+
+// itemSchema returns the schema of the i-th item of the array of s, and its location.
+func (vi *violator) itemSchema(s *Schema, kloc string, i int) (*Schema, string) {
+	switch {
+	case s.Items == nil || len(s.Items.Schemas) == 0:
+		return nil, ""
+	case !s.Items.HasMultiple:
+		return s.Items.Schemas[0], appendLocation(kloc, keyItems)
+	case i < len(s.Items.Schemas):
+		return s.Items.Schemas[i], appendLocation(kloc, keyItems, strconv.Itoa(i))
+	case s.AdditionalItems != nil:
+		return s.AdditionalItems.Schema, appendLocation(kloc, keyAdditionalItems)
+	}
+
+	return nil, ""
+}
+
+// try replaces the value at path by each of the candidates, and records the first instance which violates only
+// the keyword at loc, or the keyword under loc if nested is true.
+func (vi *violator) try(loc string, nested bool, path []string, candidates []interface{}) {
+	if !nested && vi.seen[loc] {
+		return
+	}
+
+	for _, c := range candidates {
+		inst := replaceValue(vi.root, path, c)
+		st := &state{v: vi.v}
+		st.validate(vi.v.root, inst, "", "")
+		if len(st.errs) == 0 {
+			continue
+		}
+		kloc := st.errs[0].KeywordLocation
+		if kloc != loc && (!nested || !strings.HasPrefix(kloc, loc+"/")) {
+			continue
+		}
+		single := true
+		for _, e := range st.errs[1:] {
+			single = single && e.KeywordLocation == kloc
+		}
+		if !single || vi.seen[kloc] {
+			continue
+		}
+
+		vi.seen[kloc] = true
+		vi.violations = append(vi.violations, &Violation{
+			KeywordLocation:  kloc,
+			InstanceLocation: st.errs[0].InstanceLocation,
+			Message:          st.errs[0].Message,
+			Instance:         copyValue(inst),
+		})
+		return
+	}
+}
+
+// breakGeneric breaks the keywords of s which apply to any type.
+func (vi *violator) breakGeneric(s *Schema, kloc string, inst interface{}, path []string) {
+	if len(s.Type) > 0 {
+		vi.try(appendLocation(kloc, keyType), false, path, vi.otherTypes(s.Type))
+	}
+	if s.Const != nil {
+		vi.try(appendLocation(kloc, keyConst), false, path, append(vi.perturb(inst), vi.otherTypes(nil)...))
+	}
+	if len(s.Enum) > 0 {
+		cands := vi.perturb(inst)
+		for _, e := range s.Enum {
+			cands = append(cands, vi.perturb(e.Interface())...)
+		}
+		vi.try(appendLocation(kloc, keyEnum), false, path, append(cands, vi.otherTypes(nil)...))
+	}
+	if len(s.AnyOf) > 0 {
+		vi.try(appendLocation(kloc, keyAnyOf), false, path, append(vi.perturb(inst), vi.otherTypes(nil)...))
+	}
+	if len(s.OneOf) > 0 {
+		cands := append(vi.perturb(inst), vi.otherTypes(nil)...)
+		for _, sub := range s.OneOf {
+			// the instance of a branch may match the other branches.
+			cands = append(cands, vi.g.generate(sub))
+		}
+		vi.try(appendLocation(kloc, keyOneOf), false, path, cands)
+	}
+	if s.Not != nil {
+		cands := make([]interface{}, 0, generateAttempts)
+		for i := 0; i < generateAttempts; i++ {
+			cands = append(cands, vi.g.generate(s.Not))
+		}
+		vi.try(appendLocation(kloc, keyNot), false, path, cands)
+	}
+}
+
+// otherTypes returns the values of the types other than the types, in random order.
+func (vi *violator) otherTypes(types Types) []interface{} {
+	allowed := make(map[Type]bool)
+	for _, t := range types {
+		allowed[t] = true
+	}
+	var values []interface{}
+	add := func(t Type, v interface{}) {
+		if !allowed[t] && !(t == IntegerType && allowed[NumberType]) {
+			values = append(values, v)
+		}
+	}
+	add(NullType, nil)
+	add(BooleanType, vi.g.rand.Intn(2) == 1)
+	add(IntegerType, int64(vi.g.rand.Intn(2*generateSpan+1)-generateSpan))
+	add(NumberType, float64(vi.g.rand.Intn(2*generateSpan))-generateSpan+0.5)
+	add(StringType, vi.g.letters(1+vi.g.rand.Intn(8)))
+	add(ArrayType, []interface{}{})
+	add(ObjectType, map[string]interface{}{})
+	vi.g.rand.Shuffle(len(values), func(i, j int) { values[i], values[j] = values[j], values[i] })
+
+	return values
+}
+
+// perturb returns the values which are slightly different from v.
+func (vi *violator) perturb(v interface{}) []interface{} {
+	if f, ok := numberValue(v); ok {
+		return []interface{}{jsonNumber(f + 1), jsonNumber(f - 1), f + 0.5}
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return []interface{}{false, int64(0)}
+	case bool:
+		return []interface{}{!v}
+	case string:
+		return []interface{}{v + vi.g.letters(1), "", vi.g.letters(1 + vi.g.rand.Intn(8))}
+	case []interface{}:
+		return []interface{}{append(copyValue(v).([]interface{}), nil), []interface{}{}}
+	case map[string]interface{}:
+		c := copyValue(v).(map[string]interface{})
+		c[vi.g.letters(8)] = nil
+		return []interface{}{c, map[string]interface{}{}}
+	}
+
+	return nil
+}
+
+// numberValue returns the float64 value of the number v.
+func numberValue(v interface{}) (float64, bool) {
+	n, ok := normalizeInstance(v).(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+
+	return f, err == nil
+}
+
+// jsonNumber returns f as int64 if f is an exact integer, or as float64 otherwise.
+func jsonNumber(f float64) interface{} {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f)
+	}
+
+	return f
+}
+
+// breakNumber breaks the numeric keywords of s.
+func (vi *violator) breakNumber(s *Schema, kloc string, inst interface{}, path []string) {
+	f, _ := numberValue(inst)
+	if m := s.MultipleOf; m > 0 {
+		vi.try(appendLocation(kloc, keyMultipleOf), false, path,
+			[]interface{}{jsonNumber(f + m/2), jsonNumber(f + 1), jsonNumber(f - 1), f + 0.5})
+	}
+
+	above := func(b float64, inclusive bool) []interface{} {
+		cands := []interface{}{jsonNumber(math.Floor(b) + 1), jsonNumber(b + 1)}
+		if inclusive {
+			cands = append([]interface{}{jsonNumber(b)}, cands...)
+		} else {
+			cands = append(cands, math.Nextafter(b, math.Inf(1)))
+		}
+		if m := s.MultipleOf; m > 0 {
+			k := math.Floor(b/m) + 1
+			if inclusive {
+				k = math.Ceil(b / m)
+			}
+			cands = append([]interface{}{jsonNumber(k * m)}, cands...)
+		}
+		return cands
+	}
+	below := func(b float64, inclusive bool) []interface{} {
+		cands := []interface{}{jsonNumber(math.Ceil(b) - 1), jsonNumber(b - 1)}
+		if inclusive {
+			cands = append([]interface{}{jsonNumber(b)}, cands...)
+		} else {
+			cands = append(cands, math.Nextafter(b, math.Inf(-1)))
+		}
+		if m := s.MultipleOf; m > 0 {
+			k := math.Ceil(b/m) - 1
+			if inclusive {
+				k = math.Floor(b / m)
+			}
+			cands = append([]interface{}{jsonNumber(k * m)}, cands...)
+		}
+		return cands
+	}
+
+	if s.Maximum != nil {
+		vi.try(appendLocation(kloc, keyMaximum), false, path, above(*s.Maximum, false))
+	}
+	if s.ExclusiveMaximum != nil {
+		vi.try(appendLocation(kloc, keyExclusiveMaximum), false, path, above(*s.ExclusiveMaximum, true))
+	}
+	if s.Minimum != nil {
+		vi.try(appendLocation(kloc, keyMinimum), false, path, below(*s.Minimum, false))
+	}
+	if s.ExclusiveMinimum != nil {
+		vi.try(appendLocation(kloc, keyExclusiveMinimum), false, path, below(*s.ExclusiveMinimum, true))
+	}
+}
+
+// breakString breaks the string keywords of s.
+func (vi *violator) breakString(s *Schema, kloc string, str string, path []string) {
+	n := int64(utf8.RuneCountInString(str))
+	if s.MaxLength != nil && *s.MaxLength < math.MaxInt32 {
+		pad := int(*s.MaxLength + 1 - n)
+		if pad < 1 {
+			pad = 1
+		}
+		var cands []interface{}
+		if n > 0 {
+			last, _ := utf8.DecodeLastRuneInString(str)
+			cands = append(cands, str+strings.Repeat(string(last), pad))
+		}
+		cands = append(cands, str+vi.g.letters(pad), vi.g.letters(pad)+str)
+		vi.try(appendLocation(kloc, keyMaxLength), false, path, cands)
+	}
+	if s.MinLength > 0 && n >= s.MinLength {
+		runes := []rune(str)
+		keep := int(s.MinLength - 1)
+		vi.try(appendLocation(kloc, keyMinLength), false, path,
+			[]interface{}{string(runes[:keep]), string(runes[len(runes)-keep:])})
+	}
+	if s.Pattern != nil {
+		cands := []interface{}{str + "!", "!" + str, "", vi.g.letters(1 + vi.g.rand.Intn(8))}
+		if re, err := syntax.Parse(s.Pattern.String(), syntax.Perl); err == nil {
+			// the strings of the other patterns are likely to match the other keywords.
+			cands = append(cands, vi.g.regexp(re.Simplify())+"!")
+		}
+		vi.try(appendLocation(kloc, keyPattern), false, path, cands)
+	}
+	if s.Format != "" {
+		vi.try(appendLocation(kloc, keyFormat), false, path,
+			[]interface{}{"!" + str, str + "!", "", "%", "not-a-" + string(s.Format)})
+	}
+}
+
+// breakArray breaks the array keywords of s.
+func (vi *violator) breakArray(s *Schema, kloc string, arr []interface{}, path []string) {
+	// extend returns arr with the new items up to the n items.
+	extend := func(n int) []interface{} {
+		c := copyValue(arr).([]interface{})
+		for i := len(c); i < n; i++ {
+			if sub := itemAt(s, i); sub != nil {
+				c = append(c, vi.g.generate(sub))
+			} else {
+				c = append(c, vi.g.scalar())
+			}
+		}
+		return c
+	}
+
+	if s.MaxItems != nil && *s.MaxItems < math.MaxInt16 {
+		n := int(*s.MaxItems) + 1
+		cands := make([]interface{}, 0, generateAttempts)
+		for i := 0; i < generateAttempts; i++ {
+			cands = append(cands, extend(n))
+		}
+		vi.try(appendLocation(kloc, keyMaxItems), false, path, cands)
+	}
+	if s.MinItems > 0 && int64(len(arr)) >= s.MinItems {
+		keep := int(s.MinItems - 1)
+		vi.try(appendLocation(kloc, keyMinItems), false, path,
+			[]interface{}{copyValue(arr[:keep]), copyValue(arr[len(arr)-keep:])})
+	}
+	if s.UniqueItems {
+		var cands []interface{}
+		if len(arr) >= 2 {
+			c := copyValue(arr).([]interface{})
+			c[1] = copyValue(c[0])
+			cands = append(cands, c)
+		}
+		if len(arr) >= 1 {
+			c := copyValue(arr).([]interface{})
+			cands = append(cands, append(c, copyValue(c[vi.g.rand.Intn(len(c))])))
+		} else {
+			c := extend(1)
+			cands = append(cands, append(c, copyValue(c[0])))
+		}
+		vi.try(appendLocation(kloc, keyUniqueItems), false, path, cands)
+	}
+	if s.Contains != nil && s.Contains.Schema != nil {
+		st := &state{v: vi.v}
+		c := make([]interface{}, 0, len(arr))
+		for _, item := range arr {
+			if !st.valid(s.Contains.Schema, item, "", "") {
+				c = append(c, copyValue(item))
+			}
+		}
+		vi.try(appendLocation(kloc, keyContains), false, path, []interface{}{c, []interface{}{}})
+	}
+	if s.Items != nil && s.Items.HasMultiple && s.AdditionalItems != nil && s.AdditionalItems.Schema != nil {
+		n := len(s.Items.Schemas)
+		if len(arr) >= n {
+			loc := appendLocation(kloc, keyAdditionalItems)
+			var cands []interface{}
+			for _, v := range vi.otherTypes(nil) {
+				cands = append(cands, append(copyValue(arr).([]interface{}), v))
+			}
+			vi.try(loc, !isFalse(s.AdditionalItems.Schema), path, cands)
+		}
+	}
+}
+
+// isFalse reports whether s is the false schema.
+func isFalse(s *Schema) bool {
+	return s != nil && s.Bool != nil && !*s.Bool
+}
+
+// breakObject breaks the object keywords of s.
+func (vi *violator) breakObject(s *Schema, kloc string, obj map[string]interface{}, path []string) {
+	required := make(map[string]bool, len(s.Required))
+	for _, r := range s.Required {
+		required[r.Value] = true
+	}
+	names := sortedInstanceKeys(obj)
+
+	// with returns obj with the new property.
+	with := func(name string) map[string]interface{} {
+		c := copyValue(obj).(map[string]interface{})
+		if sub := propertyOf(s, name); sub != nil {
+			c[name] = vi.g.generate(sub)
+		} else {
+			c[name] = vi.g.scalar()
+		}
+		return c
+	}
+	// without returns obj without the properties.
+	without := func(names ...string) map[string]interface{} {
+		c := copyValue(obj).(map[string]interface{})
+		for _, name := range names {
+			delete(c, name)
+		}
+		return c
+	}
+
+	if s.MaxProperties != nil && *s.MaxProperties < math.MaxInt16 {
+		nameSchema := propertyNameSchema(s)
+		cands := make([]interface{}, 0, generateAttempts)
+		for i := 0; i < generateAttempts; i++ {
+			c := copyValue(obj).(map[string]interface{})
+			has := make(map[string]bool, len(c))
+			for name := range c {
+				has[name] = true
+			}
+			for j := 0; int64(len(c)) <= *s.MaxProperties && j < 2*int(*s.MaxProperties)+generateAttempts; j++ {
+				name := vi.g.propertyName(s, nameSchema, has)
+				if has[name] {
+					continue
+				}
+				has[name] = true
+				if sub := propertyOf(s, name); sub != nil {
+					c[name] = vi.g.generate(sub)
+				} else {
+					c[name] = vi.g.scalar()
+				}
+			}
+			cands = append(cands, c)
+		}
+		vi.try(appendLocation(kloc, keyMaxProperties), false, path, cands)
+	}
+	if s.MinProperties > 0 && int64(len(obj)) >= s.MinProperties {
+		var optional []string
+		for _, name := range names {
+			if !required[name] {
+				optional = append(optional, name)
+			}
+		}
+		vi.g.rand.Shuffle(len(optional), func(i, j int) { optional[i], optional[j] = optional[j], optional[i] })
+		if drop := len(obj) - int(s.MinProperties) + 1; drop <= len(optional) {
+			vi.try(appendLocation(kloc, keyMinProperties), false, path, []interface{}{without(optional[:drop]...)})
+		}
+	}
+	if len(s.Required) > 0 {
+		var cands []interface{}
+		for _, i := range vi.g.rand.Perm(len(s.Required)) {
+			cands = append(cands, without(s.Required[i].Value))
+		}
+		vi.try(appendLocation(kloc, keyRequired), false, path, cands)
+	}
+	if s.AdditionalProperties != nil && isFalse(s.AdditionalProperties.Schema) {
+		var cands []interface{}
+		for i := 0; i < generateAttempts; i++ {
+			name := vi.g.letters(1 + vi.g.rand.Intn(8))
+			if propertyOf(s, name) != s.AdditionalProperties.Schema {
+				continue
+			}
+			c := copyValue(obj).(map[string]interface{})
+			c[name] = vi.g.scalar()
+			cands = append(cands, c)
+		}
+		vi.try(appendLocation(kloc, keyAdditionalProperties), false, path, cands)
+	}
+	if s.PropertyNames != nil {
+		var cands []interface{}
+		for _, name := range []string{"", "!", vi.g.letters(1 + vi.g.rand.Intn(8)), strings.Repeat("x", 64)} {
+			if _, ok := obj[name]; ok {
+				continue
+			}
+			cands = append(cands, with(name))
+			// renaming a property keeps the number of the properties.
+			for _, old := range names {
+				if !required[old] {
+					c := with(name)
+					delete(c, old)
+					cands = append(cands, c)
+				}
+			}
+		}
+		vi.try(appendLocation(kloc, keyPropertyNames), true, path, cands)
+	}
+	if s.Dependencies != nil {
+		for _, name := range names {
+			var cands []interface{}
+			for _, dep := range s.Dependencies.Names[name] {
+				if _, ok := obj[dep]; ok && dep != name {
+					cands = append(cands, without(dep))
+				}
+			}
+			if len(cands) > 0 {
+				vi.try(appendLocation(kloc, keyDependencies, name), false, path, cands)
+			}
+		}
+	}
+}
+
+// replaceValue returns the deep copy of root whose value at path is replaced by v.
+func replaceValue(root interface{}, path []string, v interface{}) interface{} {
+	if len(path) == 0 {
+		return v
+	}
+
+	switch x := root.(type) {
+	case []interface{}:
+		c := make([]interface{}, len(x))
+		copy(c, x)
+		i, _ := strconv.Atoi(path[0])
+		c[i] = replaceValue(x[i], path[1:], v)
+		return c
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			c[k] = e
+		}
+		c[path[0]] = replaceValue(x[path[0]], path[1:], v)
+		return c
+	}
+
+	return root
+}
@@ -0,0 +1,144 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestGenerateInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   []string
+	}{
+		{
+			name:   "integer",
+			schema: `{"type": "integer", "minimum": 1, "maximum": 10, "multipleOf": 2}`,
+			want:   []string{"/maximum", "/minimum", "/multipleOf", "/type"},
+		},
+		{
+			name:   "number",
+			schema: `{"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1}`,
+			want:   []string{"/exclusiveMaximum", "/exclusiveMinimum", "/type"},
+		},
+		{
+			name:   "string",
+			schema: `{"type": "string", "minLength": 2, "maxLength": 4, "pattern": "^[a-z]+$"}`,
+			want:   []string{"/maxLength", "/minLength", "/pattern", "/type"},
+		},
+		{
+			name:   "format",
+			schema: `{"type": "string", "format": "date"}`,
+			want:   []string{"/format", "/type"},
+		},
+		{
+			name:   "array",
+			schema: `{"type": "array", "items": {"type": "integer"}, "minItems": 1, "maxItems": 3, "uniqueItems": true, "contains": {"minimum": 0}}`,
+			// the empty array violates both of the "minItems" and the "contains".
+			want: []string{"/contains", "/items/type", "/maxItems", "/type", "/uniqueItems"},
+		},
+		{
+			name:   "tuple",
+			schema: `{"type": "array", "items": [{"type": "string"}], "additionalItems": false, "minItems": 1}`,
+			want:   []string{"/additionalItems", "/items/0/type", "/minItems", "/type"},
+		},
+		{
+			name:   "object",
+			schema: `{"type": "object", "required": ["a"], "properties": {"a": {}}, "patternProperties": {"^x": {"type": "null"}}, "dependencies": {"a": ["b"]}, "propertyNames": {"maxLength": 3}, "maxProperties": 4}`,
+			want:   []string{"/dependencies/a", "/maxProperties", "/propertyNames/maxLength", "/required", "/type"},
+		},
+		{
+			name:   "closed object",
+			schema: `{"type": "object", "required": ["a"], "properties": {"a": {"type": "string"}}, "additionalProperties": false, "minProperties": 1}`,
+			// the object without "a" also violates the "minProperties".
+			want: []string{"/additionalProperties", "/properties/a/type", "/type"},
+		},
+		{name: "enum", schema: `{"enum": [1, 2]}`, want: []string{"/enum"}},
+		{name: "const", schema: `{"const": "a"}`, want: []string{"/const"}},
+		{name: "anyOf", schema: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, want: []string{"/anyOf"}},
+		{name: "oneOf", schema: `{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`, want: []string{"/oneOf"}},
+		{name: "not", schema: `{"not": {"type": "string"}}`, want: []string{"/not"}},
+		{name: "allOf", schema: `{"allOf": [{"type": "integer"}, {"minimum": 3}]}`, want: []string{"/allOf/0/type", "/allOf/1/minimum"}},
+		{
+			name:   "if",
+			schema: `{"if": {"type": "integer"}, "then": {"minimum": 5}, "else": {"type": "string"}}`,
+			want:   []string{"/else/type", "/then/minimum"},
+		},
+		{
+			name:   "ref",
+			schema: `{"definitions": {"a": {"type": "integer", "maximum": 3}}, "type": "array", "items": {"$ref": "#/definitions/a"}, "minItems": 1}`,
+			want:   []string{"/items/$ref/maximum", "/items/$ref/type", "/minItems", "/type"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := mustSchema(t, tt.schema)
+			v := MustCompile(s)
+			seen := make(map[string]bool)
+			for seed := int64(0); seed < 20; seed++ {
+				locs := make(map[string]bool)
+				for _, vio := range GenerateInvalid(s, rand.New(rand.NewSource(seed))) {
+					if locs[vio.KeywordLocation] {
+						t.Errorf("seed %d: GenerateInvalid() has the Violations of %s twice", seed, vio.KeywordLocation)
+					}
+					locs[vio.KeywordLocation] = true
+					seen[vio.KeywordLocation] = true
+
+					errs := sortedErrors(t, v.Validate(vio.Instance))
+					if len(errs) == 0 {
+						t.Errorf("seed %d: %s: Instance %#v is valid", seed, vio.KeywordLocation, vio.Instance)
+						continue
+					}
+					for _, e := range errs {
+						if e.KeywordLocation != vio.KeywordLocation {
+							t.Errorf("seed %d: %s: Instance %#v violates %s", seed, vio.KeywordLocation, vio.Instance, e.KeywordLocation)
+						}
+					}
+					if vio.InstanceLocation != errs[0].InstanceLocation {
+						t.Errorf("seed %d: %s: InstanceLocation = %q, want %q", seed, vio.KeywordLocation, vio.InstanceLocation, errs[0].InstanceLocation)
+					}
+				}
+			}
+
+			got := make([]string, 0, len(seen))
+			for loc := range seen {
+				got = append(got, loc)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GenerateInvalid() = the Violations of %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateInvalidNone(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema *Schema
+	}{
+		{name: "nil", schema: nil},
+		{name: "false", schema: BoolSchema(false)},
+		{name: "true", schema: BoolSchema(true)},
+		{name: "unresolvable ref", schema: &Schema{Ref: "#/definitions/missing"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := GenerateInvalid(tt.schema, rand.New(rand.NewSource(0))); got != nil {
+				t.Errorf("GenerateInvalid() = %v, want nil", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// generateSeeds is the number of the seeds of the rand used by the Generate tests.
+const generateSeeds = 100
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{name: "empty", schema: `{}`},
+		{name: "integer", schema: `{"type": "integer", "minimum": 3, "exclusiveMaximum": 7, "multipleOf": 2}`},
+		{name: "number", schema: `{"type": "number", "exclusiveMinimum": 0, "maximum": 0.5, "multipleOf": 0.01}`},
+		{name: "string", schema: `{"type": "string", "minLength": 2, "maxLength": 5}`},
+		{name: "pattern", schema: `{"type": "string", "pattern": "^[a-f]{2}-\\d+$"}`},
+		{name: "format", schema: `{"type": "string", "format": "date-time"}`},
+		{name: "enum", schema: `{"enum": [1, "a", [null], {"b": true}]}`},
+		{name: "const", schema: `{"const": {"a": [1, 2]}}`},
+		{name: "items false", schema: `{"items": false}`},
+		{name: "items false with maxItems", schema: `{"type": "array", "items": false, "maxItems": 3}`},
+		{name: "tuple", schema: `{"type": "array", "items": [{"type": "string"}, {"type": "integer"}], "additionalItems": false, "minItems": 1}`},
+		{name: "tuple false item", schema: `{"type": "array", "items": [{"type": "string"}, false]}`},
+		{name: "unique items", schema: `{"type": "array", "items": {"enum": [1, 2, 3]}, "uniqueItems": true, "minItems": 2}`},
+		{name: "contains", schema: `{"type": "array", "contains": {"const": 42}}`},
+		{name: "contains false", schema: `{"contains": false}`},
+		{name: "object", schema: `{"type": "object", "required": ["a"], "properties": {"a": {"type": "string"}, "b": false}, "additionalProperties": false}`},
+		{name: "propertyNames", schema: `{"type": "object", "propertyNames": {"pattern": "^x"}, "minProperties": 2}`},
+		{name: "dependencies", schema: `{"type": "object", "properties": {"a": {}}, "dependencies": {"a": ["b"]}, "minProperties": 1}`},
+		{name: "allOf", schema: `{"allOf": [{"type": "integer"}, {"minimum": 10}, {"maximum": 12}]}`},
+		{name: "allOf multipleOf", schema: `{"allOf": [{"multipleOf": 2}], "anyOf": [{"multipleOf": 3}], "oneOf": [{"multipleOf": 5}]}`},
+		{name: "allOf ref", schema: `{"definitions": {"a": {"type": "string", "minLength": 3}}, "allOf": [{"$ref": "#/definitions/a"}, {"maxLength": 3}]}`},
+		{name: "allOf id", schema: `{"allOf": [{"$ref": "#foo"}], "definitions": {"A": {"$id": "#foo", "type": "integer"}}}`},
+		{name: "nested allOf", schema: `{"allOf": [{"allOf": [{"$ref": "#/definitions/a"}]}], "definitions": {"a": {"type": "boolean"}}}`},
+		{name: "if", schema: `{"if": {"type": "integer"}, "then": {"minimum": 5}, "else": {"type": "string"}}`},
+		{name: "if false", schema: `{"if": false, "then": {"const": "then"}, "else": {"const": "else"}}`},
+		{name: "if true", schema: `{"if": true, "then": {"const": "then"}, "else": {"const": "else"}}`},
+		{
+			name:   "recursive",
+			schema: `{"type": "object", "required": ["value"], "properties": {"value": {"type": "integer"}, "children": {"type": "array", "items": {"$ref": "#"}}}}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := mustSchema(t, tt.schema)
+			v := MustCompile(s)
+			for seed := int64(0); seed < generateSeeds; seed++ {
+				inst := Generate(s, rand.New(rand.NewSource(seed)))
+				if !v.IsValid(inst) {
+					t.Errorf("seed %d: Generate() = %#v, which is invalid: %v", seed, inst, v.Validate(inst))
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateItemsFalse(t *testing.T) {
+	got := Generate(mustSchema(t, `{"items": false}`), rand.New(rand.NewSource(0)))
+	if want := []interface{}{}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Generate() = %#v, want %#v", got, want)
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	s := mustSchema(t, `{"type": "object", "properties": {"a": {"type": "array", "items": {"type": "string"}}, "b": {"type": "number"}}, "additionalProperties": {"type": "integer"}, "minProperties": 4}`)
+	for seed := int64(0); seed < generateSeeds; seed++ {
+		a := Generate(s, rand.New(rand.NewSource(seed)))
+		b := Generate(s, rand.New(rand.NewSource(seed)))
+		if !reflect.DeepEqual(a, b) {
+			t.Fatalf("seed %d: Generate() = %#v and %#v, want the same instance", seed, a, b)
+		}
+	}
+}
+
+func TestGenerateFalse(t *testing.T) {
+	if got := Generate(BoolSchema(false), rand.New(rand.NewSource(0))); got != nil {
+		t.Errorf("Generate(false) = %#v, want nil", got)
+	}
+	if got := Generate(nil, rand.New(rand.NewSource(0))); got != nil {
+		t.Errorf("Generate(nil) = %#v, want nil", got)
+	}
+}
+
+// generateSkips is the map of the JSON-Schema-Test-Suite groups, which are "<file>/<description>" of any draft,
+// to the reason why the Generate does not always find the valid instance of them.
+var generateSkips = map[string]string{
+	"dependencies.json/dependent subschema incompatible with root":                "the dependent schemas are satisfied only by the validation",
+	"not.json/collect annotations inside a 'not', even if collection is disabled": "the \"not\" is satisfied only by the validation",
+	"oneOf.json/oneOf with empty schema":                                          "the exclusive \"oneOf\" is satisfied only by the validation",
+	"unevaluatedItems.json/unevaluatedItems with oneOf":                           "the \"unevaluatedItems\" is satisfied only by the validation",
+}
+
+func TestGenerateSuite(t *testing.T) {
+	const seeds = 20
+
+	for _, draft := range suiteDrafts {
+		for _, file := range suiteFiles(t, draft) {
+			if strings.HasPrefix(file, "optional/") {
+				continue
+			}
+			for _, g := range readSuiteFile(t, filepath.Join(suiteDir, "tests", draft, filepath.FromSlash(file))) {
+				satisfiable := false
+				for _, c := range g.Tests {
+					satisfiable = satisfiable || c.Valid
+				}
+				if _, skip := generateSkips[file+"/"+g.Description]; skip || !satisfiable {
+					continue
+				}
+				s := &Schema{}
+				if err := s.UnmarshalJSON(g.Schema); err != nil {
+					continue
+				}
+				v, err := Compile(s)
+				if err != nil {
+					// the remote "$ref"s are not resolved.
+					continue
+				}
+				for seed := int64(0); seed < seeds; seed++ {
+					inst := Generate(s, rand.New(rand.NewSource(seed)))
+					if !v.IsValid(inst) {
+						t.Errorf("%s/%s/%s: seed %d: Generate() = %#v, which is invalid: %v",
+							draft, file, g.Description, seed, inst, v.Validate(inst))
+					}
+				}
+			}
+		}
+	}
+}
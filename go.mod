@@ -1,5 +1,5 @@
 module github.com/zchee/go-jsonschema
 
-go 1.13
+go 1.18
 
 require github.com/francoispqt/gojay v1.2.13
@@ -0,0 +1,166 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lazyregexp
+
+import (
+	"container/list"
+	"regexp"
+	"sync"
+	"sync/atomic"
+
+	"github.com/zchee/go-jsonschema/pkg/regexp/ecma262"
+)
+
+// DefaultCacheSize is the default maximum number of entries of the DefaultCache.
+const DefaultCacheSize = 4096
+
+// DefaultCache is the process-wide Cache used by the regexps created by Compile.
+var DefaultCache = NewCache(DefaultCacheSize)
+
+// CacheStats represents the statistics of the Cache.
+type CacheStats struct {
+	Hits       uint64
+	Misses     uint64
+	Evictions  uint64
+	Entries    int
+	MaxEntries int
+}
+
+// cacheKey represents a key of the Cache.
+type cacheKey struct {
+	str     string
+	dialect Dialect
+}
+
+// cacheEntry represents an entry of the Cache.
+type cacheEntry struct {
+	key cacheKey
+	rx  matcher
+	err error
+}
+
+// Cache represents a compiled regexp cache keyed by the source and the dialect, which evicts the least recently used entry.
+//
+// Cache is safe for concurrent use by multiple goroutines.
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	entries    map[cacheKey]*list.Element
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// NewCache returns a new Cache which holds at most maxEntries entries.
+//
+// A maxEntries less than or equal to 0 disables the caching.
+func NewCache(maxEntries int) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		entries:    make(map[cacheKey]*list.Element),
+	}
+}
+
+// SetMaxEntries sets the maximum number of entries of c, and evicts the overflowed entries.
+func (c *Cache) SetMaxEntries(n int) {
+	c.mu.Lock()
+	c.maxEntries = n
+	c.evict()
+	c.mu.Unlock()
+}
+
+// Purge removes all entries of c.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	c.ll.Init()
+	c.entries = make(map[cacheKey]*list.Element)
+	c.mu.Unlock()
+}
+
+// Stats returns the statistics of c.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	entries, maxEntries := c.ll.Len(), c.maxEntries
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:       atomic.LoadUint64(&c.hits),
+		Misses:     atomic.LoadUint64(&c.misses),
+		Evictions:  atomic.LoadUint64(&c.evictions),
+		Entries:    entries,
+		MaxEntries: maxEntries,
+	}
+}
+
+// Check reports the syntax error of str in the dialect, compiling it through c.
+func (c *Cache) Check(str string, dialect Dialect) error {
+	_, err := c.get(str, dialect)
+	return err
+}
+
+// get returns the compiled regexp of str in the dialect.
+func (c *Cache) get(str string, dialect Dialect) (matcher, error) {
+	key := cacheKey{str: str, dialect: dialect}
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.ll.MoveToFront(elem)
+		e := elem.Value.(*cacheEntry)
+		c.mu.Unlock()
+		atomic.AddUint64(&c.hits, 1)
+		return e.rx, e.err
+	}
+	c.mu.Unlock()
+	atomic.AddUint64(&c.misses, 1)
+
+	// compiles without the lock, the concurrent misses of the same key may compile twice.
+	rx, err := compile(str, dialect)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.maxEntries <= 0 {
+		return rx, err
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.ll.MoveToFront(elem)
+		e := elem.Value.(*cacheEntry)
+		return e.rx, e.err
+	}
+	c.entries[key] = c.ll.PushFront(&cacheEntry{key: key, rx: rx, err: err})
+	c.evict()
+
+	return rx, err
+}
+
+// evict removes the least recently used entries over the maxEntries.
+func (c *Cache) evict() {
+	for c.ll.Len() > 0 && c.ll.Len() > c.maxEntries {
+		elem := c.ll.Back()
+		c.ll.Remove(elem)
+		delete(c.entries, elem.Value.(*cacheEntry).key)
+		atomic.AddUint64(&c.evictions, 1)
+	}
+}
+
+// compile compiles str in the dialect.
+func compile(str string, dialect Dialect) (matcher, error) {
+	if dialect == ECMA262 {
+		rx, err := ecma262.Compile(str)
+		if err != nil {
+			return nil, err
+		}
+		return rx, nil
+	}
+
+	rx, err := regexp.Compile(str)
+	if err != nil {
+		return nil, err
+	}
+
+	return rx, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lazyregexp
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	type lookup struct {
+		str     string
+		dialect Dialect
+	}
+
+	tests := []struct {
+		name       string
+		maxEntries int
+		lookups    []lookup
+		want       CacheStats
+	}{
+		{
+			name:       "hit",
+			maxEntries: 2,
+			lookups:    []lookup{{"a", RE2}, {"a", RE2}},
+			want:       CacheStats{Hits: 1, Misses: 1, Entries: 1, MaxEntries: 2},
+		},
+		{
+			name:       "keyed by dialect",
+			maxEntries: 2,
+			lookups:    []lookup{{"a", RE2}, {"a", ECMA262}},
+			want:       CacheStats{Misses: 2, Entries: 2, MaxEntries: 2},
+		},
+		{
+			name:       "evicts least recently used",
+			maxEntries: 2,
+			lookups:    []lookup{{"a", RE2}, {"b", RE2}, {"a", RE2}, {"c", RE2}, {"a", RE2}, {"b", RE2}},
+			want:       CacheStats{Hits: 2, Misses: 4, Evictions: 2, Entries: 2, MaxEntries: 2},
+		},
+		{
+			name:       "caches the error",
+			maxEntries: 2,
+			lookups:    []lookup{{"(", ECMA262}, {"(", ECMA262}},
+			want:       CacheStats{Hits: 1, Misses: 1, Entries: 1, MaxEntries: 2},
+		},
+		{
+			name:       "disabled",
+			maxEntries: 0,
+			lookups:    []lookup{{"a", RE2}, {"a", RE2}},
+			want:       CacheStats{Misses: 2},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewCache(tt.maxEntries)
+			for _, l := range tt.lookups {
+				_ = c.Check(l.str, l.dialect)
+			}
+			if got := c.Stats(); got != tt.want {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheCheck(t *testing.T) {
+	c := NewCache(4)
+	if err := c.Check("(?=a)", ECMA262); err != nil {
+		t.Errorf("Check(ECMA262) = %v", err)
+	}
+	if err := c.Check("(?=a)", RE2); err == nil {
+		t.Error("Check(RE2) = nil, want error")
+	}
+}
+
+func TestCacheSetMaxEntries(t *testing.T) {
+	c := NewCache(4)
+	for i := 0; i < 4; i++ {
+		_ = c.Check(strconv.Itoa(i), RE2)
+	}
+	c.SetMaxEntries(1)
+	want := CacheStats{Misses: 4, Evictions: 3, Entries: 1, MaxEntries: 1}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+
+	// the most recently used entry survives.
+	_ = c.Check("3", RE2)
+	if got := c.Stats().Hits; got != 1 {
+		t.Errorf("Hits = %d, want 1", got)
+	}
+
+	c.Purge()
+	if got := c.Stats().Entries; got != 0 {
+		t.Errorf("Entries = %d after Purge, want 0", got)
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	c := NewCache(8)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 64; j++ {
+				re := &Regexp{str: strconv.Itoa((i + j) % 16), dialect: Dialect(j % 2), cache: c}
+				if !re.MatchString(re.str) {
+					t.Errorf("%q does not match itself", re.str)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	st := c.Stats()
+	if st.Entries > 8 {
+		t.Errorf("Entries = %d, want <= 8", st.Entries)
+	}
+	if st.Hits+st.Misses != 16*64 {
+		t.Errorf("Hits+Misses = %d, want %d", st.Hits+st.Misses, 16*64)
+	}
+}
@@ -10,17 +10,46 @@ package lazyregexp
 
 import (
 	"regexp"
+	"regexp/syntax"
 	"sync"
 
 	regexpinterface "github.com/zchee/go-jsonschema/pkg/regexp"
+	"github.com/zchee/go-jsonschema/pkg/regexp/ecma262"
 )
 
+// Dialect represents a dialect of the regular expression.
+type Dialect int
+
+// The list of Dialect.
+const (
+	// RE2 is the RE2 syntax accepted by the Go regexp package.
+	RE2 Dialect = iota
+
+	// ECMA262 is the ECMA-262 regular expression, which is used by the JSON Schema.
+	ECMA262
+)
+
+// matcher is the methods of regexpinterface.Regexp implemented by both regexp.Regexp and ecma262.Regexp.
+type matcher interface {
+	String() string
+	FindSubmatch(s []byte) [][]byte
+	FindStringSubmatch(s string) []string
+	FindStringSubmatchIndex(s string) []int
+	ReplaceAllString(src, repl string) string
+	FindString(s string) string
+	FindAllString(s string, n int) []string
+	MatchString(s string) bool
+	SubexpNames() []string
+}
+
 // Regexp is a wrapper around regexp.Regexp, where the underlying regexp will be
 // compiled the first time it is needed.
 type Regexp struct {
-	str  string
-	once sync.Once
-	rx   *regexp.Regexp
+	str     string
+	dialect Dialect
+	cache   *Cache
+	once    sync.Once
+	rx      matcher
 }
 
 // MustCompile creates a new lazy regexp, delaying the compiling work until it is first
@@ -30,22 +59,60 @@ func MustCompile(str string) regexpinterface.Regexp {
 	return &Regexp{str: str}
 }
 
+// Compile checks the syntax of str in the dialect, and creates a new lazy regexp
+// which delays the compiling work until it is first needed.
+//
+// The regexp is compiled through the DefaultCache.
+func Compile(str string, dialect Dialect) (*Regexp, error) {
+	switch dialect {
+	case ECMA262:
+		if err := ecma262.Validate(str); err != nil {
+			return nil, err
+		}
+	default:
+		if _, err := syntax.Parse(str, syntax.Perl); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Regexp{str: str, dialect: dialect, cache: DefaultCache}, nil
+}
+
+// Dialect returns the dialect of r.
+func (r *Regexp) Dialect() Dialect {
+	return r.dialect
+}
+
 func (r *Regexp) Copy() regexpinterface.Regexp {
 	return r
 }
 
-func (r *Regexp) re() *regexp.Regexp {
+func (r *Regexp) re() matcher {
 	r.once.Do(r.build)
 	return r.rx
 }
 
 func (r *Regexp) build() {
-	r.rx = regexp.MustCompile(r.str)
-	r.str = ""
+	if r.cache != nil {
+		rx, err := r.cache.get(r.str, r.dialect)
+		if err != nil {
+			panic(err)
+		}
+		r.rx = rx
+		return
+	}
+
+	switch r.dialect {
+	case ECMA262:
+		r.rx = ecma262.MustCompile(r.str)
+	default:
+		r.rx = regexp.MustCompile(r.str)
+	}
 }
 
+// String returns the source text of the regexp without compiling it.
 func (r *Regexp) String() string {
-	return r.re().String()
+	return r.str
 }
 
 func (r *Regexp) FindSubmatch(s []byte) [][]byte {
@@ -72,6 +139,17 @@ func (r *Regexp) FindAllString(s string, n int) []string {
 	return r.re().FindAllString(s, n)
 }
 
+// MatchStringError is like MatchString but reports the matching error of the ECMA262 dialect.
+func (r *Regexp) MatchStringError(s string) (bool, error) {
+	if m, ok := r.re().(interface {
+		MatchStringError(s string) (bool, error)
+	}); ok {
+		return m.MatchStringError(s)
+	}
+
+	return r.re().MatchString(s), nil
+}
+
 func (r *Regexp) MatchString(s string) bool {
 	return r.re().MatchString(s)
 }
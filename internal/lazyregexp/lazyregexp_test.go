@@ -0,0 +1,84 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lazyregexp
+
+import (
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		expr    string
+		dialect Dialect
+		wantErr bool
+	}{
+		{expr: "^[a-z]+$", dialect: RE2},
+		{expr: "^[a-z]+$", dialect: ECMA262},
+		{expr: "(?=a)", dialect: RE2, wantErr: true},
+		{expr: "(?=a)", dialect: ECMA262},
+		{expr: "(?i)a", dialect: RE2},
+		{expr: "(?i)a", dialect: ECMA262, wantErr: true},
+		{expr: "(", dialect: RE2, wantErr: true},
+		{expr: "(", dialect: ECMA262, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+
+			re, err := Compile(tt.expr, tt.dialect)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Compile(%q, %d) error = %v, wantErr %t", tt.expr, tt.dialect, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := re.Dialect(); got != tt.dialect {
+				t.Errorf("Dialect() = %d, want %d", got, tt.dialect)
+			}
+			if got := re.String(); got != tt.expr {
+				t.Errorf("String() = %q, want %q", got, tt.expr)
+			}
+		})
+	}
+}
+
+func TestRegexpLazy(t *testing.T) {
+	c := NewCache(8)
+	re := &Regexp{str: "(?<=a)b", dialect: ECMA262, cache: c}
+
+	if st := c.Stats(); st.Misses != 0 || st.Entries != 0 {
+		t.Fatalf("the regexp is compiled before the first use: %+v", st)
+	}
+	if got := re.String(); got != "(?<=a)b" {
+		t.Errorf("String() = %q", got)
+	}
+	if st := c.Stats(); st.Misses != 0 {
+		t.Fatalf("String compiles the regexp: %+v", st)
+	}
+
+	if !re.MatchString("ab") || re.MatchString("cb") {
+		t.Error("MatchString does not follow the ECMA-262 lookbehind")
+	}
+	if got := re.FindString("xab"); got != "b" {
+		t.Errorf("FindString() = %q, want %q", got, "b")
+	}
+	if ok, err := re.MatchStringError("ab"); !ok || err != nil {
+		t.Errorf("MatchStringError() = %t, %v", ok, err)
+	}
+	if st := c.Stats(); st.Misses != 1 || st.Entries != 1 {
+		t.Errorf("the regexp is compiled more than once: %+v", st)
+	}
+}
+
+func TestMustCompile(t *testing.T) {
+	re := MustCompile(`^\d+$`)
+	if !re.MatchString("123") || re.MatchString("12a") {
+		t.Error("MatchString does not follow the pattern")
+	}
+	if re.Copy() != re {
+		t.Error("Copy returns a different regexp")
+	}
+}
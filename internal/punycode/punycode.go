@@ -0,0 +1,206 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package punycode implements the Punycode encoding of RFC 3492.
+//
+// RFC 3492:
+//  https://tools.ietf.org/html/rfc3492
+package punycode
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	base        int32 = 36
+	damp        int32 = 700
+	initialBias int32 = 72
+	initialN    int32 = 128
+	skew        int32 = 38
+	tmax        int32 = 26
+	tmin        int32 = 1
+	delimiter         = '-'
+)
+
+// ErrInvalid is returned when the input is not valid Punycode.
+var ErrInvalid = errors.New("punycode: invalid input")
+
+// ErrOverflow is returned when the input overflows the Punycode arithmetic.
+var ErrOverflow = errors.New("punycode: overflow")
+
+// adapt is the bias adaptation function of RFC 3492, section 6.1.
+func adapt(delta, numPoints int32, firstTime bool) int32 {
+	if firstTime {
+		delta /= damp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+
+	k := int32(0)
+	for delta > ((base-tmin)*tmax)/2 {
+		delta /= base - tmin
+		k += base
+	}
+
+	return k + (base-tmin+1)*delta/(delta+skew)
+}
+
+// threshold returns the threshold t of RFC 3492, section 6.2 for k and bias.
+func threshold(k, bias int32) int32 {
+	switch {
+	case k <= bias:
+		return tmin
+	case k >= bias+tmax:
+		return tmax
+	default:
+		return k - bias
+	}
+}
+
+// decodeDigit returns the numeric value of the basic code point c.
+func decodeDigit(c byte) (int32, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int32(c-'0') + 26, true
+	case c >= 'A' && c <= 'Z':
+		return int32(c - 'A'), true
+	case c >= 'a' && c <= 'z':
+		return int32(c - 'a'), true
+	}
+
+	return 0, false
+}
+
+// encodeDigit returns the basic code point of the d digit.
+func encodeDigit(d int32) byte {
+	if d < 26 {
+		return byte(d) + 'a'
+	}
+
+	return byte(d-26) + '0'
+}
+
+// Decode decodes the Punycode s, which must not have the "xn--" ACE prefix.
+func Decode(s string) (string, error) {
+	var output []rune
+	pos := 0
+	if i := strings.LastIndexByte(s, delimiter); i >= 0 {
+		for j := 0; j < i; j++ {
+			if s[j] >= utf8.RuneSelf {
+				return "", ErrInvalid
+			}
+			output = append(output, rune(s[j]))
+		}
+		pos = i + 1
+	}
+
+	n, bias, i := initialN, initialBias, int32(0)
+	for pos < len(s) {
+		oldi, w := i, int32(1)
+		for k := base; ; k += base {
+			if pos >= len(s) {
+				return "", ErrInvalid
+			}
+			digit, ok := decodeDigit(s[pos])
+			pos++
+			if !ok {
+				return "", ErrInvalid
+			}
+			if digit > (math.MaxInt32-i)/w {
+				return "", ErrOverflow
+			}
+			i += digit * w
+			t := threshold(k, bias)
+			if digit < t {
+				break
+			}
+			if w > math.MaxInt32/(base-t) {
+				return "", ErrOverflow
+			}
+			w *= base - t
+		}
+
+		numPoints := int32(len(output) + 1)
+		bias = adapt(i-oldi, numPoints, oldi == 0)
+		if i/numPoints > math.MaxInt32-n {
+			return "", ErrOverflow
+		}
+		n += i / numPoints
+		i %= numPoints
+		if n > utf8.MaxRune || (n >= 0xD800 && n <= 0xDFFF) {
+			return "", ErrInvalid
+		}
+
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = n
+		i++
+	}
+
+	return string(output), nil
+}
+
+// Encode encodes s to Punycode without the "xn--" ACE prefix.
+func Encode(s string) (string, error) {
+	input := []rune(s)
+	var b strings.Builder
+	for _, r := range input {
+		if r < utf8.RuneSelf {
+			b.WriteByte(byte(r))
+		}
+	}
+	basic := int32(b.Len())
+	h := basic
+	if basic > 0 {
+		b.WriteByte(delimiter)
+	}
+
+	n, delta, bias := initialN, int32(0), initialBias
+	for h < int32(len(input)) {
+		m := int32(math.MaxInt32)
+		for _, r := range input {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+		if (m - n) > (math.MaxInt32-delta)/(h+1) {
+			return "", ErrOverflow
+		}
+		delta += (m - n) * (h + 1)
+		n = m
+
+		for _, r := range input {
+			if r < n {
+				delta++
+				if delta == math.MaxInt32 {
+					return "", ErrOverflow
+				}
+			}
+			if r != n {
+				continue
+			}
+			q := delta
+			for k := base; ; k += base {
+				t := threshold(k, bias)
+				if q < t {
+					break
+				}
+				b.WriteByte(encodeDigit(t + (q-t)%(base-t)))
+				q = (q - t) / (base - t)
+			}
+			b.WriteByte(encodeDigit(q))
+			bias = adapt(delta, h+1, h == basic)
+			delta = 0
+			h++
+		}
+		delta++
+		n++
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package punycode
+
+import "testing"
+
+func TestPunycode(t *testing.T) {
+	// the sample strings of RFC 3492, section 7.1.
+	tests := []struct {
+		name    string
+		decoded string
+		encoded string
+	}{
+		{name: "ascii", decoded: "abc", encoded: "abc-"},
+		{name: "empty", decoded: "", encoded: ""},
+		{name: "arabic", decoded: "ليهمابتكلموشعربي؟", encoded: "egbpdaj6bu4bxfgehfvwxn"},
+		{name: "chinese", decoded: "他们为什么不说中文", encoded: "ihqwcrb4cv8a8dqg056pqjye"},
+		{name: "czech", decoded: "Pročprostěnemluvíčesky", encoded: "Proprostnemluvesky-uyb24dma41a"},
+		{name: "japanese", decoded: "ひとつ屋根の下2", encoded: "2-u9tlzr9756bt3uc0v"},
+		{name: "mixed", decoded: "3年B組金八先生", encoded: "3B-ww4c5e180e575a65lsy2b"},
+		{name: "german", decoded: "bücher", encoded: "bcher-kva"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Encode(tt.decoded)
+			if err != nil {
+				t.Fatalf("Encode(%q): %v", tt.decoded, err)
+			}
+			if got != tt.encoded {
+				t.Errorf("Encode(%q) = %q, want %q", tt.decoded, got, tt.encoded)
+			}
+			got, err = Decode(tt.encoded)
+			if err != nil {
+				t.Fatalf("Decode(%q): %v", tt.encoded, err)
+			}
+			if got != tt.decoded {
+				t.Errorf("Decode(%q) = %q, want %q", tt.encoded, got, tt.decoded)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []string{
+		"a-!",
+		"99999999999",
+		"ü-abc",
+	}
+	for _, in := range tests {
+		if got, err := Decode(in); err == nil {
+			t.Errorf("Decode(%q) = %q, want error", in, got)
+		}
+	}
+}
@@ -8,19 +8,19 @@
 // The (*rtype).nameOff method is a convenience wrapper for this function.
 // Implemented in the runtime package.
 // func resolveNameOff(ptrInModule unsafe.Pointer, off int32) unsafe.Pointer
-TEXT ·ResolveNameOff(SB), NOSPLIT, $0-25
+TEXT ·ResolveNameOff(SB), NOSPLIT, $0-24
 	JMP	reflect·resolveNameOff(SB)
 
 // ResolveTypeOff resolves an *rtype offset from a base type.
 // The (*rtype).typeOff method is a convenience wrapper for this function.
 // Implemented in the runtime package.
 // func ResolveTypeOff(rtype unsafe.Pointer, off int32) unsafe.Pointer
-TEXT ·ResolveTypeOff(SB), NOSPLIT, $0-25
+TEXT ·ResolveTypeOff(SB), NOSPLIT, $0-24
 	JMP	reflect·resolveTypeOff(SB)
 
 // resolveTextOff resolves an function pointer offset from a base type.
 // The (*rtype).textOff method is a convenience wrapper for this function.
 // Implemented in the runtime package.
 // func ResolveTextOff(rtype unsafe.Pointer, off int32) unsafe.Pointer
-TEXT ·ResolveTextOff(SB), NOSPLIT, $0-25
-	JMP	reflectyresolveTextOff(SB)
+TEXT ·ResolveTextOff(SB), NOSPLIT, $0-24
+	JMP	reflect·resolveTextOff(SB)
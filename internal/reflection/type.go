@@ -71,7 +71,7 @@ type Method struct {
 	Tfn   TextOff // fn used for normal method call
 }
 
-//go:linkname rtype reflect.rtype
+// rtype is the opaque pointer to the runtime type descriptor of the reflect package.
 type rtype unsafe.Pointer
 
 type Rtype struct {
@@ -365,7 +365,7 @@ func (t *Rtype) ConvertibleTo(u reflect.Type) bool {
 	if u == nil {
 		panic("reflect: nil type passed to Type.ConvertibleTo")
 	}
-	return convertOp(unsafe.Pointer(&u), unsafe.Pointer(&t)) != nil
+	return ToType(t).ConvertibleTo(u)
 }
 
 func (t *Rtype) Comparable() bool {
@@ -679,7 +679,7 @@ func haveIdenticalUnderlyingType(T, V *rtype, cmpTags bool) bool {
 			if cmpTags && tf.Name.Tag() != vf.Name.Tag() {
 				return false
 			}
-			if tf.OffsetEmbed != vf.OffsetEmbed {
+			if tf.Offset != vf.Offset {
 				return false
 			}
 		}
@@ -691,9 +691,9 @@ func haveIdenticalUnderlyingType(T, V *rtype, cmpTags bool) bool {
 
 // StructField
 type StructField struct {
-	Name        Name    // name is always non-empty
-	Type        *Rtype  // type of field
-	OffsetEmbed uintptr // byte offset of field<<1 | isEmbedded
+	Name   Name    // name is always non-empty
+	Type   *Rtype  // type of field
+	Offset uintptr // byte offset of field
 }
 
 // StructType represents a struct type.
@@ -721,15 +721,13 @@ func add(p unsafe.Pointer, x uintptr, whySafe string) unsafe.Pointer {
 //	1<<0 the Name is exported
 //	1<<1 tag data follows the Name
 //	1<<2 pkgPath nameOff follows the Name and tag
+//	1<<3 the Name is of an embedded (a.k.a. anonymous) field
 //
-// The next two bytes are the data length:
+// Following that, there is a varint-encoded length of the Name,
+// followed by the Name itself.
 //
-//	 l := uint16(data[1])<<8 | uint16(data[2])
-//
-// Bytes [3:3+l] are the string data.
-//
-// If tag data follows then bytes 3+l and 3+l+1 are the tag length,
-// with the data following.
+// If tag data is present, it also has a varint-encoded length
+// followed by the tag itself.
 //
 // If the import path follows, then 4 bytes at the end of
 // the data form a nameOff. The import path is only set for concrete
@@ -749,39 +747,62 @@ func (n Name) IsExported() bool {
 	return (*n.bytes)&(1<<0) != 0
 }
 
+func (n Name) HasTag() bool {
+	return (*n.bytes)&(1<<1) != 0
+}
+
+func (n Name) IsEmbedded() bool {
+	return (*n.bytes)&(1<<3) != 0
+}
+
+// ReadVarint parses a varint as encoded by encoding/binary.
+// It returns the number of encoded bytes and the encoded value.
+func (n Name) ReadVarint(off int) (int, int) {
+	v := 0
+	for i := 0; ; i++ {
+		x := *n.Data(off+i, "read varint")
+		v += int(x&0x7f) << (7 * i)
+		if x&0x80 == 0 {
+			return i + 1, v
+		}
+	}
+}
+
 func (n Name) NameLen() int {
-	return int(uint16(*n.Data(1, "name len field"))<<8 | uint16(*n.Data(2, "name len field")))
+	_, l := n.ReadVarint(1)
+	return l
 }
 
 func (n Name) TagLen() int {
-	if *n.Data(0, "name flag field")&(1<<1) == 0 {
+	if !n.HasTag() {
 		return 0
 	}
-	off := 3 + n.NameLen()
-	return int(uint16(*n.Data(off, "name taglen field"))<<8 | uint16(*n.Data(off+1, "name taglen field")))
+	i, l := n.ReadVarint(1)
+	_, l2 := n.ReadVarint(1 + i + l)
+	return l2
 }
 
 func (n Name) Name() (s string) {
 	if n.bytes == nil {
 		return
 	}
-	b := (*[4]byte)(unsafe.Pointer(n.bytes))
+	i, l := n.ReadVarint(1)
 
 	hdr := (*StringHeader)(unsafe.Pointer(&s))
-	hdr.Data = unsafe.Pointer(&b[3])
-	hdr.Len = int(b[1])<<8 | int(b[2])
+	hdr.Data = unsafe.Pointer(n.Data(1+i, "non-empty string"))
+	hdr.Len = l
 	return s
 }
 
 func (n Name) Tag() (s string) {
-	tl := n.TagLen()
-	if tl == 0 {
+	if !n.HasTag() {
 		return ""
 	}
-	nl := n.NameLen()
+	i, l := n.ReadVarint(1)
+	i2, l2 := n.ReadVarint(1 + i + l)
 	hdr := (*StringHeader)(unsafe.Pointer(&s))
-	hdr.Data = unsafe.Pointer(n.Data(3+nl+2, "non-empty string"))
-	hdr.Len = tl
+	hdr.Data = unsafe.Pointer(n.Data(1+i+l+i2, "non-empty string"))
+	hdr.Len = l2
 	return s
 }
 
@@ -789,46 +810,67 @@ func (n Name) PkgPath() string {
 	if n.bytes == nil || *n.Data(0, "name flag field")&(1<<2) == 0 {
 		return ""
 	}
-	off := 3 + n.NameLen()
-	if tl := n.TagLen(); tl > 0 {
-		off += 2 + tl
+	i, l := n.ReadVarint(1)
+	off := 1 + i + l
+	if n.HasTag() {
+		i2, l2 := n.ReadVarint(off)
+		off += i2 + l2
 	}
 	var nameOff int32
 	// Note that this field may not be aligned in memory,
 	// so we cannot use a direct int32 assignment here.
 	copy((*[4]byte)(unsafe.Pointer(&nameOff))[:], (*[4]byte)(unsafe.Pointer(n.Data(off, "name offset field")))[:])
-	pkgPathName := Name{(*byte)(ResolveTypeOff(unsafe.Pointer(n.bytes), nameOff))}
+	pkgPathName := Name{(*byte)(ResolveNameOff(unsafe.Pointer(n.bytes), nameOff))}
 	return pkgPathName.Name()
 }
 
-func NewName(n, tag string, exported bool) Name {
-	if len(n) > 1<<16-1 {
-		panic("reflect.nameFrom: name too long: " + n)
+// writeVarint writes n to buf in varint form. Returns the
+// number of bytes written. n must be nonnegative.
+func writeVarint(buf []byte, n int) int {
+	for i := 0; ; i++ {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 {
+			buf[i] = b
+			return i + 1
+		}
+		buf[i] = b | 0x80
 	}
-	if len(tag) > 1<<16-1 {
-		panic("reflect.nameFrom: tag too long: " + tag)
+}
+
+func NewName(n, tag string, exported, embedded bool) Name {
+	if len(n) >= 1<<29 {
+		panic("reflect.nameFrom: name too long: " + n[:1024] + "...")
+	}
+	if len(tag) >= 1<<29 {
+		panic("reflect.nameFrom: tag too long: " + tag[:1024] + "...")
 	}
+	var nameLen [10]byte
+	var tagLen [10]byte
+	nameLenLen := writeVarint(nameLen[:], len(n))
+	tagLenLen := writeVarint(tagLen[:], len(tag))
 
 	var bits byte
-	l := 1 + 2 + len(n)
+	l := 1 + nameLenLen + len(n)
 	if exported {
 		bits |= 1 << 0
 	}
 	if len(tag) > 0 {
-		l += 2 + len(tag)
+		l += tagLenLen + len(tag)
 		bits |= 1 << 1
 	}
+	if embedded {
+		bits |= 1 << 3
+	}
 
 	b := make([]byte, l)
 	b[0] = bits
-	b[1] = uint8(len(n) >> 8)
-	b[2] = uint8(len(n))
-	copy(b[3:], n)
+	copy(b[1:], nameLen[:nameLenLen])
+	copy(b[1+nameLenLen:], n)
 	if len(tag) > 0 {
-		tb := b[3+len(n):]
-		tb[0] = uint8(len(tag) >> 8)
-		tb[1] = uint8(len(tag))
-		copy(tb[2:], tag)
+		tb := b[1+nameLenLen+len(n):]
+		copy(tb, tagLen[:tagLenLen])
+		copy(tb[tagLenLen:], tag)
 	}
 
 	return Name{bytes: &b[0]}
@@ -976,5 +1018,67 @@ func ToType(t *Rtype) reflect.Type {
 	if t == nil {
 		return nil
 	}
-	return *(*reflect.Type)(unsafe.Pointer(t))
+	// reuses the itab of the *reflect.rtype, which is the same type as the Rtype.
+	typ := reflect.TypeOf(0)
+	(*nonEmptyInterface)(unsafe.Pointer(&typ)).word = unsafe.Pointer(t)
+	return typ
+}
+
+// nonEmptyInterface is the header for an interface value with methods.
+type nonEmptyInterface struct {
+	itab unsafe.Pointer
+	word unsafe.Pointer
+}
+
+// emptyInterface is the header for an interface{} value.
+type emptyInterface struct {
+	typ  *Rtype
+	word unsafe.Pointer
+}
+
+// TypeOf returns the *Rtype of the dynamic type of i, or nil if i is nil.
+func TypeOf(i interface{}) *Rtype {
+	eface := *(*emptyInterface)(unsafe.Pointer(&i))
+	return eface.typ
+}
+
+// FromType returns the *Rtype of the reflect.Type t.
+func FromType(t reflect.Type) *Rtype {
+	if t == nil {
+		return nil
+	}
+	return (*Rtype)((*nonEmptyInterface)(unsafe.Pointer(&t)).word)
+}
+
+// ElemType returns the element *Rtype of the array, chan, map, pointer or slice type.
+func (t *Rtype) ElemType() *Rtype {
+	switch t.Kind() {
+	case reflect.Array:
+		return (*ArrayType)(unsafe.Pointer(t)).Elem
+	case reflect.Chan:
+		return (*ChanType)(unsafe.Pointer(t)).Elem
+	case reflect.Map:
+		return (*MapType)(unsafe.Pointer(t)).Elem
+	case reflect.Ptr:
+		return (*PtrType)(unsafe.Pointer(t)).Elem
+	case reflect.Slice:
+		return (*SliceType)(unsafe.Pointer(t)).Elem
+	}
+	panic("reflect: Elem of invalid type " + t.String())
+}
+
+// KeyType returns the key *Rtype of the map type.
+func (t *Rtype) KeyType() *Rtype {
+	if t.Kind() != reflect.Map {
+		panic("reflect: Key of non-map type " + t.String())
+	}
+	return (*MapType)(unsafe.Pointer(t)).Key
+}
+
+// Fields returns the fields of the struct type.
+func (t *Rtype) Fields() []StructField {
+	if t.Kind() != reflect.Struct {
+		panic("reflect: Fields of non-struct type " + t.String())
+	}
+	return (*StructType)(unsafe.Pointer(t)).Fields
 }
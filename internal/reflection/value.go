@@ -1,7 +1,6 @@
 package reflection
 
 import (
-	"reflect"
 	"unsafe"
 )
 
@@ -68,6 +67,3 @@ const (
 	flagMethodShift      = 10
 	flagRO          flag = flagStickyRO | flagEmbedRO
 )
-
-//go:linkname convertOp reflect.convertOp
-func convertOp(dst, src unsafe.Pointer) func(reflect.Value, reflect.Type) reflect.Value
@@ -34,6 +34,7 @@ const (
 	keyRequired             = "required"
 	keyAdditionalProperties = "additionalProperties"
 	keyDefinitions          = "definitions"
+	keyDefs                 = "$defs"
 	keyProperties           = "properties"
 	keyPatternProperties    = "patternProperties"
 	keyDependencies         = "dependencies"
@@ -63,3 +64,19 @@ const (
 	keyHasMultiple = "HasMultiple"
 	keyNames       = "Names"
 )
+
+const (
+	keyLine             = "line"
+	keyValid            = "valid"
+	keyErrors           = "errors"
+	keyError            = "error"
+	keyKeywordLocation  = "keywordLocation"
+	keyInstanceLocation = "instanceLocation"
+	keyMessage          = "message"
+)
+
+const (
+	keyKeyword         = "keyword"
+	keyBreaksProducers = "breaksProducers"
+	keyBreaksConsumers = "breaksConsumers"
+)
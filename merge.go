@@ -0,0 +1,631 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"math/big"
+	"net/url"
+	"strings"
+)
+
+// Merge returns the Schema which is equivalent to the "allOf" of the schemas.
+//
+// The keywords of the schemas are combined into the single Schema: the "properties" are merged, the "required"s are
+// united, the tightest numeric, length and count bounds are taken, and the "enum"s and the "type"s are intersected.
+// The keywords which cannot be combined losslessly, such as the different "pattern"s or the second "if", and the
+// schemas which have the "$ref" or the "$id" are kept in the "allOf" of the result. The nested "allOf"s are merged too.
+// The "$id" and the "$schema" of the first schema are kept in the result.
+//
+// The result is the false schema if the intersection is obviously empty. Merge does not modify the schemas, but the
+// result may share their sub schemas.
+func Merge(schemas ...*Schema) *Schema {
+	m := &merger{dst: &Schema{}}
+	queue := append([]*Schema(nil), schemas...)
+	for i := 0; i < len(queue); i++ {
+		s := queue[i]
+		switch {
+		case s == nil || (s.Bool != nil && *s.Bool):
+			continue
+		case s.Bool != nil:
+			return BoolSchema(false)
+		case s.Ref != "" || (s.ID != "" && i > 0):
+			m.rest = append(m.rest, s)
+			continue
+		}
+
+		if i == 0 {
+			m.dst.Schema = s.Schema
+			m.dst.ID = s.ID
+		}
+		queue = append(queue, s.AllOf...)
+		if !m.merge(s) {
+			return BoolSchema(false)
+		}
+	}
+	m.dst.AllOf = m.rest
+
+	return m.dst
+}
+
+// mergePair returns the Merge of a and b, or either of them if the other is nil or the true schema.
+func mergePair(a, b *Schema) *Schema {
+	switch {
+	case b == nil || (b.Bool != nil && *b.Bool):
+		return a
+	case a == nil || (a.Bool != nil && *a.Bool):
+		return b
+	}
+
+	return Merge(a, b)
+}
+
+// merger represents a state of the Merge.
+type merger struct {
+	dst *Schema
+
+	// rest is the list of the schemas and the keywords which are not merged into the dst.
+	rest SchemaList
+}
+
+// merge merges the keywords of s into the dst, and reports whether the dst may still accept any instance.
+//
+// The containers of the dst are copied before they are modified, since they may be shared with the schemas.
+func (m *merger) merge(s *Schema) bool {
+	d := m.dst
+	var left *Schema
+	keep := func(f func(l *Schema)) {
+		if left == nil {
+			left = &Schema{}
+			m.rest = append(m.rest, left)
+		}
+		f(left)
+	}
+
+	// the annotations do not affect the validation, so the first ones are taken.
+	if d.Title == "" {
+		d.Title = s.Title
+	}
+	if d.Comment == "" {
+		d.Comment = s.Comment
+	}
+	if d.Description == "" {
+		d.Description = s.Description
+	}
+	if d.Default == nil {
+		d.Default = s.Default
+	}
+	d.ReadOnly = d.ReadOnly || s.ReadOnly
+	d.WriteOnly = d.WriteOnly || s.WriteOnly
+	if len(s.Examples) > 0 {
+		d.Examples = append(append(Interfaces(nil), d.Examples...), s.Examples...)
+	}
+
+	// numbers
+	d.Maximum = minFloat(d.Maximum, s.Maximum)
+	d.ExclusiveMaximum = minFloat(d.ExclusiveMaximum, s.ExclusiveMaximum)
+	d.Minimum = maxFloat(d.Minimum, s.Minimum)
+	d.ExclusiveMinimum = maxFloat(d.ExclusiveMinimum, s.ExclusiveMinimum)
+	switch {
+	case s.MultipleOf == 0 || isMultipleOf(d.MultipleOf, s.MultipleOf):
+	case d.MultipleOf == 0 || isMultipleOf(s.MultipleOf, d.MultipleOf):
+		d.MultipleOf = s.MultipleOf
+	default:
+		keep(func(l *Schema) { l.MultipleOf = s.MultipleOf })
+	}
+
+	// strings
+	d.MaxLength = minInt(d.MaxLength, s.MaxLength)
+	if s.MinLength > d.MinLength {
+		d.MinLength = s.MinLength
+	}
+	switch {
+	case s.Pattern == nil || (d.Pattern != nil && d.Pattern.String() == s.Pattern.String()):
+	case d.Pattern == nil:
+		d.Pattern = s.Pattern
+	default:
+		keep(func(l *Schema) { l.Pattern = s.Pattern })
+	}
+	if !mergeString((*string)(&d.Format), string(s.Format)) {
+		keep(func(l *Schema) { l.Format = s.Format })
+	}
+	if !mergeString(&d.ContentMediaType, s.ContentMediaType) {
+		keep(func(l *Schema) { l.ContentMediaType = s.ContentMediaType })
+	}
+	if !mergeString(&d.ContentEncoding, s.ContentEncoding) {
+		keep(func(l *Schema) { l.ContentEncoding = s.ContentEncoding })
+	}
+
+	// arrays
+	d.MaxItems = minInt(d.MaxItems, s.MaxItems)
+	if s.MinItems > d.MinItems {
+		d.MinItems = s.MinItems
+	}
+	d.UniqueItems = d.UniqueItems || s.UniqueItems
+	mergeItems(d, s, keep)
+	switch {
+	case s.Contains == nil:
+	case d.Contains == nil:
+		d.Contains = s.Contains
+	default:
+		keep(func(l *Schema) { l.Contains = s.Contains })
+	}
+
+	// objects
+	d.MaxProperties = minInt(d.MaxProperties, s.MaxProperties)
+	if s.MinProperties > d.MinProperties {
+		d.MinProperties = s.MinProperties
+	}
+	d.Required = unionRequired(d.Required, s.Required)
+	mergeProperties(d, s, keep)
+	if s.PropertyNames != nil {
+		d.PropertyNames = mergePair(d.PropertyNames, s.PropertyNames)
+	}
+	mergeDependencies(d, s, keep)
+	d.Definitions = unionDefinitions(d.Definitions, s.Definitions, func(k string, sub *Schema) {
+		keep(func(l *Schema) {
+			if l.Definitions == nil {
+				l.Definitions = make(Definitions)
+			}
+			l.Definitions[k] = sub
+		})
+	})
+	d.Defs = unionDefinitions(d.Defs, s.Defs, func(k string, sub *Schema) {
+		keep(func(l *Schema) {
+			if l.Defs == nil {
+				l.Defs = make(Definitions)
+			}
+			l.Defs[k] = sub
+		})
+	})
+
+	// any instance types
+	if s.Const != nil {
+		if d.Const != nil && !equal(d.Const.Interface(), s.Const.Interface()) {
+			return false
+		}
+		d.Const = s.Const
+	}
+	if s.Enum != nil {
+		if d.Enum == nil {
+			d.Enum = s.Enum
+		} else if d.Enum = intersectEnum(d.Enum, s.Enum); len(d.Enum) == 0 {
+			return false
+		}
+	}
+	if d.Const != nil && d.Enum != nil {
+		if len(intersectEnum(d.Enum, Enum{d.Const})) == 0 {
+			return false
+		}
+		d.Enum = nil
+	}
+	if s.Type != nil {
+		if d.Type == nil {
+			d.Type = s.Type
+		} else if d.Type = intersectTypes(d.Type, s.Type); len(d.Type) == 0 {
+			return false
+		}
+	}
+
+	// conditionals and combinators
+	switch {
+	case s.If == nil && s.Then == nil && s.Else == nil:
+	case d.If == nil && d.Then == nil && d.Else == nil:
+		d.If, d.Then, d.Else = s.If, s.Then, s.Else
+	default:
+		keep(func(l *Schema) { l.If, l.Then, l.Else = s.If, s.Then, s.Else })
+	}
+	switch {
+	case s.AnyOf == nil:
+	case d.AnyOf == nil:
+		d.AnyOf = s.AnyOf
+	default:
+		keep(func(l *Schema) { l.AnyOf = s.AnyOf })
+	}
+	switch {
+	case s.OneOf == nil:
+	case d.OneOf == nil:
+		d.OneOf = s.OneOf
+	default:
+		keep(func(l *Schema) { l.OneOf = s.OneOf })
+	}
+	switch {
+	case s.Not == nil:
+	case d.Not == nil:
+		d.Not = s.Not
+	default:
+		// not A and not B is not (A or B).
+		d.Not = &Schema{AnyOf: SchemaList{d.Not, s.Not}}
+	}
+
+	return true
+}
+
+// mergeItems merges the "items" and the "additionalItems" of s into d.
+func mergeItems(d, s *Schema, keep func(func(l *Schema))) {
+	switch {
+	case s.Items == nil && s.AdditionalItems == nil:
+	case d.Items == nil && d.AdditionalItems == nil:
+		d.Items, d.AdditionalItems = s.Items, s.AdditionalItems
+	case isSingleItems(d.Items) && isSingleItems(s.Items):
+		// the "additionalItems" is ignored if the "items" is a single schema.
+		d.Items = &Items{Schemas: SchemaList{mergePair(d.Items.Schemas[0], s.Items.Schemas[0])}}
+	default:
+		keep(func(l *Schema) { l.Items, l.AdditionalItems = s.Items, s.AdditionalItems })
+	}
+}
+
+// isSingleItems reports whether items is the single schema for all the elements.
+func isSingleItems(items *Items) bool {
+	return items != nil && !items.HasMultiple && len(items.Schemas) == 1
+}
+
+// mergeProperties merges the "properties", the "patternProperties" and the "additionalProperties" of s into d.
+//
+// The property which is defined only in either schema is validated by the "additionalProperties" of the other,
+// so they are merged. The "patternProperties" are merged only if the other schema has none of them.
+func mergeProperties(d, s *Schema, keep func(func(l *Schema))) {
+	hasObject := func(x *Schema) bool {
+		return x.Properties != nil || x.PatternProperties != nil || x.AdditionalProperties != nil
+	}
+	switch {
+	case !hasObject(s):
+		return
+	case !hasObject(d):
+		d.Properties, d.PatternProperties, d.AdditionalProperties = s.Properties, s.PatternProperties, s.AdditionalProperties
+		return
+	case len(d.PatternProperties) > 0 || len(s.PatternProperties) > 0:
+		keep(func(l *Schema) {
+			l.Properties, l.PatternProperties, l.AdditionalProperties = s.Properties, s.PatternProperties, s.AdditionalProperties
+		})
+		return
+	}
+
+	var dap, sap *Schema
+	if d.AdditionalProperties != nil {
+		dap = d.AdditionalProperties.Schema
+	}
+	if s.AdditionalProperties != nil {
+		sap = s.AdditionalProperties.Schema
+	}
+
+	props := make(Properties, len(d.Properties)+len(s.Properties))
+	for k, p := range d.Properties {
+		if sp, ok := s.Properties[k]; ok {
+			props[k] = mergePair(p, sp)
+		} else {
+			props[k] = mergePair(p, sap)
+		}
+	}
+	for k, sp := range s.Properties {
+		if _, ok := d.Properties[k]; !ok {
+			props[k] = mergePair(dap, sp)
+		}
+	}
+	d.PatternProperties = nil
+	d.Properties = props
+	if ap := mergePair(dap, sap); ap != nil {
+		d.AdditionalProperties = &AdditionalProperties{Schema: ap}
+	}
+}
+
+// mergeDependencies merges the "dependencies" of s into d.
+func mergeDependencies(d, s *Schema, keep func(func(l *Schema))) {
+	if s.Dependencies == nil {
+		return
+	}
+	if d.Dependencies == nil {
+		d.Dependencies = s.Dependencies
+		return
+	}
+
+	deps := &DependencyMap{Names: make(map[string][]string), Schemas: make(map[string]*Schema)}
+	for k, names := range d.Dependencies.Names {
+		deps.Names[k] = names
+	}
+	for k, sub := range d.Dependencies.Schemas {
+		deps.Schemas[k] = sub
+	}
+	for k, names := range s.Dependencies.Names {
+		if _, ok := deps.Schemas[k]; ok {
+			keep(func(l *Schema) { l.Dependencies = &DependencyMap{Names: map[string][]string{k: names}} })
+			continue
+		}
+		deps.Names[k] = unionStrings(deps.Names[k], names)
+	}
+	for k, sub := range s.Dependencies.Schemas {
+		if _, ok := deps.Names[k]; ok {
+			keep(func(l *Schema) { l.Dependencies = &DependencyMap{Schemas: map[string]*Schema{k: sub}} })
+			continue
+		}
+		deps.Schemas[k] = mergePair(deps.Schemas[k], sub)
+	}
+	d.Dependencies = deps
+}
+
+// unionDefinitions returns the union of a and b, and calls conflict for the definition of b which conflicts with a.
+func unionDefinitions(a, b Definitions, conflict func(k string, sub *Schema)) Definitions {
+	if len(b) == 0 {
+		return a
+	}
+	if len(a) == 0 {
+		return b
+	}
+
+	u := make(Definitions, len(a)+len(b))
+	for k, sub := range a {
+		u[k] = sub
+	}
+	for _, k := range sortedKeys(b) {
+		if sub, ok := u[k]; ok && sub != b[k] {
+			conflict(k, b[k])
+			continue
+		}
+		u[k] = b[k]
+	}
+
+	return u
+}
+
+// unionRequired returns the union of the required property names of a and b, which keeps their order.
+func unionRequired(a, b StringArray) StringArray {
+	if len(b) == 0 {
+		return a
+	}
+
+	u := append(StringArray(nil), a...)
+	seen := make(map[string]bool, len(a))
+	for _, s := range a {
+		seen[s.Value] = true
+	}
+	for _, s := range b {
+		if !seen[s.Value] {
+			seen[s.Value] = true
+			u = append(u, s)
+		}
+	}
+
+	return u
+}
+
+// unionStrings returns the union of a and b, which keeps their order.
+func unionStrings(a, b []string) []string {
+	u := append([]string(nil), a...)
+	for _, s := range b {
+		found := false
+		for _, x := range u {
+			if x == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			u = append(u, s)
+		}
+	}
+
+	return u
+}
+
+// intersectEnum returns the values of a which are also in b.
+func intersectEnum(a, b Enum) Enum {
+	is := Enum{}
+	for _, x := range a {
+		for _, y := range b {
+			if equal(x.Interface(), y.Interface()) {
+				is = append(is, x)
+				break
+			}
+		}
+	}
+
+	return is
+}
+
+// intersectTypes returns the types which are accepted by both of a and b.
+//
+// The "integer" is the subset of the "number".
+func intersectTypes(a, b Types) Types {
+	has := func(ts Types, t Type) bool {
+		for _, x := range ts {
+			if x == t {
+				return true
+			}
+		}
+		return false
+	}
+
+	is := Types{}
+	add := func(t Type) {
+		if !has(is, t) {
+			is = append(is, t)
+		}
+	}
+	for _, t := range a {
+		switch {
+		case has(b, t):
+			add(t)
+		case t == IntegerType && has(b, NumberType), t == NumberType && has(b, IntegerType):
+			add(IntegerType)
+		}
+	}
+
+	return is
+}
+
+// mergeString merges the string keyword value s into d, and reports whether they are not conflicted.
+func mergeString(d *string, s string) bool {
+	switch {
+	case s == "" || *d == s:
+		return true
+	case *d == "":
+		*d = s
+		return true
+	}
+
+	return false
+}
+
+// minFloat returns the smaller of a and b, or the other if either is nil.
+func minFloat(a, b *float64) *float64 {
+	if a == nil || (b != nil && *b < *a) {
+		return b
+	}
+
+	return a
+}
+
+// maxFloat returns the larger of a and b, or the other if either is nil.
+func maxFloat(a, b *float64) *float64 {
+	if a == nil || (b != nil && *b > *a) {
+		return b
+	}
+
+	return a
+}
+
+// minInt returns the smaller of a and b, or the other if either is nil.
+func minInt(a, b *int64) *int64 {
+	if a == nil || (b != nil && *b < *a) {
+		return b
+	}
+
+	return a
+}
+
+// isMultipleOf reports whether a is the multiple of b, where both are positive.
+func isMultipleOf(a, b float64) bool {
+	if a == 0 || b == 0 {
+		return false
+	}
+
+	return new(big.Rat).Quo(floatRat(a), floatRat(b)).IsInt()
+}
+
+// FlattenAllOf returns the copy of s whose "allOf"s are merged into their parents by the Merge.
+//
+// The "allOf" member which has the "$ref" is replaced by the copy of the referenced Schema unless the reference
+// is recursive or crosses the "$id". The "allOf" member which contains the target of any "$ref" is kept at the same
+// index, since its location must not be changed, and so is the "allOf" of the schema whose other sub schemas are
+// referenced.
+// FlattenAllOf returns an error if a "$ref" of s cannot be resolved within s.
+func FlattenAllOf(s *Schema) (*Schema, error) {
+	d, err := newDereferencer(s)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &flattener{
+		dereferencer: d,
+		inlining:     make(map[*Schema]bool),
+	}
+	for _, t := range d.targets {
+		f.targetLocs = append(f.targetLocs, d.locs[t])
+	}
+
+	return f.flatten(s), nil
+}
+
+// flattener represents a state of the FlattenAllOf.
+type flattener struct {
+	*dereferencer
+
+	// targetLocs is the list of the locations of the "$ref" targets.
+	targetLocs []string
+
+	// inlining is the set of the "$ref" targets which are being inlined.
+	inlining map[*Schema]bool
+}
+
+// flatten returns the copy of x whose "allOf"s are merged.
+func (f *flattener) flatten(x *Schema) *Schema {
+	if x.Bool != nil {
+		return x
+	}
+
+	c := x.mapSubschemas(f.flatten)
+	// the keywords adjacent to the "$ref" are ignored, but its sub schemas may be referenced.
+	if x.Ref != "" || len(x.AllOf) == 0 || f.hasInnerTarget(x) {
+		return c
+	}
+
+	base := *c
+	base.AllOf = nil
+	members := []*Schema{&base}
+	var pinned SchemaList
+	for i, orig := range x.AllOf {
+		member := c.AllOf[i]
+		switch {
+		case f.hasTarget(orig):
+			// the merged members before it are replaced with the true schemas to keep its index.
+			for len(pinned) < i {
+				pinned = append(pinned, BoolSchema(true))
+			}
+			pinned = append(pinned, member)
+		case orig.Ref != "":
+			t := f.targets[orig]
+			if f.inlining[t] || t.ID != "" || f.baseOf(t).String() != f.baseOf(orig).String() {
+				members = append(members, member)
+				continue
+			}
+			f.inlining[t] = true
+			members = append(members, f.flatten(t))
+			delete(f.inlining, t)
+		default:
+			members = append(members, member)
+		}
+	}
+
+	merged := Merge(members...)
+	if len(pinned) > 0 && merged.Bool == nil {
+		merged.AllOf = append(pinned, merged.AllOf...)
+	}
+
+	return merged
+}
+
+// hasTarget reports whether the original schema x or its sub schemas are the targets of the "$ref"s.
+func (f *flattener) hasTarget(x *Schema) bool {
+	loc, ok := f.locs[x]
+	if !ok {
+		return false
+	}
+	for _, tl := range f.targetLocs {
+		if tl == loc || strings.HasPrefix(tl, loc+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasInnerTarget reports whether the original schema x has the target of the "$ref" in its sub schemas which
+// may be changed by merging the "allOf" of x, that is, other than in the "allOf" and the definitions.
+func (f *flattener) hasInnerTarget(x *Schema) bool {
+	loc, ok := f.locs[x]
+	if !ok {
+		return false
+	}
+	for _, tl := range f.targetLocs {
+		if !strings.HasPrefix(tl, loc+"/") {
+			continue
+		}
+		switch strings.SplitN(tl[len(loc)+1:], "/", 2)[0] {
+		case keyAllOf, keyDefinitions, keyDefs:
+		default:
+			return true
+		}
+	}
+
+	return false
+}
+
+// baseOf returns the base URI of x, or the empty URI if it is unknown.
+func (f *flattener) baseOf(x *Schema) *url.URL {
+	if u, ok := f.bases[x]; ok {
+		return u
+	}
+
+	return &url.URL{}
+}
@@ -0,0 +1,241 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name      string
+		schemas   []string
+		want      string
+		instances []string
+	}{
+		{
+			name:      "numbers",
+			schemas:   []string{`{"minimum": 1, "maximum": 10, "multipleOf": 2}`, `{"minimum": 3, "exclusiveMaximum": 8, "multipleOf": 4}`},
+			want:      `{"minimum": 3, "maximum": 10, "exclusiveMaximum": 8, "multipleOf": 4}`,
+			instances: []string{`4`, `2`, `6`, `8`, `12`},
+		},
+		{
+			name:      "multipleOf kept",
+			schemas:   []string{`{"multipleOf": 2}`, `{"multipleOf": 3}`},
+			want:      `{"multipleOf": 2, "allOf": [{"multipleOf": 3}]}`,
+			instances: []string{`6`, `4`, `9`},
+		},
+		{
+			name:      "strings",
+			schemas:   []string{`{"type": "string", "minLength": 1, "pattern": "^a"}`, `{"maxLength": 3, "minLength": 2, "pattern": "b$"}`},
+			want:      `{"type": "string", "minLength": 2, "maxLength": 3, "pattern": "^a", "allOf": [{"pattern": "b$"}]}`,
+			instances: []string{`"ab"`, `"a"`, `"abcb"`, `"ba"`, `"aab"`},
+		},
+		{
+			name:      "types",
+			schemas:   []string{`{"type": ["number", "string"]}`, `{"type": ["integer", "null"]}`},
+			want:      `{"type": "integer"}`,
+			instances: []string{`1`, `1.5`, `"a"`, `null`},
+		},
+		{
+			name:      "disjoint types",
+			schemas:   []string{`{"type": "string"}`, `{"type": "number"}`},
+			want:      `false`,
+			instances: []string{`1`, `"a"`},
+		},
+		{
+			name:      "enum and const",
+			schemas:   []string{`{"enum": [1, "a", null]}`, `{"enum": ["a", null, 2]}`, `{"const": "a"}`},
+			want:      `{"const": "a"}`,
+			instances: []string{`"a"`, `null`, `1`},
+		},
+		{
+			name:      "conflicting const",
+			schemas:   []string{`{"const": 1}`, `{"const": 2}`},
+			want:      `false`,
+			instances: []string{`1`, `2`},
+		},
+		{
+			name:    "properties",
+			schemas: []string{`{"properties": {"a": {"type": "integer"}, "b": {"minLength": 1}}, "required": ["a"]}`, `{"properties": {"a": {"minimum": 0}, "c": {}}, "additionalProperties": {"type": "string"}, "required": ["c", "a"]}`},
+			want: `{
+				"properties": {"a": {"type": "integer", "minimum": 0}, "b": {"type": "string", "minLength": 1}, "c": {}},
+				"additionalProperties": {"type": "string"},
+				"required": ["a", "c"]
+			}`,
+			instances: []string{`{"a": 1, "c": 2}`, `{"a": -1, "c": 2}`, `{"a": 1, "b": 2, "c": 2}`, `{"a": 1, "c": 2, "d": 3}`, `{"a": 1}`},
+		},
+		{
+			name:      "patternProperties kept",
+			schemas:   []string{`{"patternProperties": {"^a": {"type": "integer"}}}`, `{"properties": {"ab": {"minimum": 1}}}`},
+			want:      `{"patternProperties": {"^a": {"type": "integer"}}, "allOf": [{"properties": {"ab": {"minimum": 1}}}]}`,
+			instances: []string{`{"ab": 1}`, `{"ab": 0}`, `{"ab": 1.5}`},
+		},
+		{
+			name:      "items",
+			schemas:   []string{`{"items": {"type": "integer"}, "minItems": 1}`, `{"items": {"minimum": 0}, "maxItems": 2, "uniqueItems": true}`},
+			want:      `{"items": {"type": "integer", "minimum": 0}, "minItems": 1, "maxItems": 2, "uniqueItems": true}`,
+			instances: []string{`[1]`, `[]`, `[-1]`, `[1, 1]`, `[1, 2, 3]`},
+		},
+		{
+			name:      "tuple items kept",
+			schemas:   []string{`{"items": [{"type": "string"}], "additionalItems": false}`, `{"items": {"maxLength": 1}}`},
+			want:      `{"items": [{"type": "string"}], "additionalItems": false, "allOf": [{"items": {"maxLength": 1}}]}`,
+			instances: []string{`["a"]`, `["ab"]`, `["a", "b"]`},
+		},
+		{
+			name:      "dependencies",
+			schemas:   []string{`{"dependencies": {"a": ["b"], "c": {"required": ["d"]}}}`, `{"dependencies": {"a": ["e"], "c": ["f"]}}`},
+			want:      `{"dependencies": {"a": ["b", "e"], "c": {"required": ["d"]}}, "allOf": [{"dependencies": {"c": ["f"]}}]}`,
+			instances: []string{`{"a": 1, "b": 2, "e": 3}`, `{"a": 1, "b": 2}`, `{"c": 1, "d": 2, "f": 3}`, `{"c": 1, "d": 2}`},
+		},
+		{
+			name:      "not",
+			schemas:   []string{`{"not": {"type": "string"}}`, `{"not": {"type": "null"}}`},
+			want:      `{"not": {"anyOf": [{"type": "string"}, {"type": "null"}]}}`,
+			instances: []string{`1`, `"a"`, `null`},
+		},
+		{
+			name:      "nested allOf and $id",
+			schemas:   []string{`{"allOf": [{"maximum": 5}, {"$id": "http://example.com/a", "minimum": 1}]}`, `{"minimum": 0}`},
+			want:      `{"minimum": 0, "maximum": 5, "allOf": [{"$id": "http://example.com/a", "minimum": 1}]}`,
+			instances: []string{`3`, `0`, `6`},
+		},
+		{
+			name:      "boolean schemas",
+			schemas:   []string{`true`, `{"type": "string"}`, `true`},
+			want:      `{"type": "string"}`,
+			instances: []string{`"a"`, `1`},
+		},
+		{
+			name:      "false",
+			schemas:   []string{`{"type": "string"}`, `false`},
+			want:      `false`,
+			instances: []string{`"a"`},
+		},
+		{
+			name:      "annotations",
+			schemas:   []string{`{"$id": "http://example.com/a", "title": "a", "examples": [1]}`, `{"title": "b", "description": "b", "examples": [2], "readOnly": true}`},
+			want:      `{"$id": "http://example.com/a", "title": "a", "description": "b", "examples": [1, 2], "readOnly": true}`,
+			instances: []string{`1`},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			schemas := make([]*Schema, len(tt.schemas))
+			for i, s := range tt.schemas {
+				schemas[i] = mustSchema(t, s)
+			}
+			before := mustJSON(t, &Schema{AllOf: schemas})
+
+			got := Merge(schemas...)
+			var want interface{}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if g := mustJSON(t, got); !reflect.DeepEqual(g, want) {
+				b, _ := json.Marshal(g)
+				t.Errorf("Merge() = %s, want %s", b, tt.want)
+			}
+			if after := mustJSON(t, &Schema{AllOf: schemas}); !reflect.DeepEqual(after, before) {
+				t.Errorf("Merge() modified the schemas: %v, want %v", after, before)
+			}
+
+			// the merged schema must accept the same instances as the "allOf" of the schemas.
+			merged := MustCompile(got)
+			allOf := MustCompile(&Schema{AllOf: schemas})
+			for _, inst := range tt.instances {
+				v := mustInstance(t, inst)
+				if g, w := merged.IsValid(v), allOf.IsValid(v); g != w {
+					t.Errorf("Merge().IsValid(%s) = %t, want %t", inst, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestFlattenAllOf(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    string
+		want      string
+		instances []string
+	}{
+		{
+			name:      "nested",
+			schema:    `{"properties": {"a": {"allOf": [{"type": "integer"}, {"allOf": [{"minimum": 1}, {"maximum": 3}]}]}}}`,
+			want:      `{"properties": {"a": {"type": "integer", "minimum": 1, "maximum": 3}}}`,
+			instances: []string{`{"a": 2}`, `{"a": 0}`, `{"a": 2.5}`, `{"a": 4}`},
+		},
+		{
+			name:      "inlined $ref",
+			schema:    `{"definitions": {"pos": {"minimum": 1}}, "allOf": [{"$ref": "#/definitions/pos"}, {"type": "integer"}]}`,
+			want:      `{"definitions": {"pos": {"minimum": 1}}, "minimum": 1, "type": "integer"}`,
+			instances: []string{`1`, `0`, `1.5`},
+		},
+		{
+			name:      "recursive $ref",
+			schema:    `{"definitions": {"node": {"allOf": [{"type": "object"}, {"properties": {"next": {"allOf": [{"$ref": "#/definitions/node"}, {"required": ["v"]}]}}}]}}, "$ref": "#/definitions/node"}`,
+			want:      `{"definitions": {"node": {"type": "object", "properties": {"next": {"type": "object", "properties": {"next": {"required": ["v"], "allOf": [{"$ref": "#/definitions/node"}]}}, "required": ["v"]}}}}, "$ref": "#/definitions/node"}`,
+			instances: []string{`{"next": {"v": 1}}`, `{"next": {}}`, `{"next": {"v": 1, "next": {}}}`, `{"next": {"v": 1, "next": {"v": 2}}}`},
+		},
+		{
+			name:      "target kept",
+			schema:    `{"allOf": [{"minimum": 1}, {"maximum": 5}], "properties": {"a": {"$ref": "#/allOf/1"}}}`,
+			want:      `{"allOf": [true, {"maximum": 5}], "minimum": 1, "properties": {"a": {"$ref": "#/allOf/1"}}}`,
+			instances: []string{`{"a": 5}`, `{"a": 6}`, `0`},
+		},
+		{
+			name:      "$ref across $id",
+			schema:    `{"definitions": {"a": {"$id": "http://example.com/a", "type": "string"}}, "allOf": [{"$ref": "http://example.com/a"}, {"minLength": 1}]}`,
+			want:      `{"definitions": {"a": {"$id": "http://example.com/a", "type": "string"}}, "minLength": 1, "allOf": [{"$ref": "http://example.com/a"}]}`,
+			instances: []string{`"a"`, `""`, `1`},
+		},
+		{
+			name:      "unsatisfiable",
+			schema:    `{"properties": {"a": {"allOf": [{"type": "string"}, {"type": "integer"}]}}}`,
+			want:      `{"properties": {"a": false}}`,
+			instances: []string{`{"a": 1}`, `{}`},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := mustSchema(t, tt.schema)
+			got, err := FlattenAllOf(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want interface{}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if g := mustJSON(t, got); !reflect.DeepEqual(g, want) {
+				b, _ := json.Marshal(g)
+				t.Errorf("FlattenAllOf() = %s, want %s", b, tt.want)
+			}
+
+			flat := MustCompile(got)
+			orig := MustCompile(mustSchema(t, tt.schema))
+			for _, inst := range tt.instances {
+				v := mustInstance(t, inst)
+				if g, w := flat.IsValid(v), orig.IsValid(v); g != w {
+					t.Errorf("FlattenAllOf().IsValid(%s) = %t, want %t", inst, g, w)
+				}
+			}
+		})
+	}
+
+	if _, err := FlattenAllOf(mustSchema(t, `{"allOf": [{"$ref": "#/definitions/missing"}]}`)); err == nil {
+		t.Error("FlattenAllOf() error = nil, want unresolvable $ref")
+	}
+}
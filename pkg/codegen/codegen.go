@@ -0,0 +1,976 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+	"github.com/zchee/go-jsonschema/pkg/jsonpointer"
+)
+
+// DefaultPackageName is the default package name of the generated source.
+const DefaultPackageName = "schema"
+
+// maxRefDepth is the maximum number of the "$ref" hops followed on the type resolution.
+const maxRefDepth = 32
+
+// Option represents an option of the Generate.
+type Option func(*generator)
+
+// WithPackageName sets the package name of the generated source.
+func WithPackageName(name string) Option {
+	return func(g *generator) {
+		g.pkgName = name
+	}
+}
+
+// WithRootName sets the type name of the root schema.
+//
+// The default name is derived from the "title" of the root schema, or "Root".
+func WithRootName(name string) Option {
+	return func(g *generator) {
+		g.rootName = name
+	}
+}
+
+// WithGojay enables the generation of the gojay method set, the Pooler Reset, the sync.Pool and
+// the Stream type of the generated structs, which are the same as the ones of the jsonschema package types.
+//
+// The generated source does not import the jsonschema package, while the Reset method still satisfies jsonschema.Pooler.
+func WithGojay() Option {
+	return func(g *generator) {
+		g.gojay = true
+	}
+}
+
+// WithHeader sets the header comment, such as the license boilerplate, of the generated source.
+func WithHeader(header []byte) Option {
+	return func(g *generator) {
+		g.header = header
+	}
+}
+
+// Boilerplate reads the boilerplate file at path, and replaces its "YEAR" placeholder by year.
+func Boilerplate(path string, year int) ([]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.Replace(b, []byte("YEAR"), []byte(strconv.Itoa(year)), -1), nil
+}
+
+// kind represents the kind of the generated Go type.
+type kind int
+
+// The list of kind.
+const (
+	kindAny kind = iota
+	kindBool
+	kindInt
+	kindFloat
+	kindString
+	kindArray
+	kindMap
+	kindStruct
+	kindEnum
+	kindUnion
+	kindRaw
+)
+
+// nilable reports whether the zero value of the k kind is nil.
+func (k kind) nilable() bool {
+	switch k {
+	case kindAny, kindArray, kindMap, kindUnion, kindRaw:
+		return true
+	default:
+		return false
+	}
+}
+
+// namedType represents a schema which is generated as the named Go type.
+type namedType struct {
+	name   string
+	loc    string
+	schema *jsonschema.Schema
+	typ    *goType
+}
+
+// union represents a "oneOf" whose branches are selected by the discriminator property.
+type union struct {
+	prop     string
+	values   []string
+	branches jsonschema.SchemaList
+}
+
+// generator represents a state of the Go source generation.
+type generator struct {
+	pkgName  string
+	rootName string
+	header   []byte
+	gojay    bool
+
+	root    *jsonschema.Schema
+	names   map[*jsonschema.Schema]*namedType
+	used    nameSet
+	queue   []*namedType
+	kinds   map[*jsonschema.Schema]kind
+	unions  map[*jsonschema.Schema]*union
+	imports map[string]string
+	helpers map[string]bool
+	body    bytes.Buffer
+}
+
+// Generate generates the Go source of the types of the root schema.
+//
+// The root schema is generated as the root type if it has any type keywords, and each
+// "definitions" is generated as the named type.
+func Generate(root *jsonschema.Schema, opts ...Option) ([]byte, error) {
+	if root == nil {
+		return nil, errors.New("codegen: nil schema")
+	}
+
+	g := &generator{
+		pkgName: DefaultPackageName,
+		root:    root,
+		names:   make(map[*jsonschema.Schema]*namedType),
+		used:    make(nameSet),
+		kinds:   make(map[*jsonschema.Schema]kind),
+		unions:  make(map[*jsonschema.Schema]*union),
+		imports: make(map[string]string),
+		helpers: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.rootName == "" {
+		g.rootName = goName(root.Title)
+	}
+	if g.rootName == "" {
+		g.rootName = "Root"
+	}
+
+	// names the root and the definitions first, so that they take the names precedence over the inline types.
+	if hasType(root) {
+		g.name(root, g.rootName, "#")
+	}
+	for _, k := range sortedKeys(root.Definitions) {
+		g.name(root.Definitions[k], goName(k), "#"+appendLocation("", "definitions", k))
+	}
+	for i := 0; i < len(g.queue); i++ {
+		g.decl(g.queue[i])
+	}
+
+	var src bytes.Buffer
+	if len(g.header) > 0 {
+		src.Write(bytes.TrimSpace(g.header))
+		src.WriteString("\n\n")
+	}
+	src.WriteString("// Code generated by jsonschema-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", g.pkgName)
+	if len(g.imports) > 0 {
+		src.WriteString("import (\n")
+		paths := make([]string, 0, len(g.imports))
+		for path := range g.imports {
+			paths = append(paths, path)
+		}
+		// the standard library packages precede the others.
+		sort.Slice(paths, func(i, j int) bool {
+			if si, sj := isStdPackage(paths[i]), isStdPackage(paths[j]); si != sj {
+				return si
+			}
+			return paths[i] < paths[j]
+		})
+		for i, path := range paths {
+			if i > 0 && isStdPackage(paths[i-1]) != isStdPackage(path) {
+				src.WriteString("\n")
+			}
+			fmt.Fprintf(&src, "\t%s %q\n", g.imports[path], path)
+		}
+		src.WriteString(")\n\n")
+	}
+	src.Write(g.body.Bytes())
+	for _, name := range sortedNames(g.helpers) {
+		src.WriteString(helperDecls[name])
+	}
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return src.Bytes(), fmt.Errorf("codegen: format generated source: %v", err)
+	}
+
+	return out, nil
+}
+
+// hasType reports whether s has any keywords which determine the Go type.
+func hasType(s *jsonschema.Schema) bool {
+	return s.Ref != "" || len(s.Type) > 0 || len(s.Properties) > 0 || len(s.AllOf) > 0 ||
+		len(s.OneOf) > 0 || len(s.Enum) > 0 || s.Items != nil || s.AdditionalProperties != nil
+}
+
+// name returns the named type of s, and queues s for the declaration if s is not named yet.
+func (g *generator) name(s *jsonschema.Schema, hint, loc string) *namedType {
+	if nt, ok := g.names[s]; ok {
+		return nt
+	}
+	var suffixes []string
+	if g.gojay && g.kindOf(s) == kindStruct {
+		// reserves the names of the pool and the Stream type.
+		suffixes = []string{"Pool", "Stream"}
+	}
+	nt := &namedType{name: g.used.unique(hint, suffixes...), loc: loc, schema: s}
+	g.names[s] = nt
+	g.queue = append(g.queue, nt)
+
+	return nt
+}
+
+// resolve returns the schema referenced by ref and its location.
+//
+// The references to the outside of the root schema document are not resolved.
+func (g *generator) resolve(ref string) (*jsonschema.Schema, string, bool) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, "", false
+	}
+	if u.Scheme != "" || u.Host != "" || u.Path != "" {
+		id, err := url.Parse(g.root.ID)
+		if err != nil || g.root.ID == "" || id.Scheme != u.Scheme || id.Host != u.Host || id.Path != u.Path {
+			return nil, "", false
+		}
+	}
+
+	switch {
+	case u.Fragment == "":
+		return g.root, "#", true
+	case !strings.HasPrefix(u.Fragment, "/"):
+		// location-independent identifiers are not supported.
+		return nil, "", false
+	}
+	ptr, err := jsonpointer.Parse(u.Fragment)
+	if err != nil {
+		return nil, "", false
+	}
+	target, ok := g.root.Lookup(ptr)
+	if !ok {
+		return nil, "", false
+	}
+
+	return target, "#" + u.Fragment, true
+}
+
+// deref follows the "$ref" of s, and returns the referenced schema.
+func (g *generator) deref(s *jsonschema.Schema) (*jsonschema.Schema, bool) {
+	for i := 0; s != nil && s.Ref != ""; i++ {
+		if i == maxRefDepth {
+			return nil, false
+		}
+		target, _, ok := g.resolve(s.Ref)
+		if !ok {
+			return nil, false
+		}
+		s = target
+	}
+
+	return s, true
+}
+
+// kindOf returns the kind of the Go type of s.
+func (g *generator) kindOf(s *jsonschema.Schema) kind {
+	s, ok := g.deref(s)
+	if !ok {
+		return kindRaw
+	}
+	if k, ok := g.kinds[s]; ok {
+		return k
+	}
+	// guards the recursion through the subschemas which refer to s.
+	g.kinds[s] = kindAny
+	k := g.schemaKind(s)
+	g.kinds[s] = k
+
+	return k
+}
+
+// schemaKind returns the kind of the Go type of the dereferenced s.
+func (g *generator) schemaKind(s *jsonschema.Schema) kind {
+	switch {
+	case s == nil || s.Bool != nil:
+		return kindAny
+	case len(s.OneOf) > 0:
+		if g.unionOf(s) != nil {
+			return kindUnion
+		}
+		return kindAny
+	case len(s.Enum) > 0:
+		if enumKind(s) != kindAny {
+			return kindEnum
+		}
+		return kindAny
+	}
+
+	types := nonNullTypes(s.Type)
+	if props, _ := g.properties(s); len(props) > 0 && (len(types) == 0 || hasObjectType(types)) {
+		return kindStruct
+	}
+	if len(types) == 1 {
+		switch types[0] {
+		case jsonschema.BooleanType:
+			return kindBool
+		case jsonschema.IntegerType:
+			return kindInt
+		case jsonschema.NumberType:
+			return kindFloat
+		case jsonschema.StringType:
+			return kindString
+		case jsonschema.ArrayType:
+			return kindArray
+		case jsonschema.ObjectType:
+			return kindMap
+		}
+	}
+	if len(types) == 0 {
+		switch {
+		case s.Const != nil:
+			return constKind(s.Const.Interface())
+		case s.Items != nil:
+			return kindArray
+		case s.AdditionalProperties != nil:
+			return kindMap
+		case len(s.AllOf) > 0:
+			if sub := g.allOfType(s); sub != nil {
+				return g.kindOf(sub)
+			}
+		}
+	}
+
+	return kindAny
+}
+
+// allOfType returns the first "allOf" subschema of s which determines the Go type, or nil if there is no such subschema.
+//
+// The other subschemas, such as the ones which only have the annotations, do not change the Go type.
+func (g *generator) allOfType(s *jsonschema.Schema) *jsonschema.Schema {
+	for _, sub := range s.AllOf {
+		if g.kindOf(sub) != kindAny {
+			return sub
+		}
+	}
+
+	return nil
+}
+
+// nullable reports whether s allows the null value.
+func (g *generator) nullable(s *jsonschema.Schema) bool {
+	s, ok := g.deref(s)
+	if !ok || s == nil {
+		return false
+	}
+	for _, t := range s.Type {
+		if t == jsonschema.NullType {
+			return true
+		}
+	}
+	for _, e := range s.Enum {
+		if e.Interface() == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// properties returns the properties and the required property names of s, including the ones of its "allOf".
+func (g *generator) properties(s *jsonschema.Schema) (map[string]*jsonschema.Schema, map[string]bool) {
+	props := make(map[string]*jsonschema.Schema)
+	required := make(map[string]bool)
+	seen := make(map[*jsonschema.Schema]bool)
+
+	var collect func(s *jsonschema.Schema)
+	collect = func(s *jsonschema.Schema) {
+		s, ok := g.deref(s)
+		if !ok || s == nil || seen[s] {
+			return
+		}
+		seen[s] = true
+		for k, p := range s.Properties {
+			if _, ok := props[k]; !ok {
+				props[k] = p
+			}
+		}
+		for _, req := range s.Required {
+			required[req.Value] = true
+		}
+		for _, sub := range s.AllOf {
+			collect(sub)
+		}
+	}
+	collect(s)
+
+	return props, required
+}
+
+// goType represents a generated Go type.
+type goType struct {
+	// expr is the Go type expression.
+	expr string
+
+	// kind is the kind of the JSON value, whose kindEnum is the underlying kindString or kindInt.
+	kind kind
+
+	// elem is the element type of the kindArray or kindMap type.
+	elem *goType
+}
+
+// anyType is the goType of the arbitrary JSON value.
+var anyType = &goType{expr: "interface{}", kind: kindAny}
+
+// typeOf returns the Go type of s.
+//
+// The hint and loc are the name and the location of s, which are used if s needs the named type.
+func (g *generator) typeOf(s *jsonschema.Schema, hint, loc string) *goType {
+	if s == nil || s.Bool != nil {
+		return anyType
+	}
+	if nt, ok := g.names[s]; ok {
+		return g.namedTypeOf(nt)
+	}
+	if s.Ref != "" {
+		return g.refType(s)
+	}
+	switch g.kindOf(s) {
+	case kindStruct, kindEnum, kindUnion:
+		return g.namedTypeOf(g.name(s, hint, loc))
+	}
+
+	return g.inline(s, hint, loc)
+}
+
+// namedTypeOf returns the Go type of the named type.
+func (g *generator) namedTypeOf(nt *namedType) *goType {
+	if nt.typ != nil {
+		return nt.typ
+	}
+	// the recursive named type, such as the slice of itself, refers to itself as the arbitrary value.
+	nt.typ = &goType{expr: nt.name, kind: kindAny}
+
+	var t goType
+	switch k := g.kindOf(nt.schema); k {
+	case kindStruct, kindUnion:
+		t = goType{kind: k}
+	case kindEnum:
+		t = goType{kind: enumKind(nt.schema)}
+	default:
+		if nt.schema.Ref != "" {
+			t = *g.refType(nt.schema)
+		} else {
+			t = *g.inline(nt.schema, nt.name, nt.loc)
+		}
+	}
+	t.expr = nt.name
+	*nt.typ = t
+
+	return nt.typ
+}
+
+// refType returns the Go type of the schema referenced by the "$ref" of s.
+func (g *generator) refType(s *jsonschema.Schema) *goType {
+	target, loc, ok := g.resolve(s.Ref)
+	if !ok || target == s {
+		return g.rawType()
+	}
+	if nt, ok := g.names[target]; ok {
+		return g.namedTypeOf(nt)
+	}
+
+	hint := g.rootName
+	if ptr, err := jsonpointer.Parse(strings.TrimPrefix(loc, "#")); err == nil && len(ptr) > 0 {
+		hint = goName(ptr[len(ptr)-1])
+	}
+	switch g.kindOf(target) {
+	case kindStruct, kindEnum, kindUnion:
+		return g.namedTypeOf(g.name(target, hint, loc))
+	}
+	if target.Ref != "" {
+		return g.refType(target)
+	}
+
+	return g.inline(target, hint, loc)
+}
+
+// rawType returns the Go type of the unresolved JSON value.
+func (g *generator) rawType() *goType {
+	g.imports["encoding/json"] = ""
+	return &goType{expr: "json.RawMessage", kind: kindRaw}
+}
+
+// inline returns the Go type of s, which does not need the named type.
+func (g *generator) inline(s *jsonschema.Schema, hint, loc string) *goType {
+	k := g.kindOf(s)
+	if k != kindStruct && len(s.Type) == 0 && len(s.OneOf) == 0 && len(s.Enum) == 0 && s.Const == nil && s.Items == nil && s.AdditionalProperties == nil {
+		// the type is determined by the "allOf".
+		if sub := g.allOfType(s); sub != nil {
+			for i := range s.AllOf {
+				if s.AllOf[i] == sub {
+					return g.typeOf(sub, hint, fmt.Sprintf("%s/allOf/%d", loc, i))
+				}
+			}
+		}
+	}
+
+	switch k {
+	case kindBool:
+		return &goType{expr: "bool", kind: k}
+	case kindInt:
+		return &goType{expr: "int64", kind: k}
+	case kindFloat:
+		return &goType{expr: "float64", kind: k}
+	case kindString:
+		return &goType{expr: "string", kind: k}
+	case kindRaw:
+		return g.rawType()
+
+	case kindArray:
+		elem := anyType
+		if s.Items != nil && !s.Items.HasMultiple && len(s.Items.Schemas) > 0 {
+			elem = g.typeOf(s.Items.Schemas[0], hint+"Item", loc+"/items")
+		}
+		return &goType{expr: "[]" + elem.expr, kind: k, elem: elem}
+
+	case kindMap:
+		elem := anyType
+		if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+			elem = g.typeOf(s.AdditionalProperties.Schema, hint+"Value", loc+"/additionalProperties")
+		}
+		return &goType{expr: "map[string]" + elem.expr, kind: k, elem: elem}
+	}
+
+	return anyType
+}
+
+// decl writes the declaration of the named type.
+func (g *generator) decl(nt *namedType) {
+	s := nt.schema
+	g.writeComment("", fmt.Sprintf("%s represents the %q JSON Schema.", nt.name, nt.loc), s.Description)
+
+	if s.Ref != "" {
+		fmt.Fprintf(&g.body, "type %s = %s\n\n", nt.name, g.refType(s).expr)
+		return
+	}
+
+	switch g.kindOf(s) {
+	case kindStruct:
+		g.structDecl(nt)
+	case kindEnum:
+		g.enumDecl(nt)
+	case kindUnion:
+		g.unionDecl(nt)
+	default:
+		fmt.Fprintf(&g.body, "type %s %s\n\n", nt.name, g.inline(s, nt.name, nt.loc).expr)
+	}
+}
+
+// field represents a generated struct field.
+type field struct {
+	name string
+	prop string
+	typ  *goType
+
+	// ptr reports whether the field is the pointer to typ.
+	ptr bool
+
+	// omit reports whether the field is omitted if empty.
+	omit bool
+}
+
+// fieldType returns the Go type expression of f.
+func (f *field) fieldType() string {
+	if f.ptr {
+		return "*" + f.typ.expr
+	}
+
+	return f.typ.expr
+}
+
+// structDecl writes the struct declaration of the object schema.
+func (g *generator) structDecl(nt *namedType) {
+	props, required := g.properties(nt.schema)
+	names := make(nameSet)
+	var fields []*field
+
+	fmt.Fprintf(&g.body, "type %s struct {\n", nt.name)
+	for _, prop := range sortedKeys(props) {
+		ps := props[prop]
+		if ps.Bool != nil && !*ps.Bool {
+			// the property is never allowed.
+			continue
+		}
+		f := &field{name: names.unique(goName(prop)), prop: prop}
+		f.typ = g.typeOf(ps, nt.name+f.name, nt.loc+appendLocation("", "properties", prop))
+
+		req := required[prop]
+		switch {
+		case req && !g.nullable(ps) && f.typ.expr != nt.name:
+		case f.typ.kind.nilable():
+			f.omit = !req
+		default:
+			f.ptr, f.omit = true, !req
+		}
+		fields = append(fields, f)
+
+		tag := prop
+		if f.omit {
+			tag += ",omitempty"
+		}
+		if desc := description(ps); desc != "" {
+			g.writeComment("\t", desc)
+		}
+		fmt.Fprintf(&g.body, "\t%s %s `json:%s`\n", f.name, f.fieldType(), strconv.Quote(tag))
+	}
+	g.body.WriteString("}\n\n")
+
+	for _, f := range fields {
+		if f.typ.kind == kindUnion || f.typ.kind == kindArray && f.typ.elem.kind == kindUnion {
+			g.unmarshalDecl(nt.name, fields)
+			break
+		}
+	}
+	if g.gojay {
+		g.gojayDecl(nt.name, fields)
+	}
+}
+
+// unmarshalDecl writes the UnmarshalJSON method of the struct which has the union fields.
+//
+// The union fields are decoded by the Unmarshal function of the union, and the other fields
+// are decoded by the encoding/json package.
+func (g *generator) unmarshalDecl(name string, all []*field) {
+	g.imports["encoding/json"] = ""
+
+	var fields []*field
+	for _, f := range all {
+		if f.typ.kind == kindUnion || f.typ.kind == kindArray && f.typ.elem.kind == kindUnion {
+			fields = append(fields, f)
+		}
+	}
+
+	fmt.Fprintf(&g.body, "// UnmarshalJSON implements json.Unmarshaler.\n")
+	fmt.Fprintf(&g.body, "func (x *%s) UnmarshalJSON(data []byte) error {\n", name)
+	fmt.Fprintf(&g.body, "type alias %s\n", name)
+	g.body.WriteString("var raw struct {\n*alias\n")
+	for _, f := range fields {
+		typ := "json.RawMessage"
+		if f.typ.kind == kindArray {
+			typ = "[]json.RawMessage"
+		}
+		fmt.Fprintf(&g.body, "%s %s `json:%s`\n", f.name, typ, strconv.Quote(f.prop))
+	}
+	g.body.WriteString("}\n")
+	g.body.WriteString("raw.alias = (*alias)(x)\n")
+	g.body.WriteString("if err := json.Unmarshal(data, &raw); err != nil {\nreturn err\n}\n")
+	for _, f := range fields {
+		if f.typ.kind == kindArray {
+			fmt.Fprintf(&g.body, "if raw.%s != nil {\n", f.name)
+			fmt.Fprintf(&g.body, "x.%s = make([]%s, len(raw.%s))\n", f.name, f.typ.elem.expr, f.name)
+			fmt.Fprintf(&g.body, "for i, b := range raw.%s {\n", f.name)
+			g.body.WriteString("if string(b) == \"null\" {\ncontinue\n}\n")
+			fmt.Fprintf(&g.body, "v, err := Unmarshal%s(b)\nif err != nil {\nreturn err\n}\n", f.typ.elem.expr)
+			fmt.Fprintf(&g.body, "x.%s[i] = v\n}\n}\n", f.name)
+			continue
+		}
+		fmt.Fprintf(&g.body, "if len(raw.%s) > 0 && string(raw.%s) != \"null\" {\n", f.name, f.name)
+		fmt.Fprintf(&g.body, "v, err := Unmarshal%s(raw.%s)\nif err != nil {\nreturn err\n}\n", f.typ.expr, f.name)
+		fmt.Fprintf(&g.body, "x.%s = v\n}\n", f.name)
+	}
+	g.body.WriteString("\nreturn nil\n}\n\n")
+}
+
+// enumDecl writes the defined type and its constants of the enum schema.
+func (g *generator) enumDecl(nt *namedType) {
+	s := nt.schema
+	underlying := "string"
+	if enumKind(s) == kindInt {
+		underlying = "int64"
+	}
+	fmt.Fprintf(&g.body, "type %s %s\n\n", nt.name, underlying)
+
+	fmt.Fprintf(&g.body, "// The list of %s.\nconst (\n", nt.name)
+	seen := make(map[string]bool)
+	for _, e := range s.Enum {
+		var lit, value string
+		switch v := e.Interface().(type) {
+		case nil:
+			continue
+		case string:
+			lit, value = strconv.Quote(v), v
+		case json.Number:
+			lit, value = v.String(), v.String()
+		}
+		if seen[lit] {
+			continue
+		}
+		seen[lit] = true
+		fmt.Fprintf(&g.body, "%s %s = %s\n", g.used.unique(nt.name+valueName(value)), nt.name, lit)
+	}
+	g.body.WriteString(")\n\n")
+}
+
+// unionDecl writes the interface type, the branch methods and the Unmarshal function of the union schema.
+func (g *generator) unionDecl(nt *namedType) {
+	g.imports["fmt"] = ""
+	if g.gojay {
+		g.imports["github.com/francoispqt/gojay"] = ""
+	} else {
+		g.imports["encoding/json"] = ""
+	}
+
+	u := g.unionOf(nt.schema)
+	branches := make([]string, len(u.branches))
+	for i, b := range u.branches {
+		branches[i] = g.typeOf(b, nt.name+valueName(u.values[i]), fmt.Sprintf("%s/oneOf/%d", nt.loc, i)).expr
+	}
+
+	method := "is" + nt.name
+	fmt.Fprintf(&g.body, "//\n// %s is one of the %s, which is selected by the %q property.\n", nt.name, joinTypes(branches), u.prop)
+	fmt.Fprintf(&g.body, "type %s interface {\n%s()\n}\n\n", nt.name, method)
+	seen := make(map[string]bool)
+	for _, b := range branches {
+		if seen[b] {
+			continue
+		}
+		seen[b] = true
+		fmt.Fprintf(&g.body, "func (*%s) %s() {}\n", b, method)
+	}
+	g.body.WriteString("\n")
+
+	fmt.Fprintf(&g.body, "// Unmarshal%s unmarshals data into the %s selected by its %q property.\n", nt.name, nt.name, u.prop)
+	fmt.Fprintf(&g.body, "func Unmarshal%s(data []byte) (%s, error) {\n", nt.name, nt.name)
+	unmarshal := "json.Unmarshal"
+	if g.gojay {
+		// the branches implement gojay.UnmarshalerJSONObject.
+		unmarshal = "gojay.UnmarshalJSONObject"
+		fmt.Fprintf(&g.body, "var value string\nif err := gojay.UnmarshalJSONObject(data, gojay.DecodeObjectFunc(func(dec *gojay.Decoder, k string) error {\n"+
+			"if k == %s {\nreturn dec.String(&value)\n}\nreturn nil\n})); err != nil {\nreturn nil, err\n}\n\n", strconv.Quote(u.prop))
+		fmt.Fprintf(&g.body, "var v interface {\n%s\ngojay.UnmarshalerJSONObject\n}\n", nt.name)
+	} else {
+		fmt.Fprintf(&g.body, "var d struct {\nValue string `json:%s`\n}\n", strconv.Quote(u.prop))
+		g.body.WriteString("if err := json.Unmarshal(data, &d); err != nil {\nreturn nil, err\n}\nvalue := d.Value\n\n")
+		fmt.Fprintf(&g.body, "var v %s\n", nt.name)
+	}
+	g.body.WriteString("switch value {\n")
+	for i, b := range branches {
+		fmt.Fprintf(&g.body, "case %s:\nv = &%s{}\n", strconv.Quote(u.values[i]), b)
+	}
+	fmt.Fprintf(&g.body, "default:\nreturn nil, fmt.Errorf(\"unknown %s %s %%q\", value)\n}\n", nt.name, u.prop)
+	fmt.Fprintf(&g.body, "if err := %s(data, v); err != nil {\nreturn nil, err\n}\n\nreturn v, nil\n}\n\n", unmarshal)
+}
+
+// unionOf returns the union of the "oneOf" of s, or nil if its branches have no discriminator.
+//
+// The discriminator is the first property, in the sorted order, which every branch object
+// declares with the distinct "const" string value.
+func (g *generator) unionOf(s *jsonschema.Schema) *union {
+	if u, ok := g.unions[s]; ok {
+		return u
+	}
+	// guards the recursion through the branches.
+	g.unions[s] = nil
+
+	branchProps := make([]map[string]*jsonschema.Schema, len(s.OneOf))
+	for i, b := range s.OneOf {
+		if g.kindOf(b) != kindStruct {
+			return nil
+		}
+		branchProps[i], _ = g.properties(b)
+	}
+
+next:
+	for _, prop := range sortedKeys(branchProps[0]) {
+		values := make([]string, len(s.OneOf))
+		seen := make(map[string]bool)
+		for i, props := range branchProps {
+			v, ok := g.constString(props[prop])
+			if !ok || seen[v] {
+				continue next
+			}
+			seen[v] = true
+			values[i] = v
+		}
+		u := &union{prop: prop, values: values, branches: s.OneOf}
+		g.unions[s] = u
+		return u
+	}
+
+	return nil
+}
+
+// constString returns the single string value of s, which is the "const" or the one element "enum".
+func (g *generator) constString(s *jsonschema.Schema) (string, bool) {
+	s, ok := g.deref(s)
+	if !ok || s == nil {
+		return "", false
+	}
+
+	var v interface{}
+	switch {
+	case s.Const != nil:
+		v = s.Const.Interface()
+	case len(s.Enum) == 1:
+		v = s.Enum[0].Interface()
+	}
+	str, ok := v.(string)
+
+	return str, ok
+}
+
+// writeComment writes the paragraphs as the comment lines with the indent.
+func (g *generator) writeComment(indent string, paragraphs ...string) {
+	first := true
+	for _, p := range paragraphs {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !first {
+			fmt.Fprintf(&g.body, "%s//\n", indent)
+		}
+		first = false
+		for _, line := range strings.Split(p, "\n") {
+			fmt.Fprintf(&g.body, "%s// %s\n", indent, strings.TrimRightFunc(line, func(r rune) bool { return r == ' ' || r == '\t' || r == '\r' }))
+		}
+	}
+}
+
+// description returns the description, or the title, of s.
+func description(s *jsonschema.Schema) string {
+	if s.Description != "" {
+		return s.Description
+	}
+
+	return s.Title
+}
+
+// enumKind returns the kind of the enum values of s, which is kindString, kindInt or kindAny for the mixed values.
+//
+// The null value is allowed with the other values, and is represented by the nil pointer.
+func enumKind(s *jsonschema.Schema) kind {
+	k := kindAny
+	for _, e := range s.Enum {
+		v := e.Interface()
+		if v == nil {
+			continue
+		}
+		ek := constKind(v)
+		if ek != kindString && ek != kindInt || k != kindAny && k != ek {
+			return kindAny
+		}
+		k = ek
+	}
+
+	return k
+}
+
+// constKind returns the kind of the JSON value v.
+func constKind(v interface{}) kind {
+	switch v := v.(type) {
+	case string:
+		return kindString
+	case bool:
+		return kindBool
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return kindInt
+		}
+		return kindFloat
+	case []interface{}:
+		return kindArray
+	case map[string]interface{}:
+		return kindMap
+	default:
+		return kindAny
+	}
+}
+
+// nonNullTypes returns the types except the null type.
+func nonNullTypes(types jsonschema.Types) []jsonschema.Type {
+	var ts []jsonschema.Type
+	for _, t := range types {
+		if t != jsonschema.NullType {
+			ts = append(ts, t)
+		}
+	}
+
+	return ts
+}
+
+// hasObjectType reports whether the types contain the object type.
+func hasObjectType(types []jsonschema.Type) bool {
+	for _, t := range types {
+		if t == jsonschema.ObjectType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// joinTypes returns the English list of the pointer types of the names.
+func joinTypes(names []string) string {
+	ptrs := make([]string, len(names))
+	for i, n := range names {
+		ptrs[i] = "*" + n
+	}
+	if len(ptrs) == 1 {
+		return ptrs[0]
+	}
+
+	return strings.Join(ptrs[:len(ptrs)-1], ", ") + " or " + ptrs[len(ptrs)-1]
+}
+
+// appendLocation appends the escaped tokens to the loc JSON Pointer.
+func appendLocation(loc string, tokens ...string) string {
+	for _, tok := range tokens {
+		loc += "/" + jsonpointer.Escape(tok)
+	}
+
+	return loc
+}
+
+// isStdPackage reports whether the import path is the standard library package.
+func isStdPackage(path string) bool {
+	return !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+}
+
+// sortedNames returns the sorted keys of m.
+func sortedNames(m map[string]bool) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// sortedKeys returns the sorted keys of m.
+func sortedKeys(m map[string]*jsonschema.Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
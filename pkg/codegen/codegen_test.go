@@ -0,0 +1,195 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codegen
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+)
+
+// typeCheck parses and type checks the generated source src.
+func typeCheck(tb testing.TB, src []byte) {
+	tb.Helper()
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "generated.go", src, parser.ParseComments)
+	if err != nil {
+		tb.Fatalf("parse generated source: %v\n%s", err, src)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check(f.Name.Name, fset, []*ast.File{f}, nil); err != nil {
+		tb.Fatalf("type check generated source: %v\n%s", err, src)
+	}
+}
+
+func mustSchema(tb testing.TB, data string) *jsonschema.Schema {
+	tb.Helper()
+
+	var s jsonschema.Schema
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		tb.Fatalf("unmarshal schema %s: %v", data, err)
+	}
+
+	return &s
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		opts   []Option
+		want   []string
+	}{
+		{
+			name:   "struct",
+			schema: `{"title": "user", "type": "object", "properties": {"id": {"type": "string", "description": "the identifier"}, "age": {"type": "integer"}, "tags": {"type": "array", "items": {"type": "string"}}}, "required": ["id"]}`,
+			want: []string{
+				"package schema\n",
+				"// User represents the \"#\" JSON Schema.\ntype User struct {",
+				"\tAge *int64 `json:\"age,omitempty\"`",
+				"\t// the identifier\n\tID   string   `json:\"id\"`",
+				"\tTags []string `json:\"tags,omitempty\"`",
+			},
+		},
+		{
+			name:   "nullable required",
+			schema: `{"type": "object", "properties": {"owner": {"type": ["string", "null"]}}, "required": ["owner"]}`,
+			want:   []string{"\tOwner *string `json:\"owner\"`"},
+		},
+		{
+			name:   "false property",
+			schema: `{"type": "object", "properties": {"a": {"type": "string"}, "b": false}}`,
+			want:   []string{"\tA *string `json:\"a,omitempty\"`\n}"},
+		},
+		{
+			name:   "map",
+			schema: `{"type": "object", "additionalProperties": {"type": "number"}}`,
+			want:   []string{"type Root map[string]float64"},
+		},
+		{
+			name:   "enum",
+			schema: `{"definitions": {"kind": {"enum": ["cat", "dog-like", null]}, "level": {"enum": [1, -1]}}}`,
+			want: []string{
+				"type Kind string",
+				"KindCat     Kind = \"cat\"",
+				"KindDogLike Kind = \"dog-like\"",
+				"type Level int64",
+				"Level1      Level = 1",
+				"LevelMinus1 Level = -1",
+			},
+		},
+		{
+			name:   "ref",
+			schema: `{"definitions": {"name": {"type": "string"}, "alias": {"$ref": "#/definitions/name"}, "remote": {"$ref": "http://example.com/schema.json"}}}`,
+			want: []string{
+				"type Name string",
+				"type Alias = Name",
+				"type Remote = json.RawMessage",
+			},
+		},
+		{
+			name:   "recursive",
+			schema: `{"title": "node", "type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#"}}, "parent": {"$ref": "#"}}}`,
+			want: []string{
+				"\tChildren []Node `json:\"children,omitempty\"`",
+				"\tParent   *Node  `json:\"parent,omitempty\"`",
+			},
+		},
+		{
+			name:   "allOf",
+			schema: `{"definitions": {"base": {"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}, "derived": {"allOf": [{"$ref": "#/definitions/base"}, {"properties": {"name": {"type": "string"}}}]}}}`,
+			want: []string{
+				"type Derived struct {\n\tID   int64   `json:\"id\"`\n\tName *string `json:\"name,omitempty\"`\n}",
+			},
+		},
+		{
+			name: "union",
+			schema: `{"type": "object", "properties": {"pet": {"oneOf": [{"$ref": "#/definitions/cat"}, {"$ref": "#/definitions/dog"}]}},
+				"definitions": {
+					"cat": {"type": "object", "properties": {"kind": {"const": "cat"}}, "required": ["kind"]},
+					"dog": {"type": "object", "properties": {"kind": {"const": "dog"}}, "required": ["kind"]}
+				}}`,
+			want: []string{
+				"// RootPet is one of the *Cat or *Dog, which is selected by the \"kind\" property.\ntype RootPet interface {",
+				"func (*Cat) isRootPet() {}",
+				"func UnmarshalRootPet(data []byte) (RootPet, error) {",
+				"func (x *Root) UnmarshalJSON(data []byte) error {",
+			},
+		},
+		{
+			name:   "options",
+			schema: `{"title": "ignored", "type": "string"}`,
+			opts:   []Option{WithPackageName("models"), WithRootName("Name"), WithHeader([]byte("// header\n"))},
+			want: []string{
+				"// header\n\n// Code generated by jsonschema-gen. DO NOT EDIT.\n\npackage models\n",
+				"type Name string",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := Generate(mustSchema(t, tt.schema), tt.opts...)
+			if err != nil {
+				t.Fatalf("Generate() error = %v\n%s", err, src)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(src), want) {
+					t.Errorf("Generate() does not contain %q\n%s", want, src)
+				}
+			}
+			typeCheck(t, src)
+		})
+	}
+}
+
+func TestGenerateNoDiscriminator(t *testing.T) {
+	src, err := Generate(mustSchema(t, `{"oneOf": [
+		{"type": "object", "properties": {"kind": {"const": "a"}}},
+		{"type": "object", "properties": {"kind": {"const": "a"}}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "type Root interface{}") {
+		t.Errorf("the oneOf without the distinct discriminator is not the arbitrary value\n%s", src)
+	}
+}
+
+func TestGenerateNil(t *testing.T) {
+	if _, err := Generate(nil); err == nil {
+		t.Error("Generate(nil) = nil error")
+	}
+}
+
+func TestBoilerplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "boilerplate.go.txt")
+	if err := ioutil.WriteFile(path, []byte("// Copyright YEAR The Authors.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Boilerplate(path, 2019)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "// Copyright 2019 The Authors.\n"; string(got) != want {
+		t.Errorf("Boilerplate() = %q, want %q", got, want)
+	}
+	if _, err := Boilerplate(filepath.Join(t.TempDir(), "missing"), 2019); err == nil {
+		t.Error("Boilerplate() of the missing file = nil error")
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package codegen an implementation of the Go type generator from JSON Schema.
+//
+// The generated types follow the JSON Schema keywords:
+//
+// The "properties" become the struct fields with the json tags. The "required" properties are
+// the non-pointer fields, and the others are the pointer fields with the omitempty option.
+//
+// The string or integer "enum" becomes the defined type and its typed constants.
+//
+// The "oneOf" of the objects which have the common discriminator property, whose "const" values
+// differ between the branches, becomes the interface type implemented by the branch types,
+// with the Unmarshal function which selects the branch by the discriminator.
+//
+// The "definitions" become the named types, and the "$ref" to them are resolved to the shared types.
+package codegen
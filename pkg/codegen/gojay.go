@@ -0,0 +1,402 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// gojayDecl writes the gojay method set, the Pooler Reset, the pool and the Stream type of the struct,
+// which are the same as the ones of the jsonschema package types.
+func (g *generator) gojayDecl(name string, fields []*field) {
+	g.imports["github.com/francoispqt/gojay"] = ""
+	g.imports["sync"] = ""
+
+	w := &g.body
+	pool := name + "Pool"
+
+	fmt.Fprintf(w, "// %s is the pool of %s.\n", pool, name)
+	fmt.Fprintf(w, "var %s = &sync.Pool{\nNew: func() interface{} {\nreturn &%s{}\n},\n}\n\n", pool, name)
+
+	w.WriteString("var (\n")
+	fmt.Fprintf(w, "// compile time check whether the %s implements gojay.MarshalerJSONObject interface.\n", name)
+	fmt.Fprintf(w, "_ gojay.MarshalerJSONObject = &%s{}\n", name)
+	fmt.Fprintf(w, "// compile time check whether the %s implements gojay.UnmarshalerJSONObject interface.\n", name)
+	fmt.Fprintf(w, "_ gojay.UnmarshalerJSONObject = &%s{}\n", name)
+	w.WriteString(")\n\n")
+
+	w.WriteString("// MarshalJSONObject implements gojay.MarshalerJSONObject.\n")
+	fmt.Fprintf(w, "func (x *%s) MarshalJSONObject(enc *gojay.Encoder) {\n", name)
+	for _, f := range fields {
+		w.WriteString(g.encodeField(f))
+	}
+	w.WriteString("}\n\n")
+
+	w.WriteString("// IsNil implements gojay.MarshalerJSONObject.\n//\n// IsNil checks if instance is nil.\n")
+	fmt.Fprintf(w, "func (x *%s) IsNil() bool {\nreturn x == nil\n}\n\n", name)
+
+	w.WriteString("// UnmarshalJSONObject implements gojay.UnmarshalerJSONObject.\n")
+	if len(fields) == 0 {
+		fmt.Fprintf(w, "func (*%s) UnmarshalJSONObject(*gojay.Decoder, string) error {\nreturn nil\n}\n\n", name)
+	} else {
+		fmt.Fprintf(w, "func (x *%s) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {\nswitch k {\n", name)
+		for i, f := range fields {
+			if i > 0 {
+				w.WriteString("\n")
+			}
+			fmt.Fprintf(w, "case %s:\n%s", strconv.Quote(f.prop), g.decodeField(f))
+		}
+		w.WriteString("}\n\nreturn nil\n}\n\n")
+	}
+
+	w.WriteString("// NKeys implements gojay.UnmarshalerJSONObject.\n//\n// NKeys returns the number of keys to unmarshal.\n")
+	fmt.Fprintf(w, "func (*%s) NKeys() int { return %d }\n\n", name, len(fields))
+
+	w.WriteString("// Reset resets the fields, and puts x back to the pool.\n")
+	fmt.Fprintf(w, "func (x *%s) Reset() {\n", name)
+	for _, f := range fields {
+		fmt.Fprintf(w, "x.%s = %s\n", f.name, zeroValue(f))
+	}
+	fmt.Fprintf(w, "%s.Put(x)\n}\n\n", pool)
+
+	stream := name + "Stream"
+	fmt.Fprintf(w, "// %s represents a stream encoding and decoding to %s.\n", stream, name)
+	fmt.Fprintf(w, "type %s chan *%s\n\n", stream, name)
+	w.WriteString("var (\n")
+	fmt.Fprintf(w, "// compile time check whether the %s implements gojay.MarshalerStream interface.\n", stream)
+	fmt.Fprintf(w, "_ gojay.MarshalerStream = (*%s)(nil)\n", stream)
+	fmt.Fprintf(w, "// compile time check whether the %s implements gojay.UnmarshalerStream interface.\n", stream)
+	fmt.Fprintf(w, "_ gojay.UnmarshalerStream = (*%s)(nil)\n", stream)
+	w.WriteString(")\n\n")
+
+	w.WriteString("// MarshalStream implements gojay.MarshalerStream.\n")
+	fmt.Fprintf(w, "func (s %s) MarshalStream(enc *gojay.StreamEncoder) {\n", stream)
+	w.WriteString("select {\ncase o := <-s:\nenc.Object(o)\n\ncase <-enc.Done():\nreturn\n}\n}\n\n")
+
+	w.WriteString("// UnmarshalStream implements gojay.UnmarshalerStream.\n")
+	fmt.Fprintf(w, "func (s %s) UnmarshalStream(dec *gojay.StreamDecoder) error {\n", stream)
+	fmt.Fprintf(w, "o := %s.Get().(*%s)\n", pool, name)
+	w.WriteString("if err := dec.Object(o); err != nil {\nreturn err\n}\ns <- o\n\nreturn nil\n}\n\n")
+}
+
+// zeroValue returns the zero value expression of the field.
+func zeroValue(f *field) string {
+	if f.ptr || f.typ.kind.nilable() {
+		return "nil"
+	}
+	switch f.typ.kind {
+	case kindBool:
+		return "false"
+	case kindInt, kindFloat:
+		return "0"
+	case kindString:
+		return `""`
+	default:
+		return f.typ.expr + "{}"
+	}
+}
+
+// encodeField returns the statements which encode the field of the receiver x.
+func (g *generator) encodeField(f *field) string {
+	expr := "x." + f.name
+	key := strconv.Quote(f.prop)
+
+	if f.ptr && f.typ.kind == kindStruct {
+		if f.omit {
+			return fmt.Sprintf("enc.ObjectKeyOmitEmpty(%s, %s)\n", key, expr)
+		}
+		return fmt.Sprintf("enc.ObjectKeyNullEmpty(%s, %s)\n", key, expr)
+	}
+
+	var cond string
+	switch {
+	case f.ptr:
+		cond = expr + " != nil"
+		expr = "*" + expr
+	case f.typ.kind == kindRaw:
+		// the empty json.RawMessage is not the valid JSON.
+		cond = "len(" + expr + ") > 0"
+	case f.omit && (f.typ.kind == kindArray || f.typ.kind == kindMap):
+		cond = "len(" + expr + ") > 0"
+	case f.omit && f.typ.kind == kindAny, f.typ.kind == kindUnion:
+		cond = expr + " != nil"
+	}
+
+	stmt := g.encode(f.typ, key, expr, true, 0)
+	switch {
+	case cond == "":
+		return stmt
+	case f.omit:
+		return fmt.Sprintf("if %s {\n%s}\n", cond, stmt)
+	default:
+		return fmt.Sprintf("if %s {\n%s} else {\nenc.NullKey(%s)\n}\n", cond, stmt, key)
+	}
+}
+
+// encode returns the statements which encode the value expr of the type t.
+//
+// The key is the quoted object key, or the empty for the array element. The addressable reports
+// whether the address of expr can be taken, and the depth is the nesting depth of the local variables.
+func (g *generator) encode(t *goType, key, expr string, addressable bool, depth int) string {
+	call := func(method string) string {
+		if key == "" {
+			return "enc." + method + "("
+		}
+		return "enc." + method + "Key(" + key + ", "
+	}
+	v := func(name string) string { return name + strconv.Itoa(depth) }
+
+	switch t.kind {
+	case kindBool:
+		return call("Bool") + convert(expr, "bool", t.expr) + ")\n"
+	case kindInt:
+		return call("Int64") + convert(expr, "int64", t.expr) + ")\n"
+	case kindFloat:
+		return call("Float64") + convert(expr, "float64", t.expr) + ")\n"
+	case kindString:
+		return call("String") + convert(expr, "string", t.expr) + ")\n"
+
+	case kindStruct:
+		if addressable {
+			return call("Object") + "&" + expr + ")\n"
+		}
+		return fmt.Sprintf("%s := %s\n%s&%s)\n", v("o"), expr, call("Object"), v("o"))
+
+	case kindUnion:
+		stmt := fmt.Sprintf("if %s, ok := %s.(gojay.MarshalerJSONObject); ok {\n%s%s)\n}", v("m"), expr, call("Object"), v("m"))
+		if key == "" {
+			return stmt + " else {\nenc.Null()\n}\n"
+		}
+		return stmt + "\n"
+
+	case kindArray:
+		return fmt.Sprintf("%sgojay.EncodeArrayFunc(func(enc *gojay.Encoder) {\nfor %s := range %s {\n%s}\n}))\n",
+			call("Array"), v("i"), expr, g.encode(t.elem, "", expr+"["+v("i")+"]", true, depth+1))
+
+	case kindMap:
+		g.imports["sort"] = ""
+		keys, k := v("keys"), v("k")
+		return fmt.Sprintf("%sgojay.EncodeObjectFunc(func(enc *gojay.Encoder) {\n"+
+			"%s := make([]string, 0, len(%s))\nfor %s := range %s {\n%s = append(%s, %s)\n}\nsort.Strings(%s)\n"+
+			"for _, %s := range %s {\n%s}\n}))\n",
+			call("Object"), keys, expr, k, expr, keys, keys, k, keys, k, keys, g.encode(t.elem, k, expr+"["+k+"]", false, depth+1))
+
+	case kindRaw:
+		if addressable {
+			return call("AddEmbeddedJSON") + "(*gojay.EmbeddedJSON)(&" + expr + "))\n"
+		}
+		return fmt.Sprintf("%s := gojay.EmbeddedJSON(%s)\n%s&%s)\n", v("raw"), expr, call("AddEmbeddedJSON"), v("raw"))
+
+	default:
+		g.imports["encoding/json"] = ""
+		g.helpers["encodeValue"] = true
+		if key == "" {
+			return "encodeValue(enc, " + expr + ")\n"
+		}
+		return "encodeValueKey(enc, " + key + ", " + expr + ")\n"
+	}
+}
+
+// decodeField returns the statements of the case clause which decode the field of the receiver x.
+func (g *generator) decodeField(f *field) string {
+	expr := "x." + f.name
+
+	if f.ptr {
+		switch f.typ.kind {
+		case kindStruct:
+			return fmt.Sprintf("if %s == nil {\n%s = &%s{}\n}\nreturn dec.Object(%s)\n", expr, expr, f.typ.expr, expr)
+		case kindBool, kindInt, kindFloat, kindString:
+			basic, _ := basicType(f.typ.kind)
+			call := g.decodeBasic(f.typ.kind, true)
+			if f.typ.expr == basic {
+				return fmt.Sprintf("return %s&%s)\n", call, expr)
+			}
+			return fmt.Sprintf("var v *%s\nif err := %s&v); err != nil {\nreturn err\n}\nif v != nil {\nc := %s(*v)\n%s = &c\n}\nreturn nil\n",
+				basic, call, f.typ.expr, expr)
+		}
+	}
+
+	if call := g.decodeCall(f.typ, expr, 0); call != "" {
+		return "return " + call + "\n"
+	}
+
+	return g.decode(f.typ, expr, 0) + "return nil\n"
+}
+
+// decode returns the statements which decode the value of the type t into the addressable dst,
+// and return the error if any.
+func (g *generator) decode(t *goType, dst string, depth int) string {
+	if call := g.decodeCall(t, dst, depth); call != "" {
+		return "if err := " + call + "; err != nil {\nreturn err\n}\n"
+	}
+
+	// the union value is decoded by its Unmarshal function.
+	raw, v := "raw"+strconv.Itoa(depth), "v"+strconv.Itoa(depth)
+	return fmt.Sprintf("var %s gojay.EmbeddedJSON\nif err := dec.EmbeddedJSON(&%s); err != nil {\nreturn err\n}\n"+
+		"if len(%s) > 0 && string(%s) != \"null\" {\n%s, err := Unmarshal%s(%s)\nif err != nil {\nreturn err\n}\n%s = %s\n}\n",
+		raw, raw, raw, raw, v, t.expr, raw, dst, v)
+}
+
+// decodeCall returns the call expression which decodes the value of the type t into the addressable dst,
+// or the empty if the value needs the multiple statements.
+func (g *generator) decodeCall(t *goType, dst string, depth int) string {
+	d := strconv.Itoa(depth)
+
+	switch t.kind {
+	case kindBool, kindInt, kindFloat, kindString:
+		basic, _ := basicType(t.kind)
+		return g.decodeBasic(t.kind, false) + convert("&"+dst, "*"+basic, "*"+t.expr) + ")"
+
+	case kindStruct:
+		return "dec.Object(&" + dst + ")"
+
+	case kindArray:
+		e := "e" + d
+		return fmt.Sprintf("dec.Array(gojay.DecodeArrayFunc(func(dec *gojay.Decoder) error {\nvar %s %s\n%s%s = append(%s, %s)\n\nreturn nil\n}))",
+			e, t.elem.expr, g.decode(t.elem, e, depth+1), dst, dst, e)
+
+	case kindMap:
+		k, v := "k"+d, "v"+d
+		return fmt.Sprintf("dec.Object(gojay.DecodeObjectFunc(func(dec *gojay.Decoder, %s string) error {\nvar %s %s\n%s"+
+			"if %s == nil {\n%s = make(%s)\n}\n%s[%s] = %s\n\nreturn nil\n}))",
+			k, v, t.elem.expr, g.decode(t.elem, v, depth+1), dst, dst, t.expr, dst, k, v)
+
+	case kindRaw:
+		return "dec.EmbeddedJSON((*gojay.EmbeddedJSON)(&" + dst + "))"
+
+	case kindUnion:
+		return ""
+
+	default:
+		return "dec.Interface(" + convert("&"+dst, "*interface{}", "*"+t.expr) + ")"
+	}
+}
+
+// decodeBasic returns the beginning of the call expression which decodes the value of the basic kind,
+// followed by the pointer argument and the closing parenthesis. The null reports whether the argument
+// is the pointer to the pointer, which is left nil on the null value.
+//
+// The number is decoded by the decodeFloat64 helper since gojay cannot decode the exponent of more
+// than two digits, such as 1.5e300.
+func (g *generator) decodeBasic(k kind, null bool) string {
+	_, method := basicType(k)
+	if null {
+		method += "Null"
+	}
+	if k != kindFloat {
+		return "dec." + method + "("
+	}
+
+	g.imports["bytes"] = ""
+	g.imports["encoding/json"] = ""
+	g.imports["fmt"] = ""
+	g.imports["strconv"] = ""
+	g.helpers["decodeFloat64"] = true
+
+	return "decode" + method + "(dec, "
+}
+
+// basicType returns the predeclared Go type and the gojay method name of the kind.
+func basicType(k kind) (string, string) {
+	switch k {
+	case kindBool:
+		return "bool", "Bool"
+	case kindInt:
+		return "int64", "Int64"
+	case kindFloat:
+		return "float64", "Float64"
+	default:
+		return "string", "String"
+	}
+}
+
+// convert returns the conversion of expr from the typ type to the basic type, or expr if the types are the same.
+func convert(expr, basic, typ string) string {
+	if basic == typ {
+		return expr
+	}
+	if strings.HasPrefix(basic, "*") {
+		return "(" + basic + ")(" + expr + ")"
+	}
+
+	return basic + "(" + expr + ")"
+}
+
+// helperDecls is the source of the helper functions used by the generated gojay methods.
+var helperDecls = map[string]string{
+	"encodeValue": `// encodeValueKey encodes the key and the arbitrary JSON value v.
+func encodeValueKey(enc *gojay.Encoder, key string, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		// lets gojay report the unsupported type of v.
+		enc.AddInterfaceKey(key, v)
+		return
+	}
+	raw := gojay.EmbeddedJSON(b)
+	enc.AddEmbeddedJSONKey(key, &raw)
+}
+
+// encodeValue encodes the arbitrary JSON value v as the array element.
+func encodeValue(enc *gojay.Encoder, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		// lets gojay report the unsupported type of v.
+		enc.AddInterface(v)
+		return
+	}
+	raw := gojay.EmbeddedJSON(b)
+	enc.AddEmbeddedJSON(&raw)
+}
+
+`,
+	"decodeFloat64": `// decodeFloat64 decodes the JSON number to f.
+//
+// The number is parsed by the strconv since gojay cannot decode the exponent of more than two digits.
+func decodeFloat64(dec *gojay.Decoder, f *float64) error {
+	var raw gojay.EmbeddedJSON
+	if err := dec.EmbeddedJSON(&raw); err != nil {
+		return err
+	}
+
+	return parseFloat64(raw, f)
+}
+
+// decodeFloat64Null decodes the JSON number or null to f, which is left nil on null.
+func decodeFloat64Null(dec *gojay.Decoder, f **float64) error {
+	var raw gojay.EmbeddedJSON
+	if err := dec.EmbeddedJSON(&raw); err != nil {
+		return err
+	}
+	if string(bytes.TrimSpace(raw)) == "null" {
+		return nil
+	}
+	var v float64
+	if err := parseFloat64(raw, &v); err != nil {
+		return err
+	}
+	*f = &v
+
+	return nil
+}
+
+// parseFloat64 parses the raw JSON number to f.
+func parseFloat64(raw []byte, f *float64) error {
+	b := bytes.TrimSpace(raw)
+	if len(b) == 0 || (b[0] != '-' && (b[0] < '0' || b[0] > '9')) || !json.Valid(b) {
+		return fmt.Errorf("expected number, but got %s", b)
+	}
+	v, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return err
+	}
+	*f = v
+
+	return nil
+}
+
+`,
+}
@@ -0,0 +1,189 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codegen
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGojay(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   []string
+	}{
+		{
+			name:   "basic",
+			schema: `{"type": "object", "properties": {"b": {"type": "boolean"}, "i": {"type": "integer"}, "s": {"type": "string"}}, "required": ["s"]}`,
+			want: []string{
+				"var RootPool = &sync.Pool{",
+				"func (x *Root) MarshalJSONObject(enc *gojay.Encoder) {",
+				"\tcase \"b\":\n\t\treturn dec.BoolNull(&x.B)",
+				"\tcase \"i\":\n\t\treturn dec.Int64Null(&x.I)",
+				"\tcase \"s\":\n\t\treturn dec.String(&x.S)",
+				"func (*Root) NKeys() int { return 3 }",
+				"\tRootPool.Put(x)",
+				"type RootStream chan *Root",
+			},
+		},
+		{
+			name:   "number",
+			schema: `{"type": "object", "properties": {"f": {"type": "number"}, "r": {"type": "number"}, "l": {"type": "array", "items": {"type": "number"}}, "s": {"$ref": "#/definitions/score"}}, "required": ["r"], "definitions": {"score": {"type": "number"}}}`,
+			want: []string{
+				"\t\treturn decodeFloat64Null(dec, &x.F)",
+				"\t\treturn decodeFloat64(dec, &x.R)",
+				"\t\t\tif err := decodeFloat64(dec, &e0); err != nil {",
+				"\t\tif err := decodeFloat64Null(dec, &v); err != nil {",
+				"func decodeFloat64(dec *gojay.Decoder, f *float64) error {",
+			},
+		},
+		{
+			name:   "map and raw",
+			schema: `{"type": "object", "properties": {"m": {"additionalProperties": {"type": "integer"}}, "r": {"$ref": "http://example.com/schema.json"}, "a": {}}}`,
+			want: []string{
+				"\"sort\"",
+				"\t\treturn dec.EmbeddedJSON((*gojay.EmbeddedJSON)(&x.R))",
+				"func encodeValueKey(enc *gojay.Encoder, key string, v interface{}) {",
+			},
+		},
+		{
+			name: "union",
+			schema: `{"type": "object", "properties": {"pet": {"oneOf": [{"$ref": "#/definitions/cat"}, {"$ref": "#/definitions/dog"}]}},
+				"definitions": {
+					"cat": {"type": "object", "properties": {"kind": {"const": "cat"}}, "required": ["kind"]},
+					"dog": {"type": "object", "properties": {"kind": {"const": "dog"}}, "required": ["kind"]}
+				}}`,
+			want: []string{
+				"\tvar v interface {\n\t\tRootPet\n\t\tgojay.UnmarshalerJSONObject\n\t}",
+				"\t\tv0, err := UnmarshalRootPet(raw0)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := Generate(mustSchema(t, tt.schema), WithGojay())
+			if err != nil {
+				t.Fatalf("Generate() error = %v\n%s", err, src)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(src), want) {
+					t.Errorf("Generate() does not contain %q\n%s", want, src)
+				}
+			}
+			if strings.Contains(string(src), "github.com/zchee/go-jsonschema") {
+				t.Errorf("the generated source imports the jsonschema package\n%s", src)
+			}
+			typeCheck(t, src)
+		})
+	}
+}
+
+func TestGenerateGojayRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the build of the generated source in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	src, err := Generate(mustSchema(t, `{"type": "object", "properties": {
+		"f": {"type": "number"},
+		"r": {"type": "number"},
+		"l": {"type": "array", "items": {"type": "number"}},
+		"n": {"type": ["number", "null"]}
+	}, "required": ["r"]}`), WithGojay(), WithPackageName("main"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const main = `package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/francoispqt/gojay"
+)
+
+func main() {
+	var x Root
+	if err := gojay.UnmarshalJSONObject([]byte(os.Args[1]), &x); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	b, err := gojay.MarshalJSONObject(&x)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Print(string(b))
+}
+`
+	gosum, err := ioutil.ReadFile(filepath.Join("..", "..", "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"go.mod":       []byte("module example.com/generated\n\ngo 1.18\n\nrequire github.com/francoispqt/gojay v1.2.13\n"),
+		"go.sum":       gosum,
+		"generated.go": src,
+		"main.go":      []byte(main),
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bin := filepath.Join(dir, "generated")
+	build := exec.Command(goBin, "build", "-o", bin, ".")
+	build.Dir = dir
+	build.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("build generated source: %v\n%s", err, out)
+	}
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: `{"f": 1.5e30, "r": -2.5E-300, "l": [1e100, 0.5], "n": null}`, want: `{"f":1.5e+30,"r":-2.5e-300,"l":[1e+100,0.5]}`},
+		{in: `{"r": 12345678901234567890}`, want: `{"r":1.2345678901234567e19}`},
+		{in: `{"r": "1"}`, wantErr: true},
+		{in: `{"r": 1, "f": true}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		out, err := exec.Command(bin, tt.in).CombinedOutput()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("decode %s: error = %v, wantErr %t\n%s", tt.in, err, tt.wantErr, out)
+		}
+		if tt.wantErr {
+			continue
+		}
+		var got, want interface{}
+		if err := json.Unmarshal(out, &got); err != nil {
+			t.Fatalf("decode %s = %s: %v", tt.in, out, err)
+		}
+		if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("decode %s = %s, want %s", tt.in, out, tt.want)
+		}
+	}
+}
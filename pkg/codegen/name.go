@@ -0,0 +1,139 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codegen
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// initialisms is the list of the commonly used initialisms, which are written in the upper case.
+var initialisms = map[string]bool{
+	"API":   true,
+	"ASCII": true,
+	"CPU":   true,
+	"CSS":   true,
+	"DNS":   true,
+	"HTML":  true,
+	"HTTP":  true,
+	"HTTPS": true,
+	"ID":    true,
+	"IP":    true,
+	"JSON":  true,
+	"SQL":   true,
+	"TCP":   true,
+	"TLS":   true,
+	"UDP":   true,
+	"UI":    true,
+	"URI":   true,
+	"URL":   true,
+	"UUID":  true,
+	"XML":   true,
+}
+
+// goName returns the exported Go identifier of s.
+func goName(s string) string {
+	name := camelCase(s)
+	if name != "" && !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+
+	return name
+}
+
+// camelCase returns the upper camel case of s.
+//
+// The words of s are split by the non-alphanumeric runes and the lower to upper case boundaries.
+func camelCase(s string) string {
+	var b strings.Builder
+	for _, word := range splitWords(s) {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(word)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+
+	return b.String()
+}
+
+// splitWords splits s into the words.
+func splitWords(s string) []string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, string(cur))
+			cur = cur[:0]
+		}
+	}
+	for _, r := range s {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && len(cur) > 0 && unicode.IsLower(cur[len(cur)-1]):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+	}
+	flush()
+
+	return words
+}
+
+// valueName returns the Go identifier suffix of the enum value v.
+func valueName(v string) string {
+	if strings.HasPrefix(v, "-") {
+		return "Minus" + valueName(v[1:])
+	}
+	// the leading digits are allowed after the type name prefix.
+	name := camelCase(v)
+	if name == "" {
+		return "Empty"
+	}
+
+	return name
+}
+
+// nameSet represents a set of the used Go identifiers.
+type nameSet map[string]bool
+
+// unique returns the unused identifier based on name, and marks it as used.
+//
+// The identifiers of the returned identifier with the suffixes are also unused, and are marked as used.
+func (ns nameSet) unique(name string, suffixes ...string) string {
+	if name == "" {
+		name = "X"
+	}
+	cand := name
+	for i := 2; ns.used(cand, suffixes); i++ {
+		cand = name + strconv.Itoa(i)
+	}
+	ns[cand] = true
+	for _, suffix := range suffixes {
+		ns[cand+suffix] = true
+	}
+
+	return cand
+}
+
+// used reports whether the name or any of the name with the suffixes is used.
+func (ns nameSet) used(name string, suffixes []string) bool {
+	if ns[name] {
+		return true
+	}
+	for _, suffix := range suffixes {
+		if ns[name+suffix] {
+			return true
+		}
+	}
+
+	return false
+}
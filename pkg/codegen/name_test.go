@@ -0,0 +1,91 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package codegen
+
+import (
+	"testing"
+)
+
+func TestGoName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "foo", want: "Foo"},
+		{in: "fooBar", want: "FooBar"},
+		{in: "foo_bar-baz", want: "FooBarBaz"},
+		{in: "id", want: "ID"},
+		{in: "userId", want: "UserID"},
+		{in: "http url", want: "HTTPURL"},
+		{in: "HTMLParser", want: "HTMLParser"},
+		{in: "2fa", want: "X2fa"},
+		{in: "$schema", want: "Schema"},
+		{in: "élan", want: "Élan"},
+		{in: "--", want: ""},
+	}
+	for _, tt := range tests {
+		if got := goName(tt.in); got != tt.want {
+			t.Errorf("goName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValueName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "cat", want: "Cat"},
+		{in: "dog-like", want: "DogLike"},
+		{in: "-1", want: "Minus1"},
+		{in: "42", want: "42"},
+		{in: "", want: "Empty"},
+		{in: "*", want: "Empty"},
+	}
+	for _, tt := range tests {
+		if got := valueName(tt.in); got != tt.want {
+			t.Errorf("valueName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNameSetUnique(t *testing.T) {
+	tests := []struct {
+		name     string
+		used     []string
+		in       string
+		suffixes []string
+		want     string
+	}{
+		{name: "unused", in: "Foo", want: "Foo"},
+		{name: "used", used: []string{"Foo"}, in: "Foo", want: "Foo2"},
+		{name: "used twice", used: []string{"Foo", "Foo2"}, in: "Foo", want: "Foo3"},
+		{name: "suffix used", used: []string{"FooPool"}, in: "Foo", suffixes: []string{"Pool"}, want: "Foo2"},
+		{name: "empty", in: "", want: "X"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ns := make(nameSet)
+			for _, u := range tt.used {
+				ns[u] = true
+			}
+			got := ns.unique(tt.in, tt.suffixes...)
+			if got != tt.want {
+				t.Errorf("unique(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if !ns[got] {
+				t.Errorf("%q is not marked as used", got)
+			}
+			for _, suffix := range tt.suffixes {
+				if !ns[got+suffix] {
+					t.Errorf("%q is not marked as used", got+suffix)
+				}
+			}
+		})
+	}
+}
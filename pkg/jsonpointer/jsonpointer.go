@@ -0,0 +1,195 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonpointer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pointer represents a parsed JSON Pointer as the list of its reference tokens.
+//
+// RFC 6901:
+//  https://tools.ietf.org/html/rfc6901
+type Pointer []string
+
+// Parse parses s as a JSON Pointer.
+func Parse(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("jsonpointer: %q does not start with '/'", s)
+	}
+
+	tokens := strings.Split(s[1:], "/")
+	for i, tok := range tokens {
+		t, err := unescape(tok)
+		if err != nil {
+			return nil, err
+		}
+		tokens[i] = t
+	}
+
+	return Pointer(tokens), nil
+}
+
+// unescape decodes the "~0" and "~1" escape sequences of the reference token tok.
+func unescape(tok string) (string, error) {
+	if strings.IndexByte(tok, '~') < 0 {
+		return tok, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(tok); i++ {
+		c := tok[i]
+		if c != '~' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+1 >= len(tok) {
+			return "", fmt.Errorf("jsonpointer: incomplete escape sequence in %q", tok)
+		}
+		switch tok[i+1] {
+		case '0':
+			b.WriteByte('~')
+		case '1':
+			b.WriteByte('/')
+		default:
+			return "", fmt.Errorf("jsonpointer: invalid escape sequence %q in %q", tok[i:i+2], tok)
+		}
+		i++
+	}
+
+	return b.String(), nil
+}
+
+// Escape escapes the reference token tok.
+func Escape(tok string) string {
+	if strings.IndexAny(tok, "~/") < 0 {
+		return tok
+	}
+	tok = strings.ReplaceAll(tok, "~", "~0")
+	return strings.ReplaceAll(tok, "/", "~1")
+}
+
+// String implements fmt.Stringer.
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, tok := range p {
+		b.WriteByte('/')
+		b.WriteString(Escape(tok))
+	}
+
+	return b.String()
+}
+
+// Append returns a new Pointer which is p followed by the toks reference tokens.
+func (p Pointer) Append(toks ...string) Pointer {
+	np := make(Pointer, 0, len(p)+len(toks))
+	np = append(np, p...)
+	return append(np, toks...)
+}
+
+// ErrNotFound is returned by Get when the pointer does not resolve to a value.
+var ErrNotFound = errors.New("jsonpointer: value not found")
+
+// Get returns the value referenced by p in doc.
+//
+// doc must be a value decoded by encoding/json into an interface{},
+// that is composed of map[string]interface{}, []interface{} and scalar values.
+func (p Pointer) Get(doc interface{}) (interface{}, error) {
+	cur := doc
+	for _, tok := range p {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			next, ok := v[tok]
+			if !ok {
+				return nil, ErrNotFound
+			}
+			cur = next
+
+		case []interface{}:
+			idx, err := arrayIndex(tok, len(v))
+			if err != nil {
+				return nil, err
+			}
+			cur = v[idx]
+
+		default:
+			return nil, ErrNotFound
+		}
+	}
+
+	return cur, nil
+}
+
+// arrayIndex parses tok as an array index of an array of length n.
+func arrayIndex(tok string, n int) (int, error) {
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') {
+		return 0, fmt.Errorf("jsonpointer: invalid array index %q", tok)
+	}
+	for i := 0; i < len(tok); i++ {
+		if tok[i] < '0' || tok[i] > '9' {
+			return 0, fmt.Errorf("jsonpointer: invalid array index %q", tok)
+		}
+	}
+	idx, err := strconv.Atoi(tok)
+	if err != nil || idx >= n {
+		return 0, ErrNotFound
+	}
+
+	return idx, nil
+}
+
+// Relative represents a parsed Relative JSON Pointer.
+//
+// Relative JSON Pointers:
+//  https://tools.ietf.org/html/draft-handrews-relative-json-pointer-01
+type Relative struct {
+	// Up is the number of levels up from the current location.
+	Up int
+
+	// Key reports whether the pointer ends with '#', which refers to the key or index of the referenced value.
+	Key bool
+
+	// Pointer is the JSON Pointer evaluated after moving Up levels.
+	Pointer Pointer
+}
+
+// ParseRelative parses s as a Relative JSON Pointer.
+func ParseRelative(s string) (*Relative, error) {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("jsonpointer: %q does not start with a non-negative integer", s)
+	}
+	if n > 1 && s[0] == '0' {
+		return nil, fmt.Errorf("jsonpointer: %q has a leading zero", s)
+	}
+	up, err := strconv.Atoi(s[:n])
+	if err != nil {
+		return nil, fmt.Errorf("jsonpointer: %q: %v", s, err)
+	}
+
+	rel := &Relative{Up: up}
+	rest := s[n:]
+	if rest == "#" {
+		rel.Key = true
+		return rel, nil
+	}
+
+	p, err := Parse(rest)
+	if err != nil {
+		return nil, err
+	}
+	rel.Pointer = p
+
+	return rel, nil
+}
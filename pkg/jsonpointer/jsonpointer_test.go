@@ -0,0 +1,169 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonpointer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Pointer
+		wantErr bool
+	}{
+		{in: "", want: Pointer{}},
+		{in: "/", want: Pointer{""}},
+		{in: "/foo/0", want: Pointer{"foo", "0"}},
+		{in: "/a~1b/m~0n", want: Pointer{"a/b", "m~n"}},
+		{in: "/~01", want: Pointer{"~1"}},
+		{in: "foo", wantErr: true},
+		{in: "/foo~", wantErr: true},
+		{in: "/foo~2", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %t", tt.in, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %#v, want %#v", tt.in, got, tt.want)
+			}
+			if s := got.String(); s != tt.in {
+				t.Errorf("Parse(%q).String() = %q", tt.in, s)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "foo", want: "foo"},
+		{in: "a/b", want: "a~1b"},
+		{in: "m~n", want: "m~0n"},
+		{in: "~/", want: "~0~1"},
+	}
+	for _, tt := range tests {
+		if got := Escape(tt.in); got != tt.want {
+			t.Errorf("Escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPointerAppend(t *testing.T) {
+	p := Pointer{"a"}
+	q := p.Append("b", "c/d")
+	if got, want := q.String(), "/a/b/c~1d"; got != want {
+		t.Errorf("Append().String() = %q, want %q", got, want)
+	}
+	if got, want := p.String(), "/a"; got != want {
+		t.Errorf("Append modifies the receiver: %q, want %q", got, want)
+	}
+}
+
+func TestPointerGet(t *testing.T) {
+	// the example document of RFC 6901, section 5.
+	const doc = `{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"g|h": 4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ": 7,
+		"m~n": 8
+	}`
+	var v interface{}
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ptr     string
+		want    interface{}
+		wantErr bool
+	}{
+		{ptr: "", want: v},
+		{ptr: "/foo", want: []interface{}{"bar", "baz"}},
+		{ptr: "/foo/0", want: "bar"},
+		{ptr: "/", want: float64(0)},
+		{ptr: "/a~1b", want: float64(1)},
+		{ptr: "/c%d", want: float64(2)},
+		{ptr: "/e^f", want: float64(3)},
+		{ptr: "/g|h", want: float64(4)},
+		{ptr: "/i\\j", want: float64(5)},
+		{ptr: "/k\"l", want: float64(6)},
+		{ptr: "/ ", want: float64(7)},
+		{ptr: "/m~0n", want: float64(8)},
+		{ptr: "/foo/2", wantErr: true},
+		{ptr: "/foo/01", wantErr: true},
+		{ptr: "/foo/-", wantErr: true},
+		{ptr: "/bar", wantErr: true},
+		{ptr: "/foo/0/x", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.ptr, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := Parse(tt.ptr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Get(v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get(%q) error = %v, wantErr %t", tt.ptr, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get(%q) = %#v, want %#v", tt.ptr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRelative(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    *Relative
+		wantErr bool
+	}{
+		{in: "0", want: &Relative{Pointer: Pointer{}}},
+		{in: "1/0", want: &Relative{Up: 1, Pointer: Pointer{"0"}}},
+		{in: "2/highly/nested/objects", want: &Relative{Up: 2, Pointer: Pointer{"highly", "nested", "objects"}}},
+		{in: "0#", want: &Relative{Key: true}},
+		{in: "/foo", wantErr: true},
+		{in: "01/a", wantErr: true},
+		{in: "1#/a", wantErr: true},
+		{in: "-1/a", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseRelative(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRelative(%q) error = %v, wantErr %t", tt.in, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRelative(%q) = %#v, want %#v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
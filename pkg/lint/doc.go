@@ -0,0 +1,24 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lint an implementation of the JSON Schema linter.
+//
+// The Linter walks the schema and its sub schemas, and checks each of them by the Rules.
+// The violations are reported as the Problems with the JSON Pointer locations in the schema.
+//
+// The DefaultRules find the mistakes which make the schema behave unexpectedly:
+//
+// The "$ref" which cannot be resolved, the "format" which is not registered to the
+// jsonschema.DefaultFormatRegistry, the lower bound which exceeds the upper bound such as the "minLength"
+// greater than the "maxLength", the "definitions" which are not referenced from the root schema,
+// and the unknown keywords, which are checked only by the LintBytes since the Schema does not keep them.
+//
+// The StyleRules enforce the conventions of the schemas:
+//
+// Every property has the "description", every schema has the "type", the "additionalProperties"
+// of the object schema is explicit, and the "pattern" is anchored by "^" and "$".
+//
+// The custom Rule is made by the NewRule, which can use the Context to resolve the "$ref"s
+// and to look into the raw JSON of the schema.
+package lint
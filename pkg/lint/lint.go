@@ -0,0 +1,353 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+	"github.com/zchee/go-jsonschema/pkg/jsonpointer"
+)
+
+// Problem represents a violation of the Rule.
+type Problem struct {
+	// Location is the JSON Pointer of the violating schema or keyword.
+	Location string
+
+	// Rule is the name of the violated Rule.
+	Rule string
+
+	// Message is the human readable description of the violation.
+	Message string
+}
+
+// String implements fmt.Stringer.
+func (p *Problem) String() string {
+	loc := p.Location
+	if loc == "" {
+		loc = "(root)"
+	}
+
+	return fmt.Sprintf("%s: %s (%s)", loc, p.Message, p.Rule)
+}
+
+// Rule represents a lint rule.
+type Rule interface {
+	// Name returns the name of the Rule, such as "unresolvable-ref".
+	Name() string
+
+	// Check checks the schema s at the loc JSON Pointer, and reports the violations by the Context.Report.
+	Check(ctx *Context, loc string, s *jsonschema.Schema)
+}
+
+// rule represents a Rule made by the NewRule.
+type rule struct {
+	name  string
+	check func(ctx *Context, loc string, s *jsonschema.Schema)
+}
+
+// NewRule returns the Rule of the name which checks each schema by the check function.
+func NewRule(name string, check func(ctx *Context, loc string, s *jsonschema.Schema)) Rule {
+	return &rule{name: name, check: check}
+}
+
+// Name implements Rule.
+func (r *rule) Name() string { return r.name }
+
+// Check implements Rule.
+func (r *rule) Check(ctx *Context, loc string, s *jsonschema.Schema) { r.check(ctx, loc, s) }
+
+// Linter represents a schema linter which checks the schemas by the Rules.
+type Linter struct {
+	rules    []Rule
+	registry *jsonschema.Registry
+}
+
+// New returns the Linter of the rules, or the DefaultRules if no rule is given.
+func New(rules ...Rule) *Linter {
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+
+	return &Linter{rules: rules}
+}
+
+// SetRegistry sets the Registry which resolves the "$ref"s to the outside of the schema.
+//
+// The external "$ref"s are not checked if the Linter has no Registry.
+func (l *Linter) SetRegistry(reg *jsonschema.Registry) {
+	l.registry = reg
+}
+
+// Lint checks s and all sub schemas of s, and returns the Problems in the depth-first order of the schemas.
+func (l *Linter) Lint(s *jsonschema.Schema) []*Problem {
+	return l.lint(s, nil)
+}
+
+// LintBytes is like Lint, but decodes the schema from data, whose raw JSON is available to the Rules.
+func (l *Linter) LintBytes(data []byte) ([]*Problem, error) {
+	s := &jsonschema.Schema{}
+	if err := s.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("lint: decode schema: %v", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("lint: decode schema: %v", err)
+	}
+
+	return l.lint(s, raw), nil
+}
+
+// lint checks s whose raw JSON is raw, or nil if unknown.
+func (l *Linter) lint(s *jsonschema.Schema, raw interface{}) []*Problem {
+	ctx := newContext(s, raw, l.registry)
+	s.Walk(func(loc string, sub *jsonschema.Schema) bool {
+		for _, r := range l.rules {
+			ctx.rule = r.Name()
+			r.Check(ctx, loc, sub)
+		}
+		return true
+	})
+
+	return ctx.problems
+}
+
+// Context represents the root schema being linted, which is passed to the Rules.
+type Context struct {
+	root     *jsonschema.Schema
+	raw      interface{}
+	registry *jsonschema.Registry
+
+	// rule is the name of the running Rule.
+	rule     string
+	problems []*Problem
+
+	// locs is the map of the schema to its JSON Pointer location in the root.
+	locs map[*jsonschema.Schema]string
+
+	// bases is the map of the location of the schema to its base URI.
+	bases map[string]*url.URL
+
+	// resources is the map of the absolute URI without the empty fragment to the identified schema.
+	resources map[string]*jsonschema.Schema
+
+	// reachable is the set of the schemas used by the validation of the root, which is computed lazily.
+	reachable map[*jsonschema.Schema]bool
+}
+
+// errExternal is returned by the resolve if the reference is outside of the root and there is no Registry.
+var errExternal = errors.New("external reference")
+
+// newContext returns the Context of the root schema s.
+func newContext(s *jsonschema.Schema, raw interface{}, reg *jsonschema.Registry) *Context {
+	ctx := &Context{
+		root:      s,
+		raw:       raw,
+		registry:  reg,
+		locs:      make(map[*jsonschema.Schema]string),
+		bases:     make(map[string]*url.URL),
+		resources: make(map[string]*jsonschema.Schema),
+	}
+	ctx.resources[""] = s
+	s.Walk(func(loc string, sub *jsonschema.Schema) bool {
+		ctx.locs[sub] = loc
+		base := ctx.baseOf(loc)
+		if sub.ID != "" {
+			if u, err := url.Parse(sub.ID); err == nil {
+				id := base.ResolveReference(u)
+				if id.Fragment == "" {
+					id.RawFragment = ""
+					base = id
+				}
+				ctx.resources[id.String()] = sub
+			}
+		}
+		ctx.bases[loc] = base
+		return true
+	})
+
+	return ctx
+}
+
+// baseOf returns the base URI of the nearest ancestor schema of loc.
+func (c *Context) baseOf(loc string) *url.URL {
+	for loc != "" {
+		loc = loc[:strings.LastIndex(loc, "/")]
+		if base, ok := c.bases[loc]; ok {
+			return base
+		}
+	}
+
+	return &url.URL{}
+}
+
+// Root returns the root schema being linted.
+func (c *Context) Root() *jsonschema.Schema { return c.root }
+
+// Report reports the violation of the running Rule at the loc JSON Pointer.
+func (c *Context) Report(loc, format string, args ...interface{}) {
+	c.problems = append(c.problems, &Problem{Location: loc, Rule: c.rule, Message: fmt.Sprintf(format, args...)})
+}
+
+// Location returns the JSON Pointer location of s in the root schema.
+func (c *Context) Location(s *jsonschema.Schema) (string, bool) {
+	loc, ok := c.locs[s]
+	return loc, ok
+}
+
+// Keywords returns the sorted keywords of the raw JSON of the schema at loc.
+//
+// Keywords returns false if the raw JSON is not available, that is, the schema is not linted by the LintBytes.
+func (c *Context) Keywords(loc string) ([]string, bool) {
+	if c.raw == nil {
+		return nil, false
+	}
+	ptr, err := jsonpointer.Parse(loc)
+	if err != nil {
+		return nil, false
+	}
+	v, err := ptr.Get(c.raw)
+	if err != nil {
+		return nil, false
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys, true
+}
+
+// Resolve returns the schema referenced by the ref of the schema at loc.
+//
+// Resolve returns nil and no error if ref is outside of the root schema document and the Linter has no Registry,
+// since it cannot be checked.
+func (c *Context) Resolve(loc, ref string) (*jsonschema.Schema, error) {
+	s, err := c.resolve(loc, ref)
+	if err == errExternal {
+		return nil, nil
+	}
+
+	return s, err
+}
+
+// resolve returns the schema referenced by the ref of the schema at loc, or errExternal.
+func (c *Context) resolve(loc, ref string) (*jsonschema.Schema, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	base, ok := c.bases[loc]
+	if !ok {
+		base = c.baseOf(loc)
+	}
+	abs := base.ResolveReference(u)
+
+	if abs.Fragment != "" && !strings.HasPrefix(abs.Fragment, "/") {
+		if s, ok := c.resources[abs.String()]; ok {
+			return s, nil
+		}
+	} else {
+		doc := *abs
+		doc.Fragment, doc.RawFragment = "", ""
+		if s, ok := c.resources[doc.String()]; ok {
+			ptr, err := jsonpointer.Parse(abs.Fragment)
+			if err != nil {
+				return nil, err
+			}
+			if target, ok := s.Lookup(ptr); ok {
+				return target, nil
+			}
+			return nil, fmt.Errorf("no schema at %q", ptr)
+		}
+	}
+
+	if c.isLocal(abs) {
+		return nil, fmt.Errorf("unknown schema %q", abs)
+	}
+	if c.registry == nil || !abs.IsAbs() {
+		return nil, errExternal
+	}
+	s, ok := c.registry.Lookup(abs.String())
+	if !ok {
+		return nil, fmt.Errorf("unknown schema %q", abs)
+	}
+
+	return s, nil
+}
+
+// isLocal reports whether the document of abs is the root or its identified sub schema.
+func (c *Context) isLocal(abs *url.URL) bool {
+	doc := *abs
+	doc.Fragment, doc.RawFragment = "", ""
+	_, ok := c.resources[doc.String()]
+
+	return ok
+}
+
+// Reachable reports whether s is used by the validation of the root schema, that is, s is the root,
+// or the sub schema of the reachable schema other than the "definitions", or referenced by the reachable "$ref".
+func (c *Context) Reachable(s *jsonschema.Schema) bool {
+	if c.reachable == nil {
+		c.computeReachable()
+	}
+
+	return c.reachable[s]
+}
+
+// computeReachable computes the reachable schemas from the root.
+func (c *Context) computeReachable() {
+	defs := make(map[*jsonschema.Schema]bool)
+	c.root.Walk(func(_ string, s *jsonschema.Schema) bool {
+		for _, d := range s.Definitions {
+			defs[d] = true
+		}
+		for _, d := range s.Defs {
+			defs[d] = true
+		}
+		return true
+	})
+
+	c.reachable = make(map[*jsonschema.Schema]bool)
+	queue := []*jsonschema.Schema{c.root}
+	for len(queue) > 0 {
+		start := queue[0]
+		queue = queue[1:]
+		if c.reachable[start] {
+			continue
+		}
+		start.Walk(func(_ string, s *jsonschema.Schema) bool {
+			if s != start && defs[s] {
+				return false
+			}
+			c.reachable[s] = true
+			if s.Ref == "" {
+				return true
+			}
+			loc, ok := c.locs[s]
+			if !ok {
+				return true
+			}
+			if t, err := c.resolve(loc, s.Ref); err == nil && !c.reachable[t] {
+				queue = append(queue, t)
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"regexp/syntax"
+	"sort"
+
+	jsonschema "github.com/zchee/go-jsonschema"
+	"github.com/zchee/go-jsonschema/pkg/jsonpointer"
+)
+
+// DefaultRules is the list of the Rules which find the mistakes of the schemas.
+var DefaultRules = []Rule{
+	UnresolvableRef,
+	UnknownFormat,
+	InvalidBounds,
+	UnreachableDefinition,
+	UnknownKeyword,
+}
+
+// StyleRules is the list of the Rules which enforce the conventions of the schemas.
+var StyleRules = []Rule{
+	PropertyDescription,
+	MissingType,
+	ImplicitAdditionalProperties,
+	UnanchoredPattern,
+}
+
+// UnresolvableRef reports the "$ref" which cannot be resolved.
+var UnresolvableRef = NewRule("unresolvable-ref", func(ctx *Context, loc string, s *jsonschema.Schema) {
+	if s.Ref == "" {
+		return
+	}
+	if _, err := ctx.Resolve(loc, s.Ref); err != nil {
+		ctx.Report(appendLocation(loc, "$ref"), "unresolvable $ref %q: %v", s.Ref, err)
+	}
+})
+
+// UnknownFormat reports the "format" which is not registered to the jsonschema.DefaultFormatRegistry.
+var UnknownFormat = NewRule("unknown-format", func(ctx *Context, loc string, s *jsonschema.Schema) {
+	if s.Format == "" {
+		return
+	}
+	if _, ok := jsonschema.DefaultFormatRegistry.Lookup(s.Format); !ok {
+		ctx.Report(appendLocation(loc, "format"), "unknown format %q", s.Format)
+	}
+})
+
+// InvalidBounds reports the lower bound which exceeds the upper bound, such as the "minLength" greater than
+// the "maxLength", which makes the schema reject every instance of the type.
+var InvalidBounds = NewRule("invalid-bounds", func(ctx *Context, loc string, s *jsonschema.Schema) {
+	if s.MaxLength != nil && s.MinLength > *s.MaxLength {
+		ctx.Report(appendLocation(loc, "minLength"), "minLength %d is greater than maxLength %d", s.MinLength, *s.MaxLength)
+	}
+	if s.MaxItems != nil && s.MinItems > *s.MaxItems {
+		ctx.Report(appendLocation(loc, "minItems"), "minItems %d is greater than maxItems %d", s.MinItems, *s.MaxItems)
+	}
+	if s.MaxProperties != nil && s.MinProperties > *s.MaxProperties {
+		ctx.Report(appendLocation(loc, "minProperties"), "minProperties %d is greater than maxProperties %d",
+			s.MinProperties, *s.MaxProperties)
+	}
+	if s.Minimum != nil && s.Maximum != nil && *s.Minimum > *s.Maximum {
+		ctx.Report(appendLocation(loc, "minimum"), "minimum %v is greater than maximum %v", *s.Minimum, *s.Maximum)
+	}
+})
+
+// UnreachableDefinition reports the "definitions" and the "$defs" which are not used by the validation of
+// the root schema. The definitions which have the "$id" are not reported since they may be referenced from
+// the other schemas.
+var UnreachableDefinition = NewRule("unreachable-definition", func(ctx *Context, loc string, s *jsonschema.Schema) {
+	check := func(keyword string, defs jsonschema.Definitions) {
+		for _, k := range sortedKeys(defs) {
+			d := defs[k]
+			if d.ID != "" || hasReachable(ctx, d) {
+				continue
+			}
+			ctx.Report(appendLocation(loc, keyword, k), "definition %q is not referenced", k)
+		}
+	}
+	check("definitions", s.Definitions)
+	check("$defs", s.Defs)
+})
+
+// hasReachable reports whether s or any of its sub schemas is reachable.
+func hasReachable(ctx *Context, s *jsonschema.Schema) bool {
+	found := false
+	s.Walk(func(_ string, sub *jsonschema.Schema) bool {
+		found = found || ctx.Reachable(sub)
+		return !found
+	})
+
+	return found
+}
+
+// knownKeywords is the set of the keywords of the JSON Schema draft-04 to 2019-09.
+var knownKeywords = map[string]bool{
+	"$schema": true, "$id": true, "id": true, "$ref": true, "$comment": true, "$anchor": true,
+	"$recursiveRef": true, "$recursiveAnchor": true, "$vocabulary": true, "definitions": true, "$defs": true,
+	"title": true, "description": true, "default": true, "readOnly": true, "writeOnly": true, "examples": true,
+	"deprecated": true, "multipleOf": true, "maximum": true, "exclusiveMaximum": true, "minimum": true,
+	"exclusiveMinimum": true, "maxLength": true, "minLength": true, "pattern": true, "additionalItems": true,
+	"items": true, "maxItems": true, "minItems": true, "uniqueItems": true, "contains": true, "maxContains": true,
+	"minContains": true, "unevaluatedItems": true, "maxProperties": true, "minProperties": true, "required": true,
+	"additionalProperties": true, "properties": true, "patternProperties": true, "dependencies": true,
+	"dependentRequired": true, "dependentSchemas": true, "propertyNames": true, "unevaluatedProperties": true,
+	"const": true, "enum": true, "type": true, "format": true, "contentMediaType": true, "contentEncoding": true,
+	"contentSchema": true, "if": true, "then": true, "else": true, "allOf": true, "anyOf": true, "oneOf": true,
+	"not": true,
+}
+
+// UnknownKeyword reports the keywords which are not defined by the JSON Schema, which are usually the typos.
+//
+// UnknownKeyword needs the raw JSON of the schema, so it reports nothing if the schema is not linted by the LintBytes.
+var UnknownKeyword = NewRule("unknown-keyword", func(ctx *Context, loc string, s *jsonschema.Schema) {
+	keywords, ok := ctx.Keywords(loc)
+	if !ok {
+		return
+	}
+	for _, k := range keywords {
+		if !knownKeywords[k] {
+			ctx.Report(appendLocation(loc, k), "unknown keyword %q", k)
+		}
+	}
+})
+
+// PropertyDescription reports the property which does not have the "description", which may be given by
+// the referenced schema.
+var PropertyDescription = NewRule("property-description", func(ctx *Context, loc string, s *jsonschema.Schema) {
+	for _, k := range sortedKeys(s.Properties) {
+		p := s.Properties[k]
+		ploc := appendLocation(loc, "properties", k)
+		for i := 0; p != nil && p.Description == "" && p.Ref != "" && i < maxRefDepth; i++ {
+			p, _ = ctx.Resolve(ploc, p.Ref)
+			if p != nil {
+				ploc, _ = ctx.Location(p)
+			}
+		}
+		if p != nil && p.Bool == nil && p.Description == "" {
+			ctx.Report(appendLocation(loc, "properties", k), "property %q has no description", k)
+		}
+	}
+})
+
+// maxRefDepth is the maximum number of the "$ref" hops followed by the Rules.
+const maxRefDepth = 32
+
+// MissingType reports the schema which does not have the "type". The schemas which are constrained by
+// the "$ref", the "const", the "enum", or the boolean logic keywords are not reported.
+var MissingType = NewRule("missing-type", func(ctx *Context, loc string, s *jsonschema.Schema) {
+	if s.Bool != nil || len(s.Type) > 0 || s.Ref != "" || s.Const != nil || len(s.Enum) > 0 ||
+		len(s.AllOf) > 0 || len(s.AnyOf) > 0 || len(s.OneOf) > 0 || s.Not != nil {
+		return
+	}
+	ctx.Report(loc, "schema has no type")
+})
+
+// ImplicitAdditionalProperties reports the object schema which does not have the "additionalProperties".
+var ImplicitAdditionalProperties = NewRule("implicit-additional-properties", func(ctx *Context, loc string, s *jsonschema.Schema) {
+	if s.AdditionalProperties != nil || s.Bool != nil || s.Ref != "" {
+		return
+	}
+	isObject := len(s.Properties) > 0 || len(s.PatternProperties) > 0
+	for _, t := range s.Type {
+		isObject = isObject || t == jsonschema.ObjectType
+	}
+	if isObject {
+		ctx.Report(loc, "object schema has no explicit additionalProperties")
+	}
+})
+
+// UnanchoredPattern reports the "pattern" and the "patternProperties" which are not anchored by "^" and "$",
+// which match the substring of the instance.
+var UnanchoredPattern = NewRule("unanchored-pattern", func(ctx *Context, loc string, s *jsonschema.Schema) {
+	if s.Pattern != nil && !isAnchored(s.Pattern.String()) {
+		ctx.Report(appendLocation(loc, "pattern"), "pattern %q is not anchored", s.Pattern.String())
+	}
+	exprs := make([]string, 0, len(s.PatternProperties))
+	for expr := range s.PatternProperties {
+		exprs = append(exprs, expr)
+	}
+	sort.Strings(exprs)
+	for _, expr := range exprs {
+		if !isAnchored(expr) {
+			ctx.Report(appendLocation(loc, "patternProperties", expr), "patternProperties %q is not anchored", expr)
+		}
+	}
+})
+
+// isAnchored reports whether the regular expression expr is anchored at both ends, that is, every match of expr
+// begins at the beginning of the text and ends at the end of the text. The anchors in the alternatives are
+// anchored only if all of the alternatives are anchored, such as "^a$|^b$", unlike "^a|b$".
+func isAnchored(expr string) bool {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return false
+	}
+
+	return beginsAnchored(re) && endsAnchored(re)
+}
+
+// beginsAnchored reports whether every match of re begins at the beginning of the text.
+func beginsAnchored(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginText:
+		return true
+	case syntax.OpCapture:
+		return beginsAnchored(re.Sub[0])
+	case syntax.OpConcat:
+		return len(re.Sub) > 0 && beginsAnchored(re.Sub[0])
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if !beginsAnchored(sub) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// endsAnchored reports whether every match of re ends at the end of the text.
+func endsAnchored(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpEndText:
+		return true
+	case syntax.OpCapture:
+		return endsAnchored(re.Sub[0])
+	case syntax.OpConcat:
+		return len(re.Sub) > 0 && endsAnchored(re.Sub[len(re.Sub)-1])
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if !endsAnchored(sub) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// appendLocation appends the escaped tokens to the loc JSON Pointer.
+func appendLocation(loc string, tokens ...string) string {
+	for _, tok := range tokens {
+		loc += "/" + jsonpointer.Escape(tok)
+	}
+
+	return loc
+}
+
+// sortedKeys returns the sorted keys of m.
+func sortedKeys(m map[string]*jsonschema.Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
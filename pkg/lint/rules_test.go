@@ -0,0 +1,131 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsAnchored(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "^a$", want: true},
+		{expr: "^$", want: true},
+		{expr: "^(a|b)$", want: true},
+		{expr: "(^a$)", want: true},
+		{expr: "^a$|^b$", want: true},
+		{expr: `^a\\$`, want: true},
+		{expr: "a", want: false},
+		{expr: "^a", want: false},
+		{expr: "a$", want: false},
+		{expr: "^a|b$", want: false},
+		{expr: "^a$|b", want: false},
+		{expr: `^a\$`, want: false},
+		{expr: "(?m)^a$", want: false},
+		{expr: "^a$?", want: false},
+		{expr: "(", want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isAnchored(tt.expr); got != tt.want {
+				t.Errorf("isAnchored(%q) = %t, want %t", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   Rule
+		schema string
+		want   []string
+	}{
+		{
+			name:   "unresolvable-ref",
+			rule:   UnresolvableRef,
+			schema: `{"definitions": {"a": {}}, "properties": {"a": {"$ref": "#/definitions/a"}, "b": {"$ref": "#/definitions/b"}, "c": {"$ref": "http://example.com/c.json"}}}`,
+			want:   []string{`/properties/b/$ref: unresolvable $ref "#/definitions/b": no schema at "/definitions/b" (unresolvable-ref)`},
+		},
+		{
+			name:   "unknown-format",
+			rule:   UnknownFormat,
+			schema: `{"properties": {"a": {"format": "email"}, "b": {"format": "emial"}}}`,
+			want:   []string{`/properties/b/format: unknown format "emial" (unknown-format)`},
+		},
+		{
+			name:   "invalid-bounds",
+			rule:   InvalidBounds,
+			schema: `{"minLength": 2, "maxLength": 1, "minItems": 1, "maxItems": 1, "minimum": 3, "maximum": 2}`,
+			want: []string{
+				"/minLength: minLength 2 is greater than maxLength 1 (invalid-bounds)",
+				"/minimum: minimum 3 is greater than maximum 2 (invalid-bounds)",
+			},
+		},
+		{
+			name:   "unreachable-definition",
+			rule:   UnreachableDefinition,
+			schema: `{"definitions": {"used": {"$ref": "#/definitions/nested"}, "nested": {}, "unused": {}, "identified": {"$id": "#x"}}, "$ref": "#/definitions/used"}`,
+			want:   []string{`/definitions/unused: definition "unused" is not referenced (unreachable-definition)`},
+		},
+		{
+			name:   "unknown-keyword",
+			rule:   UnknownKeyword,
+			schema: `{"type": "object", "properties": {"a": {"maxLenght": 1}}}`,
+			want:   []string{`/properties/a/maxLenght: unknown keyword "maxLenght" (unknown-keyword)`},
+		},
+		{
+			name:   "property-description",
+			rule:   PropertyDescription,
+			schema: `{"definitions": {"d": {"description": "d"}}, "properties": {"a": {"description": "a"}, "b": {"$ref": "#/definitions/d"}, "c": {}, "d": false}}`,
+			want:   []string{`/properties/c: property "c" has no description (property-description)`},
+		},
+		{
+			name:   "missing-type",
+			rule:   MissingType,
+			schema: `{"type": "object", "properties": {"a": {"enum": [1]}, "b": {"minimum": 1}, "c": true}}`,
+			want:   []string{"/properties/b: schema has no type (missing-type)"},
+		},
+		{
+			name:   "implicit-additional-properties",
+			rule:   ImplicitAdditionalProperties,
+			schema: `{"type": "object", "additionalProperties": false, "properties": {"a": {"type": "object"}, "b": {"patternProperties": {"^x$": {}}, "additionalProperties": true}}}`,
+			want:   []string{"/properties/a: object schema has no explicit additionalProperties (implicit-additional-properties)"},
+		},
+		{
+			name:   "unanchored-pattern",
+			rule:   UnanchoredPattern,
+			schema: `{"patternProperties": {"^a$": {"pattern": "^a|b$"}, "^b": {"pattern": "^(a|b)$"}}}`,
+			want: []string{
+				`/patternProperties/^b: patternProperties "^b" is not anchored (unanchored-pattern)`,
+				`/patternProperties/^a$/pattern: pattern "^a|b$" is not anchored (unanchored-pattern)`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			problems, err := New(tt.rule).LintBytes([]byte(tt.schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, p := range problems {
+				got = append(got, p.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
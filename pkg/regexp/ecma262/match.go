@@ -0,0 +1,207 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecma262
+
+// machine represents a backtracking matcher of the single match attempt.
+type machine struct {
+	input   []rune
+	caps    []int
+	steps   int
+	limit   int
+	aborted bool
+}
+
+// cont represents a continuation of the matching, which is called with the position after the matched node.
+type cont func(i int) bool
+
+// match matches the n node at the i position, and calls k with the position after the match.
+func (m *machine) match(n *node, i int, k cont) bool {
+	if m.aborted {
+		return false
+	}
+	m.steps++
+	if m.limit > 0 && m.steps > m.limit {
+		m.aborted = true
+		return false
+	}
+
+	switch n.op {
+	case opEmpty:
+		return k(i)
+
+	case opLiteral:
+		return i < len(m.input) && m.input[i] == n.r && k(i+1)
+
+	case opClass:
+		return i < len(m.input) && inRanges(n.ranges, m.input[i]) && k(i+1)
+
+	case opBegin:
+		return i == 0 && k(i)
+
+	case opEnd:
+		return i == len(m.input) && k(i)
+
+	case opWordBoundary:
+		return m.isWordBoundary(i) && k(i)
+
+	case opNoWordBoundary:
+		return !m.isWordBoundary(i) && k(i)
+
+	case opCapture:
+		return m.match(n.subs[0], i, func(j int) bool {
+			start, end := m.caps[2*n.cap], m.caps[2*n.cap+1]
+			m.caps[2*n.cap], m.caps[2*n.cap+1] = i, j
+			if k(j) {
+				return true
+			}
+			m.caps[2*n.cap], m.caps[2*n.cap+1] = start, end
+			return false
+		})
+
+	case opGroup:
+		return m.match(n.subs[0], i, k)
+
+	case opLookahead, opNegLookahead:
+		saved := m.saveCaps(n)
+		found := m.match(n.subs[0], i, func(int) bool { return true })
+		if m.aborted {
+			return false
+		}
+		if n.op == opNegLookahead {
+			// the captures in the negative lookahead are always undefined.
+			m.restoreCaps(n, saved)
+			return !found && k(i)
+		}
+		if found && k(i) {
+			return true
+		}
+		m.restoreCaps(n, saved)
+		return false
+
+	case opLookbehind, opNegLookbehind:
+		saved := m.saveCaps(n)
+		found := false
+		for j := 0; j <= i && !found; j++ {
+			found = m.match(n.subs[0], j, func(e int) bool { return e == i })
+			if m.aborted {
+				return false
+			}
+		}
+		if n.op == opNegLookbehind {
+			m.restoreCaps(n, saved)
+			return !found && k(i)
+		}
+		if found && k(i) {
+			return true
+		}
+		m.restoreCaps(n, saved)
+		return false
+
+	case opBackref:
+		start, end := m.caps[2*n.cap], m.caps[2*n.cap+1]
+		if start < 0 || end < 0 {
+			return k(i)
+		}
+		l := end - start
+		if i+l > len(m.input) {
+			return false
+		}
+		for x := 0; x < l; x++ {
+			if m.input[i+x] != m.input[start+x] {
+				return false
+			}
+		}
+		return k(i + l)
+
+	case opConcat:
+		return m.concat(n.subs, i, k)
+
+	case opAlternate:
+		for _, sub := range n.subs {
+			if m.match(sub, i, k) {
+				return true
+			}
+			if m.aborted {
+				return false
+			}
+		}
+		return false
+
+	case opRepeat:
+		return m.repeat(n, 0, i, k)
+	}
+
+	return false
+}
+
+// concat matches the subs in order.
+func (m *machine) concat(subs []*node, i int, k cont) bool {
+	if len(subs) == 0 {
+		return k(i)
+	}
+
+	return m.match(subs[0], i, func(j int) bool {
+		return m.concat(subs[1:], j, k)
+	})
+}
+
+// repeat matches the rest iterations of the opRepeat n node after the count iterations.
+func (m *machine) repeat(n *node, count, i int, k cont) bool {
+	if n.max >= 0 && count >= n.max {
+		return k(i)
+	}
+
+	iterate := func() bool {
+		// the captures in the quantified atom are reset at the each iteration.
+		saved := m.saveCaps(n)
+		for c := n.capLo; c < n.capHi; c++ {
+			m.caps[2*c], m.caps[2*c+1] = -1, -1
+		}
+		if m.match(n.subs[0], i, func(j int) bool {
+			if j == i && count >= n.min {
+				// an empty iteration after the minimum count never matches.
+				return false
+			}
+			return m.repeat(n, count+1, j, k)
+		}) {
+			return true
+		}
+		m.restoreCaps(n, saved)
+		return false
+	}
+
+	if count < n.min {
+		return iterate()
+	}
+	if n.greedy {
+		return iterate() || (!m.aborted && k(i))
+	}
+
+	return k(i) || (!m.aborted && iterate())
+}
+
+// saveCaps returns a copy of the captures in the n node.
+func (m *machine) saveCaps(n *node) []int {
+	if n.capLo >= n.capHi {
+		return nil
+	}
+
+	return append([]int(nil), m.caps[2*n.capLo:2*n.capHi]...)
+}
+
+// restoreCaps restores the captures in the n node saved by saveCaps.
+func (m *machine) restoreCaps(n *node, saved []int) {
+	copy(m.caps[2*n.capLo:], saved)
+}
+
+// isWordBoundary reports whether the i position is the word boundary.
+func (m *machine) isWordBoundary(i int) bool {
+	return m.isWordChar(i-1) != m.isWordChar(i)
+}
+
+// isWordChar reports whether the rune at the i position is the word character.
+func (m *machine) isWordChar(i int) bool {
+	return 0 <= i && i < len(m.input) && inRanges(wordRanges, m.input[i])
+}
@@ -0,0 +1,824 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecma262
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Error represents a syntax error of the regular expression.
+type Error struct {
+	Pattern string
+	Offset  int
+	Msg     string
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("ecma262: %s at offset %d of %q", e.Msg, e.Offset, e.Pattern)
+}
+
+// op represents a kind of the node.
+type op uint8
+
+// The list of op.
+const (
+	opEmpty op = iota
+	opLiteral
+	opClass
+	opBegin
+	opEnd
+	opWordBoundary
+	opNoWordBoundary
+	opCapture
+	opGroup
+	opLookahead
+	opNegLookahead
+	opLookbehind
+	opNegLookbehind
+	opBackref
+	opConcat
+	opAlternate
+	opRepeat
+)
+
+// node represents a node of the parsed regular expression.
+type node struct {
+	op     op
+	r      rune   // opLiteral
+	ranges []rune // opClass, the sorted and merged pairs of lo and hi
+	cap    int    // opCapture, opBackref
+	name   string // opCapture, and opBackref until resolved
+	min    int    // opRepeat
+	max    int    // opRepeat, -1 means unbounded
+	greedy bool   // opRepeat
+	subs   []*node
+
+	// capLo and capHi are the range of the capture indexes in the node, used by opRepeat to reset the captures.
+	capLo, capHi int
+}
+
+// maxRepeat is the maximum count of the bounded quantifier.
+const maxRepeat = 1 << 16
+
+// parser represents a parser of the ECMA-262 Pattern grammar.
+//
+// ECMAScript 2019, section 21.2.1:
+//  https://www.ecma-international.org/ecma-262/10.0/#sec-patterns
+type parser struct {
+	pattern  string
+	src      []rune
+	offs     []int
+	pos      int
+	names    []string
+	backrefs []*node
+}
+
+// parse parses the pattern.
+func parse(pattern string) (root *node, names []string, err error) {
+	p := &parser{pattern: pattern, names: []string{""}}
+	for i, r := range pattern {
+		p.src = append(p.src, r)
+		p.offs = append(p.offs, i)
+	}
+	p.offs = append(p.offs, len(pattern))
+
+	defer func() {
+		if e := recover(); e != nil {
+			perr, ok := e.(*Error)
+			if !ok {
+				panic(e)
+			}
+			root, names, err = nil, nil, perr
+		}
+	}()
+
+	root = p.disjunction()
+	if p.pos < len(p.src) {
+		p.fail("unmatched ')'")
+	}
+	for _, br := range p.backrefs {
+		if br.name != "" {
+			br.cap = p.lookupName(br.name)
+			if br.cap < 0 {
+				p.failAt(0, fmt.Sprintf("undefined group name %q", br.name))
+			}
+			continue
+		}
+		if br.cap >= len(p.names) {
+			p.failAt(0, fmt.Sprintf("invalid back reference \\%d", br.cap))
+		}
+	}
+
+	return root, p.names, nil
+}
+
+// fail panics with the *Error at the current position.
+func (p *parser) fail(msg string) {
+	p.failAt(p.pos, msg)
+}
+
+// failAt panics with the *Error at the pos.
+func (p *parser) failAt(pos int, msg string) {
+	if pos > len(p.src) {
+		pos = len(p.src)
+	}
+	panic(&Error{Pattern: p.pattern, Offset: p.offs[pos], Msg: msg})
+}
+
+// more reports whether the parser has the unread runes.
+func (p *parser) more() bool {
+	return p.pos < len(p.src)
+}
+
+// peek returns the current rune, or -1 at the end.
+func (p *parser) peek() rune {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return -1
+}
+
+// lookingAt reports whether the unread runes start with s.
+func (p *parser) lookingAt(s string) bool {
+	i := p.pos
+	for _, r := range s {
+		if i >= len(p.src) || p.src[i] != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// lookupName returns the capture index of the named group, or -1.
+func (p *parser) lookupName(name string) int {
+	for i, n := range p.names {
+		if i > 0 && n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// disjunction parses the Disjunction.
+func (p *parser) disjunction() *node {
+	lo := len(p.names)
+	alts := []*node{p.alternative()}
+	for p.peek() == '|' {
+		p.pos++
+		alts = append(alts, p.alternative())
+	}
+
+	var n *node
+	if len(alts) == 1 {
+		n = alts[0]
+	} else {
+		n = &node{op: opAlternate, subs: alts}
+	}
+	n.capLo, n.capHi = lo, len(p.names)
+
+	return n
+}
+
+// alternative parses the Alternative.
+func (p *parser) alternative() *node {
+	lo := len(p.names)
+	var terms []*node
+	for p.more() && p.peek() != '|' && p.peek() != ')' {
+		terms = append(terms, p.term())
+	}
+
+	var n *node
+	switch len(terms) {
+	case 0:
+		n = &node{op: opEmpty}
+	case 1:
+		n = terms[0]
+	default:
+		n = &node{op: opConcat, subs: terms}
+	}
+	n.capLo, n.capHi = lo, len(p.names)
+
+	return n
+}
+
+// term parses the Term.
+func (p *parser) term() *node {
+	start := p.pos
+	lo := len(p.names)
+
+	var atom *node
+	quantifiable := true
+	switch r := p.peek(); r {
+	case '^':
+		p.pos++
+		atom, quantifiable = &node{op: opBegin}, false
+	case '$':
+		p.pos++
+		atom, quantifiable = &node{op: opEnd}, false
+	case '\\':
+		if p.lookingAt(`\b`) {
+			p.pos += 2
+			atom, quantifiable = &node{op: opWordBoundary}, false
+			break
+		}
+		if p.lookingAt(`\B`) {
+			p.pos += 2
+			atom, quantifiable = &node{op: opNoWordBoundary}, false
+			break
+		}
+		atom = p.atomEscape()
+	case '(':
+		atom = p.group()
+		switch atom.op {
+		case opLookbehind, opNegLookbehind:
+			quantifiable = false
+		}
+	case '.':
+		p.pos++
+		atom = &node{op: opClass, ranges: negateRanges(lineTerminators)}
+	case '[':
+		atom = p.class()
+	case '*', '+', '?':
+		p.fail("nothing to repeat")
+	case '{':
+		if _, _, ok := p.scanBraces(); ok {
+			p.fail("nothing to repeat")
+		}
+		p.pos++
+		atom = &node{op: opLiteral, r: r}
+	case ']', '}':
+		// Annex B allows the lone closing brackets as the pattern characters.
+		p.pos++
+		atom = &node{op: opLiteral, r: r}
+	default:
+		p.pos++
+		atom = &node{op: opLiteral, r: r}
+	}
+	atom.capLo, atom.capHi = lo, len(p.names)
+
+	min, max, ok := p.quantifier()
+	if !ok {
+		return atom
+	}
+	if !quantifiable {
+		p.failAt(start, "nothing to repeat")
+	}
+	greedy := true
+	if p.peek() == '?' {
+		p.pos++
+		greedy = false
+	}
+
+	return &node{op: opRepeat, min: min, max: max, greedy: greedy, subs: []*node{atom}, capLo: lo, capHi: len(p.names)}
+}
+
+// quantifier parses the QuantifierPrefix if any.
+func (p *parser) quantifier() (min, max int, ok bool) {
+	switch p.peek() {
+	case '*':
+		p.pos++
+		return 0, -1, true
+	case '+':
+		p.pos++
+		return 1, -1, true
+	case '?':
+		p.pos++
+		return 0, 1, true
+	case '{':
+		start := p.pos
+		min, max, ok := p.scanBraces()
+		if !ok {
+			return 0, 0, false
+		}
+		if max >= 0 && min > max {
+			p.failAt(start, "numbers out of order in {} quantifier")
+		}
+		return min, max, true
+	}
+
+	return 0, 0, false
+}
+
+// scanBraces scans the "{n}", "{n,}" or "{n,m}" quantifier, and advances the parser only if it is found.
+func (p *parser) scanBraces() (min, max int, ok bool) {
+	i := p.pos + 1
+	digits := func() (int, bool) {
+		start := i
+		for i < len(p.src) && '0' <= p.src[i] && p.src[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return 0, false
+		}
+		n, err := strconv.Atoi(string(p.src[start:i]))
+		if err != nil || n > maxRepeat {
+			p.failAt(start, "quantifier count too large")
+		}
+		return n, true
+	}
+
+	min, ok = digits()
+	if !ok {
+		return 0, 0, false
+	}
+	max = min
+	if i < len(p.src) && p.src[i] == ',' {
+		i++
+		max = -1
+		if i < len(p.src) && p.src[i] != '}' {
+			if max, ok = digits(); !ok {
+				return 0, 0, false
+			}
+		}
+	}
+	if i >= len(p.src) || p.src[i] != '}' {
+		return 0, 0, false
+	}
+	p.pos = i + 1
+
+	return min, max, true
+}
+
+// group parses the parenthesized atoms and assertions.
+func (p *parser) group() *node {
+	start := p.pos
+	p.pos++ // '('
+
+	var n *node
+	switch {
+	case p.lookingAt("?:"):
+		p.pos += 2
+		n = &node{op: opGroup}
+	case p.lookingAt("?="):
+		p.pos += 2
+		n = &node{op: opLookahead}
+	case p.lookingAt("?!"):
+		p.pos += 2
+		n = &node{op: opNegLookahead}
+	case p.lookingAt("?<="):
+		p.pos += 3
+		n = &node{op: opLookbehind}
+	case p.lookingAt("?<!"):
+		p.pos += 3
+		n = &node{op: opNegLookbehind}
+	case p.lookingAt("?<"):
+		p.pos += 2
+		name := p.groupName()
+		if p.lookupName(name) >= 0 {
+			p.failAt(start, fmt.Sprintf("duplicate group name %q", name))
+		}
+		n = &node{op: opCapture, cap: len(p.names), name: name}
+		p.names = append(p.names, name)
+	case p.lookingAt("?"):
+		p.fail("invalid group")
+	default:
+		n = &node{op: opCapture, cap: len(p.names)}
+		p.names = append(p.names, "")
+	}
+
+	n.subs = []*node{p.disjunction()}
+	if p.peek() != ')' {
+		p.failAt(start, "missing ')'")
+	}
+	p.pos++
+
+	return n
+}
+
+// groupName parses the GroupName without the leading '<'.
+func (p *parser) groupName() string {
+	start := p.pos
+	for p.more() && p.peek() != '>' {
+		r := p.peek()
+		if !(r == '$' || r == '_' || unicode.IsLetter(r) || (p.pos > start && (unicode.IsDigit(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Pc)))) {
+			p.fail("invalid group name")
+		}
+		p.pos++
+	}
+	if !p.more() || p.pos == start {
+		p.failAt(start, "invalid group name")
+	}
+	name := string(p.src[start:p.pos])
+	p.pos++ // '>'
+
+	return name
+}
+
+// atomEscape parses the AtomEscape.
+func (p *parser) atomEscape() *node {
+	start := p.pos
+	p.pos++ // '\\'
+	if !p.more() {
+		p.failAt(start, "\\ at end of pattern")
+	}
+
+	switch r := p.peek(); {
+	case '1' <= r && r <= '9':
+		i := p.pos
+		for i < len(p.src) && '0' <= p.src[i] && p.src[i] <= '9' {
+			i++
+		}
+		n, err := strconv.Atoi(string(p.src[p.pos:i]))
+		if err != nil {
+			p.fail("invalid back reference")
+		}
+		p.pos = i
+		br := &node{op: opBackref, cap: n}
+		p.backrefs = append(p.backrefs, br)
+		return br
+
+	case r == 'k':
+		p.pos++
+		if p.peek() != '<' {
+			p.fail("invalid named reference")
+		}
+		p.pos++
+		br := &node{op: opBackref, name: p.groupName()}
+		p.backrefs = append(p.backrefs, br)
+		return br
+	}
+
+	if ranges, ok := p.classEscape(); ok {
+		return &node{op: opClass, ranges: ranges}
+	}
+
+	return &node{op: opLiteral, r: p.characterEscape(false)}
+}
+
+// classEscape parses the CharacterClassEscape if any.
+func (p *parser) classEscape() ([]rune, bool) {
+	switch p.peek() {
+	case 'd':
+		p.pos++
+		return digitRanges, true
+	case 'D':
+		p.pos++
+		return negateRanges(digitRanges), true
+	case 's':
+		p.pos++
+		return spaceRanges, true
+	case 'S':
+		p.pos++
+		return negateRanges(spaceRanges), true
+	case 'w':
+		p.pos++
+		return wordRanges, true
+	case 'W':
+		p.pos++
+		return negateRanges(wordRanges), true
+	case 'p', 'P':
+		negate := p.peek() == 'P'
+		start := p.pos
+		p.pos++
+		if p.peek() != '{' {
+			p.fail("invalid property escape")
+		}
+		end := p.pos
+		for end < len(p.src) && p.src[end] != '}' {
+			end++
+		}
+		if end >= len(p.src) {
+			p.fail("invalid property escape")
+		}
+		ranges, ok := propertyRanges(string(p.src[p.pos+1 : end]))
+		if !ok {
+			p.failAt(start, fmt.Sprintf("invalid property name %q", string(p.src[p.pos+1:end])))
+		}
+		p.pos = end + 1
+		if negate {
+			ranges = negateRanges(ranges)
+		}
+		return ranges, true
+	}
+
+	return nil, false
+}
+
+// characterEscape parses the CharacterEscape, or the ClassEscape if inClass.
+func (p *parser) characterEscape(inClass bool) rune {
+	start := p.pos - 1
+	r := p.peek()
+	p.pos++
+	switch r {
+	case 't':
+		return '\t'
+	case 'n':
+		return '\n'
+	case 'v':
+		return '\v'
+	case 'f':
+		return '\f'
+	case 'r':
+		return '\r'
+	case 'b':
+		if inClass {
+			return '\b'
+		}
+	case '-':
+		if inClass {
+			return '-'
+		}
+	case 'c':
+		if c := p.peek(); ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') {
+			p.pos++
+			return c % 32
+		}
+		p.failAt(start, "invalid control escape")
+	case '0':
+		if c := p.peek(); '0' <= c && c <= '9' {
+			p.failAt(start, "invalid decimal escape")
+		}
+		return 0
+	case 'x':
+		if v, ok := p.hex(2); ok {
+			return v
+		}
+		p.failAt(start, "invalid hexadecimal escape")
+	case 'u':
+		if p.peek() == '{' {
+			p.pos++
+			end := p.pos
+			for end < len(p.src) && p.src[end] != '}' {
+				end++
+			}
+			v, err := strconv.ParseUint(string(p.src[p.pos:end]), 16, 32)
+			if err != nil || end >= len(p.src) || v > unicode.MaxRune {
+				p.failAt(start, "invalid unicode escape")
+			}
+			p.pos = end + 1
+			return rune(v)
+		}
+		v, ok := p.hex(4)
+		if !ok {
+			p.failAt(start, "invalid unicode escape")
+		}
+		if utf16.IsSurrogate(v) && v < 0xdc00 && p.lookingAt(`\u`) {
+			save := p.pos
+			p.pos += 2
+			if lo, ok := p.hex(4); ok && 0xdc00 <= lo && lo <= 0xdfff {
+				return utf16.DecodeRune(v, lo)
+			}
+			p.pos = save
+		}
+		return v
+	}
+
+	// IdentityEscape allows the SyntaxCharacter and '/' only.
+	if strings.ContainsRune(`^$\.*+?()[]{}|/`, r) {
+		return r
+	}
+	p.failAt(start, fmt.Sprintf("invalid escape \\%c", r))
+
+	return 0
+}
+
+// hex parses the n hexadecimal digits.
+func (p *parser) hex(n int) (rune, bool) {
+	if p.pos+n > len(p.src) {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(string(p.src[p.pos:p.pos+n]), 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	p.pos += n
+
+	return rune(v), true
+}
+
+// class parses the CharacterClass.
+func (p *parser) class() *node {
+	start := p.pos
+	p.pos++ // '['
+	negate := false
+	if p.peek() == '^' {
+		p.pos++
+		negate = true
+	}
+
+	var ranges []rune
+	for {
+		if !p.more() {
+			p.failAt(start, "missing ']'")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			break
+		}
+
+		lo, set := p.classAtom()
+		if set != nil {
+			ranges = append(ranges, set...)
+			if p.peek() == '-' && p.pos+1 < len(p.src) && p.src[p.pos+1] != ']' {
+				p.fail("invalid character class range")
+			}
+			continue
+		}
+		if p.peek() != '-' || p.pos+1 >= len(p.src) || p.src[p.pos+1] == ']' {
+			ranges = append(ranges, lo, lo)
+			continue
+		}
+		rangeStart := p.pos
+		p.pos++ // '-'
+		hi, set := p.classAtom()
+		if set != nil {
+			p.failAt(rangeStart, "invalid character class range")
+		}
+		if lo > hi {
+			p.failAt(rangeStart, "range out of order in character class")
+		}
+		ranges = append(ranges, lo, hi)
+	}
+
+	ranges = mergeRanges(ranges)
+	if negate {
+		ranges = negateRanges(ranges)
+	}
+
+	return &node{op: opClass, ranges: ranges}
+}
+
+// classAtom parses the ClassAtom, and returns either a single rune or a set of ranges.
+func (p *parser) classAtom() (rune, []rune) {
+	r := p.peek()
+	if r != '\\' {
+		p.pos++
+		return r, nil
+	}
+
+	p.pos++
+	if !p.more() {
+		p.fail("\\ at end of pattern")
+	}
+	if ranges, ok := p.classEscape(); ok {
+		return 0, ranges
+	}
+
+	return p.characterEscape(true), nil
+}
+
+// lineTerminators is the ranges of the LineTerminator.
+var lineTerminators = []rune{'\n', '\n', '\r', '\r', 0x2028, 0x2029}
+
+// digitRanges is the ranges of the "\d".
+var digitRanges = []rune{'0', '9'}
+
+// wordRanges is the ranges of the "\w".
+var wordRanges = []rune{'0', '9', 'A', 'Z', '_', '_', 'a', 'z'}
+
+// spaceRanges is the ranges of the "\s", that is the WhiteSpace and LineTerminator.
+var spaceRanges = mergeRanges([]rune{
+	'\t', '\r', // \t \n \v \f \r
+	' ', ' ',
+	0xa0, 0xa0,
+	0x1680, 0x1680,
+	0x2000, 0x200a,
+	0x2028, 0x2029,
+	0x202f, 0x202f,
+	0x205f, 0x205f,
+	0x3000, 0x3000,
+	0xfeff, 0xfeff,
+})
+
+// mergeRanges sorts and merges the overlapping or adjacent ranges.
+func mergeRanges(ranges []rune) []rune {
+	type pair struct{ lo, hi rune }
+	pairs := make([]pair, 0, len(ranges)/2)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		pairs = append(pairs, pair{ranges[i], ranges[i+1]})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].lo < pairs[j].lo })
+
+	merged := make([]rune, 0, len(ranges))
+	for _, pr := range pairs {
+		if n := len(merged); n > 0 && pr.lo <= merged[n-1]+1 {
+			if pr.hi > merged[n-1] {
+				merged[n-1] = pr.hi
+			}
+			continue
+		}
+		merged = append(merged, pr.lo, pr.hi)
+	}
+
+	return merged
+}
+
+// negateRanges returns the complement of the sorted and merged ranges.
+func negateRanges(ranges []rune) []rune {
+	var neg []rune
+	next := rune(0)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if ranges[i] > next {
+			neg = append(neg, next, ranges[i]-1)
+		}
+		next = ranges[i+1] + 1
+	}
+	if next <= unicode.MaxRune {
+		neg = append(neg, next, unicode.MaxRune)
+	}
+
+	return neg
+}
+
+// inRanges reports whether r is in the sorted and merged ranges.
+func inRanges(ranges []rune, r rune) bool {
+	i := sort.Search(len(ranges)/2, func(i int) bool { return ranges[2*i+1] >= r })
+	return i < len(ranges)/2 && ranges[2*i] <= r
+}
+
+// generalCategoryAliases is the map of the long name of the General_Category to the short name.
+var generalCategoryAliases = map[string]string{
+	"Letter": "L", "Cased_Letter": "LC", "Uppercase_Letter": "Lu", "Lowercase_Letter": "Ll", "Titlecase_Letter": "Lt",
+	"Modifier_Letter": "Lm", "Other_Letter": "Lo",
+	"Mark": "M", "Nonspacing_Mark": "Mn", "Spacing_Mark": "Mc", "Enclosing_Mark": "Me",
+	"Number": "N", "Decimal_Number": "Nd", "digit": "Nd", "Letter_Number": "Nl", "Other_Number": "No",
+	"Punctuation": "P", "punct": "P", "Connector_Punctuation": "Pc", "Dash_Punctuation": "Pd", "Open_Punctuation": "Ps",
+	"Close_Punctuation": "Pe", "Initial_Punctuation": "Pi", "Final_Punctuation": "Pf", "Other_Punctuation": "Po",
+	"Symbol": "S", "Math_Symbol": "Sm", "Currency_Symbol": "Sc", "Modifier_Symbol": "Sk", "Other_Symbol": "So",
+	"Separator": "Z", "Space_Separator": "Zs", "Line_Separator": "Zl", "Paragraph_Separator": "Zp",
+	"Other": "C", "Control": "Cc", "cntrl": "Cc", "Format": "Cf", "Surrogate": "Cs", "Private_Use": "Co",
+}
+
+// propertyRanges returns the ranges of the UnicodePropertyValueExpression.
+func propertyRanges(expr string) ([]rune, bool) {
+	name, value := expr, ""
+	if i := strings.IndexByte(expr, '='); i >= 0 {
+		name, value = expr[:i], expr[i+1:]
+	}
+
+	var table *unicode.RangeTable
+	switch {
+	case value == "":
+		switch name {
+		case "Any":
+			return []rune{0, unicode.MaxRune}, true
+		case "ASCII":
+			return []rune{0, unicode.MaxASCII}, true
+		case "Assigned":
+			var ranges []rune
+			for _, t := range unicode.Categories {
+				ranges = append(ranges, tableRanges(t)...)
+			}
+			return mergeRanges(ranges), true
+		case "LC":
+			return mergeRanges(append(append(tableRanges(unicode.Lu), tableRanges(unicode.Ll)...), tableRanges(unicode.Lt)...)), true
+		}
+		if alias, ok := generalCategoryAliases[name]; ok {
+			name = alias
+		}
+		if t, ok := unicode.Categories[name]; ok {
+			table = t
+		} else if t, ok := unicode.Properties[name]; ok {
+			table = t
+		}
+	case name == "General_Category" || name == "gc":
+		if alias, ok := generalCategoryAliases[value]; ok {
+			value = alias
+		}
+		if value == "LC" {
+			return propertyRanges("LC")
+		}
+		table = unicode.Categories[value]
+	case name == "Script" || name == "sc" || name == "Script_Extensions" || name == "scx":
+		table = unicode.Scripts[value]
+	}
+	if table == nil {
+		return nil, false
+	}
+
+	return mergeRanges(tableRanges(table)), true
+}
+
+// tableRanges returns the ranges of the t.
+func tableRanges(t *unicode.RangeTable) []rune {
+	var ranges []rune
+	for _, r := range t.R16 {
+		if r.Stride == 1 {
+			ranges = append(ranges, rune(r.Lo), rune(r.Hi))
+			continue
+		}
+		for c := rune(r.Lo); c <= rune(r.Hi); c += rune(r.Stride) {
+			ranges = append(ranges, c, c)
+		}
+	}
+	for _, r := range t.R32 {
+		if r.Stride == 1 {
+			ranges = append(ranges, rune(r.Lo), rune(r.Hi))
+			continue
+		}
+		for c := rune(r.Lo); c <= rune(r.Hi); c += rune(r.Stride) {
+			ranges = append(ranges, c, c)
+		}
+	}
+
+	return ranges
+}
@@ -0,0 +1,496 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ecma262 implements the ECMA-262 regular expressions.
+//
+// The patterns are matched with the code point semantics, which is the same as the "u" flag of the ECMAScript.
+// The patterns which has the same meaning in RE2 syntax are translated to and matched by the Go regexp package,
+// and the others, such as the lookaround assertions and back references, are matched by the backtracking matcher
+// bounded by the backtrack limit.
+//
+// ECMAScript 2019, section 21.2:
+//  https://www.ecma-international.org/ecma-262/10.0/#sec-regexp-regular-expression-objects
+package ecma262
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	regexpinterface "github.com/zchee/go-jsonschema/pkg/regexp"
+)
+
+// DefaultBacktrackLimit is the default maximum number of the backtracking steps of the single match.
+const DefaultBacktrackLimit = 1 << 20
+
+// ErrBacktrackLimit is returned when the match exceeds the backtrack limit.
+var ErrBacktrackLimit = errors.New("ecma262: backtrack limit exceeded")
+
+// Regexp represents a compiled ECMA-262 regular expression.
+//
+// Regexp is safe for concurrent use by multiple goroutines.
+type Regexp struct {
+	expr  string
+	prog  *node
+	names []string
+	limit int
+
+	// re2 is the equivalent Go regexp, or nil if the pattern is not compatible with RE2.
+	re2 *regexp.Regexp
+
+	// re2Submatch reports whether the submatches of re2 are also equivalent.
+	re2Submatch bool
+}
+
+// compile time check whether the Regexp implements regexpinterface.Regexp interface.
+var _ regexpinterface.Regexp = (*Regexp)(nil)
+
+// Option represents an option of the Compile.
+type Option func(*Regexp)
+
+// WithBacktrackLimit sets the maximum number of the backtracking steps of the single match.
+//
+// The match exceeding the limit is treated as no match. A limit less than or equal to 0 means no limit.
+func WithBacktrackLimit(n int) Option {
+	return func(re *Regexp) {
+		re.limit = n
+	}
+}
+
+// Compile parses the ECMA-262 regular expression.
+func Compile(expr string, opts ...Option) (*Regexp, error) {
+	prog, names, err := parse(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	re := &Regexp{
+		expr:  expr,
+		prog:  prog,
+		names: names,
+		limit: DefaultBacktrackLimit,
+	}
+	for _, opt := range opts {
+		opt(re)
+	}
+
+	t := &translator{exactSubmatch: true}
+	if t.translate(prog) {
+		// the translation may still be rejected by RE2, e.g. the repeat count larger than 1000.
+		if re2, err := regexp.Compile(t.String()); err == nil {
+			re.re2 = re2
+			re.re2Submatch = t.exactSubmatch
+		}
+	}
+
+	return re, nil
+}
+
+// Validate checks the syntax of the ECMA-262 regular expression without compiling it.
+func Validate(expr string) error {
+	_, _, err := parse(expr)
+	return err
+}
+
+// MustCompile is like Compile but panics if the expression cannot be parsed.
+func MustCompile(expr string, opts ...Option) *Regexp {
+	re, err := Compile(expr, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return re
+}
+
+// IsRE2 reports whether re is matched by the Go regexp package.
+func (re *Regexp) IsRE2() bool { return re.re2 != nil }
+
+// String returns the source text of the regular expression.
+func (re *Regexp) String() string { return re.expr }
+
+// Copy returns re, because Regexp is immutable.
+func (re *Regexp) Copy() regexpinterface.Regexp { return re }
+
+// SubexpNames returns the names of the capturing groups. The first element is always the empty string.
+func (re *Regexp) SubexpNames() []string {
+	return re.names
+}
+
+// MatchString reports whether s contains any match of re.
+//
+// MatchString returns false if the match exceeds the backtrack limit.
+func (re *Regexp) MatchString(s string) bool {
+	ok, _ := re.MatchStringError(s)
+	return ok
+}
+
+// MatchStringError is like MatchString but returns ErrBacktrackLimit if the match exceeds the backtrack limit.
+func (re *Regexp) MatchStringError(s string) (bool, error) {
+	if re.re2 != nil {
+		return re.re2.MatchString(s), nil
+	}
+
+	loc, err := re.find(s, 0)
+	return loc != nil, err
+}
+
+// FindStringSubmatchIndex returns the byte index pairs of the leftmost match and its submatches in s.
+func (re *Regexp) FindStringSubmatchIndex(s string) []int {
+	if re.re2Submatch {
+		return re.re2.FindStringSubmatchIndex(s)
+	}
+
+	loc, _ := re.find(s, 0)
+	return loc
+}
+
+// FindStringSubmatch returns the leftmost match and its submatches in s.
+func (re *Regexp) FindStringSubmatch(s string) []string {
+	loc := re.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return nil
+	}
+
+	sub := make([]string, len(loc)/2)
+	for i := range sub {
+		if loc[2*i] >= 0 {
+			sub[i] = s[loc[2*i]:loc[2*i+1]]
+		}
+	}
+
+	return sub
+}
+
+// FindSubmatch returns the leftmost match and its submatches in b.
+func (re *Regexp) FindSubmatch(b []byte) [][]byte {
+	if re.re2Submatch {
+		return re.re2.FindSubmatch(b)
+	}
+
+	loc := re.FindStringSubmatchIndex(string(b))
+	if loc == nil {
+		return nil
+	}
+	sub := make([][]byte, len(loc)/2)
+	for i := range sub {
+		if loc[2*i] >= 0 {
+			sub[i] = b[loc[2*i]:loc[2*i+1]:loc[2*i+1]]
+		}
+	}
+
+	return sub
+}
+
+// FindString returns the leftmost match in s.
+func (re *Regexp) FindString(s string) string {
+	loc := re.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return ""
+	}
+
+	return s[loc[0]:loc[1]]
+}
+
+// FindAllString returns at most n successive matches in s. If n < 0, it returns all matches.
+func (re *Regexp) FindAllString(s string, n int) []string {
+	if re.re2Submatch {
+		return re.re2.FindAllString(s, n)
+	}
+
+	var matches []string
+	re.allMatches(s, n, func(loc []int) {
+		matches = append(matches, s[loc[0]:loc[1]])
+	})
+
+	return matches
+}
+
+// ReplaceAllString returns a copy of src, replacing the matches of re with repl.
+//
+// Inside repl, "$" signs are interpreted as in the Expand method of the Go regexp package.
+func (re *Regexp) ReplaceAllString(src, repl string) string {
+	if re.re2Submatch {
+		return re.re2.ReplaceAllString(src, repl)
+	}
+
+	var b strings.Builder
+	last := 0
+	re.allMatches(src, -1, func(loc []int) {
+		b.WriteString(src[last:loc[0]])
+		re.expand(&b, repl, src, loc)
+		last = loc[1]
+	})
+	b.WriteString(src[last:])
+
+	return b.String()
+}
+
+// allMatches calls fn with the submatch indexes of at most n successive matches in s.
+func (re *Regexp) allMatches(s string, n int, fn func(loc []int)) {
+	for pos, count := 0, 0; pos <= len(s) && (n < 0 || count < n); count++ {
+		loc, _ := re.find(s, pos)
+		if loc == nil {
+			return
+		}
+		fn(loc)
+		pos = loc[1]
+		if loc[0] == loc[1] {
+			// skip the rune after the empty match.
+			if pos == len(s) {
+				return
+			}
+			_, size := utf8.DecodeRuneInString(s[pos:])
+			pos += size
+		}
+	}
+}
+
+// expand appends repl to b, with the "$name" and "${name}" replaced by the submatches.
+func (re *Regexp) expand(b *strings.Builder, repl, src string, loc []int) {
+	for {
+		i := strings.IndexByte(repl, '$')
+		if i < 0 || i+1 >= len(repl) {
+			b.WriteString(repl)
+			return
+		}
+		b.WriteString(repl[:i])
+		repl = repl[i+1:]
+		if repl[0] == '$' {
+			b.WriteByte('$')
+			repl = repl[1:]
+			continue
+		}
+
+		var name string
+		if repl[0] == '{' {
+			end := strings.IndexByte(repl, '}')
+			if end < 0 {
+				b.WriteByte('$')
+				continue
+			}
+			name, repl = repl[1:end], repl[end+1:]
+		} else {
+			end := 0
+			for end < len(repl) && (repl[end] == '_' || ('0' <= repl[end] && repl[end] <= '9') || ('a' <= repl[end] && repl[end] <= 'z') || ('A' <= repl[end] && repl[end] <= 'Z')) {
+				end++
+			}
+			if end == 0 {
+				b.WriteByte('$')
+				continue
+			}
+			name, repl = repl[:end], repl[end:]
+		}
+
+		idx := -1
+		if n, err := strconv.Atoi(name); err == nil {
+			idx = n
+		} else {
+			for i, sub := range re.names {
+				if i > 0 && sub == name {
+					idx = i
+					break
+				}
+			}
+		}
+		if idx >= 0 && 2*idx+1 < len(loc) && loc[2*idx] >= 0 {
+			b.WriteString(src[loc[2*idx]:loc[2*idx+1]])
+		}
+	}
+}
+
+// find returns the byte index pairs of the leftmost match and its submatches in s at or after the pos byte offset.
+func (re *Regexp) find(s string, pos int) ([]int, error) {
+	input := make([]rune, 0, len(s))
+	offs := make([]int, 0, len(s)+1)
+	start := -1
+	for i, r := range s {
+		if start < 0 && i >= pos {
+			start = len(input)
+		}
+		input = append(input, r)
+		offs = append(offs, i)
+	}
+	offs = append(offs, len(s))
+	if start < 0 {
+		start = len(input)
+	}
+
+	m := &machine{
+		input: input,
+		caps:  make([]int, 2*len(re.names)),
+		limit: re.limit,
+	}
+	anchored := startsWithBegin(re.prog)
+	for ; start <= len(input); start++ {
+		for i := range m.caps {
+			m.caps[i] = -1
+		}
+		if m.match(re.prog, start, func(end int) bool {
+			m.caps[0], m.caps[1] = start, end
+			return true
+		}) {
+			loc := make([]int, len(m.caps))
+			for i, c := range m.caps {
+				loc[i] = -1
+				if c >= 0 {
+					loc[i] = offs[c]
+				}
+			}
+			return loc, nil
+		}
+		if m.aborted {
+			return nil, ErrBacktrackLimit
+		}
+		if anchored {
+			break
+		}
+	}
+
+	return nil, nil
+}
+
+// startsWithBegin reports whether the n node always starts with the "^" assertion.
+func startsWithBegin(n *node) bool {
+	switch n.op {
+	case opBegin:
+		return true
+	case opConcat:
+		return startsWithBegin(n.subs[0])
+	case opCapture, opGroup:
+		return startsWithBegin(n.subs[0])
+	case opAlternate:
+		for _, sub := range n.subs {
+			if !startsWithBegin(sub) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// translator translates the node to the equivalent RE2 syntax.
+type translator struct {
+	strings.Builder
+
+	// exactSubmatch reports whether the submatches of the translation are also equivalent.
+	exactSubmatch bool
+}
+
+// translate writes the RE2 syntax equivalent to the n node, and reports whether it is possible.
+func (t *translator) translate(n *node) bool {
+	switch n.op {
+	case opEmpty:
+		t.WriteString("(?:)")
+
+	case opLiteral:
+		t.WriteString(regexp.QuoteMeta(string(n.r)))
+
+	case opClass:
+		if len(n.ranges) == 0 {
+			t.WriteString(`[^\x00-\x{10FFFF}]`)
+			return true
+		}
+		t.WriteByte('[')
+		for i := 0; i+1 < len(n.ranges); i += 2 {
+			t.writeRune(n.ranges[i])
+			if n.ranges[i+1] != n.ranges[i] {
+				t.WriteByte('-')
+				t.writeRune(n.ranges[i+1])
+			}
+		}
+		t.WriteByte(']')
+
+	case opBegin:
+		t.WriteString(`\A`)
+
+	case opEnd:
+		t.WriteString(`\z`)
+
+	case opWordBoundary:
+		t.WriteString(`\b`)
+
+	case opNoWordBoundary:
+		t.WriteString(`\B`)
+
+	case opCapture:
+		if n.name != "" {
+			t.WriteString("(?P<" + n.name + ">")
+		} else {
+			t.WriteByte('(')
+		}
+		if !t.translate(n.subs[0]) {
+			return false
+		}
+		t.WriteByte(')')
+
+	case opGroup:
+		t.WriteString("(?:")
+		if !t.translate(n.subs[0]) {
+			return false
+		}
+		t.WriteByte(')')
+
+	case opConcat:
+		for _, sub := range n.subs {
+			if !t.translate(sub) {
+				return false
+			}
+		}
+
+	case opAlternate:
+		t.WriteString("(?:")
+		for i, sub := range n.subs {
+			if i > 0 {
+				t.WriteByte('|')
+			}
+			if !t.translate(sub) {
+				return false
+			}
+		}
+		t.WriteByte(')')
+
+	case opRepeat:
+		if n.capLo < n.capHi && n.max != 0 && n.max != 1 {
+			// the captures in the repeated atom are reset at the each iteration in ECMA-262, but not in RE2.
+			t.exactSubmatch = false
+		}
+		t.WriteString("(?:")
+		if !t.translate(n.subs[0]) {
+			return false
+		}
+		t.WriteByte(')')
+		switch {
+		case n.min == 0 && n.max < 0:
+			t.WriteByte('*')
+		case n.min == 1 && n.max < 0:
+			t.WriteByte('+')
+		case n.min == 0 && n.max == 1:
+			t.WriteByte('?')
+		case n.max < 0:
+			t.WriteString("{" + strconv.Itoa(n.min) + ",}")
+		case n.min == n.max:
+			t.WriteString("{" + strconv.Itoa(n.min) + "}")
+		default:
+			t.WriteString("{" + strconv.Itoa(n.min) + "," + strconv.Itoa(n.max) + "}")
+		}
+		if !n.greedy {
+			t.WriteByte('?')
+		}
+
+	default:
+		// the lookaround assertions and back references.
+		return false
+	}
+
+	return true
+}
+
+// writeRune writes the escaped r for the RE2 character class.
+func (t *translator) writeRune(r rune) {
+	t.WriteString(`\x{` + strconv.FormatInt(int64(r), 16) + `}`)
+}
@@ -0,0 +1,215 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecma262
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatchString(t *testing.T) {
+	tests := []struct {
+		expr  string
+		input string
+		want  bool
+		re2   bool
+	}{
+		{expr: "^abc$", input: "abc", want: true, re2: true},
+		{expr: "^abc$", input: "abcd", want: false, re2: true},
+		{expr: "a+", input: "xaay", want: true, re2: true},
+		{expr: "^\\d+$", input: "0123", want: true, re2: true},
+		{expr: "^\\d+$", input: "٠", want: false, re2: true},
+		{expr: "^\\w+$", input: "é", want: false, re2: true},
+		{expr: "^\\s$", input: " ", want: true, re2: true},
+		{expr: "^\\s$", input: "\ufeff", want: true, re2: true},
+		{expr: "^.$", input: "\U0001f600", want: true, re2: true},
+		{expr: "^.$", input: "\n", want: false, re2: true},
+		{expr: "^\\p{Letter}+$", input: "abé", want: true, re2: true},
+		{expr: "^\\cJ$", input: "\n", want: true, re2: true},
+		{expr: "^\\u00e9$", input: "é", want: true, re2: true},
+		{expr: "^\\u{1F600}$", input: "\U0001f600", want: true, re2: true},
+		{expr: "^[^]$", input: "\n", want: true, re2: true},
+		{expr: "^[]$", input: "a", want: false, re2: true},
+		{expr: "^a{2,3}$", input: "aaa", want: true, re2: true},
+		{expr: "^a{2,3}$", input: "aaaa", want: false, re2: true},
+		{expr: "^(?:ab)*?c$", input: "ababc", want: true, re2: true},
+		{expr: "^(?<year>\\d{4})-\\k<year>$", input: "2019-2019", want: true},
+		{expr: "^(\\w)\\1$", input: "aa", want: true},
+		{expr: "^(\\w)\\1$", input: "ab", want: false},
+		{expr: "^(?=a)\\w+$", input: "abc", want: true},
+		{expr: "^(?=a)\\w+$", input: "bcd", want: false},
+		{expr: "^(?!a)\\w+$", input: "bcd", want: true},
+		{expr: "(?<=\\$)\\d+", input: "$42", want: true},
+		{expr: "(?<=\\$)\\d+", input: "42", want: false},
+		{expr: "(?<!\\$)\\b\\d+", input: "$42", want: false},
+		{expr: "\\bfoo\\b", input: "a foo b", want: true, re2: true},
+		{expr: "\\Bfoo", input: "afoo", want: true, re2: true},
+		{expr: "^a{$", input: "a{", want: true, re2: true},
+		{expr: "^a}$", input: "a}", want: true, re2: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expr+"/"+tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			re, err := Compile(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := re.MatchString(tt.input); got != tt.want {
+				t.Errorf("MatchString(%q) = %t, want %t", tt.input, got, tt.want)
+			}
+			if got := re.IsRE2(); got != tt.re2 {
+				t.Errorf("IsRE2() = %t, want %t", got, tt.re2)
+			}
+		})
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	tests := []string{
+		"(",
+		"a)",
+		"[b-a]",
+		"a**",
+		"*a",
+		"a{2,1}",
+		"\\k<name>",
+		"(?<a>x)(?<a>y)",
+		"\\1",
+		"\\p{Unknown}",
+		"\\u{110000}",
+		"\\c",
+		"\\-",
+		"(?<=a)+",
+	}
+	for _, expr := range tests {
+		expr := expr
+		t.Run(expr, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Compile(expr)
+			var serr *Error
+			if !errors.As(err, &serr) {
+				t.Fatalf("Compile(%q) = %v, want *Error", expr, err)
+			}
+			if serr.Pattern != expr {
+				t.Errorf("Error.Pattern = %q, want %q", serr.Pattern, expr)
+			}
+			if err := Validate(expr); err == nil {
+				t.Errorf("Validate(%q) = nil, want error", expr)
+			}
+		})
+	}
+}
+
+func TestFindStringSubmatch(t *testing.T) {
+	tests := []struct {
+		expr  string
+		input string
+		want  []string
+	}{
+		{expr: "(a)(b)?", input: "xa", want: []string{"a", "a", ""}},
+		{expr: "(\\d+)-(\\d+)", input: "tel: 03-1234", want: []string{"03-1234", "03", "1234"}},
+		{expr: "(z)((a+)?(b+)?(c))*", input: "zaacbbbcac", want: []string{"zaacbbbcac", "z", "ac", "a", "", "c"}},
+		{expr: "(?=(a+))", input: "baaabac", want: []string{"", "aaa"}},
+		{expr: "(a)|b", input: "b", want: []string{"b", ""}},
+		{expr: "(x)", input: "y", want: nil},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+
+			re := MustCompile(tt.expr)
+			if got := re.FindStringSubmatch(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindStringSubmatch(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubexpNames(t *testing.T) {
+	re := MustCompile("(?<first>a)(b)(?<third>c)")
+	want := []string{"", "first", "", "third"}
+	if got := re.SubexpNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SubexpNames() = %q, want %q", got, want)
+	}
+}
+
+func TestFindAllString(t *testing.T) {
+	tests := []struct {
+		expr  string
+		input string
+		n     int
+		want  []string
+	}{
+		{expr: "a+", input: "abaac", n: -1, want: []string{"a", "aa"}},
+		{expr: "a+", input: "abaac", n: 1, want: []string{"a"}},
+		{expr: "(?=b)", input: "abab", n: -1, want: []string{"", ""}},
+		{expr: "(\\w)\\1", input: "aabbcd", n: -1, want: []string{"aa", "bb"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+
+			re := MustCompile(tt.expr)
+			if got := re.FindAllString(tt.input, tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindAllString(%q, %d) = %q, want %q", tt.input, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceAllString(t *testing.T) {
+	tests := []struct {
+		expr string
+		src  string
+		repl string
+		want string
+	}{
+		{expr: "a(x*)b", src: "-ab-axxb-", repl: "$1", want: "--xx-"},
+		{expr: "a(?<x>x*)b", src: "-ab-axxb-", repl: "${x}W", want: "-W-xxW-"},
+		{expr: "(\\w)\\1", src: "aabc", repl: "<$1>", want: "<a>bc"},
+		{expr: "(?<=a)b", src: "abcb", repl: "B", want: "aBcb"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+
+			re := MustCompile(tt.expr)
+			if got := re.ReplaceAllString(tt.src, tt.repl); got != tt.want {
+				t.Errorf("ReplaceAllString(%q, %q) = %q, want %q", tt.src, tt.repl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBacktrackLimit(t *testing.T) {
+	// the back reference forces the backtracking matcher.
+	re := MustCompile("^(a+)+\\1b$", WithBacktrackLimit(1000))
+	input := strings.Repeat("a", 30)
+
+	ok, err := re.MatchStringError(input)
+	if !errors.Is(err, ErrBacktrackLimit) {
+		t.Fatalf("MatchStringError() = %t, %v, want %v", ok, err, ErrBacktrackLimit)
+	}
+	if re.MatchString(input) {
+		t.Error("MatchString() = true after exceeding the backtrack limit")
+	}
+}
+
+func TestMustCompilePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustCompile did not panic")
+		}
+	}()
+	MustCompile("(")
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package uritemplate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zchee/go-jsonschema/pkg/jsonpointer"
+)
+
+// InstanceVars resolves the variables of t from the instance as the JSON Hyper-Schema link does.
+//
+// The attachment is the JSON Pointer of the instance location which the link is attached to.
+// The templatePointers is the value of the "templatePointers" keyword, which maps the variable name to
+// a JSON Pointer or a Relative JSON Pointer evaluated against the attachment.
+// The variable which has no pointer is resolved from the property of the same name of the attachment.
+//
+// The unresolvable variables are omitted from the result, so that they can be supplied by the
+// user input described by the "hrefSchema" keyword.
+//
+// JSON Hyper-Schema, section 7.2:
+//  https://tools.ietf.org/html/draft-handrews-json-schema-hyperschema-02#section-7.2
+func (t *Template) InstanceVars(instance interface{}, attachment string, templatePointers map[string]string) (map[string]interface{}, error) {
+	base, err := jsonpointer.Parse(attachment)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]interface{})
+	for _, name := range t.Varnames() {
+		ptr, ok := templatePointers[name]
+		if !ok {
+			ptr = "0" + jsonpointer.Pointer{name}.String()
+		}
+
+		v, err := resolve(instance, base, ptr)
+		switch err {
+		case nil:
+			vars[name] = v
+		case jsonpointer.ErrNotFound:
+		default:
+			return nil, fmt.Errorf("uritemplate: templatePointers %q of %q: %v", ptr, name, err)
+		}
+	}
+
+	return vars, nil
+}
+
+// ExpandInstance expands t with the variables resolved from the instance by InstanceVars.
+//
+// The input overrides the resolved variables, which corresponds to the user input validated by "hrefSchema".
+func (t *Template) ExpandInstance(instance interface{}, attachment string, templatePointers map[string]string, input map[string]interface{}) (string, error) {
+	vars, err := t.InstanceVars(instance, attachment, templatePointers)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range input {
+		vars[k] = v
+	}
+
+	return t.Expand(vars)
+}
+
+// resolve resolves the ptr JSON Pointer or Relative JSON Pointer against the base location of the instance.
+func resolve(instance interface{}, base jsonpointer.Pointer, ptr string) (interface{}, error) {
+	if ptr == "" || strings.HasPrefix(ptr, "/") {
+		p, err := jsonpointer.Parse(ptr)
+		if err != nil {
+			return nil, err
+		}
+		return p.Get(instance)
+	}
+
+	rel, err := jsonpointer.ParseRelative(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if rel.Up > len(base) {
+		return nil, jsonpointer.ErrNotFound
+	}
+	loc := base[:len(base)-rel.Up]
+	if rel.Key {
+		if len(loc) == 0 {
+			return nil, jsonpointer.ErrNotFound
+		}
+		return loc[len(loc)-1], nil
+	}
+
+	return loc.Append(rel.Pointer...).Get(instance)
+}
@@ -0,0 +1,545 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package uritemplate an implementation of URI Template for Go.
+//
+// RFC 6570:
+//  https://tools.ietf.org/html/rfc6570
+package uritemplate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error represents a syntax error of the URI Template.
+type Error struct {
+	Template string
+	Offset   int
+	Msg      string
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("uritemplate: %s at offset %d of %q", e.Msg, e.Offset, e.Template)
+}
+
+// Template represents a parsed URI Template.
+type Template struct {
+	raw   string
+	parts []part
+}
+
+// part represents a literal or expression part of the Template.
+type part struct {
+	literal string
+	op      *operator
+	specs   []varspec
+}
+
+// varspec represents a variable specifier of the expression.
+type varspec struct {
+	name    string
+	prefix  int
+	explode bool
+}
+
+// operator represents an expression operator and its expansion behavior.
+//
+// RFC 6570, Appendix A:
+//  https://tools.ietf.org/html/rfc6570#appendix-A
+type operator struct {
+	first    string
+	sep      string
+	named    bool
+	ifemp    string
+	reserved bool
+}
+
+var operators = map[byte]*operator{
+	0:   {first: "", sep: ","},
+	'+': {first: "", sep: ",", reserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifemp: "="},
+	'&': {first: "&", sep: "&", named: true, ifemp: "="},
+	'#': {first: "#", sep: ",", reserved: true},
+}
+
+// Parse parses s as a URI Template.
+func Parse(s string) (*Template, error) {
+	t := &Template{raw: s}
+
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return nil, &Error{Template: s, Offset: i, Msg: "unclosed expression"}
+			}
+			p, err := parseExpression(s, i+1, i+end)
+			if err != nil {
+				return nil, err
+			}
+			t.parts = append(t.parts, p)
+			i += end + 1
+
+		case '}':
+			return nil, &Error{Template: s, Offset: i, Msg: "unopened expression"}
+
+		default:
+			j := i
+			for j < len(s) && s[j] != '{' && s[j] != '}' {
+				j++
+			}
+			if err := checkLiteral(s, i, j); err != nil {
+				return nil, err
+			}
+			t.parts = append(t.parts, part{literal: s[i:j]})
+			i = j
+		}
+	}
+
+	return t, nil
+}
+
+// MustParse is like Parse but panics if the s cannot be parsed.
+func MustParse(s string) *Template {
+	t, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+// Validate reports whether s is a syntactically valid URI Template.
+func Validate(s string) error {
+	_, err := Parse(s)
+	return err
+}
+
+// checkLiteral checks whether s[start:end] consists only of the allowed literal characters.
+func checkLiteral(s string, start, end int) error {
+	for i := start; i < end; {
+		r, size := utf8.DecodeRuneInString(s[i:end])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			return &Error{Template: s, Offset: i, Msg: "invalid UTF-8"}
+		case r <= ' ' || r == 0x7f || strings.ContainsRune(`"'<>\^`+"`|", r):
+			return &Error{Template: s, Offset: i, Msg: fmt.Sprintf("invalid literal character %q", r)}
+		case r == '%':
+			if i+2 >= end || !isHex(s[i+1]) || !isHex(s[i+2]) {
+				return &Error{Template: s, Offset: i, Msg: "invalid percent-encoding"}
+			}
+		}
+		i += size
+	}
+
+	return nil
+}
+
+// parseExpression parses the expression of s[start:end].
+func parseExpression(s string, start, end int) (part, error) {
+	expr := s[start:end]
+	if expr == "" {
+		return part{}, &Error{Template: s, Offset: start, Msg: "empty expression"}
+	}
+
+	op := operators[0]
+	switch c := expr[0]; c {
+	case '+', '#', '.', '/', ';', '?', '&':
+		op = operators[c]
+		expr = expr[1:]
+		start++
+	case '=', ',', '!', '@', '|':
+		return part{}, &Error{Template: s, Offset: start, Msg: fmt.Sprintf("reserved operator %q", c)}
+	}
+
+	var specs []varspec
+	for _, v := range strings.Split(expr, ",") {
+		spec, err := parseVarspec(s, start, v)
+		if err != nil {
+			return part{}, err
+		}
+		specs = append(specs, spec)
+		start += len(v) + 1
+	}
+
+	return part{op: op, specs: specs}, nil
+}
+
+// parseVarspec parses v as a varspec which starts at offset of s.
+func parseVarspec(s string, offset int, v string) (varspec, error) {
+	spec := varspec{}
+	switch i := strings.IndexByte(v, ':'); {
+	case strings.HasSuffix(v, "*"):
+		spec.explode = true
+		v = v[:len(v)-1]
+
+	case i >= 0:
+		n := v[i+1:]
+		if n == "" || len(n) > 4 || n[0] == '0' || !isDigits(n) {
+			return spec, &Error{Template: s, Offset: offset + i, Msg: fmt.Sprintf("invalid prefix modifier %q", n)}
+		}
+		spec.prefix, _ = strconv.Atoi(n)
+		v = v[:i]
+	}
+
+	if !isVarname(v) {
+		return spec, &Error{Template: s, Offset: offset, Msg: fmt.Sprintf("invalid variable name %q", v)}
+	}
+	spec.name = v
+
+	return spec, nil
+}
+
+// isVarname reports whether s is a valid varname.
+func isVarname(s string) bool {
+	if s == "" || s[0] == '.' || s[len(s)-1] == '.' {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+		case c == '.':
+			if s[i-1] == '.' {
+				return false
+			}
+		case c == '%':
+			if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+				return false
+			}
+			i += 2
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isDigits reports whether s consists only of ASCII digits.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isHex reports whether c is a hexadecimal digit.
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// String implements fmt.Stringer.
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Varnames returns the list of variable names used in t, in the order of appearance.
+func (t *Template) Varnames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, p := range t.parts {
+		for _, spec := range p.specs {
+			if !seen[spec.name] {
+				seen[spec.name] = true
+				names = append(names, spec.name)
+			}
+		}
+	}
+
+	return names
+}
+
+// Expand expands t with the vars.
+//
+// The value of vars must be a string, a number, a boolean, a list ([]interface{} or []string),
+// an associative array (map[string]interface{} or map[string]string), or nil which means undefined.
+// The keys of associative arrays are expanded in the sorted order.
+func (t *Template) Expand(vars map[string]interface{}) (string, error) {
+	var b strings.Builder
+	for _, p := range t.parts {
+		if p.op == nil {
+			b.WriteString(p.literal)
+			continue
+		}
+		if err := p.expand(&b, vars); err != nil {
+			return "", err
+		}
+	}
+
+	return b.String(), nil
+}
+
+// expand expands the expression p into b.
+func (p part) expand(b *strings.Builder, vars map[string]interface{}) error {
+	op := p.op
+	first := true
+	for _, spec := range p.specs {
+		v, err := normalize(vars[spec.name])
+		if err != nil {
+			return fmt.Errorf("uritemplate: variable %q: %v", spec.name, err)
+		}
+		if v == nil {
+			continue
+		}
+
+		if first {
+			b.WriteString(op.first)
+			first = false
+		} else {
+			b.WriteString(op.sep)
+		}
+
+		switch v := v.(type) {
+		case string:
+			if spec.prefix > 0 {
+				v = truncate(v, spec.prefix)
+			}
+			op.writeNamed(b, spec.name, v)
+
+		case []string:
+			if spec.prefix > 0 {
+				return fmt.Errorf("uritemplate: prefix modifier is not applicable to the composite value %q", spec.name)
+			}
+			op.expandList(b, spec, v)
+
+		case []kv:
+			if spec.prefix > 0 {
+				return fmt.Errorf("uritemplate: prefix modifier is not applicable to the composite value %q", spec.name)
+			}
+			op.expandAssoc(b, spec, v)
+		}
+	}
+
+	return nil
+}
+
+// writeNamed writes the name=value pair if op is named, otherwise writes value only.
+func (op *operator) writeNamed(b *strings.Builder, name, value string) {
+	if op.named {
+		b.WriteString(name)
+		if value == "" {
+			b.WriteString(op.ifemp)
+			return
+		}
+		b.WriteByte('=')
+	}
+	b.WriteString(op.escape(value))
+}
+
+// expandList expands the list value.
+func (op *operator) expandList(b *strings.Builder, spec varspec, list []string) {
+	if !spec.explode {
+		if op.named {
+			b.WriteString(spec.name)
+			b.WriteByte('=')
+		}
+		for i, s := range list {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(op.escape(s))
+		}
+		return
+	}
+
+	for i, s := range list {
+		if i > 0 {
+			b.WriteString(op.sep)
+		}
+		op.writeNamed(b, spec.name, s)
+	}
+}
+
+// expandAssoc expands the associative array value.
+func (op *operator) expandAssoc(b *strings.Builder, spec varspec, pairs []kv) {
+	if !spec.explode {
+		if op.named {
+			b.WriteString(spec.name)
+			b.WriteByte('=')
+		}
+		for i, p := range pairs {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(op.escape(p.key))
+			b.WriteByte(',')
+			b.WriteString(op.escape(p.value))
+		}
+		return
+	}
+
+	for i, p := range pairs {
+		if i > 0 {
+			b.WriteString(op.sep)
+		}
+		b.WriteString(op.escape(p.key))
+		if op.named && p.value == "" {
+			b.WriteString(op.ifemp)
+			continue
+		}
+		b.WriteByte('=')
+		b.WriteString(op.escape(p.value))
+	}
+}
+
+// kv represents a key-value pair of the associative array.
+type kv struct {
+	key   string
+	value string
+}
+
+// normalize normalizes v into a string, []string, []kv or nil.
+func normalize(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+
+	case string:
+		return v, nil
+
+	case []string:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		return v, nil
+
+	case []interface{}:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		list := make([]string, 0, len(v))
+		for _, e := range v {
+			s, err := scalar(e)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, s)
+		}
+		return list, nil
+
+	case map[string]string:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		pairs := make([]kv, 0, len(v))
+		for k, e := range v {
+			pairs = append(pairs, kv{key: k, value: e})
+		}
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].key < pairs[j].key })
+		return pairs, nil
+
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		pairs := make([]kv, 0, len(v))
+		for k, e := range v {
+			s, err := scalar(e)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, kv{key: k, value: s})
+		}
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].key < pairs[j].key })
+		return pairs, nil
+
+	default:
+		return scalar(v)
+	}
+}
+
+// scalar converts the scalar value v into a string.
+func scalar(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	case nil:
+		return "", nil
+	}
+
+	return "", fmt.Errorf("unsupported value type %T", v)
+}
+
+// truncate returns the first n characters of s.
+func truncate(s string, n int) string {
+	i := 0
+	for j := range s {
+		if i == n {
+			return s[:j]
+		}
+		i++
+	}
+
+	return s
+}
+
+const hexDigits = "0123456789ABCDEF"
+
+// escape percent-encodes s. The reserved characters and percent-encoded triplets
+// are kept as-is if op allows the reserved characters.
+func (op *operator) escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isUnreserved(c):
+			b.WriteByte(c)
+			continue
+		case op.reserved && isReserved(c):
+			b.WriteByte(c)
+			continue
+		case op.reserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			b.WriteString(s[i : i+3])
+			i += 2
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&0x0f])
+	}
+
+	return b.String()
+}
+
+// isUnreserved reports whether c is a RFC 3986 unreserved character.
+func isUnreserved(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// isReserved reports whether c is a RFC 3986 reserved character.
+func isReserved(c byte) bool {
+	return strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0
+}
@@ -0,0 +1,201 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package uritemplate
+
+import (
+	"reflect"
+	"testing"
+)
+
+// rfcVars is the variables of the examples of RFC 6570, section 3.2.
+var rfcVars = map[string]interface{}{
+	"count":      []interface{}{"one", "two", "three"},
+	"dom":        []string{"example", "com"},
+	"dub":        "me/too",
+	"hello":      "Hello World!",
+	"half":       "50%",
+	"var":        "value",
+	"who":        "fred",
+	"base":       "http://example.com/home/",
+	"path":       "/foo/bar",
+	"list":       []interface{}{"red", "green", "blue"},
+	"keys":       map[string]interface{}{"semi": ";", "dot": ".", "comma": ","},
+	"v":          "6",
+	"x":          "1024",
+	"y":          "768",
+	"empty":      "",
+	"empty_keys": map[string]interface{}{},
+	"undef":      nil,
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{tmpl: "{var}", want: "value"},
+		{tmpl: "{hello}", want: "Hello%20World%21"},
+		{tmpl: "{half}", want: "50%25"},
+		{tmpl: "O{empty}X", want: "OX"},
+		{tmpl: "O{undef}X", want: "OX"},
+		{tmpl: "{x,y}", want: "1024,768"},
+		{tmpl: "{x,hello,y}", want: "1024,Hello%20World%21,768"},
+		{tmpl: "?{x,empty}", want: "?1024,"},
+		{tmpl: "?{x,undef}", want: "?1024"},
+		{tmpl: "{var:3}", want: "val"},
+		{tmpl: "{var:30}", want: "value"},
+		{tmpl: "{list}", want: "red,green,blue"},
+		{tmpl: "{list*}", want: "red,green,blue"},
+		{tmpl: "{keys}", want: "comma,%2C,dot,.,semi,%3B"},
+		{tmpl: "{keys*}", want: "comma=%2C,dot=.,semi=%3B"},
+		{tmpl: "{+var}", want: "value"},
+		{tmpl: "{+hello}", want: "Hello%20World!"},
+		{tmpl: "{+path}/here", want: "/foo/bar/here"},
+		{tmpl: "{+base}index", want: "http://example.com/home/index"},
+		{tmpl: "{+path:6}/here", want: "/foo/b/here"},
+		{tmpl: "X{#var}", want: "X#value"},
+		{tmpl: "X{#hello}", want: "X#Hello%20World!"},
+		{tmpl: "{#path:6}/here", want: "#/foo/b/here"},
+		{tmpl: "X{.var}", want: "X.value"},
+		{tmpl: "X{.x,y}", want: "X.1024.768"},
+		{tmpl: "www{.dom*}", want: "www.example.com"},
+		{tmpl: "X{.list*}", want: "X.red.green.blue"},
+		{tmpl: "{/var}", want: "/value"},
+		{tmpl: "{/var,x}/here", want: "/value/1024/here"},
+		{tmpl: "{/list*,path:4}", want: "/red/green/blue/%2Ffoo"},
+		{tmpl: "{;x,y}", want: ";x=1024;y=768"},
+		{tmpl: "{;x,y,empty}", want: ";x=1024;y=768;empty"},
+		{tmpl: "{;hello:5}", want: ";hello=Hello"},
+		{tmpl: "{;keys*}", want: ";comma=%2C;dot=.;semi=%3B"},
+		{tmpl: "{?x,y}", want: "?x=1024&y=768"},
+		{tmpl: "{?x,y,empty}", want: "?x=1024&y=768&empty="},
+		{tmpl: "{?list}", want: "?list=red,green,blue"},
+		{tmpl: "{?list*}", want: "?list=red&list=green&list=blue"},
+		{tmpl: "{?empty_keys}", want: ""},
+		{tmpl: "?fixed=yes{&x}", want: "?fixed=yes&x=1024"},
+		{tmpl: "{&var:3}", want: "&var=val"},
+		{tmpl: "{/count}", want: "/one,two,three"},
+		{tmpl: "{/dub}", want: "/me%2Ftoo"},
+		{tmpl: "http://example.com/~{who}", want: "http://example.com/~fred"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.tmpl, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := MustParse(tt.tmpl).Expand(rfcVars)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand(%q) = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"{",
+		"}",
+		"{var",
+		"{}",
+		"{var:0}",
+		"{var:10000}",
+		"{keys*:3}",
+		"{!var}",
+		"{va r}",
+		"{%zz}",
+		"a b",
+		"%zz",
+	}
+	for _, tmpl := range tests {
+		if _, err := Parse(tmpl); err == nil {
+			t.Errorf("Parse(%q) succeeds, want error", tmpl)
+		}
+		if err := Validate(tmpl); err == nil {
+			t.Errorf("Validate(%q) succeeds, want error", tmpl)
+		}
+	}
+}
+
+func TestVarnames(t *testing.T) {
+	got := MustParse("{/a,b}{?c,a}x{#d:3}").Varnames()
+	if want := []string{"a", "b", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Varnames() = %q, want %q", got, want)
+	}
+}
+
+func TestExpandInstance(t *testing.T) {
+	instance := map[string]interface{}{
+		"id": "42",
+		"owner": map[string]interface{}{
+			"name": "alice",
+		},
+		"items": []interface{}{
+			map[string]interface{}{"id": "7"},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		tmpl             string
+		attachment       string
+		templatePointers map[string]string
+		input            map[string]interface{}
+		want             string
+	}{
+		{
+			name: "property of attachment",
+			tmpl: "/things/{id}",
+			want: "/things/42",
+		},
+		{
+			name:             "absolute pointer",
+			tmpl:             "/users/{name}",
+			templatePointers: map[string]string{"name": "/owner/name"},
+			want:             "/users/alice",
+		},
+		{
+			name:             "relative pointer",
+			tmpl:             "/things/{parent}/items/{id}",
+			attachment:       "/items/0",
+			templatePointers: map[string]string{"parent": "2/id"},
+			want:             "/things/42/items/7",
+		},
+		{
+			name:             "relative key",
+			tmpl:             "/items/{index}",
+			attachment:       "/items/0",
+			templatePointers: map[string]string{"index": "0#"},
+			want:             "/items/0",
+		},
+		{
+			name: "unresolvable is omitted",
+			tmpl: "/things{?q}",
+			want: "/things",
+		},
+		{
+			name:  "input overrides",
+			tmpl:  "/things/{id}{?q}",
+			input: map[string]interface{}{"id": "1", "q": "x"},
+			want:  "/things/1?q=x",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := MustParse(tt.tmpl).ExpandInstance(instance, tt.attachment, tt.templatePointers, tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandInstance() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
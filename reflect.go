@@ -0,0 +1,461 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zchee/go-jsonschema/internal/reflection"
+)
+
+// Reflect returns the draft-07 JSON Schema of the Go type of v.
+//
+// The exported struct fields become the "properties" named by their json tags, and the fields without the omitempty
+// option become the "required". The pointer, slice and map fields without the omitempty option, and such elements,
+// also accept null, which encoding/json encodes for their nil values. The fields of the embedded structs are promoted
+// to the embedding struct as the encoding/json does.
+//
+// The slices and arrays become the "items", and the maps become the "additionalProperties". The named types become
+// the "definitions" referred by the "$ref", except the type of v itself which is referred by "#", so the recursive
+// types are supported.
+//
+// The constraints of the fields are given by the jsonschema struct tag, see TagName. Reflect returns an error
+// if the tag is invalid. The types which implement the JSONSchemaer supply their own schemas.
+func Reflect(v interface{}) (*Schema, error) {
+	s := &Schema{}
+	t := reflection.TypeOf(v)
+	if t != nil {
+		r := &reflector{
+			root:  indirectType(t),
+			names: make(map[*reflection.Rtype]string),
+			used:  make(map[string]bool),
+		}
+		if rs := r.inline(r.root); rs != nil {
+			s = rs
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		if len(r.defs) > 0 {
+			s.Definitions = r.defs
+		}
+	}
+	s.Schema = Draft7SchemaURL
+
+	return s, nil
+}
+
+// MustReflect is like Reflect but panics if the jsonschema struct tag is invalid.
+// It simplifies safe initialization of global variables holding the reflected schemas.
+func MustReflect(v interface{}) *Schema {
+	s, err := Reflect(v)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// reflector represents a state of the Reflect.
+type reflector struct {
+	root  *reflection.Rtype
+	names map[*reflection.Rtype]string
+	used  map[string]bool
+	defs  Definitions
+
+	// err is the first error of the jsonschema struct tags.
+	err error
+}
+
+// typeSchema returns the schema of t, which is the "$ref" if t is the named type.
+//
+// typeSchema returns nil if t is not representable in JSON.
+func (r *reflector) typeSchema(t *reflection.Rtype) *Schema {
+	t = indirectType(t)
+	if t == r.root {
+		return &Schema{Ref: "#"}
+	}
+	if s := wellKnownSchema(t); s != nil {
+		return s
+	}
+	if t.Name() == "" || t.PkgPath() == "" {
+		return r.inline(t)
+	}
+
+	name, ok := r.names[t]
+	if !ok {
+		// register the name before inlining, since the schema of t may refer to t itself.
+		name = r.defName(t.Name())
+		r.names[t] = name
+		s := r.inline(t)
+		if s == nil {
+			return nil
+		}
+		if r.defs == nil {
+			r.defs = make(Definitions)
+		}
+		r.defs[name] = s
+	}
+
+	return &Schema{Ref: "#/definitions/" + name}
+}
+
+// defName returns the unused definition name based on name.
+func (r *reflector) defName(name string) string {
+	cand := name
+	for i := 2; r.used[cand]; i++ {
+		cand = name + strconv.Itoa(i)
+	}
+	r.used[cand] = true
+
+	return cand
+}
+
+// inline returns the schema of t without the "$ref" to t itself.
+func (r *reflector) inline(t *reflection.Rtype) *Schema {
+	if s := schemaerSchema(t); s != nil {
+		return s
+	}
+	if s := wellKnownSchema(t); s != nil {
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{BooleanType}}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: Types{IntegerType}}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		min := float64(0)
+		return &Schema{Type: Types{IntegerType}, Minimum: &min}
+
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{NumberType}}
+
+	case reflect.String:
+		return &Schema{Type: Types{StringType}}
+
+	case reflect.Interface:
+		return &Schema{}
+
+	case reflect.Ptr:
+		return r.typeSchema(t)
+
+	case reflect.Slice:
+		if t.ElemType().Kind() == reflect.Uint8 {
+			// encoding/json encodes the []byte as the base64 string.
+			return &Schema{Type: Types{StringType}, ContentEncoding: "base64"}
+		}
+		return r.arraySchema(t)
+
+	case reflect.Array:
+		s := r.arraySchema(t)
+		if s != nil {
+			n := int64(t.Len())
+			s.MinItems = n
+			s.MaxItems = &n
+		}
+		return s
+
+	case reflect.Map:
+		switch t.KeyType().Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			return nil
+		}
+		elem := r.elemSchema(t.ElemType())
+		if elem == nil {
+			return nil
+		}
+		return &Schema{
+			Type:                 Types{ObjectType},
+			AdditionalProperties: &AdditionalProperties{Schema: elem},
+		}
+
+	case reflect.Struct:
+		return r.structSchema(t)
+	}
+
+	// the complex, chan, func and unsafe.Pointer types are not supported by encoding/json.
+	return nil
+}
+
+// arraySchema returns the array schema of the t elements.
+func (r *reflector) arraySchema(t *reflection.Rtype) *Schema {
+	elem := r.elemSchema(t.ElemType())
+	if elem == nil {
+		return nil
+	}
+
+	return &Schema{
+		Type:  Types{ArrayType},
+		Items: &Items{Schemas: SchemaList{elem}},
+	}
+}
+
+// elemSchema returns the schema of the slice, array or map element type t, which accepts null if t is nilable.
+func (r *reflector) elemSchema(t *reflection.Rtype) *Schema {
+	s := r.typeSchema(t)
+	if s != nil && isNilable(t) {
+		s = nullableSchema(s)
+	}
+
+	return s
+}
+
+// structField represents a struct field which is encoded as the object property by encoding/json.
+type structField struct {
+	name  string // property name
+	index []int  // index sequence for reflect.Value.FieldByIndex
+	typ   *reflection.Rtype
+	opts  string // options of the json tag
+	tag   string // jsonschema tag
+	// optional reports whether the field is promoted through the embedded pointer, which may be nil.
+	optional bool
+	// owner is the struct type which declares the field.
+	owner  *reflection.Rtype
+	goName string
+}
+
+// embedded represents an embedded struct type whose fields are promoted.
+type embedded struct {
+	typ      *reflection.Rtype
+	index    []int
+	optional bool
+}
+
+// structFieldsCache caches the structFields by the struct type.
+var structFieldsCache sync.Map // map[*reflection.Rtype][]structField
+
+// structFields returns the fields of the struct type t which are encoded by encoding/json.
+//
+// The fields of the embedded structs are visited in breadth-first order, so the shallower fields
+// take precedence over the deeper ones.
+func structFields(t *reflection.Rtype) []structField {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.([]structField)
+	}
+
+	var fields []structField
+	seen := make(map[string]bool)
+	visited := map[*reflection.Rtype]bool{t: true}
+	level := []embedded{{typ: t}}
+	for len(level) > 0 {
+		var next []embedded
+		for _, e := range level {
+			for i, f := range e.typ.Fields() {
+				tag := reflect.StructTag(f.Name.Tag())
+				name, opts := parseJSONTag(tag.Get("json"))
+				if name == "-" && opts == "" {
+					continue
+				}
+
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+
+				ft := indirectType(f.Type)
+				if f.Name.IsEmbedded() && name == "" && ft.Kind() == reflect.Struct {
+					if !visited[ft] {
+						visited[ft] = true
+						next = append(next, embedded{typ: ft, index: index, optional: e.optional || f.Type.Kind() == reflect.Ptr})
+					}
+					continue
+				}
+				if !f.Name.IsExported() {
+					continue
+				}
+
+				if name == "" {
+					name = f.Name.Name()
+				}
+				if seen[name] {
+					continue
+				}
+				seen[name] = true
+				fields = append(fields, structField{
+					name:     name,
+					index:    index,
+					typ:      f.Type,
+					opts:     opts,
+					tag:      tag.Get(TagName),
+					optional: e.optional,
+					owner:    e.typ,
+					goName:   f.Name.Name(),
+				})
+			}
+		}
+		level = next
+	}
+
+	actual, _ := structFieldsCache.LoadOrStore(t, fields)
+	return actual.([]structField)
+}
+
+// isStringOption reports whether the field is encoded as the JSON string by the string option of the json tag.
+func (f *structField) isStringOption() bool {
+	if !hasJSONOption(f.opts, "string") {
+		return false
+	}
+	switch indirectType(f.typ).Kind() {
+	case reflect.Bool, reflect.Float32, reflect.Float64, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+
+	return false
+}
+
+// structSchema returns the object schema of the struct type t.
+func (r *reflector) structSchema(t *reflection.Rtype) *Schema {
+	s := &Schema{
+		Type:       Types{ObjectType},
+		Properties: make(Properties),
+	}
+
+	for _, f := range structFields(t) {
+		ps := r.typeSchema(f.typ)
+		if ps == nil {
+			continue
+		}
+		if f.isStringOption() {
+			ps = &Schema{Type: Types{StringType}}
+		}
+		required := !f.optional && !hasJSONOption(f.opts, "omitempty")
+		if f.tag != "" {
+			isString := indirectType(f.typ).Kind() == reflect.String || f.isStringOption()
+			tagged, req, err := applyTag(ps, f.tag, required, isString)
+			if err != nil {
+				if r.err == nil {
+					r.err = fmt.Errorf("jsonschema: invalid %s tag of %s.%s: %v", TagName, f.owner.String(), f.goName, err)
+				}
+				continue
+			}
+			ps, required = tagged, req
+		}
+		if isNilable(f.typ) && !hasJSONOption(f.opts, "omitempty") {
+			// encoding/json encodes the nil pointer, slice and map as null.
+			ps = nullableSchema(ps)
+		}
+		s.Properties[f.name] = ps
+		if required {
+			s.Required = append(s.Required, String{Value: f.name, Initialized: true})
+		}
+	}
+
+	return s
+}
+
+// isNilable reports whether the value of t may be nil, except the interface whose schema accepts any value.
+func isNilable(t *reflection.Rtype) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return true
+	}
+
+	return false
+}
+
+// nullableSchema returns the schema which accepts null in addition to s.
+//
+// The null type is added to the "type" of s if it determines the value, otherwise s is wrapped by the "anyOf"
+// with the null schema. The schema which has no keywords is returned as is, since it accepts null already.
+func nullableSchema(s *Schema) *Schema {
+	if len(s.Type) > 0 && s.Ref == "" && s.Enum == nil && s.Const == nil {
+		for _, t := range s.Type {
+			if t == NullType {
+				return s
+			}
+		}
+		ns := *s
+		ns.Type = append(append(Types{}, s.Type...), NullType)
+		return &ns
+	}
+	if b, err := s.MarshalJSON(); err == nil && string(b) == "{}" {
+		return s
+	}
+
+	return &Schema{AnyOf: SchemaList{s, {Type: Types{NullType}}}}
+}
+
+// jsonSchemaerType is the reflect.Type of the JSONSchemaer.
+var jsonSchemaerType = reflect.TypeOf((*JSONSchemaer)(nil)).Elem()
+
+// schemaerSchema returns the schema which the zero value of t supplies as the JSONSchemaer,
+// or nil if neither t nor the pointer to t implements the JSONSchemaer.
+func schemaerSchema(t *reflection.Rtype) *Schema {
+	if t.Kind() == reflect.Interface {
+		return nil
+	}
+	typ := reflection.ToType(t)
+	pv := reflect.New(typ)
+	switch {
+	case typ.Implements(jsonSchemaerType):
+		return pv.Elem().Interface().(JSONSchemaer).JSONSchema()
+	case pv.Type().Implements(jsonSchemaerType):
+		return pv.Interface().(JSONSchemaer).JSONSchema()
+	}
+
+	return nil
+}
+
+// wellKnownSchema returns the schema of the types which have the custom JSON encoding, or nil if t is not such type.
+func wellKnownSchema(t *reflection.Rtype) *Schema {
+	switch t.PkgPath() + "." + t.Name() {
+	case "time.Time":
+		return &Schema{Type: Types{StringType}, Format: FormatDateTime}
+	case "encoding/json.RawMessage", "encoding/json/jsontext.Value":
+		// json.RawMessage is the alias of jsontext.Value with GOEXPERIMENT=jsonv2.
+		return &Schema{}
+	case "encoding/json.Number":
+		return &Schema{Type: Types{NumberType}}
+	}
+
+	return nil
+}
+
+// indirectType returns the type which t points to, dereferencing any number of pointers.
+func indirectType(t *reflection.Rtype) *reflection.Rtype {
+	for t.Kind() == reflect.Ptr {
+		t = t.ElemType()
+	}
+
+	return t
+}
+
+// parseJSONTag splits the json struct tag into the name and the comma-separated options.
+func parseJSONTag(tag string) (name, opts string) {
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+
+	return tag, ""
+}
+
+// hasJSONOption reports whether the comma-separated opts contains the opt.
+func hasJSONOption(opts, opt string) bool {
+	for opts != "" {
+		var cur string
+		if i := strings.IndexByte(opts, ','); i >= 0 {
+			cur, opts = opts[:i], opts[i+1:]
+		} else {
+			cur, opts = opts, ""
+		}
+		if cur == opt {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,277 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zchee/go-jsonschema/internal/lazyregexp"
+)
+
+// TagName is the struct tag key of the constraints which the Go type of the field cannot express.
+//
+// The tag value is the comma-separated list of the "key=value" or the "key" items, for example:
+//  Name string `json:"name" jsonschema:"minLength=3,maxLength=64,pattern=^[a-z]+$"`
+//  Kind string `json:"kind" jsonschema:"enum=a|b|c,default=a,description=The kind of the object."`
+//
+// The keys are the JSON Schema keywords:
+//  title, description, format, pattern, contentEncoding, contentMediaType    string
+//  minLength, maxLength, minItems, maxItems, minProperties, maxProperties   integer
+//  minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf         number
+//  uniqueItems, readOnly, writeOnly                                         boolean, true if the value is omitted
+//  default, const, example                                                  value
+//  enum                                                                     "|" separated values
+//
+// The example key may be repeated. The value is decoded as JSON unless the field is encoded as the JSON string,
+// such as the string kind types and the fields with the string option of the json tag, and is used as the
+// string if it is not valid JSON.
+//
+// The "required" and "optional" keys without the value override the omitempty option of the json tag.
+//
+// The comma, the "=" and the "|" in the enum values are escaped by the backslash. The other backslashes,
+// such as the ones of the pattern, are kept as is.
+const TagName = "jsonschema"
+
+// tagEscapes is the characters which are escaped by the backslash in the jsonschema struct tag.
+const tagEscapes = ",=|"
+
+// JSONSchemaer is the interface implemented by the types that supply their own JSON Schema to Reflect.
+type JSONSchemaer interface {
+	JSONSchema() *Schema
+}
+
+// tagItem represents an item of the jsonschema struct tag.
+type tagItem struct {
+	key      string
+	value    string // escaped
+	hasValue bool
+}
+
+// parseTag parses the jsonschema struct tag into the items.
+func parseTag(tag string) ([]tagItem, error) {
+	var items []tagItem
+	for _, s := range splitEscaped(tag, ',') {
+		if s == "" {
+			continue
+		}
+		item := tagItem{key: s}
+		if i := strings.IndexByte(s, '='); i >= 0 {
+			item = tagItem{key: s[:i], value: s[i+1:], hasValue: true}
+		}
+		if item.key == "" {
+			return nil, fmt.Errorf("missing key of %q", s)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// splitEscaped splits s by the sep which is not escaped by the backslash.
+//
+// The returned strings are still escaped.
+func splitEscaped(s string, sep byte) []string {
+	var ss []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case isEscape(s, i, tagEscapes):
+			i++
+		case s[i] == sep:
+			ss = append(ss, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(ss, s[start:])
+}
+
+// unescape removes the backslashes of s which escape the chars.
+func unescape(s, chars string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if isEscape(s, i, chars) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+// isEscape reports whether s[i] is the backslash which escapes any of the chars.
+func isEscape(s string, i int, chars string) bool {
+	return s[i] == '\\' && i+1 < len(s) && strings.IndexByte(chars, s[i+1]) >= 0
+}
+
+// applyTag applies the jsonschema struct tag to the property schema s.
+//
+// applyTag returns the schema which may be wrapped s, and whether the property is required. The isString
+// reports whether the property is encoded as the JSON string, whose values are used as is.
+func applyTag(s *Schema, tag string, required, isString bool) (*Schema, bool, error) {
+	items, err := parseTag(tag)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// the keywords adjacent to the "$ref" are ignored, so wraps the reference by the "allOf".
+	if s.Ref != "" {
+		for _, item := range items {
+			if item.key != "required" && item.key != "optional" {
+				s = &Schema{AllOf: SchemaList{s}}
+				break
+			}
+		}
+	}
+
+	for _, item := range items {
+		v := unescape(item.value, ",=")
+		switch item.key {
+		case "required", "optional":
+			if item.hasValue {
+				return nil, false, fmt.Errorf("%s does not take the value", item.key)
+			}
+			required = item.key == "required"
+			continue
+
+		case "enum":
+			if !item.hasValue {
+				break
+			}
+			s.Enum = nil
+			for _, ev := range splitEscaped(item.value, '|') {
+				s.Enum = append(s.Enum, NewConst(tagValue(unescape(ev, tagEscapes), isString)))
+			}
+			continue
+
+		case "uniqueItems", "readOnly", "writeOnly":
+			b := true
+			if item.hasValue {
+				if b, err = strconv.ParseBool(v); err != nil {
+					return nil, false, fmt.Errorf("invalid %s: %v", item.key, err)
+				}
+			}
+			switch item.key {
+			case "uniqueItems":
+				s.UniqueItems = b
+			case "readOnly":
+				s.ReadOnly = b
+			case "writeOnly":
+				s.WriteOnly = b
+			}
+			continue
+		}
+
+		if !item.hasValue {
+			return nil, false, fmt.Errorf("missing value of %s", item.key)
+		}
+		if err := applyTagItem(s, item.key, v, isString); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return s, required, nil
+}
+
+// applyTagItem applies the key=value item to s.
+func applyTagItem(s *Schema, key, v string, isString bool) error {
+	switch key {
+	case "title":
+		s.Title = v
+	case "description":
+		s.Description = v
+	case "format":
+		s.Format = Format(v)
+	case "contentEncoding":
+		s.ContentEncoding = v
+	case "contentMediaType":
+		s.ContentMediaType = v
+
+	case "pattern":
+		re, err := lazyregexp.Compile(v, lazyregexp.ECMA262)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", v, err)
+		}
+		s.Pattern = re
+
+	case "minLength", "maxLength", "minItems", "maxItems", "minProperties", "maxProperties":
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid %s: %q is not a non-negative integer", key, v)
+		}
+		switch key {
+		case "minLength":
+			s.MinLength = n
+		case "maxLength":
+			s.MaxLength = &n
+		case "minItems":
+			s.MinItems = n
+		case "maxItems":
+			s.MaxItems = &n
+		case "minProperties":
+			s.MinProperties = n
+		case "maxProperties":
+			s.MaxProperties = &n
+		}
+
+	case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %q is not a number", key, v)
+		}
+		switch key {
+		case "minimum":
+			s.Minimum = &f
+		case "maximum":
+			s.Maximum = &f
+		case "exclusiveMinimum":
+			s.ExclusiveMinimum = &f
+		case "exclusiveMaximum":
+			s.ExclusiveMaximum = &f
+		case "multipleOf":
+			if f <= 0 {
+				return errors.New("invalid multipleOf: must be greater than 0")
+			}
+			s.MultipleOf = f
+		}
+
+	case "default":
+		s.Default = tagValue(v, isString)
+	case "const":
+		s.Const = NewConst(tagValue(v, isString))
+	case "example":
+		s.Examples = append(s.Examples, tagValue(v, isString))
+
+	default:
+		return fmt.Errorf("unknown key %q", key)
+	}
+
+	return nil
+}
+
+// tagValue returns the value of the tag item v.
+//
+// v is decoded as JSON unless isString, and is used as the string if it is not valid JSON.
+func tagValue(v string, isString bool) interface{} {
+	if isString {
+		return v
+	}
+	dec := json.NewDecoder(bytes.NewReader([]byte(v)))
+	dec.UseNumber()
+	var x interface{}
+	if err := dec.Decode(&x); err != nil || dec.More() {
+		return v
+	}
+
+	return x
+}
@@ -0,0 +1,179 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type tagKind string
+
+type tagConstraints struct {
+	Name     string   `json:"name" jsonschema:"minLength=3,maxLength=64,pattern=^[a-z]+$"`
+	Code     string   `json:"code" jsonschema:"pattern=^\\d{3}\\,\\d+$"`
+	Kind     tagKind  `json:"kind" jsonschema:"enum=1|b\\|c|d\\,e,default=1"`
+	Level    int      `json:"level" jsonschema:"enum=1|2|3,minimum=1,exclusiveMaximum=4"`
+	Label    string   `json:"label" jsonschema:"description=a\\, b\\=c,example=x,example=y"`
+	ID       int64    `json:"id,string" jsonschema:"const=42"`
+	Tags     []string `json:"tags" jsonschema:"uniqueItems,minItems=1,optional"`
+	Comment  string   `json:"comment,omitempty" jsonschema:"required,readOnly=false,writeOnly"`
+	Price    float64  `json:"price" jsonschema:"multipleOf=0.01,format=decimal"`
+	Metadata tagKind  `json:"metadata,omitempty" jsonschema:"title=Metadata"`
+}
+
+type tagInvalid struct {
+	Valid   string `json:"valid"`
+	Invalid int    `json:"invalid" jsonschema:"minimum=x"`
+}
+
+func TestReflectTag(t *testing.T) {
+	s, err := Reflect(tagConstraints{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 3, "maxLength": 64, "pattern": "^[a-z]+$"},
+			"code": {"type": "string", "pattern": "^\\d{3},\\d+$"},
+			"kind": {"allOf": [{"$ref": "#/definitions/tagKind"}], "enum": ["1", "b|c", "d,e"], "default": "1"},
+			"level": {"type": "integer", "enum": [1, 2, 3], "minimum": 1, "exclusiveMaximum": 4},
+			"label": {"type": "string", "description": "a, b=c", "examples": ["x", "y"]},
+			"id": {"type": "string", "const": "42"},
+			"tags": {"type": ["array", "null"], "items": {"type": "string"}, "uniqueItems": true, "minItems": 1},
+			"comment": {"type": "string", "writeOnly": true},
+			"price": {"type": "number", "multipleOf": 0.01, "format": "decimal"},
+			"metadata": {"allOf": [{"$ref": "#/definitions/tagKind"}], "title": "Metadata"}
+		},
+		"required": ["name", "code", "kind", "level", "label", "id", "comment", "price"],
+		"definitions": {
+			"tagKind": {"type": "string"}
+		}
+	}`
+	var got, wantv interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantv); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, wantv) {
+		t.Errorf("Reflect() = %s, want %s", b, want)
+	}
+
+	v := MustCompile(s)
+	valid := tagConstraints{Name: "abc", Code: "123,4", Kind: "b|c", Level: 2, ID: 42, Comment: "c", Price: 1.25}
+	if err := v.ValidateValue(valid); err != nil {
+		t.Errorf("ValidateValue(%+v) = %v", valid, err)
+	}
+	invalid := valid
+	invalid.Kind = "1\\"
+	if v.IsValid(mustValue(t, invalid)) {
+		t.Errorf("IsValid(%+v) = true", invalid)
+	}
+}
+
+// mustValue returns the JSON instance of the Go value v.
+func mustValue(tb testing.TB, v interface{}) interface{} {
+	tb.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return mustInstance(tb, string(b))
+}
+
+func TestApplyTag(t *testing.T) {
+	tests := []struct {
+		tag      string
+		isString bool
+		want     string
+		wantReq  bool
+		wantErr  string
+	}{
+		{tag: "enum=1|2", isString: true, want: `{"enum": ["1", "2"]}`, wantReq: true},
+		{tag: "enum=1|2", want: `{"enum": [1, 2]}`, wantReq: true},
+		{tag: "enum=a|true|null", want: `{"enum": ["a", true, null]}`, wantReq: true},
+		{tag: `enum=a\|b|c\d`, isString: true, want: `{"enum": ["a|b", "c\\d"]}`, wantReq: true},
+		{tag: `pattern=^a\|b\.c$`, want: `{"pattern": "^a\\|b\\.c$"}`, wantReq: true},
+		{tag: `pattern=^\w+\,\w+$`, want: `{"pattern": "^\\w+,\\w+$"}`, wantReq: true},
+		{tag: `title=a\=b`, want: `{"title": "a=b"}`, wantReq: true},
+		{tag: "default=[1,2]", wantErr: "missing value of 2]"},
+		{tag: `default=[1\,2]`, want: `{"default": [1, 2]}`, wantReq: true},
+		{tag: `default=[1\,2]`, isString: true, want: `{"default": "[1,2]"}`, wantReq: true},
+		{tag: "optional", want: `{}`},
+		{tag: "optional=true", wantErr: "optional does not take the value"},
+		{tag: "minLength", wantErr: "missing value of minLength"},
+		{tag: "minLength=-1", wantErr: `invalid minLength: "-1" is not a non-negative integer`},
+		{tag: "multipleOf=0", wantErr: "invalid multipleOf: must be greater than 0"},
+		{tag: "uniqueItems=yes", wantErr: "invalid uniqueItems"},
+		{tag: "pattern=(", wantErr: `invalid pattern "("`},
+		{tag: "=a", wantErr: `missing key of "=a"`},
+		{tag: "unknown=1", wantErr: `unknown key "unknown"`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.tag, func(t *testing.T) {
+			t.Parallel()
+
+			s, req, err := applyTag(&Schema{}, tt.tag, true, tt.isString)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("applyTag(%q) error = %v, want %q", tt.tag, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyTag(%q) error = %v", tt.tag, err)
+			}
+			if req != tt.wantReq {
+				t.Errorf("applyTag(%q) required = %t, want %t", tt.tag, req, tt.wantReq)
+			}
+
+			b, err := json.Marshal(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want interface{}
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("applyTag(%q) = %s, want %s", tt.tag, b, tt.want)
+			}
+		})
+	}
+}
+
+func TestReflectInvalidTag(t *testing.T) {
+	s, err := Reflect(tagInvalid{})
+	if err == nil {
+		t.Fatalf("Reflect() = %v, want error", s)
+	}
+	if want := "invalid jsonschema tag of jsonschema.tagInvalid.Invalid"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Reflect() error = %v, want %q", err, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustReflect did not panic")
+		}
+	}()
+	MustReflect(tagInvalid{})
+}
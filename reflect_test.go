@@ -0,0 +1,207 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type reflectBasic struct {
+	Bool    bool              `json:"bool"`
+	Int     int               `json:"int"`
+	Uint    uint8             `json:"uint"`
+	Float   float64           `json:"float"`
+	String  string            `json:"string"`
+	Bytes   []byte            `json:"bytes,omitempty"`
+	Time    time.Time         `json:"time"`
+	Any     interface{}       `json:"any"`
+	Raw     json.RawMessage   `json:"raw"`
+	Skipped string            `json:"-"`
+	Quoted  int               `json:"quoted,string"`
+	Map     map[string]string `json:"map,omitempty"`
+	Array   [2]int            `json:"array"`
+	private string
+}
+
+type reflectNode struct {
+	Value    int            `json:"value"`
+	Children []*reflectNode `json:"children"`
+	Next     *reflectNode   `json:"next,omitempty"`
+}
+
+type reflectBase struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind,omitempty"`
+}
+
+type reflectEmbedding struct {
+	reflectBase
+	*reflectItem
+	Kind int `json:"kind"`
+}
+
+type reflectItem struct {
+	Name string `json:"name"`
+}
+
+type reflectNullable struct {
+	Ptr       *string                 `json:"ptr"`
+	Slice     []int                   `json:"slice"`
+	Map       map[string]int          `json:"map"`
+	Item      *reflectItem            `json:"item"`
+	Anonymous *struct{ A int }        `json:"anonymous"`
+	Items     []reflectItem           `json:"items"`
+	ItemMap   map[string]*reflectItem `json:"itemMap"`
+	Omit      *string                 `json:"omit,omitempty"`
+}
+
+func TestReflect(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{
+			name: "nil",
+			v:    nil,
+			want: `{"$schema": "http://json-schema.org/draft-07/schema#"}`,
+		},
+		{
+			name: "scalar",
+			v:    uint(0),
+			want: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "integer", "minimum": 0}`,
+		},
+		{
+			name: "basic",
+			v:    reflectBasic{},
+			want: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"type": "object",
+				"properties": {
+					"bool": {"type": "boolean"},
+					"int": {"type": "integer"},
+					"uint": {"type": "integer", "minimum": 0},
+					"float": {"type": "number"},
+					"string": {"type": "string"},
+					"bytes": {"type": "string", "contentEncoding": "base64"},
+					"time": {"type": "string", "format": "date-time"},
+					"any": {},
+					"raw": {},
+					"quoted": {"type": "string"},
+					"map": {"type": "object", "additionalProperties": {"type": "string"}},
+					"array": {"type": "array", "items": {"type": "integer"}, "minItems": 2, "maxItems": 2}
+				},
+				"required": ["bool", "int", "uint", "float", "string", "time", "any", "raw", "quoted", "array"]
+			}`,
+		},
+		{
+			name: "recursive",
+			v:    &reflectNode{},
+			want: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"type": "object",
+				"properties": {
+					"value": {"type": "integer"},
+					"children": {"type": ["array", "null"], "items": {"anyOf": [{"$ref": "#"}, {"type": "null"}]}},
+					"next": {"$ref": "#"}
+				},
+				"required": ["value", "children"]
+			}`,
+		},
+		{
+			name: "embedded",
+			v:    reflectEmbedding{},
+			want: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"type": "object",
+				"properties": {
+					"kind": {"type": "integer"},
+					"id": {"type": "string"},
+					"name": {"type": "string"}
+				},
+				"required": ["kind", "id"]
+			}`,
+		},
+		{
+			name: "nullable",
+			v:    reflectNullable{},
+			want: `{
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"type": "object",
+				"properties": {
+					"ptr": {"type": ["string", "null"]},
+					"slice": {"type": ["array", "null"], "items": {"type": "integer"}},
+					"map": {"type": ["object", "null"], "additionalProperties": {"type": "integer"}},
+					"item": {"anyOf": [{"$ref": "#/definitions/reflectItem"}, {"type": "null"}]},
+					"anonymous": {"type": ["object", "null"], "properties": {"A": {"type": "integer"}}, "required": ["A"]},
+					"items": {"type": ["array", "null"], "items": {"$ref": "#/definitions/reflectItem"}},
+					"itemMap": {"type": ["object", "null"], "additionalProperties": {"anyOf": [{"$ref": "#/definitions/reflectItem"}, {"type": "null"}]}},
+					"omit": {"type": "string"}
+				},
+				"required": ["ptr", "slice", "map", "item", "anonymous", "items", "itemMap"],
+				"definitions": {
+					"reflectItem": {"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]}
+				}
+			}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := json.Marshal(MustReflect(tt.v))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want interface{}
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Reflect() = %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestReflectValidatesZeroValue(t *testing.T) {
+	tests := []interface{}{
+		reflectBasic{},
+		reflectNode{},
+		reflectNode{Children: []*reflectNode{{Value: 1}, nil}, Next: &reflectNode{}},
+		reflectEmbedding{},
+		reflectNullable{},
+		reflectNullable{Item: &reflectItem{}, Items: []reflectItem{{}}, ItemMap: map[string]*reflectItem{"a": nil}},
+	}
+	for _, v := range tests {
+		v := v
+		t.Run(reflect.TypeOf(v).Name(), func(t *testing.T) {
+			t.Parallel()
+
+			validator, err := Compile(MustReflect(v))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := validator.ValidateValue(v); err != nil {
+				t.Errorf("ValidateValue(%#v) = %v", v, err)
+			}
+
+			b, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := validator.Validate(mustInstance(t, string(b))); err != nil {
+				t.Errorf("Validate(%s) = %v", b, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"fmt"
+
+	"github.com/zchee/go-jsonschema/internal/lazyregexp"
+	regexpinterface "github.com/zchee/go-jsonschema/pkg/regexp"
+)
+
+// RegexpEngine represents a regular expression engine of the "pattern" and "patternProperties" keywords.
+type RegexpEngine int
+
+// The list of RegexpEngine.
+const (
+	// RegexpRE2 uses the Go regexp package.
+	//
+	// The patterns which are not valid RE2 syntax fail the compilation.
+	RegexpRE2 RegexpEngine = iota
+
+	// RegexpECMA262 uses the ECMA-262 regular expressions, which is required by the JSON Schema specification.
+	//
+	// The patterns which are compatible with RE2 are still matched by the Go regexp package.
+	RegexpECMA262
+)
+
+// String implements fmt.Stringer.
+func (e RegexpEngine) String() string {
+	switch e {
+	case RegexpRE2:
+		return "RE2"
+	case RegexpECMA262:
+		return "ECMA-262"
+	default:
+		return fmt.Sprintf("RegexpEngine(%d)", int(e))
+	}
+}
+
+// dialect returns the lazyregexp.Dialect of the engine.
+func (e RegexpEngine) dialect() lazyregexp.Dialect {
+	if e == RegexpECMA262 {
+		return lazyregexp.ECMA262
+	}
+
+	return lazyregexp.RE2
+}
+
+// compile checks the syntax of the expr, and returns the Regexp which is compiled with the engine on first use.
+func (e RegexpEngine) compile(expr string) (regexpinterface.Regexp, error) {
+	return lazyregexp.Compile(expr, e.dialect())
+}
+
+// CacheStats represents the statistics of the process-wide regexp cache.
+type CacheStats struct {
+	Hits       uint64
+	Misses     uint64
+	Evictions  uint64
+	Entries    int
+	MaxEntries int
+}
+
+// RegexpCacheStats returns the statistics of the process-wide regexp cache.
+//
+// The cache is shared by the "pattern", "patternProperties" and "format": "regex" keywords of all schemas and validators,
+// and is keyed by the pattern source and the RegexpEngine.
+func RegexpCacheStats() CacheStats {
+	st := lazyregexp.DefaultCache.Stats()
+
+	return CacheStats{
+		Hits:       st.Hits,
+		Misses:     st.Misses,
+		Evictions:  st.Evictions,
+		Entries:    st.Entries,
+		MaxEntries: st.MaxEntries,
+	}
+}
+
+// SetRegexpCacheSize sets the maximum number of entries of the process-wide regexp cache.
+//
+// The least recently used entries are evicted over the size. A size less than or equal to 0 disables the caching.
+func SetRegexpCacheSize(n int) {
+	lazyregexp.DefaultCache.SetMaxEntries(n)
+}
+
+// PurgeRegexpCache removes all entries of the process-wide regexp cache.
+func PurgeRegexpCache() {
+	lazyregexp.DefaultCache.Purge()
+}
+
+// matchStringErrorer is implemented by the Regexp which reports the matching error, such as ecma262.ErrBacktrackLimit.
+type matchStringErrorer interface {
+	MatchStringError(s string) (bool, error)
+}
+
+// matchString reports whether s contains any match of re.
+func matchString(re regexpinterface.Regexp, s string) (bool, error) {
+	if m, ok := re.(matchStringErrorer); ok {
+		return m.MatchStringError(s)
+	}
+
+	return re.MatchString(s), nil
+}
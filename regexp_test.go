@@ -0,0 +1,181 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRegexpEngine(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		engine   RegexpEngine
+		instance string
+		want     bool
+		wantErr  bool
+	}{
+		{
+			name:     "RE2 pattern",
+			schema:   `{"pattern": "^[a-z]+$"}`,
+			engine:   RegexpRE2,
+			instance: `"abc"`,
+			want:     true,
+		},
+		{
+			name:    "RE2 rejects lookahead",
+			schema:  `{"pattern": "^(?=a)"}`,
+			engine:  RegexpRE2,
+			wantErr: true,
+		},
+		{
+			name:     "ECMA-262 lookahead",
+			schema:   `{"pattern": "^(?=a)\\w+$"}`,
+			engine:   RegexpECMA262,
+			instance: `"bcd"`,
+			want:     false,
+		},
+		{
+			name:     "ECMA-262 back reference",
+			schema:   `{"pattern": "^(\\w)\\1$"}`,
+			engine:   RegexpECMA262,
+			instance: `"aa"`,
+			want:     true,
+		},
+		{
+			name:     "ECMA-262 \\d is ASCII",
+			schema:   `{"pattern": "^\\d$"}`,
+			engine:   RegexpECMA262,
+			instance: `"٢"`,
+			want:     false,
+		},
+		{
+			name:     "patternProperties",
+			schema:   `{"patternProperties": {"^(?!x)": {"type": "integer"}}}`,
+			engine:   RegexpECMA262,
+			instance: `{"a": 1, "x": "not checked"}`,
+			want:     true,
+		},
+		{
+			name:     "patternProperties mismatch",
+			schema:   `{"patternProperties": {"^(?!x)": {"type": "integer"}}}`,
+			engine:   RegexpECMA262,
+			instance: `{"a": "1"}`,
+			want:     false,
+		},
+		{
+			name:    "RE2 rejects patternProperties",
+			schema:  `{"patternProperties": {"^(?!x)": {}}}`,
+			engine:  RegexpRE2,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			v, err := Compile(mustSchema(t, tt.schema), WithRegexpEngine(tt.engine))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Compile() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := v.IsValid(mustInstance(t, tt.instance)); got != tt.want {
+				t.Errorf("IsValid(%s) = %t, want %t", tt.instance, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodePattern(t *testing.T) {
+	tests := []struct {
+		data    string
+		wantErr bool
+	}{
+		{data: `{"pattern": "^(?<=a)b$"}`},
+		{data: `{"pattern": "(?i)a"}`, wantErr: true},
+		{data: `{"pattern": "("}`, wantErr: true},
+		{data: `{"patternProperties": {"(?<name>a)\\k<name>": {}}}`},
+		{data: `{"patternProperties": {"[b-a]": {}}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.data, func(t *testing.T) {
+			t.Parallel()
+
+			var s Schema
+			err := json.Unmarshal([]byte(tt.data), &s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%s) error = %v, wantErr %t", tt.data, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			b, err := json.Marshal(&s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want interface{}
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.data), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Marshal() = %s, want %s", b, tt.data)
+			}
+		})
+	}
+}
+
+func TestRegexpEngineString(t *testing.T) {
+	tests := []struct {
+		engine RegexpEngine
+		want   string
+	}{
+		{engine: RegexpRE2, want: "RE2"},
+		{engine: RegexpECMA262, want: "ECMA-262"},
+		{engine: RegexpEngine(5), want: "RegexpEngine(5)"},
+	}
+	for _, tt := range tests {
+		if got := tt.engine.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestRegexpCache(t *testing.T) {
+	defer SetRegexpCacheSize(RegexpCacheStats().MaxEntries)
+
+	SetRegexpCacheSize(1)
+	PurgeRegexpCache()
+	before := RegexpCacheStats()
+	if before.Entries != 0 || before.MaxEntries != 1 {
+		t.Fatalf("RegexpCacheStats() = %+v after purge", before)
+	}
+
+	// the same pattern in two schemas is compiled once.
+	for i := 0; i < 2; i++ {
+		v := MustCompile(mustSchema(t, `{"pattern": "^cache-test-[0-9]+$"}`))
+		if !v.IsValid("cache-test-1") {
+			t.Fatal("IsValid() = false")
+		}
+	}
+	st := RegexpCacheStats()
+	if st.Misses-before.Misses != 1 || st.Hits-before.Hits != 1 {
+		t.Errorf("RegexpCacheStats() = %+v, want 1 miss and 1 hit since %+v", st, before)
+	}
+
+	v := MustCompile(mustSchema(t, `{"pattern": "^other-cache-test$"}`))
+	v.IsValid("other-cache-test")
+	if st := RegexpCacheStats(); st.Entries != 1 || st.Evictions == before.Evictions {
+		t.Errorf("RegexpCacheStats() = %+v, want the least recently used entry evicted", st)
+	}
+}
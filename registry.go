@@ -0,0 +1,193 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/zchee/go-jsonschema/pkg/jsonpointer"
+)
+
+// Loader loads the schema document of the absolute uri, which does not have the fragment.
+type Loader func(uri string) (*Schema, error)
+
+// Registry represents a set of the schema documents identified by their absolute URIs,
+// which resolves the "$ref"s to the external schemas.
+//
+// Registry is safe for concurrent use by multiple goroutines.
+type Registry struct {
+	mu     sync.RWMutex
+	loader Loader
+
+	// resources is the map of the absolute URI without the empty fragment to the identified schema resource.
+	resources map[string]*resource
+
+	// docs is the map of the added document to its URI.
+	docs map[*Schema]*url.URL
+}
+
+// resource represents a schema resource identified by the URI, which is the document or a sub schema of it.
+type resource struct {
+	schema *Schema
+	doc    *Schema
+	docURI *url.URL
+	loc    string // JSON Pointer of the schema in the doc
+}
+
+// NewRegistry returns the empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		resources: make(map[string]*resource),
+		docs:      make(map[*Schema]*url.URL),
+	}
+}
+
+// SetLoader sets the Loader which loads the documents not added to r.
+//
+// The loaded documents are added to r.
+func (r *Registry) SetLoader(l Loader) {
+	r.mu.Lock()
+	r.loader = l
+	r.mu.Unlock()
+}
+
+// Add adds the schema document s identified by the absolute uri.
+//
+// The sub schemas of s identified by the "$id" are also added, which are resolved against uri.
+func (r *Registry) Add(uri string, s *Schema) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("jsonschema: invalid URI %q: %v", uri, err)
+	}
+	if !u.IsAbs() {
+		return fmt.Errorf("jsonschema: URI %q is not absolute", uri)
+	}
+	if u.Fragment != "" {
+		return fmt.Errorf("jsonschema: URI %q has the fragment", uri)
+	}
+	u = stripFragment(u)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.docs[s] = u
+	r.resources[u.String()] = &resource{schema: s, doc: s, docURI: u}
+	walkResources(s, u, func(loc string, sub *Schema, base, id *url.URL) {
+		if id != nil {
+			r.resources[id.String()] = &resource{schema: sub, doc: s, docURI: u, loc: loc}
+		}
+	})
+
+	return nil
+}
+
+// Lookup returns the schema identified by the absolute uri, whose fragment is the JSON Pointer or
+// the location-independent identifier.
+//
+// Lookup loads the document by the Loader if it is not added.
+func (r *Registry) Lookup(uri string) (*Schema, bool) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, false
+	}
+	res, ptr, err := r.resolve(u)
+	if err != nil {
+		return nil, false
+	}
+
+	return res.schema.Lookup(ptr)
+}
+
+// resolve returns the resource identified by the absolute u, and the JSON Pointer of the fragment of u
+// which is relative to the resource.
+func (r *Registry) resolve(u *url.URL) (*resource, jsonpointer.Pointer, error) {
+	key := u.String()
+	var ptr jsonpointer.Pointer
+	if u.Fragment == "" || strings.HasPrefix(u.Fragment, "/") {
+		key = stripFragment(u).String()
+		p, err := jsonpointer.Parse(u.Fragment)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid fragment of %q: %v", u, err)
+		}
+		ptr = p
+	}
+
+	r.mu.RLock()
+	res, ok := r.resources[key]
+	loader := r.loader
+	r.mu.RUnlock()
+	if ok {
+		return res, ptr, nil
+	}
+
+	doc := stripFragment(u)
+	if loader == nil || !doc.IsAbs() {
+		return nil, nil, fmt.Errorf("unknown schema %q", doc)
+	}
+	r.mu.RLock()
+	_, loaded := r.resources[doc.String()]
+	r.mu.RUnlock()
+	if !loaded {
+		s, err := loader(doc.String())
+		if err != nil {
+			return nil, nil, fmt.Errorf("load %q: %v", doc, err)
+		}
+		if err := r.Add(doc.String(), s); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	r.mu.RLock()
+	res, ok = r.resources[key]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown schema %q", u)
+	}
+
+	return res, ptr, nil
+}
+
+// uriOf returns the URI which the document s is added with.
+func (r *Registry) uriOf(s *Schema) (*url.URL, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	u, ok := r.docs[s]
+
+	return u, ok
+}
+
+// walkResources calls fn for s and all sub schemas of s like the walk, with the base URI of the schema,
+// and the absolute "$id" URI of the schema resolved against base, or nil if the schema does not have the "$id".
+//
+// The "$id" which has the fragment is the location-independent identifier, which does not change the base URI.
+func walkResources(s *Schema, base *url.URL, fn func(loc string, s *Schema, base, id *url.URL)) {
+	seen := make(map[*Schema]bool)
+	var visit func(loc string, s *Schema, base *url.URL)
+	visit = func(loc string, s *Schema, base *url.URL) {
+		if seen[s] {
+			return
+		}
+		seen[s] = true
+
+		var id *url.URL
+		if s.ID != "" {
+			if u, err := url.Parse(s.ID); err == nil {
+				id = base.ResolveReference(u)
+				if id.Fragment == "" {
+					id = stripFragment(id)
+					base = id
+				}
+			}
+		}
+		fn(loc, s, base, id)
+		for _, sub := range s.subschemas() {
+			visit(appendLocation(loc, sub.tokens...), sub.schema, base)
+		}
+	}
+	visit("", s, base)
+}
@@ -5,64 +5,86 @@
 package jsonschema
 
 import (
-	"regexp"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
 
 	"github.com/francoispqt/gojay"
+
+	"github.com/zchee/go-jsonschema/internal/lazyregexp"
+	regexpinterface "github.com/zchee/go-jsonschema/pkg/regexp"
 )
 
 const (
 	// Draft07SchemaURL contains the JSON Schema draft-07 URL.
 	Draft7SchemaURL = "http://json-schema.org/draft-07/schema#"
+
+	// Draft201909SchemaURL contains the JSON Schema draft 2019-09 URL.
+	Draft201909SchemaURL = "https://json-schema.org/draft/2019-09/schema"
 )
 
 // Schema represents a JSON Schema.
 type Schema struct {
-	Schema               string                     `json:"$schema"`
-	ID                   string                     `json:"$id,omitempty"`
-	Title                string                     `json:"title,omitempty"`
-	Ref                  string                     `json:"$ref,omitempty"`
-	Comment              string                     `json:"$comment,omitempty"`
-	Description          string                     `json:"description,omitempty"`
-	Default              interface{}                `json:"default,omitempty"`
-	ReadOnly             bool                       `json:"readOnly,omitempty"`
-	WriteOnly            bool                       `json:"writeOnly,omitempty"`
-	Examples             SchemaList                 `json:"examples,omitempty"`
-	MultipleOf           float64                    `json:"multipleOf,omitempty"` // exclusiveMinimum is 0
-	Maximum              float64                    `json:"maximum,omitempty"`
-	ExclusiveMaximum     bool                       `json:"exclusiveMaximum,omitempty"`
-	Minimum              float64                    `json:"minimum,omitempty"`
-	ExclusiveMinimum     bool                       `json:"exclusiveMinimum,omitempty"`
-	MaxLength            int64                      `json:"maxLength,omitempty"` // minimum should be 0
-	MinLength            int64                      `json:"minLength,omitempty"` // default should be 0
-	Pattern              *regexp.Regexp             `json:"pattern,omitempty"`
-	AdditionalItems      *AdditionalItems           `json:"additionalItems,omitempty"`
-	Items                *Items                     `json:"items,omitempty"`
-	MaxItems             int64                      `json:"maxItems,omitempty"`
-	MinItems             int64                      `json:"minItems,omitempty"`
-	UniqueItems          bool                       `json:"uniqueItems,omitempty"`
-	Contains             *AdditionalProperties      `json:"contains,omitempty"`
-	MaxProperties        int64                      `json:"maxProperties,omitempty"`
-	MinProperties        int64                      `json:"minProperties,omitempty"`
-	Required             StringArray                `json:"required,omitempty"`
-	AdditionalProperties *AdditionalProperties      `json:"additionalProperties,omitempty"`
-	Definitions          Definitions                `json:"definitions,omitempty"`
-	Properties           Properties                 `json:"properties,omitempty"`
-	PatternProperties    map[*regexp.Regexp]*Schema `json:"patternProperties,omitempty"`
-	Dependencies         *DependencyMap             `json:"dependencies,omitempty"`
-	PropertyNames        *Schema                    `json:"propertyNames,omitempty"`
-	Const                *Const                     `json:"const,omitempty"`
-	Enum                 Enum                       `json:"enum,omitempty"`
-	Type                 Type                       `json:"type,omitempty"`
-	Format               Format                     `json:"format,omitempty"`
-	ContentMediaType     string                     `json:"contentMediaType,omitempty"`
-	ContentEncoding      string                     `json:"contentEncoding,omitempty"`
-	If                   *Schema                    `json:"if,omitempty"`
-	Then                 *Schema                    `json:"then,omitempty"`
-	Else                 *Schema                    `json:"else,omitempty"`
-	AllOf                SchemaList                 `json:"allOf,omitempty"`
-	AnyOf                SchemaList                 `json:"anyOf,omitempty"`
-	OneOf                SchemaList                 `json:"oneOf,omitempty"`
-	Not                  *Schema                    `json:"not,omitempty"`
+	// Bool is the value of the boolean schema. A nil Bool means the Schema is the schema object.
+	//
+	// The true schema always passes the validation, and the false schema always fails.
+	Bool *bool `json:"-"`
+
+	Schema               string                 `json:"$schema"`
+	ID                   string                 `json:"$id,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Comment              string                 `json:"$comment,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	ReadOnly             bool                   `json:"readOnly,omitempty"`
+	WriteOnly            bool                   `json:"writeOnly,omitempty"`
+	Examples             Interfaces             `json:"examples,omitempty"`
+	MultipleOf           float64                `json:"multipleOf,omitempty"` // exclusiveMinimum is 0
+	Maximum              *float64               `json:"maximum,omitempty"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum,omitempty"`
+	MaxLength            *int64                 `json:"maxLength,omitempty"` // minimum should be 0
+	MinLength            int64                  `json:"minLength,omitempty"` // default should be 0
+	Pattern              regexpinterface.Regexp `json:"pattern,omitempty"`
+	AdditionalItems      *AdditionalItems       `json:"additionalItems,omitempty"`
+	Items                *Items                 `json:"items,omitempty"`
+	MaxItems             *int64                 `json:"maxItems,omitempty"`
+	MinItems             int64                  `json:"minItems,omitempty"`
+	UniqueItems          bool                   `json:"uniqueItems,omitempty"`
+	Contains             *AdditionalProperties  `json:"contains,omitempty"`
+	MaxProperties        *int64                 `json:"maxProperties,omitempty"`
+	MinProperties        int64                  `json:"minProperties,omitempty"`
+	Required             StringArray            `json:"required,omitempty"`
+	AdditionalProperties *AdditionalProperties  `json:"additionalProperties,omitempty"`
+	Definitions          Definitions            `json:"definitions,omitempty"`
+	Defs                 Definitions            `json:"$defs,omitempty"` // New in draft 2019-09
+	Properties           Properties             `json:"properties,omitempty"`
+	PatternProperties    PatternProperties      `json:"patternProperties,omitempty"`
+	Dependencies         *DependencyMap         `json:"dependencies,omitempty"`
+	PropertyNames        *Schema                `json:"propertyNames,omitempty"`
+	Const                *Const                 `json:"const,omitempty"`
+	Enum                 Enum                   `json:"enum,omitempty"`
+	Type                 Types                  `json:"type,omitempty"`
+	Format               Format                 `json:"format,omitempty"`
+	ContentMediaType     string                 `json:"contentMediaType,omitempty"`
+	ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+	If                   *Schema                `json:"if,omitempty"`
+	Then                 *Schema                `json:"then,omitempty"`
+	Else                 *Schema                `json:"else,omitempty"`
+	AllOf                SchemaList             `json:"allOf,omitempty"`
+	AnyOf                SchemaList             `json:"anyOf,omitempty"`
+	OneOf                SchemaList             `json:"oneOf,omitempty"`
+	Not                  *Schema                `json:"not,omitempty"`
+
+	// exclusiveMaximum and exclusiveMinimum are the draft-04 boolean "exclusiveMaximum" and "exclusiveMinimum",
+	// which make the "maximum" and the "minimum" exclusive.
+	exclusiveMaximum bool
+	exclusiveMinimum bool
 }
 
 // Version implements Schema.
@@ -77,64 +99,118 @@ var (
 	_ Pooler = &Schema{}
 )
 
+// BoolSchema returns the boolean schema of b.
+func BoolSchema(b bool) *Schema {
+	return &Schema{Bool: &b}
+}
+
 // MarshalJSON implements json.Marshaler.
 func (d Schema) MarshalJSON() ([]byte, error) {
+	if d.Bool != nil {
+		return []byte(strconv.FormatBool(*d.Bool)), nil
+	}
+
 	return gojay.MarshalJSONObject(&d)
 }
 
 // UnmarshalJSON implements json.Unmarshaler.
+//
+// The returned error is *SchemaError which has the location of the invalid keyword.
 func (d *Schema) UnmarshalJSON(data []byte) error {
-	return gojay.Unsafe.UnmarshalJSONObject(data, d)
+	switch b := bytes.TrimSpace(data); {
+	case bytes.Equal(b, []byte("true")), bytes.Equal(b, []byte("false")):
+		v := b[0] == 't'
+		d.Bool = &v
+		return nil
+	case len(b) == 0 || b[0] != '{':
+		return &SchemaError{Err: fmt.Errorf("schema must be an object or a boolean")}
+	}
+
+	return gojay.UnmarshalJSONObject(data, d)
 }
 
 // MarshalJSONObject implements gojay.MarshalerJSONObject.
+//
+// The false boolean schema is encoded as the equivalent {"not": {}} object.
 func (d *Schema) MarshalJSONObject(enc *gojay.Encoder) {
-	enc.StringKey(keySchema, d.Schema)
+	if d.Bool != nil {
+		if !*d.Bool {
+			enc.ObjectKey(keyNot, &Schema{})
+		}
+		return
+	}
+
+	enc.StringKeyOmitEmpty(keySchema, d.Schema)
 	enc.StringKeyOmitEmpty(keyID, d.ID)
 	enc.StringKeyOmitEmpty(keyTitle, d.Title)
 	enc.StringKeyOmitEmpty(keyRef, d.Ref)
 	enc.StringKeyOmitEmpty(keyComment, d.Comment)
 	enc.StringKeyOmitEmpty(keyDescription, d.Description)
-	// enc.AddInterfaceKeyOmitEmpty(keyDefault, &d.Default)
+	if d.Default != nil {
+		encodeValueKey(enc, keyDefault, d.Default)
+	}
 	enc.BoolKeyOmitEmpty(keyReadOnly, d.ReadOnly)
 	enc.BoolKeyOmitEmpty(keyWriteOnly, d.WriteOnly)
 	enc.ArrayKeyOmitEmpty(keyExamples, &d.Examples)
 	enc.Float64KeyOmitEmpty(keyMultipleOf, d.MultipleOf)
-	enc.Float64KeyOmitEmpty(keyMaximum, d.Maximum)
-	enc.BoolKeyOmitEmpty(keyExclusiveMaximum, d.ExclusiveMaximum)
-	enc.Float64KeyOmitEmpty(keyMinimum, d.Minimum)
-	enc.BoolKeyOmitEmpty(keyExclusiveMinimum, d.ExclusiveMinimum)
-	enc.Int64KeyOmitEmpty(keyMaxLength, d.MaxLength)
+	encodeFloat64Key(enc, keyMaximum, d.Maximum)
+	encodeFloat64Key(enc, keyExclusiveMaximum, d.ExclusiveMaximum)
+	encodeFloat64Key(enc, keyMinimum, d.Minimum)
+	encodeFloat64Key(enc, keyExclusiveMinimum, d.ExclusiveMinimum)
+	encodeInt64Key(enc, keyMaxLength, d.MaxLength)
 	enc.Int64KeyOmitEmpty(keyMinLength, d.MinLength)
-	// enc.AddInterfaceKeyOmitEmpty(keyPattern, &d.Pattern)
-	enc.ObjectKeyOmitEmpty(keyAdditionalItems, d.AdditionalItems)
-	enc.ObjectKeyOmitEmpty(keyItems, d.Items)
-	enc.Int64KeyOmitEmpty(keyMaxItems, d.MaxItems)
+	if d.Pattern != nil {
+		enc.StringKey(keyPattern, d.Pattern.String())
+	}
+	if d.AdditionalItems != nil {
+		encodeSchemaKey(enc, keyAdditionalItems, d.AdditionalItems.Schema)
+	}
+	if d.Items != nil {
+		if d.Items.HasMultiple {
+			enc.ArrayKey(keyItems, &d.Items.Schemas)
+		} else if len(d.Items.Schemas) > 0 {
+			encodeSchemaKey(enc, keyItems, d.Items.Schemas[0])
+		}
+	}
+	encodeInt64Key(enc, keyMaxItems, d.MaxItems)
 	enc.Int64KeyOmitEmpty(keyMinItems, d.MinItems)
 	enc.BoolKeyOmitEmpty(keyUniqueItems, d.UniqueItems)
-	enc.ObjectKeyOmitEmpty(keyContains, d.Contains)
-	enc.Int64KeyOmitEmpty(keyMaxProperties, d.MaxProperties)
+	if d.Contains != nil {
+		encodeSchemaKey(enc, keyContains, d.Contains.Schema)
+	}
+	encodeInt64Key(enc, keyMaxProperties, d.MaxProperties)
 	enc.Int64KeyOmitEmpty(keyMinProperties, d.MinProperties)
 	enc.ArrayKeyOmitEmpty(keyRequired, &d.Required)
-	enc.ObjectKeyOmitEmpty(keyAdditionalProperties, d.AdditionalProperties)
+	if d.AdditionalProperties != nil {
+		encodeSchemaKey(enc, keyAdditionalProperties, d.AdditionalProperties.Schema)
+	}
 	enc.ObjectKeyOmitEmpty(keyDefinitions, d.Definitions)
+	enc.ObjectKeyOmitEmpty(keyDefs, d.Defs)
 	enc.ObjectKeyOmitEmpty(keyProperties, d.Properties)
-	// enc.ObjectKeyOmitEmpty(keyPatternProperties, d.PatternProperties)
+	enc.ObjectKeyOmitEmpty(keyPatternProperties, d.PatternProperties)
 	enc.ObjectKeyOmitEmpty(keyDependencies, d.Dependencies)
-	enc.ObjectKeyOmitEmpty(keyPropertyNames, d.PropertyNames)
-	enc.ObjectKeyOmitEmpty(keyConst, d.Const)
+	encodeSchemaKey(enc, keyPropertyNames, d.PropertyNames)
+	if d.Const != nil {
+		encodeValueKey(enc, keyConst, d.Const.Interface())
+	}
 	enc.ArrayKeyOmitEmpty(keyEnum, &d.Enum)
-	enc.IntKeyOmitEmpty(keyType, *(*int)(&d.Type))
+	switch len(d.Type) {
+	case 0:
+	case 1:
+		enc.StringKey(keyType, d.Type[0].String())
+	default:
+		enc.ArrayKey(keyType, &d.Type)
+	}
 	enc.StringKeyOmitEmpty(keyFormat, *(*string)(&d.Format))
 	enc.StringKeyOmitEmpty(keyContentMediaType, d.ContentMediaType)
 	enc.StringKeyOmitEmpty(keyContentEncoding, d.ContentEncoding)
-	enc.ObjectKeyOmitEmpty(keyIf, d.If)
-	enc.ObjectKeyOmitEmpty(keyThen, d.Then)
-	enc.ObjectKeyOmitEmpty(keyElse, d.Else)
+	encodeSchemaKey(enc, keyIf, d.If)
+	encodeSchemaKey(enc, keyThen, d.Then)
+	encodeSchemaKey(enc, keyElse, d.Else)
 	enc.ArrayKeyOmitEmpty(keyAllOf, &d.AllOf)
 	enc.ArrayKeyOmitEmpty(keyAnyOf, &d.AnyOf)
 	enc.ArrayKeyOmitEmpty(keyOneOf, &d.OneOf)
-	enc.ObjectKeyOmitEmpty(keyNot, d.Not)
+	encodeSchemaKey(enc, keyNot, d.Not)
 }
 
 // IsNil implements gojay.MarshalerJSONObject.
@@ -145,7 +221,18 @@ func (d *Schema) IsNil() bool {
 }
 
 // UnmarshalJSONObject implements gojay.UnmarshalerJSONObject.
+//
+// The returned error is *SchemaError which has the location of the invalid keyword.
 func (d *Schema) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
+	if err := d.unmarshalKey(dec, k); err != nil {
+		return schemaError(err, k)
+	}
+
+	return nil
+}
+
+// unmarshalKey decodes the value of the k keyword.
+func (d *Schema) unmarshalKey(dec *gojay.Decoder, k string) error {
 	switch k {
 	case keySchema:
 		// o := StringPool.Get().(*String)
@@ -202,8 +289,12 @@ func (d *Schema) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
 		return dec.String(&d.Description)
 
 	case keyDefault:
-		iface := d.Default.(interface{})
-		return dec.Interface(&iface)
+		v, err := decodeValue(dec)
+		if err != nil {
+			return err
+		}
+		d.Default = v
+		return nil
 
 	case keyReadOnly:
 		// o := BooleanPool.Get().(*Boolean)
@@ -224,6 +315,7 @@ func (d *Schema) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
 		return dec.Bool(&d.WriteOnly)
 
 	case keyExamples:
+		d.Examples = nil
 		return dec.Array(&d.Examples)
 
 	case keyMultipleOf:
@@ -233,52 +325,38 @@ func (d *Schema) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
 		// 	d.MultipleOf = *o
 		// }
 		// return err
-		dec.Float64(&d.MultipleOf)
+		return decodeNumber(dec, &d.MultipleOf)
 
 	case keyMaximum:
-		// o := NumberPool.Get().(*Number)
-		// err := dec.Object(o)
-		// if err == nil {
-		// 	d.Maximum = *o
-		// }
-		// return err
-		dec.Float64(&d.Maximum)
+		if err := decodeFloat64(dec, &d.Maximum); err != nil {
+			return err
+		}
+		d.Maximum, d.ExclusiveMaximum = exclusiveBound(d.exclusiveMaximum, d.Maximum, d.ExclusiveMaximum)
+		return nil
 
 	case keyExclusiveMaximum:
-		// o := BooleanPool.Get().(*Boolean)
-		// err := dec.Object(o)
-		// if err == nil {
-		// 	d.ExclusiveMaximum = *o
-		// }
-		// return err
-		return dec.Bool(&d.ExclusiveMaximum)
+		if err := decodeExclusive(dec, &d.ExclusiveMaximum, &d.exclusiveMaximum); err != nil {
+			return err
+		}
+		d.Maximum, d.ExclusiveMaximum = exclusiveBound(d.exclusiveMaximum, d.Maximum, d.ExclusiveMaximum)
+		return nil
 
 	case keyMinimum:
-		// o := NumberPool.Get().(*Number)
-		// err := dec.Object(o)
-		// if err == nil {
-		// 	d.Minimum = *o
-		// }
-		// return err
-		dec.Float64(&d.Minimum)
+		if err := decodeFloat64(dec, &d.Minimum); err != nil {
+			return err
+		}
+		d.Minimum, d.ExclusiveMinimum = exclusiveBound(d.exclusiveMinimum, d.Minimum, d.ExclusiveMinimum)
+		return nil
 
 	case keyExclusiveMinimum:
-		// o := BooleanPool.Get().(*Boolean)
-		// err := dec.Object(o)
-		// if err == nil {
-		// 	d.ExclusiveMinimum = *o
-		// }
-		// return err
-		return dec.Bool(&d.ExclusiveMinimum)
+		if err := decodeExclusive(dec, &d.ExclusiveMinimum, &d.exclusiveMinimum); err != nil {
+			return err
+		}
+		d.Minimum, d.ExclusiveMinimum = exclusiveBound(d.exclusiveMinimum, d.Minimum, d.ExclusiveMinimum)
+		return nil
 
 	case keyMaxLength:
-		// o := IntegerPool.Get().(*Integer)
-		// err := dec.Object(o)
-		// if err == nil {
-		// 	d.MaxLength = *o
-		// }
-		// return err
-		dec.Int64(&d.MaxLength)
+		return decodeInt64(dec, &d.MaxLength)
 
 	case keyMinLength:
 		// o := IntegerPool.Get().(*Integer)
@@ -287,32 +365,50 @@ func (d *Schema) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
 		// 	d.MinLength = *o
 		// }
 		// return err
-		dec.Int64(&d.MinLength)
+		return decodeInteger(dec, &d.MinLength)
 
 	case keyPattern:
-		// re := d.Pattern.(regexpinterface.Regexp)
-		// return dec.Interface(&re)
+		var expr string
+		if err := dec.String(&expr); err != nil {
+			return err
+		}
+		re, err := lazyregexp.Compile(expr, lazyregexp.ECMA262)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", expr, err)
+		}
+		d.Pattern = re
+		return nil
 
 	case keyAdditionalItems:
-		if d.AdditionalItems == nil {
-			d.AdditionalItems = &AdditionalItems{Schema: new(Schema)}
+		s, err := decodeSchema(dec)
+		if err != nil {
+			return err
 		}
-		return dec.Object(d.AdditionalItems)
+		d.AdditionalItems = &AdditionalItems{Schema: s}
+		return nil
 
 	case keyItems:
-		if d.Items == nil {
-			d.Items = &Items{}
+		raw, err := decodeRaw(dec)
+		if err != nil {
+			return err
+		}
+		if b := bytes.TrimSpace(raw); len(b) > 0 && b[0] == '[' {
+			var ss SchemaList
+			if err := gojay.UnmarshalJSONArray(b, &ss); err != nil {
+				return err
+			}
+			d.Items = &Items{Schemas: ss, HasMultiple: true}
+			return nil
 		}
-		return dec.Object(d.Items)
+		s, err := unmarshalSchema(raw)
+		if err != nil {
+			return err
+		}
+		d.Items = &Items{Schemas: SchemaList{s}}
+		return nil
 
 	case keyMaxItems:
-		// o := IntegerPool.Get().(*Integer)
-		// err := dec.Object(o)
-		// if err == nil {
-		// 	d.MaxItems = *o
-		// }
-		// return err
-		dec.Int64(&d.MaxItems)
+		return decodeInt64(dec, &d.MaxItems)
 
 	case keyMinItems:
 		// o := IntegerPool.Get().(*Integer)
@@ -321,7 +417,7 @@ func (d *Schema) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
 		// 	d.MinItems = *o
 		// }
 		// return err
-		dec.Int64(&d.MinItems)
+		return decodeInteger(dec, &d.MinItems)
 
 	case keyUniqueItems:
 		// o := BooleanPool.Get().(*Boolean)
@@ -333,19 +429,15 @@ func (d *Schema) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
 		return dec.Bool(&d.UniqueItems)
 
 	case keyContains:
-		if d.Contains == nil {
-			d.Contains = &AdditionalProperties{Schema: new(Schema)}
+		s, err := decodeSchema(dec)
+		if err != nil {
+			return err
 		}
-		return dec.Object(d.Contains)
+		d.Contains = &AdditionalProperties{Schema: s}
+		return nil
 
 	case keyMaxProperties:
-		// o := IntegerPool.Get().(*Integer)
-		// err := dec.Object(o)
-		// if err == nil {
-		// 	d.MaxProperties = *o
-		// }
-		// return err
-		dec.Int64(&d.MaxProperties)
+		return decodeInt64(dec, &d.MaxProperties)
 
 	case keyMinProperties:
 		// o := IntegerPool.Get().(*Integer)
@@ -354,39 +446,42 @@ func (d *Schema) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
 		// 	d.MinProperties = *o
 		// }
 		// return err
-		dec.Int64(&d.MinProperties)
+		return decodeInteger(dec, &d.MinProperties)
 
 	case keyRequired:
-		// o := StringArrayPool.Get().(*StringArray)
-		// err := dec.Array(o)
-		// if err == nil {
-		// 	d.Required = *o
-		// }
-		// return err
-		dec.Array(&d.Required)
+		return dec.Array(&d.Required)
 
 	case keyAdditionalProperties:
-		if d.AdditionalProperties == nil {
-			d.AdditionalProperties = &AdditionalProperties{Schema: new(Schema)}
+		s, err := decodeSchema(dec)
+		if err != nil {
+			return err
 		}
-		return dec.Object(d.AdditionalProperties)
+		d.AdditionalProperties = &AdditionalProperties{Schema: s}
+		return nil
 
 	case keyDefinitions:
-		// return dec.Object(&d.Definitions)
+		if d.Definitions == nil {
+			d.Definitions = make(Definitions)
+		}
+		return dec.Object(d.Definitions)
+
+	case keyDefs:
+		if d.Defs == nil {
+			d.Defs = make(Definitions)
+		}
+		return dec.Object(d.Defs)
 
 	case keyProperties:
-		// if d.Properties == nil {
-		// 	d.Properties = make(Properties)
-		// }
-		// return dec.Object(d.Properties)
+		if d.Properties == nil {
+			d.Properties = make(Properties)
+		}
+		return dec.Object(d.Properties)
 
 	case keyPatternProperties:
-		// if d.PatternProperties == nil {
-		// 	d.PatternProperties = PatternProperties{
-		// 		&lazyregexp.Regexp{}: &Draft7{},
-		// 	}
-		// }
-		// return dec.Object(d.PatternProperties)
+		if d.PatternProperties == nil {
+			d.PatternProperties = make(PatternProperties)
+		}
+		return dec.Object(d.PatternProperties)
 
 	case keyDependencies:
 		if d.Dependencies == nil {
@@ -395,42 +490,34 @@ func (d *Schema) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
 		return dec.Object(d.Dependencies)
 
 	case keyPropertyNames:
-		if d.PropertyNames == nil {
-			d.PropertyNames = &Schema{}
-		}
-		o := SchemaPool.Get().(*Schema)
-		err := dec.Object(o)
-		if err == nil {
-			d.PropertyNames = o
-		}
-		return err
+		return decodeSchemaTo(dec, &d.PropertyNames)
 
 	case keyConst:
-		if d.Const == nil {
-			d.Const = &Const{}
-		}
-		o := ConstPool.Get().(*Const)
-		err := dec.Object(o)
-		if err == nil {
-			d.Const = o
+		v, err := decodeValue(dec)
+		if err != nil {
+			return err
 		}
-		return err
+		d.Const = NewConst(v)
+		return nil
 
 	case keyEnum:
-		o := EnumPool.Get().(*Enum)
-		err := dec.Array(o)
-		if err == nil {
-			d.Enum = *o
-		}
-		return err
+		d.Enum = nil
+		return dec.Array(&d.Enum)
 
 	case keyType:
-		t := int(d.Type)
-		return dec.Int(&t)
+		var v interface{}
+		if err := dec.Interface(&v); err != nil {
+			return err
+		}
+		ts, err := typesFromInterface(v)
+		if err != nil {
+			return err
+		}
+		d.Type = ts
+		return nil
 
 	case keyFormat:
-		f := string(d.Format)
-		return dec.String(&f)
+		return dec.String((*string)(&d.Format))
 
 	case keyContentMediaType:
 		// o := StringPool.Get().(*String)
@@ -439,7 +526,7 @@ func (d *Schema) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
 		// 	d.ContentMediaType = *o
 		// }
 		// return err
-		dec.String(&d.ContentMediaType)
+		return dec.String(&d.ContentMediaType)
 
 	case keyContentEncoding:
 		// o := StringPool.Get().(*String)
@@ -448,60 +535,28 @@ func (d *Schema) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
 		// 	d.ContentEncoding = *o
 		// }
 		// return err
-		dec.String(&d.ContentEncoding)
+		return dec.String(&d.ContentEncoding)
 
 	case keyIf:
-		if d.If == nil {
-			d.If = &Schema{}
-		}
-		o := SchemaPool.Get().(*Schema)
-		err := dec.Object(o)
-		if err == nil {
-			d.If = o
-		}
-		return err
+		return decodeSchemaTo(dec, &d.If)
 
 	case keyThen:
-		if d.Then == nil {
-			d.Then = &Schema{}
-		}
-		o := SchemaPool.Get().(*Schema)
-		err := dec.Object(o)
-		if err == nil {
-			d.Then = o
-		}
-		return err
+		return decodeSchemaTo(dec, &d.Then)
 
 	case keyElse:
-		if d.Else == nil {
-			d.Else = &Schema{}
-		}
-		o := SchemaPool.Get().(*Schema)
-		err := dec.Object(o)
-		if err == nil {
-			d.Else = o
-		}
-		return err
+		return decodeSchemaTo(dec, &d.Else)
 
 	case keyAllOf:
-		// return dec.Array(d.AllOf)
+		return dec.Array(&d.AllOf)
 
 	case keyAnyOf:
-		// return dec.Array(d.AnyOf)
+		return dec.Array(&d.AnyOf)
 
 	case keyOneOf:
-		// return dec.Array(d.OneOf)
+		return dec.Array(&d.OneOf)
 
 	case keyNot:
-		if d.Not == nil {
-			d.Not = &Schema{}
-		}
-		o := SchemaPool.Get().(*Schema)
-		err := dec.Object(o)
-		if err == nil {
-			d.Not = o
-		}
-		return err
+		return decodeSchemaTo(dec, &d.Not)
 	}
 
 	return nil
@@ -510,7 +565,7 @@ func (d *Schema) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
 // NKeys implements gojay.UnmarshalerJSONObject.
 //
 // NKeys returns the number of keys to unmarshal.
-func (*Schema) NKeys() int { return 46 }
+func (*Schema) NKeys() int { return 47 }
 
 // Reset implements Pooler.
 //
@@ -631,7 +686,7 @@ func (d *Schema) Reset() {
 	// d.Enum.Reset()
 	// EnumPool.Put(&d.Enum)
 
-	// d.Type = 0
+	// d.Type = nil
 
 	// d.Format = ""
 
@@ -681,6 +736,259 @@ func (d *Schema) Reset() {
 	// }
 }
 
+// encodeFloat64Key encodes the key and value if v is not nil.
+func encodeFloat64Key(enc *gojay.Encoder, key string, v *float64) {
+	if v != nil {
+		enc.Float64Key(key, *v)
+	}
+}
+
+// encodeInt64Key encodes the key and value if v is not nil.
+func encodeInt64Key(enc *gojay.Encoder, key string, v *int64) {
+	if v != nil {
+		enc.Int64Key(key, *v)
+	}
+}
+
+// encodeValueKey encodes the key and the arbitrary JSON value v.
+func encodeValueKey(enc *gojay.Encoder, key string, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		// lets gojay report the unsupported type of v.
+		enc.AddInterfaceKey(key, v)
+		return
+	}
+	raw := gojay.EmbeddedJSON(b)
+	enc.AddEmbeddedJSONKey(key, &raw)
+}
+
+// encodeValue encodes the arbitrary JSON value v as the array element.
+func encodeValue(enc *gojay.Encoder, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		// lets gojay report the unsupported type of v.
+		enc.AddInterface(v)
+		return
+	}
+	raw := gojay.EmbeddedJSON(b)
+	enc.AddEmbeddedJSON(&raw)
+}
+
+// decodeValue decodes the arbitrary JSON value, whose numbers are decoded as json.Number.
+func decodeValue(dec *gojay.Decoder) (interface{}, error) {
+	raw, err := decodeRaw(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalValue(raw)
+}
+
+// decodeRaw decodes the raw JSON value.
+//
+// gojay returns the data preceding the cursor instead of an error if no value is found, such as at the end of
+// the truncated input, which would be decoded again and again, so the raw value must be valid JSON.
+func decodeRaw(dec *gojay.Decoder) (gojay.EmbeddedJSON, error) {
+	var raw gojay.EmbeddedJSON
+	if err := dec.EmbeddedJSON(&raw); err != nil {
+		return nil, err
+	}
+	if !json.Valid(raw) {
+		return nil, errors.New("invalid JSON value")
+	}
+
+	return raw, nil
+}
+
+// unmarshalValue unmarshals the arbitrary JSON value, whose numbers are decoded as json.Number.
+func unmarshalValue(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// SchemaError represents an error of the Schema decoding.
+type SchemaError struct {
+	// Location is the JSON Pointer of the invalid keyword.
+	Location string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error.
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s: %v", instanceLocationString(e.Location), e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *SchemaError) Unwrap() error { return e.Err }
+
+// schemaError returns the *SchemaError of err, whose location is prefixed by the tokens.
+func schemaError(err error, tokens ...string) error {
+	loc := appendLocation("", tokens...)
+	if se, ok := err.(*SchemaError); ok {
+		return &SchemaError{Location: loc + se.Location, Err: se.Err}
+	}
+
+	return &SchemaError{Location: loc, Err: err}
+}
+
+// encodeSchemaKey encodes the key and s, or the boolean schema value, if s is not nil.
+func encodeSchemaKey(enc *gojay.Encoder, key string, s *Schema) {
+	switch {
+	case s == nil:
+	case s.Bool != nil:
+		enc.BoolKey(key, *s.Bool)
+	default:
+		enc.ObjectKey(key, s)
+	}
+}
+
+// encodeSchema encodes s, or the boolean schema value, as the array element.
+func encodeSchema(enc *gojay.Encoder, s *Schema) {
+	if s != nil && s.Bool != nil {
+		enc.Bool(*s.Bool)
+		return
+	}
+	enc.Object(s)
+}
+
+// decodeSchema decodes the schema object or the boolean schema.
+func decodeSchema(dec *gojay.Decoder) (*Schema, error) {
+	raw, err := decodeRaw(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalSchema(raw)
+}
+
+// decodeSchemaTo decodes the schema object or the boolean schema to s.
+func decodeSchemaTo(dec *gojay.Decoder, s **Schema) error {
+	v, err := decodeSchema(dec)
+	if err != nil {
+		return err
+	}
+	*s = v
+
+	return nil
+}
+
+// unmarshalSchema unmarshals the schema object or the boolean schema.
+func unmarshalSchema(data []byte) (*Schema, error) {
+	s := new(Schema)
+	if err := s.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// decodeFloat64 decodes the float64 value to v.
+func decodeFloat64(dec *gojay.Decoder, v **float64) error {
+	var f float64
+	if err := decodeNumber(dec, &f); err != nil {
+		return err
+	}
+	*v = &f
+
+	return nil
+}
+
+// decodeInt64 decodes the int64 value to v.
+func decodeInt64(dec *gojay.Decoder, v **int64) error {
+	var i int64
+	if err := decodeInteger(dec, &i); err != nil {
+		return err
+	}
+	*v = &i
+
+	return nil
+}
+
+// decodeNumber decodes the JSON number to f.
+//
+// The number is parsed by the strconv since gojay cannot decode the exponent of more than two digits,
+// such as 1e308, and decodes the other types of the values as zero.
+func decodeNumber(dec *gojay.Decoder, f *float64) error {
+	raw, err := decodeRaw(dec)
+	if err != nil {
+		return err
+	}
+
+	return parseNumber(raw, f)
+}
+
+// parseNumber parses the raw JSON number to f.
+func parseNumber(raw []byte, f *float64) error {
+	b := bytes.TrimSpace(raw)
+	if len(b) == 0 || (b[0] != '-' && (b[0] < '0' || b[0] > '9')) {
+		return fmt.Errorf("expected number, but got %s", b)
+	}
+	v, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return err
+	}
+	*f = v
+
+	return nil
+}
+
+// decodeInteger decodes the JSON number which is an integer, such as 1 or 1.0, to i.
+func decodeInteger(dec *gojay.Decoder, i *int64) error {
+	var f float64
+	if err := decodeNumber(dec, &f); err != nil {
+		return err
+	}
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return fmt.Errorf("expected integer, but got %v", f)
+	}
+	*i = int64(f)
+
+	return nil
+}
+
+// decodeExclusive decodes the number of the "exclusiveMaximum" or the "exclusiveMinimum" to v,
+// or the draft-04 boolean to b.
+func decodeExclusive(dec *gojay.Decoder, v **float64, b *bool) error {
+	raw, err := decodeRaw(dec)
+	if err != nil {
+		return err
+	}
+	switch string(bytes.TrimSpace(raw)) {
+	case "true":
+		*b = true
+		return nil
+	case "false":
+		*b = false
+		return nil
+	}
+
+	var f float64
+	if err := parseNumber(raw, &f); err != nil {
+		return err
+	}
+	*v = &f
+
+	return nil
+}
+
+// exclusiveBound returns the bound and the exclusive bound, where the bound becomes the exclusive bound
+// if exclusive is the draft-04 boolean true.
+func exclusiveBound(exclusive bool, bound, exclusiveBound *float64) (*float64, *float64) {
+	if exclusive && bound != nil {
+		return nil, bound
+	}
+
+	return bound, exclusiveBound
+}
+
 // SchemaStream represents a stream encoding and decoding to Draft7.
 type SchemaStream chan *Schema
 
@@ -726,7 +1034,7 @@ var (
 // MarshalJSONArray implements gojay.MarshalerJSONArray.
 func (s *SchemaList) MarshalJSONArray(enc *gojay.Encoder) {
 	for _, e := range *s {
-		enc.Object(e)
+		encodeSchema(enc, e)
 	}
 }
 
@@ -739,9 +1047,9 @@ func (s *SchemaList) IsNil() bool {
 
 // UnmarshalJSONArray implements gojay.UnmarshalerJSONArray.
 func (s *SchemaList) UnmarshalJSONArray(dec *gojay.Decoder) error {
-	o := SchemaPool.Get().(*Schema)
-	if err := dec.Object(o); err != nil {
-		return err
+	o, err := decodeSchema(dec)
+	if err != nil {
+		return schemaError(err, strconv.Itoa(len(*s)))
 	}
 	*s = append(*s, o)
 
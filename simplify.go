@@ -0,0 +1,400 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Simplify returns the copy of s which accepts the same instances with less keywords, and the list of
+// the simplifications as the Annotations whose KeywordLocations are the JSON Pointers in s, in the order of them.
+//
+// Simplify replaces the schemas which accept nothing with the false schema, removes the "anyOf" and the "oneOf"
+// branches which accept nothing and the "allOf" members which accept everything, collapses the "if" whose condition
+// is trivially true or false, and drops the "type" implied by the "const" or the "enum". The schemas which contain
+// the targets of the "$ref"s or the "$id"s are not removed, and the "$ref"s are not followed.
+//
+// Simplify returns an error if a "$ref" of s cannot be resolved within s.
+func Simplify(s *Schema) (*Schema, []*Annotation, error) {
+	d, err := newDereferencer(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sp := &simplifier{locs: d.locs, pinned: make(map[string]bool)}
+	for _, t := range d.targets {
+		sp.pin(d.locs[t])
+	}
+	walk(s, func(loc string, sub *Schema) bool {
+		if sub.ID != "" && loc != "" {
+			sp.pin(loc)
+		}
+		return true
+	})
+
+	simple := sp.simplify(s)
+	sort.SliceStable(sp.notes, func(i, j int) bool {
+		return sp.notes[i].KeywordLocation < sp.notes[j].KeywordLocation
+	})
+
+	return simple, sp.notes, nil
+}
+
+// WithSimplify makes the Compile compile the Simplify of the Schema to speed up the validation.
+//
+// The KeywordLocations of the ValidationErrors are the JSON Pointers in the simplified Schema, which is returned by
+// the Validator.Schema. The Schema is compiled as is if it cannot be simplified.
+func WithSimplify() Option {
+	return func(v *Validator) {
+		if s, _, err := Simplify(v.root); err == nil {
+			v.root = s
+		}
+	}
+}
+
+// simplifier represents a state of the Simplify.
+type simplifier struct {
+	// locs is the map of the original schema to its JSON Pointer location in the root.
+	locs map[*Schema]string
+
+	// pinned is the set of the locations which contain the targets of the "$ref"s or the "$id"s.
+	pinned map[string]bool
+
+	notes []*Annotation
+}
+
+// pin marks loc and all its ancestors as pinned.
+func (sp *simplifier) pin(loc string) {
+	for {
+		sp.pinned[loc] = true
+		i := strings.LastIndex(loc, "/")
+		if i < 0 {
+			return
+		}
+		loc = loc[:i]
+	}
+}
+
+// removable reports whether the original schema x can be removed from the root.
+func (sp *simplifier) removable(x *Schema) bool {
+	return !sp.pinned[sp.locs[x]]
+}
+
+// replaceable reports whether the original schema x can be replaced by the equivalent schema at the same location,
+// that is, none of its sub schemas is pinned.
+func (sp *simplifier) replaceable(x *Schema) bool {
+	loc := sp.locs[x]
+	for l := range sp.pinned {
+		if strings.HasPrefix(l, loc+"/") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// listRemovable reports whether the members of the original list can be removed, that is, none of them is pinned
+// since the removal shifts the locations of the following members.
+func (sp *simplifier) listRemovable(list SchemaList) bool {
+	for _, sub := range list {
+		if !sp.removable(sub) {
+			return false
+		}
+	}
+
+	return len(list) > 0
+}
+
+// noteRemoved records the removal of the members of the key list of the original schema x which accept nothing.
+func (sp *simplifier) noteRemoved(x *Schema, key string, list SchemaList) {
+	for i, sub := range list {
+		if acceptsNothing(sub) {
+			sp.note(x, key, key+" branch accepts nothing", key, strconv.Itoa(i))
+		}
+	}
+}
+
+// note records the simplification of the keyword of the original schema x.
+//
+// The note replaces the note of the same location that the sub schema accepts nothing, which is redundant.
+func (sp *simplifier) note(x *Schema, keyword, msg string, tokens ...string) {
+	loc := appendLocation(sp.locs[x], tokens...)
+	for i, n := range sp.notes {
+		if n.KeywordLocation == loc && n.Keyword == "" {
+			sp.notes = append(sp.notes[:i], sp.notes[i+1:]...)
+			break
+		}
+	}
+	sp.notes = append(sp.notes, &Annotation{KeywordLocation: loc, Keyword: keyword, Message: msg})
+}
+
+// simplify returns the simplified copy of the original schema x.
+func (sp *simplifier) simplify(x *Schema) *Schema {
+	if x.Bool != nil {
+		return x
+	}
+
+	c := x.mapSubschemas(sp.simplify)
+	if x.Ref != "" {
+		// the keywords adjacent to the "$ref" are ignored.
+		return c
+	}
+
+	if sp.listRemovable(x.AllOf) {
+		var members SchemaList
+		for i, sub := range c.AllOf {
+			if acceptsEverything(sub) {
+				sp.note(x, keyAllOf, "allOf member accepts everything", keyAllOf, strconv.Itoa(i))
+				continue
+			}
+			members = append(members, sub)
+		}
+		c.AllOf = members
+	}
+
+	if sp.listRemovable(x.AnyOf) {
+		everything := false
+		var branches SchemaList
+		for _, sub := range c.AnyOf {
+			everything = everything || acceptsEverything(sub)
+			if !acceptsNothing(sub) {
+				branches = append(branches, sub)
+			}
+		}
+		switch {
+		case everything:
+			sp.note(x, keyAnyOf, "anyOf has the branch which accepts everything", keyAnyOf)
+			c.AnyOf = nil
+		case len(branches) > 0 && len(branches) < len(c.AnyOf):
+			sp.noteRemoved(x, keyAnyOf, c.AnyOf)
+			c.AnyOf = branches
+		}
+	}
+
+	if sp.listRemovable(x.OneOf) {
+		var branches SchemaList
+		for _, sub := range c.OneOf {
+			if !acceptsNothing(sub) {
+				branches = append(branches, sub)
+			}
+		}
+		if len(branches) > 0 && len(branches) < len(c.OneOf) {
+			sp.noteRemoved(x, keyOneOf, c.OneOf)
+			c.OneOf = branches
+		}
+	}
+
+	if x.If != nil && sp.removable(x.If) && (x.Then == nil || sp.removable(x.Then)) && (x.Else == nil || sp.removable(x.Else)) {
+		collapse := true
+		var branch *Schema
+		switch {
+		case acceptsEverything(c.If):
+			sp.note(x, keyIf, "if condition accepts everything", keyIf)
+			branch = c.Then
+		case acceptsNothing(c.If):
+			sp.note(x, keyIf, "if condition accepts nothing", keyIf)
+			branch = c.Else
+		case c.Then == nil && c.Else == nil:
+			sp.note(x, keyIf, "if has neither then nor else", keyIf)
+		default:
+			collapse = false
+		}
+		if collapse {
+			c.If, c.Then, c.Else = nil, nil, nil
+			if branch != nil {
+				c.AllOf = append(c.AllOf, branch)
+			}
+		}
+	}
+
+	if len(c.Type) > 0 {
+		switch {
+		case c.Const != nil && typeAccepts(c.Type, instanceType(c.Const.Interface())):
+			sp.note(x, keyType, "type is implied by const", keyType)
+			c.Type = nil
+		case len(c.Enum) > 0 && enumHasTypes(c.Enum, c.Type):
+			sp.note(x, keyType, "type is implied by enum", keyType)
+			c.Type = nil
+		}
+	}
+
+	if acceptsNothing(c) && sp.replaceable(x) {
+		if c.Bool == nil {
+			sp.note(x, "", "schema accepts nothing")
+		}
+		return BoolSchema(false)
+	}
+
+	return c
+}
+
+// enumHasTypes reports whether all the values of enum are the instances of types.
+func enumHasTypes(enum Enum, types Types) bool {
+	for _, c := range enum {
+		if !typeAccepts(types, instanceType(c.Interface())) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// typeAccepts reports whether the instance of the it type is accepted by types.
+func typeAccepts(types Types, it Type) bool {
+	for _, t := range types {
+		if t == it || (t == NumberType && it == IntegerType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// annotationOnly is the Schema which has only the annotation and the identification keywords of x.
+func annotationOnly(x *Schema) Schema {
+	return Schema{
+		Schema:      x.Schema,
+		ID:          x.ID,
+		Title:       x.Title,
+		Comment:     x.Comment,
+		Description: x.Description,
+		Default:     x.Default,
+		ReadOnly:    x.ReadOnly,
+		WriteOnly:   x.WriteOnly,
+		Examples:    x.Examples,
+		Definitions: x.Definitions,
+		Defs:        x.Defs,
+	}
+}
+
+// acceptsEverything reports whether x obviously accepts every instance.
+func acceptsEverything(x *Schema) bool {
+	if x.Bool != nil {
+		return *x.Bool
+	}
+
+	return reflect.DeepEqual(*x, annotationOnly(x))
+}
+
+// acceptsNothing reports whether x obviously accepts no instance.
+func acceptsNothing(x *Schema) bool {
+	switch {
+	case x.Bool != nil:
+		return !*x.Bool
+	case x.Ref != "":
+		return false
+	case x.Not != nil && acceptsEverything(x.Not):
+		return true
+	case x.Enum != nil && len(x.Enum) == 0:
+		return true
+	}
+	for _, sub := range x.AllOf {
+		if acceptsNothing(sub) {
+			return true
+		}
+	}
+	if x.AnyOf != nil && allAcceptNothing(x.AnyOf) {
+		return true
+	}
+	if x.OneOf != nil && allAcceptNothing(x.OneOf) {
+		return true
+	}
+
+	types := map[Type]bool{}
+	if x.Type == nil {
+		for _, t := range []Type{NullType, BooleanType, ObjectType, ArrayType, NumberType, IntegerType, StringType} {
+			types[t] = true
+		}
+	}
+	for _, t := range x.Type {
+		types[t] = true
+		if t == NumberType {
+			types[IntegerType] = true
+		}
+	}
+	if !numbersSatisfiable(x) {
+		delete(types, NumberType)
+		delete(types, IntegerType)
+	}
+	if x.MaxLength != nil && x.MinLength > *x.MaxLength {
+		delete(types, StringType)
+	}
+	if x.MaxItems != nil && x.MinItems > *x.MaxItems {
+		delete(types, ArrayType)
+	}
+	if !objectsSatisfiable(x) {
+		delete(types, ObjectType)
+	}
+
+	if x.Const != nil && !types[instanceType(x.Const.Interface())] {
+		return true
+	}
+	if x.Enum != nil {
+		for _, c := range x.Enum {
+			if types[instanceType(c.Interface())] {
+				return false
+			}
+		}
+		return true
+	}
+
+	return len(types) == 0
+}
+
+// allAcceptNothing reports whether all of the schemas obviously accept no instance.
+func allAcceptNothing(schemas SchemaList) bool {
+	for _, sub := range schemas {
+		if !acceptsNothing(sub) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// numbersSatisfiable reports whether the numeric bounds of x can be satisfied by some number.
+func numbersSatisfiable(x *Schema) bool {
+	lo, loExclusive := x.Minimum, false
+	if x.ExclusiveMinimum != nil && (lo == nil || *x.ExclusiveMinimum >= *lo) {
+		lo, loExclusive = x.ExclusiveMinimum, true
+	}
+	hi, hiExclusive := x.Maximum, false
+	if x.ExclusiveMaximum != nil && (hi == nil || *x.ExclusiveMaximum <= *hi) {
+		hi, hiExclusive = x.ExclusiveMaximum, true
+	}
+	if lo == nil || hi == nil {
+		return true
+	}
+
+	return *lo < *hi || (*lo == *hi && !loExclusive && !hiExclusive)
+}
+
+// objectsSatisfiable reports whether the object bounds and the required properties of x can be satisfied by
+// some object.
+func objectsSatisfiable(x *Schema) bool {
+	if x.MaxProperties != nil && x.MinProperties > *x.MaxProperties {
+		return false
+	}
+
+	names := map[string]bool{}
+	for _, r := range x.Required {
+		names[r.Value] = true
+		if p, ok := x.Properties[r.Value]; ok {
+			if acceptsNothing(p) {
+				return false
+			}
+			continue
+		}
+		if len(x.PatternProperties) == 0 && x.AdditionalProperties != nil && x.AdditionalProperties.Schema != nil &&
+			acceptsNothing(x.AdditionalProperties.Schema) {
+			return false
+		}
+	}
+
+	return x.MaxProperties == nil || int64(len(names)) <= *x.MaxProperties
+}
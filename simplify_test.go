@@ -0,0 +1,142 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    string
+		want      string
+		notes     []string
+		instances []string
+	}{
+		{
+			name:      "already simple",
+			schema:    `{"type": "object", "properties": {"a": {"type": "string"}}}`,
+			want:      `{"type": "object", "properties": {"a": {"type": "string"}}}`,
+			instances: []string{`{"a": "x"}`, `{"a": 1}`},
+		},
+		{
+			name:      "anyOf branches",
+			schema:    `{"anyOf": [{"type": "string"}, {"minimum": 2, "maximum": 1, "type": "number"}, {"type": "array", "minItems": 2, "maxItems": 1}]}`,
+			want:      `{"anyOf": [{"type": "string"}]}`,
+			notes:     []string{"/anyOf/1: anyOf branch accepts nothing", "/anyOf/2: anyOf branch accepts nothing"},
+			instances: []string{`"a"`, `1.5`, `null`},
+		},
+		{
+			name:      "anyOf everything",
+			schema:    `{"anyOf": [{"type": "string"}, {"title": "any"}]}`,
+			want:      `{}`,
+			notes:     []string{"/anyOf: anyOf has the branch which accepts everything"},
+			instances: []string{`"a"`, `1`},
+		},
+		{
+			name:      "oneOf and allOf",
+			schema:    `{"oneOf": [{"type": "integer"}, false], "allOf": [true, {"minimum": 0}, {}]}`,
+			want:      `{"oneOf": [{"type": "integer"}], "allOf": [{"minimum": 0}]}`,
+			notes:     []string{"/allOf/0: allOf member accepts everything", "/allOf/2: allOf member accepts everything", "/oneOf/1: oneOf branch accepts nothing"},
+			instances: []string{`1`, `-1`, `1.5`},
+		},
+		{
+			name:      "if",
+			schema:    `{"properties": {"a": {"if": true, "then": {"type": "string"}, "else": {"type": "null"}}, "b": {"if": {"type": "string"}}}}`,
+			want:      `{"properties": {"a": {"allOf": [{"type": "string"}]}, "b": {}}}`,
+			notes:     []string{"/properties/a/if: if condition accepts everything", "/properties/b/if: if has neither then nor else"},
+			instances: []string{`{"a": "x", "b": 1}`, `{"a": null}`},
+		},
+		{
+			name:      "type implied",
+			schema:    `{"properties": {"a": {"type": "string", "const": "x"}, "b": {"type": ["integer", "null"], "enum": [1, null]}}}`,
+			want:      `{"properties": {"a": {"const": "x"}, "b": {"enum": [1, null]}}}`,
+			notes:     []string{"/properties/a/type: type is implied by const", "/properties/b/type: type is implied by enum"},
+			instances: []string{`{"a": "x", "b": null}`, `{"a": "y"}`, `{"b": 2}`},
+		},
+		{
+			name:      "accepts nothing",
+			schema:    `{"properties": {"a": {"type": "string", "minLength": 3, "maxLength": 2}, "b": {"type": "object", "required": ["c"], "properties": {"c": false}}}}`,
+			want:      `{"properties": {"a": false, "b": false}}`,
+			notes:     []string{"/properties/a: schema accepts nothing", "/properties/b: schema accepts nothing"},
+			instances: []string{`{}`, `{"a": "ab"}`, `{"b": {}}`},
+		},
+		{
+			name:      "pinned by $ref",
+			schema:    `{"anyOf": [{"type": "string"}, {"not": {}}], "properties": {"a": {"$ref": "#/anyOf/1"}}}`,
+			want:      `{"anyOf": [{"type": "string"}, false], "properties": {"a": {"$ref": "#/anyOf/1"}}}`,
+			notes:     []string{"/anyOf/1: schema accepts nothing"},
+			instances: []string{`"a"`, `{"a": 1}`},
+		},
+		{
+			name:      "pinned by $id",
+			schema:    `{"allOf": [{"$id": "http://example.com/a"}]}`,
+			want:      `{"allOf": [{"$id": "http://example.com/a"}]}`,
+			instances: []string{`1`},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := mustSchema(t, tt.schema)
+			before := mustJSON(t, s)
+			got, notes, err := Simplify(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want interface{}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if g := mustJSON(t, got); !reflect.DeepEqual(g, want) {
+				b, _ := json.Marshal(g)
+				t.Errorf("Simplify() = %s, want %s", b, tt.want)
+			}
+			var gotNotes []string
+			for _, n := range notes {
+				gotNotes = append(gotNotes, n.String())
+			}
+			if !reflect.DeepEqual(gotNotes, tt.notes) {
+				t.Errorf("Simplify() notes = %q, want %q", gotNotes, tt.notes)
+			}
+			if after := mustJSON(t, s); !reflect.DeepEqual(after, before) {
+				t.Errorf("Simplify() modified the schema: %v, want %v", after, before)
+			}
+
+			simple := MustCompile(got)
+			orig := MustCompile(mustSchema(t, tt.schema))
+			for _, inst := range tt.instances {
+				v := mustInstance(t, inst)
+				if g, w := simple.IsValid(v), orig.IsValid(v); g != w {
+					t.Errorf("Simplify().IsValid(%s) = %t, want %t", inst, g, w)
+				}
+			}
+		})
+	}
+
+	if _, _, err := Simplify(mustSchema(t, `{"$ref": "#/definitions/missing"}`)); err == nil {
+		t.Error("Simplify() error = nil, want unresolvable $ref")
+	}
+}
+
+func TestWithSimplify(t *testing.T) {
+	v := MustCompile(mustSchema(t, `{"anyOf": [{"type": "string"}, false]}`), WithSimplify())
+	if got := mustJSON(t, v.Schema()); !reflect.DeepEqual(got, map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"type": "string"}}}) {
+		t.Errorf("Schema() = %v, want the simplified schema", got)
+	}
+	if !v.IsValid("a") || v.IsValid(1.0) {
+		t.Error("the simplified schema validates differently")
+	}
+
+	// the schema which cannot be simplified is compiled as is.
+	if _, err := Compile(mustSchema(t, `{"$ref": "#/definitions/missing"}`), WithSimplify()); err == nil {
+		t.Error("Compile() error = nil, want unresolvable $ref")
+	}
+}
@@ -0,0 +1,350 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// suiteDir is the directory of the vendored JSON-Schema-Test-Suite.
+//
+// The fixtures are copied from https://github.com/json-schema-org/JSON-Schema-Test-Suite, and only the
+// drafts of suiteDrafts and the remotes referenced by them are kept.
+const suiteDir = "testdata/JSON-Schema-Test-Suite"
+
+// suiteDrafts is the list of the drafts run by the TestSuite.
+var suiteDrafts = []string{"draft4", "draft6", "draft7", "draft2019-09"}
+
+// suiteRemotes is the list of the URI prefixes of the remote schemas, and the paths served by the suiteHandler.
+//
+// The prefixes are matched in order, so the meta-schema documents precede the prefix of their directory.
+var suiteRemotes = []struct {
+	prefix string
+	path   string
+}{
+	{"http://localhost:1234/", "/remotes/"},
+	{"http://json-schema.org/draft-04/schema", "/api/draft-04/schema.json"},
+	{"http://json-schema.org/draft-06/schema", "/api/draft-06/schema.json"},
+	{"http://json-schema.org/draft-07/schema", "/api/draft-07/schema.json"},
+	{"https://json-schema.org/draft/2019-09/schema", "/api/2019-09/schema.json"},
+	{"https://json-schema.org/draft/2019-09/", "/api/2019-09/"},
+}
+
+// suiteSkips is the map of the known failures of the TestSuite to the reasons.
+//
+// The key is the path of the case, which is the draft, the file, and the descriptions of the group and the test
+// joined by "/", or the prefix of the paths. The cases are still run, and only the failed ones are skipped.
+// The TestSuite fails if all cases of the key pass, so the list does not hide the fixed cases.
+var suiteSkips = map[string]string{
+	"draft4/optional/id.json":                                                               skipDraft04ID,
+	"draft4/optional/zeroTerminatedFloats.json":                                             "the number with zero fractional part is an integer as in draft-06 and later",
+	"draft4/ref.json/$ref prevents a sibling id from changing the base uri":                 skipDraft04ID,
+	"draft4/ref.json/Recursive references between schemas":                                  skipDraft04ID,
+	"draft4/ref.json/Location-independent identifier":                                       skipDraft04ID,
+	"draft4/ref.json/Location-independent identifier with base URI change in subschema":     skipDraft04ID,
+	"draft4/ref.json/id must be resolved against nearest parent, not just immediate parent": skipDraft04ID,
+	"draft4/refRemote.json/base URI change":                                                 skipDraft04ID,
+	"draft4/refRemote.json/base URI change - change folder":                                 skipDraft04ID,
+	"draft4/refRemote.json/base URI change - change folder in subschema":                    skipDraft04ID,
+	"draft4/refRemote.json/root ref in remote ref":                                          skipDraft04ID,
+	"draft4/refRemote.json/Location-independent identifier in remote ref":                   skipDraft04ID,
+
+	"draft6/ref.json/$ref prevents a sibling $id from changing the base uri": skipRefSiblingID,
+
+	"draft7/ref.json/$ref prevents a sibling $id from changing the base uri": skipRefSiblingID,
+	"draft7/optional/content.json":                                           "contentMediaType and contentEncoding are not asserted",
+	"draft7/optional/cross-draft.json":                                       skipCrossDraft,
+	"draft7/optional/format/idn-hostname.json/validation of internationalized host names/Exceptions that are PVALID, right-to-left chars": skipIDNHostnameBidi,
+	"draft7/optional/format/iri.json/validation of IRIs/an invalid IRI based on IPv6":                                                     skipIRIIPv6,
+
+	"draft2019-09/anchor.json":                                      skipAnchor,
+	"draft2019-09/defs.json/validate definition against metaschema": skipRecursiveRef,
+	"draft2019-09/dependentRequired.json":                           "dependentRequired is not supported",
+	"draft2019-09/dependentSchemas.json":                            "dependentSchemas is not supported",
+	"draft2019-09/maxContains.json":                                 skipContainsBounds,
+	"draft2019-09/minContains.json":                                 skipContainsBounds,
+	"draft2019-09/not.json/collect annotations inside a 'not', even if collection is disabled": skipUnevaluated,
+	"draft2019-09/optional/anchor.json":      skipAnchor,
+	"draft2019-09/optional/cross-draft.json": skipCrossDraft,
+	"draft2019-09/optional/format/idn-hostname.json/validation of internationalized host names/Exceptions that are PVALID, right-to-left chars": skipIDNHostnameBidi,
+	"draft2019-09/optional/format/iri.json/validation of IRIs/an invalid IRI based on IPv6":                                                     skipIRIIPv6,
+	"draft2019-09/optional/refOfUnknownKeyword.json":                                                                                            "$ref to the values of the unknown and the non-applicator keywords is not supported",
+	"draft2019-09/recursiveRef.json":                                            skipRecursiveRef,
+	"draft2019-09/ref.json/$ref with $recursiveAnchor":                          skipRecursiveRef,
+	"draft2019-09/ref.json/ref applies alongside sibling keywords":              skipRefSiblings,
+	"draft2019-09/ref.json/ref creates new scope when adjacent to keywords":     skipUnevaluated,
+	"draft2019-09/ref.json/refs with relative uris and defs":                    skipRefSiblings,
+	"draft2019-09/ref.json/relative refs with absolute uris and defs":           skipRefSiblings,
+	"draft2019-09/ref.json/order of evaluation: $id and $anchor and $ref":       skipAnchor,
+	"draft2019-09/ref.json/URN base URI with URN and anchor ref":                skipAnchor,
+	"draft2019-09/refRemote.json/anchor within remote ref":                      skipAnchor,
+	"draft2019-09/refRemote.json/Location-independent identifier in remote ref": skipAnchor,
+	"draft2019-09/refRemote.json/$ref to $ref finds detached $anchor":           skipAnchor,
+	"draft2019-09/unevaluatedItems.json":                                        skipUnevaluated,
+	"draft2019-09/unevaluatedProperties.json":                                   skipUnevaluated,
+	"draft2019-09/vocabulary.json":                                              "$vocabulary is not supported",
+}
+
+// The list of the reasons of the suiteSkips shared by the drafts.
+const (
+	skipDraft04ID       = `the draft-04 "id" is not supported`
+	skipRefSiblingID    = `the "$id" sibling of the "$ref" changes the base URI as in draft 2019-09`
+	skipRefSiblings     = `the siblings of the "$ref" are ignored as in draft-07`
+	skipCrossDraft      = "the drafts of the referenced schemas are not detected"
+	skipIDNHostnameBidi = "the approximated Bidi rule of idn-hostname rejects the right-to-left symbols"
+	skipIRIIPv6         = "iri accepts the IPv6 address without the brackets"
+	skipAnchor          = "$anchor is not supported"
+	skipRecursiveRef    = "$recursiveRef and $recursiveAnchor are not supported"
+	skipContainsBounds  = "minContains and maxContains are not supported"
+	skipUnevaluated     = "unevaluatedItems and unevaluatedProperties are not supported"
+)
+
+// suiteGroup represents a group of the JSON-Schema-Test-Suite cases which share the schema.
+type suiteGroup struct {
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+	Tests       []*suiteCase    `json:"tests"`
+}
+
+// suiteCase represents a JSON-Schema-Test-Suite case.
+type suiteCase struct {
+	Description string          `json:"description"`
+	Data        json.RawMessage `json:"data"`
+	Valid       bool            `json:"valid"`
+}
+
+// suiteResult represents the number of the cases of a draft.
+type suiteResult struct {
+	total   int
+	passed  int
+	skipped int
+}
+
+// String implements fmt.Stringer.
+func (r suiteResult) String() string {
+	rate := 0.0
+	if r.total > 0 {
+		rate = float64(r.passed) * 100 / float64(r.total)
+	}
+
+	return fmt.Sprintf("%d/%d passed (%.1f%%), %d skipped", r.passed, r.total, rate, r.skipped)
+}
+
+// TestSuite runs the cases of the JSON-Schema-Test-Suite, and reports the pass rate of each draft.
+//
+// The remote schemas are served by the local server, so the TestSuite does not access the network.
+func TestSuite(t *testing.T) {
+	srv := httptest.NewServer(suiteHandler())
+	defer srv.Close()
+
+	reg := NewRegistry()
+	reg.SetLoader(suiteLoader(srv))
+
+	// ran and failed are the sets of the keys of the suiteSkips whose cases are run, and fail.
+	ran := make(map[string]bool)
+	failed := make(map[string]bool)
+	results := make(map[string]suiteResult)
+	for _, draft := range suiteDrafts {
+		t.Run(draft, func(t *testing.T) {
+			var res suiteResult
+			for _, file := range suiteFiles(t, draft) {
+				groups := readSuiteFile(t, filepath.Join(suiteDir, "tests", draft, filepath.FromSlash(file)))
+				format := strings.HasPrefix(file, "optional/format/")
+				t.Run(file, func(t *testing.T) {
+					for _, g := range groups {
+						t.Run(g.Description, func(t *testing.T) {
+							v, err := compileSuiteSchema(reg, g.Schema, format)
+							for _, c := range g.Tests {
+								name := strings.Join([]string{draft, file, g.Description, c.Description}, "/")
+								t.Run(c.Description, func(t *testing.T) {
+									res.total++
+									cerr := err
+									if cerr == nil {
+										cerr = runSuiteCase(v, c)
+									}
+									key, reason, skip := suiteSkip(name)
+									if skip {
+										ran[key] = true
+									}
+									switch {
+									case cerr == nil:
+										res.passed++
+									case skip:
+										failed[key] = true
+										res.skipped++
+										t.Skipf("%s: %v", reason, cerr)
+									default:
+										t.Error(cerr)
+									}
+								})
+							}
+						})
+					}
+				})
+			}
+			results[draft] = res
+		})
+	}
+
+	for _, draft := range suiteDrafts {
+		if res, ok := results[draft]; ok {
+			t.Logf("%s: %s", draft, res)
+		}
+	}
+	for key := range suiteSkips {
+		if ran[key] && !failed[key] {
+			t.Errorf("all cases of %q pass, remove it from the suiteSkips", key)
+		}
+	}
+}
+
+// suiteHandler returns the http.Handler which serves the remote schemas of the JSON-Schema-Test-Suite
+// under the "/remotes/", and the meta-schemas of the api directory under the "/api/".
+func suiteHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/remotes/", http.StripPrefix("/remotes/", http.FileServer(http.Dir(filepath.Join(suiteDir, "remotes")))))
+	mux.Handle("/api/", http.StripPrefix("/api/", http.FileServer(http.Dir("api"))))
+
+	return mux
+}
+
+// suiteLoader returns the Loader which loads the remote schemas from srv.
+func suiteLoader(srv *httptest.Server) Loader {
+	return func(uri string) (*Schema, error) {
+		for _, r := range suiteRemotes {
+			if !strings.HasPrefix(uri, r.prefix) {
+				continue
+			}
+			resp, err := srv.Client().Get(srv.URL + path.Join(r.path, strings.TrimPrefix(uri, r.prefix)))
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("GET %s: %s", uri, resp.Status)
+			}
+			data, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				return nil, err
+			}
+			s := &Schema{}
+			if err := s.UnmarshalJSON(data); err != nil {
+				return nil, err
+			}
+			return s, nil
+		}
+
+		return nil, fmt.Errorf("unknown remote %q", uri)
+	}
+}
+
+// suiteFiles returns the sorted paths of the test files of the draft, which are relative to the draft directory.
+func suiteFiles(tb testing.TB, draft string) []string {
+	tb.Helper()
+
+	dir := filepath.Join(suiteDir, "tests", draft)
+	var files []string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(p) != ".json" {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	sort.Strings(files)
+
+	return files
+}
+
+// readSuiteFile reads the groups of the test file.
+func readSuiteFile(tb testing.TB, name string) []*suiteGroup {
+	tb.Helper()
+
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var groups []*suiteGroup
+	if err := json.Unmarshal(data, &groups); err != nil {
+		tb.Fatalf("%s: %v", name, err)
+	}
+
+	return groups
+}
+
+// compileSuiteSchema compiles the schema of the group, whose external "$ref"s are bundled by reg.
+//
+// The patterns are ECMA-262 regular expressions as required by the specification, and the format is
+// asserted if format is true.
+func compileSuiteSchema(reg *Registry, data []byte, format bool) (*Validator, error) {
+	s := &Schema{}
+	if err := s.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("unmarshal schema: %v", err)
+	}
+	s, err := Bundle(s, reg)
+	if err != nil {
+		return nil, fmt.Errorf("bundle schema: %v", err)
+	}
+
+	opts := []Option{WithRegexpEngine(RegexpECMA262)}
+	if format {
+		opts = append(opts, WithFormatMode(FormatAssertion))
+	}
+	v, err := Compile(s, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("compile schema: %v", err)
+	}
+
+	return v, nil
+}
+
+// runSuiteCase validates the data of c by v, and returns the error if the result is not expected.
+func runSuiteCase(v *Validator, c *suiteCase) error {
+	dec := json.NewDecoder(bytes.NewReader(c.Data))
+	dec.UseNumber()
+	var inst interface{}
+	if err := dec.Decode(&inst); err != nil {
+		return fmt.Errorf("decode data: %v", err)
+	}
+
+	err := v.Validate(inst)
+	switch {
+	case c.Valid && err != nil:
+		return fmt.Errorf("%s is valid, but got %v", c.Data, err)
+	case !c.Valid && err == nil:
+		return fmt.Errorf("%s is invalid, but got no error", c.Data)
+	}
+
+	return nil
+}
+
+// suiteSkip returns the key of the suiteSkips which matches the path of the case, and its reason.
+func suiteSkip(name string) (string, string, bool) {
+	for key, reason := range suiteSkips {
+		if name == key || strings.HasPrefix(name, key+"/") {
+			return key, reason, true
+		}
+	}
+
+	return "", "", false
+}
@@ -5,11 +5,16 @@
 package jsonschema
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
-	"regexp"
+	"fmt"
+	"sort"
 
 	"github.com/francoispqt/gojay"
+
+	"github.com/zchee/go-jsonschema/internal/lazyregexp"
+	regexpinterface "github.com/zchee/go-jsonschema/pkg/regexp"
 )
 
 // Type represents a JSON Schema type keyword.
@@ -75,7 +80,7 @@ func (t *Type) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	x := TypeFromString(string(data))
+	x := TypeFromString(s)
 	if x == UnspecifiedType {
 		return errors.New("unspecified type")
 	}
@@ -124,6 +129,35 @@ func TypeFromString(s string) Type {
 // Types represents a list of Type.
 type Types []Type
 
+// typesFromInterface returns the Types from v which is a type name or a list of type names.
+func typesFromInterface(v interface{}) (Types, error) {
+	switch v := v.(type) {
+	case string:
+		t := TypeFromString(v)
+		if t == UnspecifiedType {
+			return nil, fmt.Errorf("unknown type %q", v)
+		}
+		return Types{t}, nil
+
+	case []interface{}:
+		ts := make(Types, 0, len(v))
+		for _, e := range v {
+			name, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid type %v", e)
+			}
+			t := TypeFromString(name)
+			if t == UnspecifiedType {
+				return nil, fmt.Errorf("unknown type %q", name)
+			}
+			ts = append(ts, t)
+		}
+		return ts, nil
+	}
+
+	return nil, fmt.Errorf("invalid type %v", v)
+}
+
 // Contains returns true if the list of types contains p.
 func (ts Types) Contains(t Type) bool {
 	for _, v := range ts {
@@ -183,7 +217,7 @@ var (
 // MarshalJSONArray implements gojay.MarshalerJSONArray.
 func (ts *Types) MarshalJSONArray(enc *gojay.Encoder) {
 	for _, e := range *ts {
-		enc.Int(int(e))
+		enc.String(e.String())
 	}
 }
 
@@ -196,12 +230,15 @@ func (ts *Types) IsNil() bool {
 
 // UnmarshalJSONArray implements gojay.UnmarshalerJSONArray.
 func (ts *Types) UnmarshalJSONArray(dec *gojay.Decoder) error {
-	var value Type
-	v := int(value)
-	if err := dec.Int(&v); err != nil {
+	var name string
+	if err := dec.String(&name); err != nil {
 		return err
 	}
-	*ts = append(*ts, Type(v))
+	t := TypeFromString(name)
+	if t == UnspecifiedType {
+		return fmt.Errorf("unknown type %q", name)
+	}
+	*ts = append(*ts, t)
 
 	return nil
 }
@@ -263,7 +300,7 @@ var (
 // MarshalJSONArray implements gojay.MarshalerJSONArray.
 func (sa *StringArray) MarshalJSONArray(enc *gojay.Encoder) {
 	for _, s := range *sa {
-		enc.Object(&s)
+		enc.String(s.Value)
 	}
 }
 
@@ -276,8 +313,8 @@ func (sa *StringArray) IsNil() bool {
 
 // UnmarshalJSONArray implements gojay.UnmarshalerJSONArray.
 func (sa *StringArray) UnmarshalJSONArray(dec *gojay.Decoder) error {
-	var value = String{}
-	if err := dec.Object(&value); err != nil {
+	var value = String{Initialized: true}
+	if err := dec.String(&value.Value); err != nil {
 		return err
 	}
 	*sa = append(*sa, value)
@@ -490,7 +527,7 @@ func (s ItemsStream) UnmarshalStream(dec *gojay.StreamDecoder) error {
 }
 
 // Pattern represents a use regular expressions to express constraints.
-type Pattern *regexp.Regexp
+type Pattern = regexpinterface.Regexp
 
 // AdditionalItems represents a JSON Schema AdditionalItems type.
 //
@@ -510,9 +547,8 @@ var (
 
 // MarshalJSONObject implements gojay.MarshalerJSONObject.
 func (ai *AdditionalItems) MarshalJSONObject(enc *gojay.Encoder) {
-	switch ai.Schema.Version() {
-	case DraftVersion7:
-		enc.Object(ai)
+	if ai.Schema != nil {
+		ai.Schema.MarshalJSONObject(enc)
 	}
 }
 
@@ -525,12 +561,11 @@ func (ai *AdditionalItems) IsNil() bool {
 
 // UnmarshalJSONObject implements gojay.UnmarshalerJSONObject.
 func (ai *AdditionalItems) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
-	switch ai.Schema.Version() {
-	case DraftVersion7:
-		return dec.Object(ai)
+	if ai.Schema == nil {
+		ai.Schema = new(Schema)
 	}
 
-	return nil
+	return ai.Schema.UnmarshalJSONObject(dec, k)
 }
 
 // NKeys implements gojay.UnmarshalerJSONObject.
@@ -542,10 +577,10 @@ func (*AdditionalItems) NKeys() int { return 0 }
 //
 // Reset reset fields.
 func (ai *AdditionalItems) Reset() {
-	switch ai.Schema.Version() {
-	case DraftVersion7:
-		ai.Reset()
-		SchemaPool.Put(ai)
+	if ai.Schema != nil {
+		ai.Schema.Reset()
+		SchemaPool.Put(ai.Schema)
+		ai.Schema = nil
 	}
 }
 
@@ -600,9 +635,8 @@ var (
 
 // MarshalJSONObject implements gojay.MarshalerJSONObject.
 func (ap *AdditionalProperties) MarshalJSONObject(enc *gojay.Encoder) {
-	switch ap.Version() {
-	case DraftVersion7:
-		enc.Object(ap)
+	if ap.Schema != nil {
+		ap.Schema.MarshalJSONObject(enc)
 	}
 }
 
@@ -615,12 +649,11 @@ func (ap *AdditionalProperties) IsNil() bool {
 
 // UnmarshalJSONObject implements gojay.UnmarshalerJSONObject.
 func (ap *AdditionalProperties) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
-	switch ap.Schema.Version() {
-	case DraftVersion7:
-		return dec.Object(ap)
+	if ap.Schema == nil {
+		ap.Schema = new(Schema)
 	}
 
-	return nil
+	return ap.Schema.UnmarshalJSONObject(dec, k)
 }
 
 // NKeys implements gojay.UnmarshalerJSONObject.
@@ -632,10 +665,10 @@ func (*AdditionalProperties) NKeys() int { return 0 }
 //
 // Reset reset fields.
 func (ap *AdditionalProperties) Reset() {
-	switch ap.Schema.Version() {
-	case DraftVersion7:
-		ap.Reset()
-		SchemaPool.Put(ap)
+	if ap.Schema != nil {
+		ap.Schema.Reset()
+		SchemaPool.Put(ap.Schema)
+		ap.Schema = nil
 	}
 }
 
@@ -674,7 +707,7 @@ func (s AdditionalPropertiesStream) UnmarshalStream(dec *gojay.StreamDecoder) er
 // Definitions provides a standardized location for schema authors
 // to inline re-usable JSON Schemas into a more general schema. The
 // keyword does not directly affect the validation result.
-type Definitions map[string]Schema
+type Definitions map[string]*Schema
 
 var (
 	// compile time check whether the Definitions implements gojay.MarshalerJSONObject interface.
@@ -687,8 +720,8 @@ var (
 
 // MarshalJSONObject implements gojay.MarshalerJSONObject.
 func (d Definitions) MarshalJSONObject(enc *gojay.Encoder) {
-	for k, v := range d {
-		enc.ObjectKey(k, &v)
+	for _, k := range sortedKeys(d) {
+		encodeSchemaKey(enc, k, d[k])
 	}
 }
 
@@ -701,12 +734,12 @@ func (d Definitions) IsNil() bool {
 
 // UnmarshalJSONObject implements gojay.UnmarshalerJSONObject.
 func (d Definitions) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
-	var s Schema
-	err := dec.Object(&s)
+	s, err := decodeSchema(dec)
 	if err != nil {
-		return err
+		return schemaError(err, k)
 	}
 	d[k] = s
+
 	return nil
 }
 
@@ -760,7 +793,7 @@ func (s DefinitionsStream) UnmarshalStream(dec *gojay.StreamDecoder) error {
 // Properties (key-value pairs) on an object are defined using the properties keyword.
 //
 // The value of properties is an object, where each key is the name of a property and each value is a JSON schema used to validate that property.
-type Properties map[string]Schema
+type Properties map[string]*Schema
 
 var (
 	// compile time check whether the Properties implements gojay.MarshalerJSONObject interface.
@@ -773,8 +806,8 @@ var (
 
 // MarshalJSONObject implements gojay.MarshalerJSONObject.
 func (p Properties) MarshalJSONObject(enc *gojay.Encoder) {
-	for k, v := range p {
-		enc.ObjectKey(k, &v)
+	for _, k := range sortedKeys(p) {
+		encodeSchemaKey(enc, k, p[k])
 	}
 }
 
@@ -787,12 +820,12 @@ func (p Properties) IsNil() bool {
 
 // UnmarshalJSONObject implements gojay.UnmarshalerJSONObject.
 func (p Properties) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
-	var s Schema
-	err := dec.Object(&s)
+	s, err := decodeSchema(dec)
 	if err != nil {
-		return err
+		return schemaError(err, k)
 	}
 	p[k] = s
+
 	return nil
 }
 
@@ -848,7 +881,15 @@ func (s PropertiesStream) UnmarshalStream(dec *gojay.StreamDecoder) error {
 
 // PatternProperties is the each property name of this object SHOULD be a valid regular expression, according to the ECMA 262 regular expression dialect.
 // Each property value of this object MUST be a valid JSON Schema.
-type PatternProperties map[*regexp.Regexp]*Schema
+//
+// PatternProperties is keyed by the source of the regular expression.
+type PatternProperties map[string]*PatternProperty
+
+// PatternProperty represents a value of the PatternProperties.
+type PatternProperty struct {
+	Regexp regexpinterface.Regexp
+	Schema *Schema
+}
 
 var (
 	// compile time check whether the PatternProperties implements gojay.MarshalerJSONObject interface.
@@ -861,8 +902,13 @@ var (
 
 // MarshalJSONObject implements gojay.MarshalerJSONObject.
 func (pp PatternProperties) MarshalJSONObject(enc *gojay.Encoder) {
-	for k, v := range pp {
-		enc.ObjectKey(k.String(), v)
+	keys := make([]string, 0, len(pp))
+	for k := range pp {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		encodeSchemaKey(enc, k, pp[k].Schema)
 	}
 }
 
@@ -875,15 +921,15 @@ func (pp PatternProperties) IsNil() bool {
 
 // UnmarshalJSONObject implements gojay.UnmarshalerJSONObject.
 func (pp PatternProperties) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
-	var s Schema
-	err := dec.Object(&s)
+	re, err := lazyregexp.Compile(k, lazyregexp.ECMA262)
 	if err != nil {
-		return err
+		return schemaError(fmt.Errorf("invalid pattern %q: %v", k, err), k)
 	}
-	re := regexp.MustCompile(k)
-	if re != nil {
-		pp[re] = &s
+	s, err := decodeSchema(dec)
+	if err != nil {
+		return schemaError(err, k)
 	}
+	pp[k] = &PatternProperty{Regexp: re, Schema: s}
 
 	return nil
 }
@@ -897,12 +943,12 @@ func (PatternProperties) NKeys() int { return 0 }
 //
 // Reset reset fields.
 func (pp PatternProperties) Reset() {
-	for i := range pp {
-		switch pp[i].Version() {
-		case DraftVersion7:
-			pp[i].Reset()
-			SchemaPool.Put(pp[i])
+	for k, p := range pp {
+		if p.Schema != nil {
+			p.Schema.Reset()
+			SchemaPool.Put(p.Schema)
 		}
+		delete(pp, k)
 	}
 }
 
@@ -1121,9 +1167,26 @@ var (
 )
 
 // MarshalJSONObject implements gojay.MarshalerJSONObject.
+//
+// The property dependencies are encoded as the string arrays, and the schema dependencies as the schemas.
 func (dm *DependencyMap) MarshalJSONObject(enc *gojay.Encoder) {
-	enc.ObjectKey(keyNames, NameMap(dm.Names))
-	enc.ObjectKey(keySchemas, SchemaMap(dm.Schemas))
+	keys := make([]string, 0, len(dm.Names)+len(dm.Schemas))
+	for k := range dm.Names {
+		keys = append(keys, k)
+	}
+	for k := range dm.Schemas {
+		if _, ok := dm.Names[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if names, ok := dm.Names[k]; ok {
+			enc.SliceStringKey(k, names)
+			continue
+		}
+		encodeSchemaKey(enc, k, dm.Schemas[k])
+	}
 }
 
 // IsNil implements gojay.MarshalerJSONObject.
@@ -1134,15 +1197,35 @@ func (dm *DependencyMap) IsNil() bool {
 }
 
 // UnmarshalJSONObject implements gojay.UnmarshalerJSONObject.
+//
+// The k is the property name, and the value is either the array of the property names or the schema.
 func (dm *DependencyMap) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
-	switch k {
-	case keyNames:
-		return dec.Object(NameMap(dm.Names))
+	raw, err := decodeRaw(dec)
+	if err != nil {
+		return schemaError(err, k)
+	}
 
-	case keySchemas:
-		return dec.Object(SchemaMap(dm.Schemas))
+	if b := bytes.TrimSpace(raw); len(b) > 0 && b[0] == '[' {
+		var names []string
+		if err := json.Unmarshal(b, &names); err != nil {
+			return schemaError(err, k)
+		}
+		if dm.Names == nil {
+			dm.Names = make(map[string][]string)
+		}
+		dm.Names[k] = names
+		return nil
 	}
 
+	s, err := unmarshalSchema(raw)
+	if err != nil {
+		return schemaError(err, k)
+	}
+	if dm.Schemas == nil {
+		dm.Schemas = make(map[string]*Schema)
+	}
+	dm.Schemas[k] = s
+
 	return nil
 }
 
@@ -1210,10 +1293,19 @@ var (
 	_ Pooler = &Const{}
 )
 
+// NewConst returns the Const of the JSON value v.
+func NewConst(v interface{}) *Const {
+	if a, ok := v.([]interface{}); ok {
+		return &Const{Type: ArrayType, Value: a}
+	}
+
+	return &Const{Type: instanceType(v), Value: []interface{}{v}}
+}
+
 // MarshalJSONObject implements gojay.MarshalerJSONObject.
 func (c *Const) MarshalJSONObject(enc *gojay.Encoder) {
 	enc.IntKey(keyType, int(c.Type))
-	enc.AddInterfaceKey(keyValue, Interfaces(c.Value))
+	enc.ArrayKey(keyValue, (*Interfaces)(&c.Value))
 }
 
 // IsNil implements gojay.MarshalerJSONObject.
@@ -1229,11 +1321,13 @@ func (c *Const) UnmarshalJSONObject(dec *gojay.Decoder, k string) error {
 	case keyType:
 		itype := int(c.Type)
 		err := dec.Int(&itype)
+		if err == nil {
+			c.Type = Type(itype)
+		}
 		return err
 
 	case keyValue:
-		ifaces := Interfaces(c.Value)
-		return dec.Array(&ifaces)
+		return dec.Array((*Interfaces)(&c.Value))
 	}
 
 	return nil
@@ -1298,9 +1392,11 @@ var (
 )
 
 // MarshalJSONArray implements gojay.MarshalerJSONArray.
+//
+// The elements are encoded as the JSON values of the enum keyword.
 func (e *Enum) MarshalJSONArray(enc *gojay.Encoder) {
 	for _, t := range *e {
-		enc.Object(t)
+		encodeValue(enc, t.Interface())
 	}
 }
 
@@ -1308,16 +1404,16 @@ func (e *Enum) MarshalJSONArray(enc *gojay.Encoder) {
 //
 // IsNil checks if instance is nil.
 func (e *Enum) IsNil() bool {
-	return e == nil
+	return e == nil || len(*e) == 0
 }
 
 // UnmarshalJSONArray implements gojay.UnmarshalerJSONArray.
 func (e *Enum) UnmarshalJSONArray(dec *gojay.Decoder) error {
-	var t Const
-	if err := dec.Object(&t); err != nil {
+	v, err := decodeValue(dec)
+	if err != nil {
 		return err
 	}
-	*e = append(*e, &t)
+	*e = append(*e, NewConst(v))
 
 	return nil
 }
@@ -1364,7 +1460,7 @@ func (s EnumStream) UnmarshalStream(dec *gojay.StreamDecoder) error {
 	return nil
 }
 
-// Interfaces represents a slice of interface.
+// Interfaces represents a slice of the arbitrary JSON values.
 type Interfaces []interface{}
 
 var (
@@ -1379,7 +1475,7 @@ var (
 // MarshalJSONArray implements gojay.MarshalerJSONArray.
 func (v *Interfaces) MarshalJSONArray(enc *gojay.Encoder) {
 	for _, t := range *v {
-		enc.AddInterface(t)
+		encodeValue(enc, t)
 	}
 }
 
@@ -1388,8 +1484,8 @@ func (v *Interfaces) IsNil() bool { return len(*v) == 0 }
 
 // UnmarshalJSONArray implements gojay.UnmarshalerJSONArray.
 func (v *Interfaces) UnmarshalJSONArray(dec *gojay.Decoder) error {
-	var t interface{}
-	if err := dec.Interface(&t); err != nil {
+	t, err := decodeValue(dec)
+	if err != nil {
 		return err
 	}
 	*v = append(*v, t)
@@ -0,0 +1,709 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRefDepth is the maximum depth of the nested "$ref" evaluation.
+const maxRefDepth = 1024
+
+// Validate validates the instance against the compiled Schema.
+//
+// The instance must be a value decoded by encoding/json into an interface{}, that is, map[string]interface{},
+// []interface{}, string, float64 or json.Number, bool, or nil. The other integer and floating-point types are also accepted.
+//
+// Validate returns ValidationErrors if the instance is invalid.
+func (v *Validator) Validate(instance interface{}) error {
+	st := &state{v: v}
+	st.validate(v.root, instance, "", "")
+	if len(st.errs) == 0 {
+		return nil
+	}
+
+	return st.errs
+}
+
+// IsValid reports whether the instance is valid against the compiled Schema.
+func (v *Validator) IsValid(instance interface{}) bool {
+	st := &state{v: v, failFast: true}
+	st.validate(v.root, instance, "", "")
+
+	return len(st.errs) == 0
+}
+
+// state represents a state of the validation.
+type state struct {
+	v        *Validator
+	errs     ValidationErrors
+	refDepth int
+	failFast bool
+}
+
+// sub returns a new state to evaluate the sub schema independently.
+func (st *state) sub() *state {
+	return &state{v: st.v, refDepth: st.refDepth, failFast: st.failFast}
+}
+
+// addError adds the ValidationError.
+func (st *state) addError(kloc, iloc, format string, args ...interface{}) {
+	st.errs = append(st.errs, &ValidationError{
+		KeywordLocation:  kloc,
+		InstanceLocation: iloc,
+		Message:          fmt.Sprintf(format, args...),
+	})
+}
+
+// valid reports whether the instance is valid against s without recording errors.
+func (st *state) valid(s *Schema, inst interface{}, kloc, iloc string) bool {
+	sub := st.sub()
+	sub.failFast = true
+	sub.validate(s, inst, kloc, iloc)
+
+	return len(sub.errs) == 0
+}
+
+// done reports whether the validation can be stopped.
+func (st *state) done() bool {
+	return st.failFast && len(st.errs) > 0
+}
+
+// validate validates the inst instance located at iloc against the s Schema located at kloc.
+func (st *state) validate(s *Schema, inst interface{}, kloc, iloc string) {
+	if s == nil {
+		return
+	}
+	if s.Bool != nil {
+		if !*s.Bool {
+			st.addError(kloc, iloc, "false schema never matches")
+		}
+		return
+	}
+
+	if s.Ref != "" {
+		target := st.refTarget(s, kloc, iloc)
+		if target == nil {
+			return
+		}
+		st.refDepth++
+		st.validate(target, inst, appendLocation(kloc, keyRef), iloc)
+		st.refDepth--
+		// all other properties in a "$ref" object are ignored in draft 7.
+		return
+	}
+
+	inst = normalizeInstance(inst)
+
+	st.validateType(s, inst, kloc, iloc)
+	st.validateConst(s, inst, kloc, iloc)
+	if st.done() {
+		return
+	}
+
+	switch inst := inst.(type) {
+	case json.Number:
+		st.validateNumber(s, inst, kloc, iloc)
+	case string:
+		st.validateString(s, inst, kloc, iloc)
+	case []interface{}:
+		st.validateArray(s, inst, kloc, iloc)
+	case map[string]interface{}:
+		st.validateObject(s, inst, kloc, iloc)
+	}
+	if st.done() {
+		return
+	}
+
+	st.validateCombinators(s, inst, kloc, iloc)
+}
+
+// refTarget returns the Schema which the "$ref" of s refers to, or nil if the reference cannot be followed.
+func (st *state) refTarget(s *Schema, kloc, iloc string) *Schema {
+	target, ok := st.v.refs[s]
+	if !ok {
+		st.addError(appendLocation(kloc, keyRef), iloc, "unresolved $ref %q", s.Ref)
+		return nil
+	}
+	if st.refDepth >= maxRefDepth {
+		st.addError(appendLocation(kloc, keyRef), iloc, "too deep $ref %q", s.Ref)
+		return nil
+	}
+
+	return target
+}
+
+// validateType validates the "type" keyword.
+func (st *state) validateType(s *Schema, inst interface{}, kloc, iloc string) {
+	if len(s.Type) == 0 {
+		return
+	}
+
+	it := instanceType(inst)
+	for _, t := range s.Type {
+		if t == it || (t == NumberType && it == IntegerType) {
+			return
+		}
+	}
+	st.addError(appendLocation(kloc, keyType), iloc, "expected %s, but got %s", typesString(s.Type), it)
+}
+
+// typesString returns the printable form of the ts.
+func typesString(ts Types) string {
+	names := make([]string, len(ts))
+	for i, t := range ts {
+		names[i] = t.String()
+	}
+
+	return strings.Join(names, " or ")
+}
+
+// validateConst validates the "const" and "enum" keywords.
+func (st *state) validateConst(s *Schema, inst interface{}, kloc, iloc string) {
+	if s.Const != nil && !equal(s.Const.Interface(), inst) {
+		st.addError(appendLocation(kloc, keyConst), iloc, "value must be %s", jsonString(s.Const.Interface()))
+	}
+
+	if len(s.Enum) > 0 {
+		for _, e := range s.Enum {
+			if equal(e.Interface(), inst) {
+				return
+			}
+		}
+		values := make([]string, len(s.Enum))
+		for i, e := range s.Enum {
+			values[i] = jsonString(e.Interface())
+		}
+		st.addError(appendLocation(kloc, keyEnum), iloc, "value must be one of %s", strings.Join(values, ", "))
+	}
+}
+
+// validateNumber validates the numeric keywords.
+func (st *state) validateNumber(s *Schema, n json.Number, kloc, iloc string) {
+	x := newNumber(n)
+
+	if s.MultipleOf > 0 && !x.multipleOf(s.MultipleOf) {
+		st.addError(appendLocation(kloc, keyMultipleOf), iloc, "%s is not a multiple of %v", n, s.MultipleOf)
+	}
+	if s.Maximum != nil && x.cmp(*s.Maximum) > 0 {
+		st.addError(appendLocation(kloc, keyMaximum), iloc, "%s is greater than the maximum %v", n, *s.Maximum)
+	}
+	if s.ExclusiveMaximum != nil && x.cmp(*s.ExclusiveMaximum) >= 0 {
+		st.addError(appendLocation(kloc, keyExclusiveMaximum), iloc, "%s is greater than or equal to the exclusive maximum %v", n, *s.ExclusiveMaximum)
+	}
+	if s.Minimum != nil && x.cmp(*s.Minimum) < 0 {
+		st.addError(appendLocation(kloc, keyMinimum), iloc, "%s is less than the minimum %v", n, *s.Minimum)
+	}
+	if s.ExclusiveMinimum != nil && x.cmp(*s.ExclusiveMinimum) <= 0 {
+		st.addError(appendLocation(kloc, keyExclusiveMinimum), iloc, "%s is less than or equal to the exclusive minimum %v", n, *s.ExclusiveMinimum)
+	}
+}
+
+// validateString validates the string keywords.
+func (st *state) validateString(s *Schema, str string, kloc, iloc string) {
+	if s.MaxLength != nil || s.MinLength > 0 {
+		n := int64(utf8.RuneCountInString(str))
+		if s.MaxLength != nil && n > *s.MaxLength {
+			st.addError(appendLocation(kloc, keyMaxLength), iloc, "length %d is greater than the maxLength %d", n, *s.MaxLength)
+		}
+		if n < s.MinLength {
+			st.addError(appendLocation(kloc, keyMinLength), iloc, "length %d is less than the minLength %d", n, s.MinLength)
+		}
+	}
+
+	if s.Pattern != nil {
+		expr := s.Pattern.String()
+		switch ok, err := matchString(st.v.patterns[expr], str); {
+		case err != nil:
+			st.addError(appendLocation(kloc, keyPattern), iloc, "pattern %q: %v", expr, err)
+		case !ok:
+			st.addError(appendLocation(kloc, keyPattern), iloc, "%q does not match the pattern %q", str, expr)
+		}
+	}
+
+	if s.Format != "" {
+		if err := st.v.formats.Check(s.Format, str); err != nil {
+			st.addError(appendLocation(kloc, keyFormat), iloc, "%v", err)
+		}
+	}
+}
+
+// validateArray validates the array keywords.
+func (st *state) validateArray(s *Schema, arr []interface{}, kloc, iloc string) {
+	st.validateItemCount(s, int64(len(arr)), kloc, iloc)
+
+	if s.UniqueItems {
+	unique:
+		for i := 1; i < len(arr); i++ {
+			for j := 0; j < i; j++ {
+				if equal(arr[i], arr[j]) {
+					st.addError(appendLocation(kloc, keyUniqueItems), iloc, "items at %d and %d are equal", j, i)
+					break unique
+				}
+			}
+		}
+	}
+
+	if s.Items != nil {
+		switch {
+		case !s.Items.HasMultiple:
+			if len(s.Items.Schemas) > 0 {
+				for i, item := range arr {
+					st.validate(s.Items.Schemas[0], item, appendLocation(kloc, keyItems), appendLocation(iloc, strconv.Itoa(i)))
+				}
+			}
+
+		default:
+			for i, item := range arr {
+				if i >= len(s.Items.Schemas) {
+					if s.AdditionalItems != nil {
+						st.validate(s.AdditionalItems.Schema, item, appendLocation(kloc, keyAdditionalItems), appendLocation(iloc, strconv.Itoa(i)))
+					}
+					continue
+				}
+				st.validate(s.Items.Schemas[i], item, appendLocation(kloc, keyItems, strconv.Itoa(i)), appendLocation(iloc, strconv.Itoa(i)))
+			}
+		}
+	}
+
+	if s.Contains != nil && s.Contains.Schema != nil {
+		found := false
+		for i, item := range arr {
+			if st.valid(s.Contains.Schema, item, appendLocation(kloc, keyContains), appendLocation(iloc, strconv.Itoa(i))) {
+				found = true
+				break
+			}
+		}
+		st.reportContains(found, kloc, iloc)
+	}
+}
+
+// validateItemCount validates the "maxItems" and "minItems" keywords against the n items.
+func (st *state) validateItemCount(s *Schema, n int64, kloc, iloc string) {
+	if s.MaxItems != nil && n > *s.MaxItems {
+		st.addError(appendLocation(kloc, keyMaxItems), iloc, "array has %d items, more than the maxItems %d", n, *s.MaxItems)
+	}
+	if n < s.MinItems {
+		st.addError(appendLocation(kloc, keyMinItems), iloc, "array has %d items, less than the minItems %d", n, s.MinItems)
+	}
+}
+
+// reportContains reports the result of the "contains" keyword.
+func (st *state) reportContains(found bool, kloc, iloc string) {
+	if !found {
+		st.addError(appendLocation(kloc, keyContains), iloc, "no items match the contains schema")
+	}
+}
+
+// validateObject validates the object keywords.
+func (st *state) validateObject(s *Schema, obj map[string]interface{}, kloc, iloc string) {
+	has := func(name string) bool {
+		_, ok := obj[name]
+		return ok
+	}
+	st.validatePropertyCount(s, int64(len(obj)), kloc, iloc)
+	st.validateRequired(s, has, kloc, iloc)
+
+	if s.PropertyNames != nil {
+		for _, name := range sortedInstanceKeys(obj) {
+			st.validate(s.PropertyNames, name, appendLocation(kloc, keyPropertyNames), appendLocation(iloc, name))
+		}
+	}
+
+	if s.Dependencies != nil {
+		for _, name := range sortedInstanceKeys(obj) {
+			st.validateDependencyNames(s, name, has, kloc, iloc)
+			if dep, ok := s.Dependencies.Schemas[name]; ok {
+				st.validate(dep, obj, appendLocation(kloc, keyDependencies, name), iloc)
+			}
+		}
+	}
+
+	if len(s.Properties) == 0 && len(s.PatternProperties) == 0 && s.AdditionalProperties == nil {
+		return
+	}
+
+	patterns := make([]string, 0, len(s.PatternProperties))
+	for expr := range s.PatternProperties {
+		patterns = append(patterns, expr)
+	}
+	sort.Strings(patterns)
+
+	for _, name := range sortedInstanceKeys(obj) {
+		value := obj[name]
+		vloc := appendLocation(iloc, name)
+		matched := false
+		if prop, ok := s.Properties[name]; ok {
+			matched = true
+			st.validate(prop, value, appendLocation(kloc, keyProperties, name), vloc)
+		}
+		for _, expr := range patterns {
+			prop := s.PatternProperties[expr]
+			ok, err := matchString(st.v.patterns[expr], name)
+			if err != nil {
+				st.addError(appendLocation(kloc, keyPatternProperties, expr), vloc, "pattern %q: %v", expr, err)
+				continue
+			}
+			if ok {
+				matched = true
+				st.validate(prop.Schema, value, appendLocation(kloc, keyPatternProperties, expr), vloc)
+			}
+		}
+		if !matched && s.AdditionalProperties != nil {
+			st.validate(s.AdditionalProperties.Schema, value, appendLocation(kloc, keyAdditionalProperties), vloc)
+		}
+		if st.done() {
+			return
+		}
+	}
+}
+
+// validatePropertyCount validates the "maxProperties" and "minProperties" keywords against the n properties.
+func (st *state) validatePropertyCount(s *Schema, n int64, kloc, iloc string) {
+	if s.MaxProperties != nil && n > *s.MaxProperties {
+		st.addError(appendLocation(kloc, keyMaxProperties), iloc, "object has %d properties, more than the maxProperties %d", n, *s.MaxProperties)
+	}
+	if n < s.MinProperties {
+		st.addError(appendLocation(kloc, keyMinProperties), iloc, "object has %d properties, less than the minProperties %d", n, s.MinProperties)
+	}
+}
+
+// validateRequired validates the "required" keyword, where has reports whether the object has the property.
+func (st *state) validateRequired(s *Schema, has func(name string) bool, kloc, iloc string) {
+	for _, req := range s.Required {
+		if !has(req.Value) {
+			st.addError(appendLocation(kloc, keyRequired), iloc, "missing required property %q", req.Value)
+		}
+	}
+}
+
+// validateDependencyNames validates the property dependencies of the name property.
+func (st *state) validateDependencyNames(s *Schema, name string, has func(name string) bool, kloc, iloc string) {
+	for _, dep := range s.Dependencies.Names[name] {
+		if !has(dep) {
+			st.addError(appendLocation(kloc, keyDependencies, name), iloc, "property %q is required by %q", dep, name)
+		}
+	}
+}
+
+// validateCombinators validates the "allOf", "anyOf", "oneOf", "not" and "if" keywords.
+func (st *state) validateCombinators(s *Schema, inst interface{}, kloc, iloc string) {
+	for i, sub := range s.AllOf {
+		st.validate(sub, inst, appendLocation(kloc, keyAllOf, strconv.Itoa(i)), iloc)
+	}
+
+	if len(s.AnyOf) > 0 {
+		matched := false
+		for i, sub := range s.AnyOf {
+			if st.valid(sub, inst, appendLocation(kloc, keyAnyOf, strconv.Itoa(i)), iloc) {
+				matched = true
+				break
+			}
+		}
+		st.reportAnyOf(matched, kloc, iloc)
+	}
+
+	if len(s.OneOf) > 0 {
+		var matched []int
+		for i, sub := range s.OneOf {
+			if st.valid(sub, inst, appendLocation(kloc, keyOneOf, strconv.Itoa(i)), iloc) {
+				matched = append(matched, i)
+			}
+		}
+		st.reportOneOf(matched, kloc, iloc)
+	}
+
+	if s.Not != nil {
+		st.reportNot(st.valid(s.Not, inst, appendLocation(kloc, keyNot), iloc), kloc, iloc)
+	}
+
+	if s.If != nil {
+		if st.valid(s.If, inst, appendLocation(kloc, keyIf), iloc) {
+			st.validate(s.Then, inst, appendLocation(kloc, keyThen), iloc)
+		} else {
+			st.validate(s.Else, inst, appendLocation(kloc, keyElse), iloc)
+		}
+	}
+}
+
+// reportAnyOf reports the result of the "anyOf" keyword.
+func (st *state) reportAnyOf(matched bool, kloc, iloc string) {
+	if !matched {
+		st.addError(appendLocation(kloc, keyAnyOf), iloc, "value does not match any of the anyOf schemas")
+	}
+}
+
+// reportOneOf reports the result of the "oneOf" keyword, where matched is the indexes of the matched schemas.
+func (st *state) reportOneOf(matched []int, kloc, iloc string) {
+	switch len(matched) {
+	case 0:
+		st.addError(appendLocation(kloc, keyOneOf), iloc, "value does not match any of the oneOf schemas")
+	case 1:
+	default:
+		st.addError(appendLocation(kloc, keyOneOf), iloc, "value matches the oneOf schemas at %v", matched)
+	}
+}
+
+// reportNot reports the result of the "not" keyword, where matched reports whether the not schema matched.
+func (st *state) reportNot(matched bool, kloc, iloc string) {
+	if matched {
+		st.addError(appendLocation(kloc, keyNot), iloc, "value must not match the not schema")
+	}
+}
+
+// sortedInstanceKeys returns the sorted keys of obj.
+func sortedInstanceKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Interface returns the value of c as a JSON value.
+//
+// The Value of the array Const holds the elements, otherwise the Value holds the single value.
+func (c *Const) Interface() interface{} {
+	if c.Type == ArrayType {
+		if c.Value == nil {
+			return []interface{}{}
+		}
+		return c.Value
+	}
+	if len(c.Value) == 0 {
+		return nil
+	}
+
+	return c.Value[0]
+}
+
+// normalizeInstance converts the numeric value of inst to json.Number.
+func normalizeInstance(inst interface{}) interface{} {
+	switch v := inst.(type) {
+	case float64:
+		return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+	case float32:
+		return json.Number(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	case int:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case int8:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case int16:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case int32:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10))
+	case uint:
+		return json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint8:
+		return json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint16:
+		return json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint32:
+		return json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10))
+	}
+
+	return inst
+}
+
+// instanceType returns the Type of the inst.
+func instanceType(inst interface{}) Type {
+	switch v := normalizeInstance(inst).(type) {
+	case nil:
+		return NullType
+	case bool:
+		return BooleanType
+	case string:
+		return StringType
+	case []interface{}:
+		return ArrayType
+	case map[string]interface{}:
+		return ObjectType
+	case json.Number:
+		if newNumber(v).isInt() {
+			return IntegerType
+		}
+		return NumberType
+	}
+
+	return UnspecifiedType
+}
+
+// numberRat returns n as a big.Rat.
+func numberRat(n json.Number) (*big.Rat, bool) {
+	return new(big.Rat).SetString(string(n))
+}
+
+// floatRat returns f as a big.Rat of its shortest decimal representation.
+func floatRat(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return r
+}
+
+// number is a JSON number to compare with the float64 keywords.
+//
+// The big.Rat refuses the number whose exponent is too large, such as 1e9999999, so such a number falls back to the
+// float64, which is ±Inf if the magnitude overflows, or the signed zero if it underflows.
+type number struct {
+	n    json.Number
+	r    *big.Rat
+	f    float64
+	tiny bool // the non-zero magnitude underflows to f
+}
+
+// newNumber returns n as a number.
+func newNumber(n json.Number) number {
+	if r, ok := numberRat(n); ok {
+		return number{n: n, r: r}
+	}
+	f, _ := strconv.ParseFloat(string(n), 64)
+	if f != 0 {
+		return number{n: n, f: f}
+	}
+	mant, _ := decimalParts(n)
+	return number{n: n, f: f, tiny: mant != nil && mant.Sign() != 0}
+}
+
+// cmp compares x and f, and returns -1, 0 or +1.
+func (x number) cmp(f float64) int {
+	switch {
+	case x.r != nil:
+		return x.r.Cmp(floatRat(f))
+	case x.f < f:
+		return -1
+	case x.f > f:
+		return +1
+	case x.tiny && math.Signbit(x.f):
+		return -1
+	case x.tiny:
+		return +1
+	}
+	return 0
+}
+
+// isInt reports whether x is an integer.
+func (x number) isInt() bool {
+	switch {
+	case x.r != nil:
+		return x.r.IsInt()
+	case x.tiny:
+		return false
+	}
+	return math.IsInf(x.f, 0) || x.f == math.Trunc(x.f)
+}
+
+// multipleOf reports whether x is a multiple of the positive m.
+func (x number) multipleOf(m float64) bool {
+	switch {
+	case x.r != nil:
+		return new(big.Rat).Quo(x.r, floatRat(m)).IsInt()
+	case x.tiny:
+		return false
+	case x.f == 0:
+		return true
+	case !math.IsInf(x.f, 0):
+		return math.Mod(x.f, m) == 0
+	}
+
+	// x is mant*10^exp and m is p/q, so x/m is an integer iff p divides mant*q*10^exp. p has fewer than k factors of
+	// 2 and 5, with k the bit length of p, so 10^exp can be cut down to 10^k for any exp >= k.
+	mant, exp := decimalParts(x.n)
+	rm := floatRat(m)
+	k := int64(rm.Num().BitLen())
+	if !exp.IsInt64() || exp.Int64() >= k {
+		t := new(big.Int).Mul(mant, rm.Denom())
+		t.Mul(t, new(big.Int).Exp(big.NewInt(10), big.NewInt(k), nil))
+		return new(big.Int).Mod(t, rm.Num()).Sign() == 0
+	}
+	r := new(big.Rat).SetInt(mant)
+	if e := exp.Int64(); e >= 0 {
+		r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(e), nil)))
+	} else {
+		r.Quo(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(-e), nil)))
+	}
+	return new(big.Rat).Quo(r, rm).IsInt()
+}
+
+// decimalParts returns the unsigned integer mantissa and the exponent of n, such that |n| = mant*10^exp.
+func decimalParts(n json.Number) (mant, exp *big.Int) {
+	s := strings.TrimPrefix(string(n), "-")
+	exp = new(big.Int)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp.SetString(strings.TrimPrefix(s[i+1:], "+"), 10)
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		exp.Sub(exp, big.NewInt(int64(len(s)-i-1)))
+		s = s[:i] + s[i+1:]
+	}
+	mant, _ = new(big.Int).SetString(s, 10)
+	return mant, exp
+}
+
+// equal reports whether a and b are the equal JSON values.
+func equal(a, b interface{}) bool {
+	a, b = normalizeInstance(a), normalizeInstance(b)
+
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		ra, aok := numberRat(a)
+		rb, bok := numberRat(b)
+		return aok && bok && ra.Cmp(rb) == 0
+
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, av := range a {
+			bv, ok := b[k]
+			if !ok || !equal(av, bv) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// jsonString returns the JSON encoding of v for the error messages.
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,602 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// ValidateReader validates the JSON instance read from r against the compiled Schema.
+//
+// Unlike Validate, the instance is not decoded into memory. The keywords are evaluated incrementally as the
+// tokens of the instance arrive, so the memory is bounded by the depth of the instance and the Schema, not by
+// the size of the instance. Only the objects and arrays which are evaluated by the keywords requiring the whole
+// value, that is the "uniqueItems", the "const" and "enum" of the objects or arrays, and the schema
+// "dependencies", are buffered.
+//
+// ValidateReader returns ValidationErrors if the instance is invalid, or an error if r is not a single JSON value.
+// The order of the ValidationErrors follows the order of the instance, and may differ from Validate.
+func (v *Validator) ValidateReader(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	sv := &streamValidator{dec: dec}
+
+	root := &streamEval{v: v, s: v.root}
+	tok, err := sv.token()
+	if err != nil {
+		return err
+	}
+	if err := sv.walk(tok, []*streamEval{root}); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("unexpected data after the instance")
+		}
+		return fmt.Errorf("jsonschema: %v", err)
+	}
+
+	if len(root.errs) == 0 {
+		return nil
+	}
+
+	return root.errs
+}
+
+// streamValidator represents a state of the ValidateReader.
+type streamValidator struct {
+	dec *json.Decoder
+	// builders are the values which are being buffered.
+	builders []*valueBuilder
+}
+
+// token returns the next token, and feeds it to the builders.
+func (sv *streamValidator) token() (json.Token, error) {
+	tok, err := sv.dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("jsonschema: %v", err)
+	}
+
+	if len(sv.builders) > 0 {
+		active := sv.builders[:0]
+		for _, b := range sv.builders {
+			b.add(tok)
+			if !b.done {
+				active = append(active, b)
+			}
+		}
+		sv.builders = active
+	}
+
+	return tok, nil
+}
+
+// walk consumes the value which starts with tok, and evaluates the evals against it.
+func (sv *streamValidator) walk(tok json.Token, evals []*streamEval) error {
+	switch tok {
+	case json.Delim('{'):
+		for _, e := range evals {
+			e.begin(sv, tok, ObjectType)
+		}
+		for sv.dec.More() {
+			kt, err := sv.token()
+			if err != nil {
+				return err
+			}
+			key, _ := kt.(string)
+			var children []*streamEval
+			for _, e := range evals {
+				children = e.property(key, children)
+			}
+			vt, err := sv.token()
+			if err != nil {
+				return err
+			}
+			if err := sv.walk(vt, children); err != nil {
+				return err
+			}
+		}
+		if _, err := sv.token(); err != nil {
+			return err
+		}
+		for _, e := range evals {
+			e.end()
+		}
+
+	case json.Delim('['):
+		for _, e := range evals {
+			e.begin(sv, tok, ArrayType)
+		}
+		for sv.dec.More() {
+			var children []*streamEval
+			for _, e := range evals {
+				children = e.item(children)
+			}
+			vt, err := sv.token()
+			if err != nil {
+				return err
+			}
+			if err := sv.walk(vt, children); err != nil {
+				return err
+			}
+		}
+		if _, err := sv.token(); err != nil {
+			return err
+		}
+		for _, e := range evals {
+			e.end()
+		}
+
+	default:
+		for _, e := range evals {
+			e.scalar(tok)
+		}
+	}
+
+	for _, e := range evals {
+		e.finish()
+	}
+
+	return nil
+}
+
+// streamEval represents an evaluation of the Schema against a value of the instance.
+type streamEval struct {
+	v          *Validator
+	s          *Schema
+	kloc, iloc string
+	refDepth   int
+	parent     *streamEval
+	// contains reports whether the value is an item which is evaluated by the "contains" keyword of the parent.
+	contains bool
+
+	errs      ValidationErrors
+	childErrs ValidationErrors
+
+	// typ is the Type of the object or array value.
+	typ Type
+	// skip reports whether the children of the value are not evaluated.
+	skip bool
+	// buf is the buffered value if the Schema requires the whole value.
+	buf *valueBuilder
+
+	ref                             *streamEval
+	allOf, anyOf, oneOf             []*streamEval
+	not, ifEval, thenEval, elseEval *streamEval
+
+	count         int64
+	seen          map[string]bool
+	patterns      []string
+	containsFound bool
+}
+
+// state returns the state to evaluate the keywords directly.
+func (e *streamEval) state() *state {
+	return &state{v: e.v, refDepth: e.refDepth}
+}
+
+// sub returns the evaluation of s against the same value.
+func (e *streamEval) sub(s *Schema, kloc string) *streamEval {
+	return &streamEval{v: e.v, s: s, kloc: kloc, iloc: e.iloc, refDepth: e.refDepth}
+}
+
+// child appends the evaluation of s against the child value located at iloc to evals.
+func (e *streamEval) child(evals []*streamEval, s *Schema, kloc, iloc string) []*streamEval {
+	if s == nil {
+		return evals
+	}
+
+	return append(evals, &streamEval{v: e.v, s: s, kloc: kloc, iloc: iloc, refDepth: e.refDepth, parent: e})
+}
+
+// scalar evaluates the scalar value tok.
+func (e *streamEval) scalar(tok json.Token) {
+	st := e.state()
+	st.validate(e.s, tok, e.kloc, e.iloc)
+	e.errs = st.errs
+}
+
+// begin starts the evaluation of the object or array, which starts with tok.
+func (e *streamEval) begin(sv *streamValidator, tok json.Token, typ Type) {
+	s := e.s
+	e.typ = typ
+	if s == nil {
+		e.skip = true
+		return
+	}
+	if s.Bool != nil {
+		if !*s.Bool {
+			st := e.state()
+			st.validate(s, nil, e.kloc, e.iloc)
+			e.errs = st.errs
+		}
+		e.skip = true
+		return
+	}
+
+	if s.Ref != "" {
+		st := e.state()
+		target := st.refTarget(s, e.kloc, e.iloc)
+		if target == nil {
+			e.errs = st.errs
+			e.skip = true
+			return
+		}
+		e.ref = e.sub(target, appendLocation(e.kloc, keyRef))
+		e.ref.refDepth++
+		e.ref.begin(sv, tok, typ)
+		return
+	}
+
+	var placeholder interface{} = []interface{}{}
+	if typ == ObjectType {
+		placeholder = map[string]interface{}{}
+	}
+	if needsBuffer(s, typ) {
+		e.buf = &valueBuilder{}
+		e.buf.add(tok)
+		sv.builders = append(sv.builders, e.buf)
+		return
+	}
+
+	st := e.state()
+	st.validateType(s, placeholder, e.kloc, e.iloc)
+	// the "const" and "enum" never match if no values are the object or array, see needsBuffer.
+	st.validateConst(s, placeholder, e.kloc, e.iloc)
+	e.errs = st.errs
+
+	if typ == ObjectType {
+		e.seen = trackedNames(s)
+		for expr := range s.PatternProperties {
+			e.patterns = append(e.patterns, expr)
+		}
+		sort.Strings(e.patterns)
+	}
+
+	for i, sub := range s.AllOf {
+		e.allOf = append(e.allOf, e.sub(sub, appendLocation(e.kloc, keyAllOf, strconv.Itoa(i))))
+	}
+	for i, sub := range s.AnyOf {
+		e.anyOf = append(e.anyOf, e.sub(sub, appendLocation(e.kloc, keyAnyOf, strconv.Itoa(i))))
+	}
+	for i, sub := range s.OneOf {
+		e.oneOf = append(e.oneOf, e.sub(sub, appendLocation(e.kloc, keyOneOf, strconv.Itoa(i))))
+	}
+	if s.Not != nil {
+		e.not = e.sub(s.Not, appendLocation(e.kloc, keyNot))
+	}
+	if s.If != nil {
+		e.ifEval = e.sub(s.If, appendLocation(e.kloc, keyIf))
+		e.thenEval = e.sub(s.Then, appendLocation(e.kloc, keyThen))
+		e.elseEval = e.sub(s.Else, appendLocation(e.kloc, keyElse))
+	}
+	e.eachSub(func(sub *streamEval) { sub.begin(sv, tok, typ) })
+}
+
+// trackedNames returns the property names whose presence is evaluated by the "required" and "dependencies" of s.
+//
+// Only the presence of these names are recorded, so the memory is not proportional to the number of properties.
+func trackedNames(s *Schema) map[string]bool {
+	if len(s.Required) == 0 && s.Dependencies == nil {
+		return nil
+	}
+
+	names := make(map[string]bool)
+	for _, req := range s.Required {
+		names[req.Value] = false
+	}
+	if s.Dependencies != nil {
+		for name, deps := range s.Dependencies.Names {
+			names[name] = false
+			for _, dep := range deps {
+				names[dep] = false
+			}
+		}
+	}
+
+	return names
+}
+
+// needsBuffer reports whether s requires the whole object or array value of the typ.
+func needsBuffer(s *Schema, typ Type) bool {
+	if typ == ArrayType && s.UniqueItems {
+		return true
+	}
+	if typ == ObjectType && s.Dependencies != nil && len(s.Dependencies.Schemas) > 0 {
+		return true
+	}
+
+	if s.Const != nil && constType(s.Const.Interface()) == typ {
+		return true
+	}
+	for _, c := range s.Enum {
+		if constType(c.Interface()) == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// constType returns the Type of the "const" or "enum" value v, which is either the object, the array or unspecified.
+func constType(v interface{}) Type {
+	switch v.(type) {
+	case map[string]interface{}:
+		return ObjectType
+	case []interface{}:
+		return ArrayType
+	}
+
+	return UnspecifiedType
+}
+
+// eachSub calls fn with the evaluations of the combinators.
+func (e *streamEval) eachSub(fn func(sub *streamEval)) {
+	if e.ref != nil {
+		fn(e.ref)
+	}
+	for _, sub := range e.allOf {
+		fn(sub)
+	}
+	for _, sub := range e.anyOf {
+		fn(sub)
+	}
+	for _, sub := range e.oneOf {
+		fn(sub)
+	}
+	for _, sub := range []*streamEval{e.not, e.ifEval, e.thenEval, e.elseEval} {
+		if sub != nil {
+			fn(sub)
+		}
+	}
+}
+
+// property appends the evaluations of the name property value to children.
+func (e *streamEval) property(name string, children []*streamEval) []*streamEval {
+	if e.skip || e.buf != nil {
+		return children
+	}
+	e.eachSub(func(sub *streamEval) { children = sub.property(name, children) })
+	if e.ref != nil {
+		return children
+	}
+
+	s := e.s
+	e.count++
+	if _, ok := e.seen[name]; ok {
+		e.seen[name] = true
+	}
+
+	if s.PropertyNames != nil {
+		st := e.state()
+		st.validate(s.PropertyNames, name, appendLocation(e.kloc, keyPropertyNames), appendLocation(e.iloc, name))
+		e.childErrs = append(e.childErrs, st.errs...)
+	}
+
+	vloc := appendLocation(e.iloc, name)
+	matched := false
+	if prop, ok := s.Properties[name]; ok {
+		matched = true
+		children = e.child(children, prop, appendLocation(e.kloc, keyProperties, name), vloc)
+	}
+	for _, expr := range e.patterns {
+		ok, err := matchString(e.v.patterns[expr], name)
+		if err != nil {
+			st := e.state()
+			st.addError(appendLocation(e.kloc, keyPatternProperties, expr), vloc, "pattern %q: %v", expr, err)
+			e.childErrs = append(e.childErrs, st.errs...)
+			continue
+		}
+		if ok {
+			matched = true
+			children = e.child(children, s.PatternProperties[expr].Schema, appendLocation(e.kloc, keyPatternProperties, expr), vloc)
+		}
+	}
+	if !matched && s.AdditionalProperties != nil {
+		children = e.child(children, s.AdditionalProperties.Schema, appendLocation(e.kloc, keyAdditionalProperties), vloc)
+	}
+
+	return children
+}
+
+// item appends the evaluations of the next array item to children.
+func (e *streamEval) item(children []*streamEval) []*streamEval {
+	if e.skip || e.buf != nil {
+		return children
+	}
+	e.eachSub(func(sub *streamEval) { children = sub.item(children) })
+	if e.ref != nil {
+		return children
+	}
+
+	s := e.s
+	i := int(e.count)
+	e.count++
+	iloc := appendLocation(e.iloc, strconv.Itoa(i))
+
+	if s.Items != nil {
+		switch {
+		case !s.Items.HasMultiple:
+			if len(s.Items.Schemas) > 0 {
+				children = e.child(children, s.Items.Schemas[0], appendLocation(e.kloc, keyItems), iloc)
+			}
+		case i < len(s.Items.Schemas):
+			children = e.child(children, s.Items.Schemas[i], appendLocation(e.kloc, keyItems, strconv.Itoa(i)), iloc)
+		case s.AdditionalItems != nil:
+			children = e.child(children, s.AdditionalItems.Schema, appendLocation(e.kloc, keyAdditionalItems), iloc)
+		}
+	}
+
+	if s.Contains != nil && s.Contains.Schema != nil && !e.containsFound {
+		children = e.child(children, s.Contains.Schema, appendLocation(e.kloc, keyContains), iloc)
+		children[len(children)-1].contains = true
+	}
+
+	return children
+}
+
+// end ends the evaluation of the object or array.
+func (e *streamEval) end() {
+	if e.skip {
+		return
+	}
+	if e.buf != nil {
+		st := e.state()
+		st.validate(e.s, e.buf.value, e.kloc, e.iloc)
+		e.errs = st.errs
+		e.buf = nil
+		return
+	}
+	e.eachSub(func(sub *streamEval) { sub.end() })
+	if e.ref != nil {
+		e.errs = append(e.errs, e.ref.errs...)
+		return
+	}
+
+	s := e.s
+	st := e.state()
+	if e.typ == ObjectType {
+		has := func(name string) bool { return e.seen[name] }
+		st.validatePropertyCount(s, e.count, e.kloc, e.iloc)
+		st.validateRequired(s, has, e.kloc, e.iloc)
+		if s.Dependencies != nil {
+			names := make([]string, 0, len(e.seen))
+			for name, ok := range e.seen {
+				if ok {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				st.validateDependencyNames(s, name, has, e.kloc, e.iloc)
+			}
+		}
+	} else {
+		st.validateItemCount(s, e.count, e.kloc, e.iloc)
+	}
+	st.errs = append(st.errs, e.childErrs...)
+	if e.typ == ArrayType && s.Contains != nil && s.Contains.Schema != nil {
+		st.reportContains(e.containsFound, e.kloc, e.iloc)
+	}
+
+	for _, sub := range e.allOf {
+		st.errs = append(st.errs, sub.errs...)
+	}
+	if len(e.anyOf) > 0 {
+		matched := false
+		for _, sub := range e.anyOf {
+			if len(sub.errs) == 0 {
+				matched = true
+				break
+			}
+		}
+		st.reportAnyOf(matched, e.kloc, e.iloc)
+	}
+	if len(e.oneOf) > 0 {
+		var matched []int
+		for i, sub := range e.oneOf {
+			if len(sub.errs) == 0 {
+				matched = append(matched, i)
+			}
+		}
+		st.reportOneOf(matched, e.kloc, e.iloc)
+	}
+	if e.not != nil {
+		st.reportNot(len(e.not.errs) == 0, e.kloc, e.iloc)
+	}
+	if e.ifEval != nil {
+		if len(e.ifEval.errs) == 0 {
+			st.errs = append(st.errs, e.thenEval.errs...)
+		} else {
+			st.errs = append(st.errs, e.elseEval.errs...)
+		}
+	}
+
+	e.errs = append(e.errs, st.errs...)
+}
+
+// finish reports the result of the evaluation to the parent.
+func (e *streamEval) finish() {
+	p := e.parent
+	if p == nil {
+		return
+	}
+	if e.contains {
+		if len(e.errs) == 0 {
+			p.containsFound = true
+		}
+		return
+	}
+	p.childErrs = append(p.childErrs, e.errs...)
+}
+
+// valueBuilder builds the value from the tokens.
+type valueBuilder struct {
+	stack []*builderFrame
+	value interface{}
+	done  bool
+}
+
+// builderFrame represents an object or array which is being built.
+type builderFrame struct {
+	obj    map[string]interface{}
+	arr    []interface{}
+	key    string
+	hasKey bool
+}
+
+// add adds the token to the value.
+func (b *valueBuilder) add(tok json.Token) {
+	switch tok {
+	case json.Delim('{'):
+		b.stack = append(b.stack, &builderFrame{obj: make(map[string]interface{})})
+		return
+	case json.Delim('['):
+		b.stack = append(b.stack, &builderFrame{arr: []interface{}{}})
+		return
+	case json.Delim('}'), json.Delim(']'):
+		f := b.stack[len(b.stack)-1]
+		b.stack = b.stack[:len(b.stack)-1]
+		if f.obj != nil {
+			b.put(f.obj)
+		} else {
+			b.put(f.arr)
+		}
+		return
+	}
+
+	if n := len(b.stack); n > 0 && b.stack[n-1].obj != nil && !b.stack[n-1].hasKey {
+		b.stack[n-1].key, _ = tok.(string)
+		b.stack[n-1].hasKey = true
+		return
+	}
+	b.put(tok)
+}
+
+// put puts the completed value v into the current object or array.
+func (b *valueBuilder) put(v interface{}) {
+	n := len(b.stack)
+	if n == 0 {
+		b.value = v
+		b.done = true
+		return
+	}
+
+	f := b.stack[n-1]
+	if f.obj != nil {
+		f.obj[f.key] = v
+		f.hasKey = false
+		return
+	}
+	f.arr = append(f.arr, v)
+}
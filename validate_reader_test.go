@@ -0,0 +1,184 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// sortedErrors returns the ValidationErrors of err sorted by the locations and the message.
+func sortedErrors(tb testing.TB, err error) ValidationErrors {
+	tb.Helper()
+
+	if err == nil {
+		return nil
+	}
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		tb.Fatalf("error = %v, want ValidationErrors", err)
+	}
+	errs = append(ValidationErrors(nil), errs...)
+	sort.Slice(errs, func(i, j int) bool {
+		a, b := errs[i], errs[j]
+		if a.InstanceLocation != b.InstanceLocation {
+			return a.InstanceLocation < b.InstanceLocation
+		}
+		if a.KeywordLocation != b.KeywordLocation {
+			return a.KeywordLocation < b.KeywordLocation
+		}
+		return a.Message < b.Message
+	})
+
+	return errs
+}
+
+func TestValidateReader(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    string
+		instances []string
+	}{
+		{
+			name:      "scalar",
+			schema:    `{"type": "integer", "minimum": 2, "multipleOf": 2}`,
+			instances: []string{`4`, `3`, `1.5`, `"4"`, `null`},
+		},
+		{
+			name:   "object",
+			schema: `{"type": "object", "required": ["a", "b"], "properties": {"a": {"type": "string", "maxLength": 2}}, "patternProperties": {"^x": {"type": "number"}}, "additionalProperties": false, "minProperties": 2, "propertyNames": {"maxLength": 3}}`,
+			instances: []string{
+				`{"a": "ab", "b": 1}`,
+				`{"a": "abc", "x1": "no", "long": 1}`,
+				`{}`,
+			},
+		},
+		{
+			name:   "array",
+			schema: `{"type": "array", "items": [{"type": "string"}, {"type": "integer"}], "additionalItems": {"type": "boolean"}, "contains": {"const": true}, "maxItems": 3}`,
+			instances: []string{
+				`["a", 1, true]`,
+				`[1, "a", false, null]`,
+				`[]`,
+			},
+		},
+		{
+			name:   "buffered",
+			schema: `{"uniqueItems": true, "items": {"enum": [{"a": 1}, [1, 2], 3]}}`,
+			instances: []string{
+				`[{"a": 1}, [1, 2], 3]`,
+				`[{"a": 1}, {"a": 1.0}]`,
+				`[[1, 2, 3]]`,
+			},
+		},
+		{
+			name:   "applicators",
+			schema: `{"allOf": [{"type": "object"}], "anyOf": [{"required": ["a"]}, {"required": ["b"]}], "oneOf": [{"properties": {"a": {"type": "integer"}}}, {"properties": {"a": {"type": "string"}}}], "not": {"required": ["c"]}, "if": {"required": ["a"]}, "then": {"properties": {"a": {"minimum": 1}}}, "else": {"required": ["d"]}}`,
+			instances: []string{
+				`{"a": 1}`,
+				`{"a": 0}`,
+				`{"b": 1, "c": 2}`,
+				`{"a": null}`,
+			},
+		},
+		{
+			name:   "dependencies",
+			schema: `{"dependencies": {"a": ["b"], "c": {"required": ["d"], "properties": {"d": {"type": "string"}}}}}`,
+			instances: []string{
+				`{"a": 1, "b": 2}`,
+				`{"a": 1}`,
+				`{"c": 1, "d": 2}`,
+			},
+		},
+		{
+			name:   "ref",
+			schema: `{"definitions": {"node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/definitions/node"}}, "value": {"type": "integer"}}}}, "$ref": "#/definitions/node"}`,
+			instances: []string{
+				`{"value": 1, "children": [{"value": 2, "children": []}]}`,
+				`{"value": 1, "children": [{"value": "2", "children": [{"value": 3.5}]}]}`,
+			},
+		},
+		{
+			name:      "huge number",
+			schema:    `{"type": "integer", "maximum": 5, "exclusiveMinimum": 0, "multipleOf": 2}`,
+			instances: []string{`1e9999999`, `-1e9999999`, `1e-9999999`, `0e9999999`},
+		},
+		{
+			name:      "boolean schema",
+			schema:    `{"properties": {"a": true, "b": false}}`,
+			instances: []string{`{"a": [1, {"x": null}]}`, `{"b": {"c": [1]}}`},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := MustCompile(mustSchema(t, tt.schema))
+			for _, inst := range tt.instances {
+				got := sortedErrors(t, v.ValidateReader(strings.NewReader(inst)))
+				want := sortedErrors(t, v.Validate(mustInstance(t, inst)))
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("ValidateReader(%s) = %v, want %v", inst, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateReaderError(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "empty", input: "", wantErr: "unexpected EOF"},
+		{name: "truncated", input: `[1, {"a": `, wantErr: "unexpected EOF"},
+		{name: "trailing", input: `1 2`, wantErr: "unexpected data after the instance"},
+		{name: "syntax", input: `{"a" 1}`, wantErr: "invalid character"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := MustCompile(&Schema{}).ValidateReader(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateReader(%q) = %v, want %q", tt.input, err, tt.wantErr)
+			}
+			var verr ValidationErrors
+			if errors.As(err, &verr) {
+				t.Errorf("ValidateReader(%q) = %T, want the decoding error", tt.input, err)
+			}
+		})
+	}
+}
+
+func TestValidateReaderLarge(t *testing.T) {
+	v := MustCompile(mustSchema(t, `{"type": "array", "items": {"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}}}}`))
+
+	const n = 100000
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		if i == n-1 {
+			b.WriteString(`{"id": "last"}`)
+			continue
+		}
+		b.WriteString(`{"id": 1}`)
+	}
+	b.WriteString("]")
+
+	errs := sortedErrors(t, v.ValidateReader(strings.NewReader(b.String())))
+	if len(errs) != 1 || errs[0].InstanceLocation != "/99999/id" {
+		t.Errorf("ValidateReader() = %v, want the error at /99999/id", errs)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"runtime"
+
+	"github.com/francoispqt/gojay"
+)
+
+// Result represents a validation result of an instance of the ValidateStream.
+type Result struct {
+	// Line is the 1-based line number of the instance.
+	Line int
+
+	// Err is nil if the instance is valid, ValidationErrors if the instance is invalid,
+	// or the other error if the line is not a JSON value or cannot be read.
+	Err error
+}
+
+var (
+	// compile time check whether the Result implements gojay.MarshalerJSONObject interface.
+	_ gojay.MarshalerJSONObject = &Result{}
+)
+
+// Valid reports whether the instance is valid.
+func (r *Result) Valid() bool {
+	return r.Err == nil
+}
+
+// MarshalJSONObject implements gojay.MarshalerJSONObject.
+func (r *Result) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.IntKey(keyLine, r.Line)
+	enc.BoolKey(keyValid, r.Valid())
+	switch err := r.Err.(type) {
+	case nil:
+	case ValidationErrors:
+		enc.ArrayKey(keyErrors, err)
+	default:
+		enc.StringKey(keyError, err.Error())
+	}
+}
+
+// IsNil implements gojay.MarshalerJSONObject.
+//
+// IsNil checks if instance is nil.
+func (r *Result) IsNil() bool {
+	return r == nil
+}
+
+// job represents an instance which is validated by the worker of the ValidateStream.
+type job struct {
+	line   int
+	data   []byte
+	result chan Result
+}
+
+// ValidateStream validates the newline-delimited JSON instances read from r against v.
+//
+// The instances are validated concurrently by the workers, and the results are sent to the returned channel
+// in the input order, one per non-blank line. The channel is closed when r reaches io.EOF, r fails to read,
+// or ctx is done.
+//
+// The number of the instances which are read ahead is bounded, so the reading blocks until the results are
+// received. The canceled ctx stops the reading, but the blocked Read of r is not interrupted.
+//
+// ValidateStream splits r by the lines instead of the gojay.StreamDecoder of the *Stream types, because the
+// StreamDecoder splits the values by the whitespace without their line numbers, stops at the first malformed value,
+// and decodes the numbers of the interface values to float64. A malformed line is reported by its Result instead,
+// and the following lines are still validated with the exact numbers.
+func ValidateStream(ctx context.Context, r io.Reader, v *Validator) <-chan Result {
+	workers := runtime.GOMAXPROCS(0)
+	out := make(chan Result)
+	jobs := make(chan *job)
+	pending := make(chan *job, 2*workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := range jobs {
+				j.result <- validateLine(v, j.line, j.data)
+			}
+		}()
+	}
+
+	go readLines(ctx, r, jobs, pending)
+
+	go func() {
+		defer close(out)
+		for j := range pending {
+			var res Result
+			select {
+			case res = <-j.result:
+			case <-ctx.Done():
+				go drain(pending)
+				return
+			}
+			select {
+			case out <- res:
+			case <-ctx.Done():
+				go drain(pending)
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// readLines reads the lines from r, and sends the jobs to both the workers and the pending queue.
+func readLines(ctx context.Context, r io.Reader, jobs, pending chan<- *job) {
+	defer close(pending)
+	defer close(jobs)
+
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(data)) > 0 {
+			j := &job{line: line, data: data, result: make(chan Result, 1)}
+			// the pending queue is sent first, which bounds the number of the read ahead instances.
+			select {
+			case pending <- j:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- j:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				j := &job{line: line, result: make(chan Result, 1)}
+				j.result <- Result{Line: line, Err: err}
+				select {
+				case pending <- j:
+				case <-ctx.Done():
+				}
+			}
+			return
+		}
+	}
+}
+
+// drain discards the remaining jobs until the pending queue is closed.
+func drain(pending <-chan *job) {
+	for range pending {
+	}
+}
+
+// validateLine validates the instance of the line.
+func validateLine(v *Validator, line int, data []byte) Result {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var inst interface{}
+	if err := dec.Decode(&inst); err != nil {
+		return Result{Line: line, Err: err}
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return Result{Line: line, Err: errors.New("invalid character after the instance")}
+	}
+
+	return Result{Line: line, Err: v.Validate(inst)}
+}
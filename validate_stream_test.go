@@ -0,0 +1,187 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/francoispqt/gojay"
+)
+
+func TestValidateStream(t *testing.T) {
+	v := MustCompile(mustSchema(t, `{"type": "object", "required": ["id"]}`))
+
+	const input = "{\"id\": 1}\n" +
+		"\n" +
+		"{\"name\": \"a\"}\r\n" +
+		"   \n" +
+		"{\"id\": \n" +
+		"[1] 2\n" +
+		"{\"id\": 3}"
+	tests := []struct {
+		line    int
+		valid   bool
+		wantErr string
+	}{
+		{line: 1, valid: true},
+		{line: 3, wantErr: `missing required property "id"`},
+		{line: 5, wantErr: "unexpected EOF"},
+		{line: 6, wantErr: "invalid character after the instance"},
+		{line: 7, valid: true},
+	}
+
+	var got []Result
+	for res := range ValidateStream(context.Background(), strings.NewReader(input), v) {
+		got = append(got, res)
+	}
+	if len(got) != len(tests) {
+		t.Fatalf("ValidateStream() = %d results, want %d: %v", len(got), len(tests), got)
+	}
+	for i, tt := range tests {
+		res := got[i]
+		if res.Line != tt.line {
+			t.Errorf("result %d: Line = %d, want %d", i, res.Line, tt.line)
+		}
+		if res.Valid() != tt.valid {
+			t.Errorf("line %d: Valid() = %t, want %t (%v)", res.Line, res.Valid(), tt.valid, res.Err)
+		}
+		if tt.wantErr != "" && (res.Err == nil || !strings.Contains(res.Err.Error(), tt.wantErr)) {
+			t.Errorf("line %d: Err = %v, want %q", res.Line, res.Err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateStreamOrder(t *testing.T) {
+	v := MustCompile(mustSchema(t, `{"type": "integer", "multipleOf": 3}`))
+
+	const n = 1000
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "%d\n", i)
+	}
+
+	line := 0
+	for res := range ValidateStream(context.Background(), strings.NewReader(b.String()), v) {
+		line++
+		if res.Line != line {
+			t.Fatalf("Line = %d, want %d", res.Line, line)
+		}
+		if want := line%3 == 0; res.Valid() != want {
+			t.Errorf("line %d: Valid() = %t, want %t", line, res.Valid(), want)
+		}
+	}
+	if line != n {
+		t.Errorf("ValidateStream() = %d results, want %d", line, n)
+	}
+}
+
+// errReader returns the data, and then the err.
+type errReader struct {
+	data string
+	err  error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+
+	return n, nil
+}
+
+func TestValidateStreamReadError(t *testing.T) {
+	errRead := errors.New("read error")
+	r := &errReader{data: "1\n2", err: errRead}
+
+	var got []Result
+	for res := range ValidateStream(context.Background(), r, MustCompile(&Schema{})) {
+		got = append(got, res)
+	}
+	if len(got) != 3 {
+		t.Fatalf("ValidateStream() = %v, want 3 results", got)
+	}
+	if !got[0].Valid() || !got[1].Valid() {
+		t.Errorf("ValidateStream() = %v, want the lines before the error valid", got)
+	}
+	if got[2].Line != 2 || !errors.Is(got[2].Err, errRead) {
+		t.Errorf("ValidateStream() last result = %+v, want the read error of line 2", got[2])
+	}
+}
+
+// endlessReader returns the endless lines of the instance.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	const line = "{}\n"
+	n := 0
+	for n+len(line) <= len(p) {
+		n += copy(p[n:], line)
+	}
+
+	return n, nil
+}
+
+func TestValidateStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	results := ValidateStream(ctx, endlessReader{}, MustCompile(&Schema{}))
+
+	for i := 0; i < 10; i++ {
+		if res := <-results; !res.Valid() {
+			t.Fatalf("line %d: %v", res.Line, res.Err)
+		}
+	}
+	cancel()
+
+	// the results which were being sent may still arrive before the channel is closed.
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case _, ok := <-results:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("the results channel is not closed after the cancel")
+		}
+	}
+}
+
+func TestResultMarshalJSONObject(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *Result
+		want   string
+	}{
+		{name: "valid", result: &Result{Line: 1}, want: `{"line":1,"valid":true}`},
+		{
+			name:   "invalid",
+			result: &Result{Line: 2, Err: ValidationErrors{{KeywordLocation: "/type", InstanceLocation: "", Message: "expected integer, but got string"}}},
+			want:   `{"line":2,"valid":false,"errors":[{"keywordLocation":"/type","instanceLocation":"","message":"expected integer, but got string"}]}`,
+		},
+		{name: "error", result: &Result{Line: 3, Err: io.ErrUnexpectedEOF}, want: `{"line":3,"valid":false,"error":"unexpected EOF"}`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := gojay.MarshalJSONObject(tt.result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("MarshalJSONObject() = %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// mustSchema decodes the JSON schema, and fails tb if it is invalid.
+func mustSchema(tb testing.TB, data string) *Schema {
+	tb.Helper()
+
+	s := &Schema{}
+	if err := s.UnmarshalJSON([]byte(data)); err != nil {
+		tb.Fatalf("unmarshal schema %s: %v", data, err)
+	}
+
+	return s
+}
+
+// mustInstance decodes the JSON instance whose numbers are json.Number, and fails tb if it is invalid.
+func mustInstance(tb testing.TB, data string) interface{} {
+	tb.Helper()
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(data)))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		tb.Fatalf("unmarshal instance %s: %v", data, err)
+	}
+
+	return v
+}
+
+func TestValidateConstEnum(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		instance string
+		want     bool
+	}{
+		{name: "const string", schema: `{"const": "foo"}`, instance: `"foo"`, want: true},
+		{name: "const string mismatch", schema: `{"const": "foo"}`, instance: `"bar"`, want: false},
+		{name: "const number", schema: `{"const": 1}`, instance: `1`, want: true},
+		{name: "const number is float", schema: `{"const": 1}`, instance: `1.0`, want: true},
+		{name: "const number mismatch", schema: `{"const": 1}`, instance: `2`, want: false},
+		{name: "const number is not string", schema: `{"const": 1}`, instance: `"1"`, want: false},
+		{name: "const large number", schema: `{"const": 9007199254740993}`, instance: `9007199254740992`, want: false},
+		{name: "const null", schema: `{"const": null}`, instance: `null`, want: true},
+		{name: "const false", schema: `{"const": false}`, instance: `0`, want: false},
+		{name: "const array", schema: `{"const": [1, "a"]}`, instance: `[1.0, "a"]`, want: true},
+		{name: "const object", schema: `{"const": {"a": [1]}}`, instance: `{"a": [1]}`, want: true},
+		{name: "const object mismatch", schema: `{"const": {"a": [1]}}`, instance: `{"a": [1], "b": 2}`, want: false},
+		{name: "enum string", schema: `{"enum": ["foo", 1]}`, instance: `"foo"`, want: true},
+		{name: "enum number", schema: `{"enum": ["foo", 1]}`, instance: `1`, want: true},
+		{name: "enum mismatch", schema: `{"enum": ["foo", 1]}`, instance: `"1"`, want: false},
+		{name: "enum array", schema: `{"enum": [[1, 2], null]}`, instance: `[1, 2]`, want: true},
+		{name: "enum null", schema: `{"enum": [[1, 2], null]}`, instance: `null`, want: true},
+		{name: "enum array mismatch", schema: `{"enum": [[1, 2], null]}`, instance: `[2, 1]`, want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := MustCompile(mustSchema(t, tt.schema))
+			err := v.Validate(mustInstance(t, tt.instance))
+			if got := err == nil; got != tt.want {
+				t.Errorf("Validate(%s) = %v, want valid %t", tt.instance, err, tt.want)
+			}
+			if got := v.IsValid(mustInstance(t, tt.instance)); got != tt.want {
+				t.Errorf("IsValid(%s) = %t, want %t", tt.instance, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConstEnumRoundTrip(t *testing.T) {
+	tests := []string{
+		`{"const":"foo"}`,
+		`{"const":1.5}`,
+		`{"const":[1,"a",null]}`,
+		`{"const":{"a":true}}`,
+		`{"enum":["foo",1,null,[true],{"a":"b"}]}`,
+	}
+	for _, data := range tests {
+		b, err := mustSchema(t, data).MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != data {
+			t.Errorf("MarshalJSON() = %s, want %s", b, data)
+		}
+	}
+}
+
+func TestDraft04ExclusiveRoundTrip(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{data: `{"maximum":5,"exclusiveMaximum":true}`, want: `{"exclusiveMaximum":5}`},
+		{data: `{"exclusiveMinimum":true,"minimum":0}`, want: `{"exclusiveMinimum":0}`},
+		{data: `{"maximum":5,"exclusiveMaximum":false}`, want: `{"maximum":5}`},
+		{data: `{"exclusiveMaximum":true}`, want: `{}`},
+		{data: `{"exclusiveMaximum":3}`, want: `{"exclusiveMaximum":3}`},
+	}
+	for _, tt := range tests {
+		b, err := mustSchema(t, tt.data).MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.want {
+			t.Errorf("MarshalJSON(%s) = %s, want %s", tt.data, b, tt.want)
+		}
+		b2, err := mustSchema(t, string(b)).MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b2) != string(b) {
+			t.Errorf("MarshalJSON(%s) = %s, want the stable %s", b, b2, b)
+		}
+	}
+
+	v := MustCompile(mustSchema(t, `{"$schema": "http://json-schema.org/draft-04/schema#", "maximum": 5, "exclusiveMaximum": true}`))
+	if v.IsValid(mustInstance(t, `5`)) || !v.IsValid(mustInstance(t, `4.5`)) {
+		t.Error("the draft-04 boolean exclusiveMaximum does not make the maximum exclusive")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		instance string
+		want     []*ValidationError
+	}{
+		{
+			name:     "valid",
+			schema:   `{"type": "object", "properties": {"a": {"type": "string"}}}`,
+			instance: `{"a": "x"}`,
+		},
+		{
+			name:     "type",
+			schema:   `{"type": ["string", "null"]}`,
+			instance: `1`,
+			want: []*ValidationError{
+				{KeywordLocation: "/type", InstanceLocation: "", Message: "expected string or null, but got integer"},
+			},
+		},
+		{
+			name:     "nested",
+			schema:   `{"properties": {"a": {"items": {"minimum": 2}}}}`,
+			instance: `{"a": [2, 1]}`,
+			want: []*ValidationError{
+				{KeywordLocation: "/properties/a/items/minimum", InstanceLocation: "/a/1", Message: "1 is less than the minimum 2"},
+			},
+		},
+		{
+			name:     "ref",
+			schema:   `{"definitions": {"pos": {"exclusiveMinimum": 0}}, "items": {"$ref": "#/definitions/pos"}}`,
+			instance: `[1, 0]`,
+			want: []*ValidationError{
+				{KeywordLocation: "/items/$ref/exclusiveMinimum", InstanceLocation: "/1", Message: "0 is less than or equal to the exclusive minimum 0"},
+			},
+		},
+		{
+			name:     "huge number",
+			schema:   `{"type": "integer", "maximum": 5, "multipleOf": 0.5}`,
+			instance: `1e9999999`,
+			want: []*ValidationError{
+				{KeywordLocation: "/maximum", InstanceLocation: "", Message: "1e9999999 is greater than the maximum 5"},
+			},
+		},
+		{
+			name:     "huge multipleOf",
+			schema:   `{"multipleOf": 7}`,
+			instance: `-1.5e9999999`,
+			want: []*ValidationError{
+				{KeywordLocation: "/multipleOf", InstanceLocation: "", Message: "-1.5e9999999 is not a multiple of 7"},
+			},
+		},
+		{
+			name:     "tiny number",
+			schema:   `{"type": "integer", "exclusiveMinimum": 0, "minimum": -1}`,
+			instance: `-1e-9999999`,
+			want: []*ValidationError{
+				{KeywordLocation: "/type", InstanceLocation: "", Message: "expected integer, but got number"},
+				{KeywordLocation: "/exclusiveMinimum", InstanceLocation: "", Message: "-1e-9999999 is less than or equal to the exclusive minimum 0"},
+			},
+		},
+		{
+			name:     "required and additionalProperties",
+			schema:   `{"required": ["a"], "additionalProperties": false}`,
+			instance: `{"b": 1}`,
+			want: []*ValidationError{
+				{KeywordLocation: "/required", InstanceLocation: "", Message: `missing required property "a"`},
+				{KeywordLocation: "/additionalProperties", InstanceLocation: "/b", Message: "false schema never matches"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := MustCompile(mustSchema(t, tt.schema)).Validate(mustInstance(t, tt.instance))
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate(%s) = %v, want nil", tt.instance, err)
+				}
+				return
+			}
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Validate(%s) = %v, want ValidationErrors", tt.instance, err)
+			}
+			if got := []*ValidationError(errs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate(%s) = %q, want %q", tt.instance, errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{name: "unresolvable ref", schema: `{"$ref": "#/definitions/missing"}`},
+		{name: "external ref", schema: `{"$ref": "http://example.com/schema.json"}`},
+		{name: "pattern is not RE2", schema: `{"pattern": "^(?=a)"}`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := Compile(mustSchema(t, tt.schema)); err == nil {
+				t.Errorf("Compile(%s) succeeds, want error", tt.schema)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/zchee/go-jsonschema/internal/reflection"
+)
+
+// maxValueDepth is the maximum depth of the nested Go value, which guards against the pointer cycles.
+const maxValueDepth = 1000
+
+var (
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+)
+
+// ValidateValue validates the Go value against the compiled Schema as if it were the equivalent JSON instance.
+//
+// The structs, maps, slices and pointers are walked directly instead of marshaling the value to JSON,
+// following the encoding/json rules: the struct fields are named by their json tags, the omitempty fields
+// with the empty values are omitted, and the []byte is the base64 string. The values which implement
+// json.Marshaler or encoding.TextMarshaler are encoded by their methods.
+//
+// ValidateValue returns ValidationErrors if the value is invalid, or an error if the value cannot be
+// encoded as JSON.
+func (v *Validator) ValidateValue(value interface{}) error {
+	inst, err := instanceOf(reflect.ValueOf(value), 0)
+	if err != nil {
+		return err
+	}
+
+	return v.Validate(inst)
+}
+
+// instanceOf returns the JSON instance of rv which Validate accepts.
+func instanceOf(rv reflect.Value, depth int) (interface{}, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	if depth > maxValueDepth {
+		return nil, fmt.Errorf("jsonschema: too deep value of %s", rv.Type())
+	}
+
+	t := rv.Type()
+	if rv.Kind() != reflect.Ptr && rv.CanAddr() && (reflect.PtrTo(t).Implements(marshalerType) || reflect.PtrTo(t).Implements(textMarshalerType)) {
+		rv = rv.Addr()
+		t = rv.Type()
+	}
+	switch {
+	case t.Implements(marshalerType):
+		if (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && rv.IsNil() {
+			return nil, nil
+		}
+		b, err := rv.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("jsonschema: marshal %s: %v", t, err)
+		}
+		return unmarshalValue(b)
+
+	case t.Implements(textMarshalerType):
+		if (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && rv.IsNil() {
+			return nil, nil
+		}
+		b, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("jsonschema: marshal %s: %v", t, err)
+		}
+		return string(b), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), nil
+
+	case reflect.Float32:
+		return float32(rv.Float()), nil
+
+	case reflect.Float64:
+		return rv.Float(), nil
+
+	case reflect.String:
+		if t == jsonNumberType {
+			if rv.Len() == 0 {
+				// encoding/json encodes the empty json.Number as 0.
+				return json.Number("0"), nil
+			}
+			return json.Number(rv.String()), nil
+		}
+		return rv.String(), nil
+
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return instanceOf(rv.Elem(), depth+1)
+
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PtrTo(t.Elem()).Implements(marshalerType) && !reflect.PtrTo(t.Elem()).Implements(textMarshalerType) {
+			return base64.StdEncoding.EncodeToString(rv.Bytes()), nil
+		}
+		return arrayInstanceOf(rv, depth)
+
+	case reflect.Array:
+		return arrayInstanceOf(rv, depth)
+
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return mapInstanceOf(rv, depth)
+
+	case reflect.Struct:
+		return structInstanceOf(rv, depth)
+	}
+
+	return nil, fmt.Errorf("jsonschema: unsupported type %s", t)
+}
+
+// arrayInstanceOf returns the JSON array of the slice or array rv.
+func arrayInstanceOf(rv reflect.Value, depth int) (interface{}, error) {
+	arr := make([]interface{}, rv.Len())
+	for i := range arr {
+		item, err := instanceOf(rv.Index(i), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = item
+	}
+
+	return arr, nil
+}
+
+// mapInstanceOf returns the JSON object of the map rv.
+func mapInstanceOf(rv reflect.Value, depth int) (interface{}, error) {
+	obj := make(map[string]interface{}, rv.Len())
+	for _, k := range rv.MapKeys() {
+		name, err := mapKeyName(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := instanceOf(rv.MapIndex(k), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		obj[name] = value
+	}
+
+	return obj, nil
+}
+
+// mapKeyName returns the object property name of the map key k.
+func mapKeyName(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", nil
+		}
+		b, err := tm.MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("jsonschema: marshal %s: %v", k.Type(), err)
+		}
+		return string(b), nil
+	}
+
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+
+	return "", fmt.Errorf("jsonschema: unsupported map key type %s", k.Type())
+}
+
+// structInstanceOf returns the JSON object of the struct rv.
+//
+// The fields are resolved once per struct type by the structFields, which reads the names and tags via internal/reflection.
+func structInstanceOf(rv reflect.Value, depth int) (interface{}, error) {
+	fields := structFields(reflection.FromType(rv.Type()))
+	obj := make(map[string]interface{}, len(fields))
+	for i := range fields {
+		f := &fields[i]
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok {
+			continue
+		}
+		if hasJSONOption(f.opts, "omitempty") && isEmptyValue(fv) {
+			continue
+		}
+
+		if f.isStringOption() {
+			value, err := stringOptionOf(fv, depth)
+			if err != nil {
+				return nil, err
+			}
+			obj[f.name] = value
+			continue
+		}
+
+		value, err := instanceOf(fv, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		obj[f.name] = value
+	}
+
+	return obj, nil
+}
+
+// fieldByIndex returns the nested field of rv, or false if the field is promoted through the nil embedded pointer.
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+
+	return rv, true
+}
+
+// stringOptionOf returns the JSON string of the field value rv which has the string option of the json tag.
+func stringOptionOf(rv reflect.Value, depth int) (interface{}, error) {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+
+	inst, err := instanceOf(rv, depth+1)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonString(inst), nil
+}
+
+// isEmptyValue reports whether rv is the empty value which is omitted by the omitempty option.
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool:
+		return !rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return rv.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return rv.IsNil()
+	}
+
+	return false
+}
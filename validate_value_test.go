@@ -0,0 +1,179 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type valueInner struct {
+	N int `json:"n"`
+}
+
+type valueEmbedded struct {
+	E string `json:"e"`
+}
+
+type valueStruct struct {
+	*valueEmbedded
+	Name    string          `json:"name"`
+	Omit    string          `json:"omit,omitempty"`
+	Skip    int             `json:"-"`
+	Quoted  int             `json:"quoted,string"`
+	Ptr     *valueInner     `json:"ptr"`
+	Items   []valueInner    `json:"items"`
+	Bytes   []byte          `json:"bytes"`
+	Map     map[int]string  `json:"map"`
+	IP      net.IP          `json:"ip"`
+	Time    time.Time       `json:"time"`
+	Number  json.Number     `json:"number"`
+	Raw     json.RawMessage `json:"raw,omitempty"`
+	Any     interface{}     `json:"any"`
+	private string
+}
+
+func TestValidateValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  interface{}
+		valid  bool
+	}{
+		{
+			name:   "scalars",
+			schema: `{"type": "array", "items": [{"type": "boolean"}, {"type": "integer"}, {"type": "integer", "minimum": 0}, {"type": "number"}, {"type": "string"}, {"type": "null"}]}`,
+			value:  []interface{}{true, int8(-3), uint64(1 << 63), float32(1.5), "s", nil},
+			valid:  true,
+		},
+		{
+			name:   "float32 is not an integer",
+			schema: `{"type": "integer"}`,
+			value:  float32(0.1),
+		},
+		{
+			name:   "struct",
+			schema: `{"type": "object", "required": ["name", "quoted", "ptr", "items", "bytes", "map", "ip", "time", "number", "any"], "additionalProperties": false, "properties": {"name": {}, "quoted": {"type": "string", "pattern": "^[0-9]+$"}, "ptr": {"type": "null"}, "items": {"items": {"required": ["n"]}}, "bytes": {"type": "string", "contentEncoding": "base64"}, "map": {"propertyNames": {"pattern": "^[0-9]+$"}}, "ip": {"format": "ipv4"}, "time": {"format": "date-time"}, "number": {"const": 0}, "any": {"type": "null"}}}`,
+			value: valueStruct{
+				Name:   "a",
+				Skip:   1,
+				Quoted: 42,
+				Items:  []valueInner{{N: 1}},
+				Bytes:  []byte("hello"),
+				Map:    map[int]string{1: "a"},
+				IP:     net.IPv4(192, 0, 2, 1),
+				Time:   time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+			valid: true,
+		},
+		{
+			name:   "struct invalid",
+			schema: `{"required": ["omit"], "properties": {"name": {"minLength": 2}, "quoted": {"type": "integer"}, "ptr": {"type": "object", "properties": {"n": {"minimum": 1}}}}}`,
+			value:  &valueStruct{Name: "a", Ptr: &valueInner{}},
+		},
+		{
+			name:   "embedded",
+			schema: `{"required": ["e"], "properties": {"e": {"const": "x"}}}`,
+			value:  valueStruct{valueEmbedded: &valueEmbedded{E: "x"}},
+			valid:  true,
+		},
+		{
+			name:   "nil embedded",
+			schema: `{"required": ["e"]}`,
+			value:  valueStruct{},
+		},
+		{
+			name:   "raw and nested",
+			schema: `{"properties": {"raw": {"type": "object", "required": ["a"]}, "any": {"type": "array", "items": {"type": "object"}}}}`,
+			value:  valueStruct{Raw: json.RawMessage(`{"a": [1, 2.5e300]}`), Any: []map[string]interface{}{{"k": 1}}},
+			valid:  true,
+		},
+		{
+			name:   "map",
+			schema: `{"type": "object", "additionalProperties": {"type": "integer", "maximum": 2}}`,
+			value:  map[string]int{"a": 1, "b": 3},
+		},
+		{
+			name:   "array",
+			schema: `{"type": "array", "minItems": 3, "uniqueItems": true}`,
+			value:  [3]string{"a", "b", "a"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := MustCompile(mustSchema(t, tt.schema))
+			got := v.ValidateValue(tt.value)
+
+			b, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := v.Validate(mustInstance(t, string(b)))
+			if (got == nil) != tt.valid {
+				t.Errorf("ValidateValue() = %v, want valid %t", got, tt.valid)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ValidateValue() = %v, want the same as Validate(%s) = %v", got, b, want)
+			}
+		})
+	}
+}
+
+type valueMarshaler struct{ v string }
+
+func (m valueMarshaler) MarshalJSON() ([]byte, error) {
+	if m.v == "" {
+		return nil, errors.New("empty")
+	}
+	return json.Marshal(m.v)
+}
+
+type valueCycle struct {
+	Next *valueCycle `json:"next"`
+}
+
+func TestValidateValueError(t *testing.T) {
+	cycle := &valueCycle{}
+	cycle.Next = cycle
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		wantErr string
+	}{
+		{name: "chan", value: make(chan int), wantErr: "unsupported type chan int"},
+		{name: "func field", value: struct{ F func() }{}, wantErr: "unsupported type func()"},
+		{name: "map key", value: map[[2]int]int{{1, 2}: 3}, wantErr: "unsupported map key type [2]int"},
+		{name: "marshaler", value: valueMarshaler{}, wantErr: "empty"},
+		{name: "cycle", value: cycle, wantErr: "too deep value"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := MustCompile(&Schema{}).ValidateValue(tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateValue() = %v, want %q", err, tt.wantErr)
+			}
+			var verr ValidationErrors
+			if errors.As(err, &verr) {
+				t.Errorf("ValidateValue() = %T, want the encoding error", err)
+			}
+		})
+	}
+
+	if err := MustCompile(&Schema{Const: NewConst("x")}).ValidateValue(valueMarshaler{v: "x"}); err != nil {
+		t.Errorf("ValidateValue(json.Marshaler) = %v", err)
+	}
+}
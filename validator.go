@@ -0,0 +1,422 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/francoispqt/gojay"
+
+	"github.com/zchee/go-jsonschema/pkg/jsonpointer"
+	regexpinterface "github.com/zchee/go-jsonschema/pkg/regexp"
+)
+
+// UnknownFormatPolicy represents a policy of the unknown "format" keyword value.
+type UnknownFormatPolicy int
+
+// The list of UnknownFormatPolicy.
+const (
+	// UnknownFormatIgnore ignores the unknown formats.
+	UnknownFormatIgnore UnknownFormatPolicy = iota
+
+	// UnknownFormatAnnotate collects the unknown formats as the Annotation of the Validator.
+	UnknownFormatAnnotate
+
+	// UnknownFormatFail fails the compilation if the Schema uses the unknown formats.
+	UnknownFormatFail
+)
+
+// Annotation represents an annotation collected while compiling the Schema.
+type Annotation struct {
+	KeywordLocation string
+	Keyword         string
+	Message         string
+}
+
+// String implements fmt.Stringer.
+func (a *Annotation) String() string {
+	return fmt.Sprintf("%s: %s", a.KeywordLocation, a.Message)
+}
+
+// ValidationError represents an error of the instance validation.
+type ValidationError struct {
+	// KeywordLocation is the JSON Pointer of the failed keyword in the Schema.
+	KeywordLocation string
+
+	// InstanceLocation is the JSON Pointer of the failed value in the instance.
+	InstanceLocation string
+
+	// Message is the human readable error message.
+	Message string
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", instanceLocationString(e.InstanceLocation), e.Message)
+}
+
+// instanceLocationString returns the printable form of the loc.
+func instanceLocationString(loc string) string {
+	if loc == "" {
+		return "(root)"
+	}
+
+	return loc
+}
+
+// ValidationErrors represents a list of ValidationError.
+type ValidationErrors []*ValidationError
+
+// Error implements error.
+func (es ValidationErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+var (
+	// compile time check whether the ValidationError implements gojay.MarshalerJSONObject interface.
+	_ gojay.MarshalerJSONObject = &ValidationError{}
+	// compile time check whether the ValidationErrors implements gojay.MarshalerJSONArray interface.
+	_ gojay.MarshalerJSONArray = ValidationErrors{}
+)
+
+// MarshalJSONObject implements gojay.MarshalerJSONObject.
+func (e *ValidationError) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey(keyKeywordLocation, e.KeywordLocation)
+	enc.StringKey(keyInstanceLocation, e.InstanceLocation)
+	enc.StringKey(keyMessage, e.Message)
+}
+
+// IsNil implements gojay.MarshalerJSONObject.
+//
+// IsNil checks if instance is nil.
+func (e *ValidationError) IsNil() bool {
+	return e == nil
+}
+
+// MarshalJSONArray implements gojay.MarshalerJSONArray.
+func (es ValidationErrors) MarshalJSONArray(enc *gojay.Encoder) {
+	for _, e := range es {
+		enc.Object(e)
+	}
+}
+
+// IsNil implements gojay.MarshalerJSONArray.
+//
+// IsNil checks if instance is nil.
+func (es ValidationErrors) IsNil() bool {
+	return len(es) == 0
+}
+
+// Validator represents a compiled Schema which validates the instances.
+//
+// Validator is safe for concurrent use by multiple goroutines.
+type Validator struct {
+	root          *Schema
+	formats       *FormatRegistry
+	unknownFormat UnknownFormatPolicy
+	annotations   []*Annotation
+	regexpEngine  RegexpEngine
+
+	// formatOpts is the list of the modifications of the formats by the Options, which are applied to
+	// the copy of the FormatRegistry after all Options regardless of their order.
+	formatOpts []func(*FormatRegistry)
+
+	// patterns is the map of the source to the compiled "pattern" and "patternProperties" regular expressions.
+	patterns map[string]regexpinterface.Regexp
+
+	// resources is the map of the absolute URI without the empty fragment to the identified Schema.
+	resources map[string]*Schema
+
+	// bases is the map of the Schema to its base URI.
+	bases map[*Schema]*url.URL
+
+	// refs is the map of the Schema to the Schema referenced by its "$ref".
+	refs map[*Schema]*Schema
+}
+
+// Option represents an option of the Compile.
+type Option func(*Validator)
+
+// WithFormatRegistry sets the FormatRegistry used by the Validator.
+//
+// The Validator uses the copy of r taken by the Compile, so the later changes of r do not affect it.
+// The default is DefaultFormatRegistry with the DefaultFormatMode of the "$schema" of the compiled Schema.
+func WithFormatRegistry(r *FormatRegistry) Option {
+	return func(v *Validator) {
+		v.formats = r
+	}
+}
+
+// WithFormatChecker registers the c FormatChecker as the f Format only to the Validator.
+//
+// The checker is registered to the FormatRegistry of the WithFormatRegistry, even if it precedes the WithFormatRegistry.
+func WithFormatChecker(f Format, c FormatChecker) Option {
+	return func(v *Validator) {
+		v.formatOpts = append(v.formatOpts, func(r *FormatRegistry) {
+			r.Register(f, c)
+		})
+	}
+}
+
+// WithFormatMode sets the FormatMode of the Validator.
+//
+// The mode overrides the FormatMode of the WithFormatRegistry, even if it precedes the WithFormatRegistry.
+func WithFormatMode(mode FormatMode) Option {
+	return func(v *Validator) {
+		v.formatOpts = append(v.formatOpts, func(r *FormatRegistry) {
+			r.SetMode(mode)
+		})
+	}
+}
+
+// WithUnknownFormatPolicy sets the UnknownFormatPolicy of the Validator.
+//
+// The default is UnknownFormatIgnore.
+func WithUnknownFormatPolicy(p UnknownFormatPolicy) Option {
+	return func(v *Validator) {
+		v.unknownFormat = p
+	}
+}
+
+// WithRegexpEngine sets the RegexpEngine of the "pattern" and "patternProperties" keywords.
+//
+// The default is RegexpRE2.
+func WithRegexpEngine(e RegexpEngine) Option {
+	return func(v *Validator) {
+		v.regexpEngine = e
+	}
+}
+
+// Compile compiles the s Schema and returns the Validator.
+func Compile(s *Schema, opts ...Option) (*Validator, error) {
+	v := &Validator{
+		root:      s,
+		formats:   DefaultFormatRegistry,
+		resources: make(map[string]*Schema),
+		bases:     make(map[*Schema]*url.URL),
+		refs:      make(map[*Schema]*Schema),
+		patterns:  make(map[string]regexpinterface.Regexp),
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	registry := v.formats
+	v.formats = registry.Clone()
+	if registry == DefaultFormatRegistry {
+		v.formats.SetMode(DefaultFormatMode(schemaVersion(s.Schema)))
+	}
+	for _, opt := range v.formatOpts {
+		opt(v.formats)
+	}
+	v.formatOpts = nil
+
+	if err := v.compile(); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// MustCompile is like Compile but panics if the Schema cannot be compiled.
+func MustCompile(s *Schema, opts ...Option) *Validator {
+	v, err := Compile(s, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// Schema returns the compiled Schema.
+func (v *Validator) Schema() *Schema { return v.root }
+
+// Annotations returns the list of Annotation collected while compiling the Schema.
+func (v *Validator) Annotations() []*Annotation { return v.annotations }
+
+// schemaVersion returns the DraftVersion of the uri "$schema", or DraftVersion7 if uri is not a known meta-schema.
+func schemaVersion(uri string) DraftVersion {
+	switch strings.TrimSuffix(uri, "#") {
+	case "http://json-schema.org/draft-04/schema":
+		return DraftVersion4
+	case "http://json-schema.org/draft-06/schema":
+		return DraftVersion6
+	case Draft201909SchemaURL:
+		return DraftVersion201909
+	}
+
+	return DraftVersion7
+}
+
+// compile indexes the identified schemas, and checks the schema keywords.
+func (v *Validator) compile() error {
+	var err error
+	v.indexResources(v.root, &url.URL{}, func(loc string, s *Schema) bool {
+		if err == nil {
+			err = v.checkFormat(loc, s)
+		}
+		if err == nil {
+			err = v.compilePatterns(loc, s)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return v.resolveRefs()
+}
+
+// resolveRefs resolves the "$ref"s of all schemas in the root Schema.
+func (v *Validator) resolveRefs() error {
+	var err error
+	walk(v.root, func(loc string, s *Schema) bool {
+		if err != nil || s.Ref == "" {
+			return err == nil
+		}
+		target, rerr := v.resolveRef(s)
+		if rerr != nil {
+			err = fmt.Errorf("%s: %v", appendLocation(loc, keyRef), rerr)
+			return false
+		}
+		v.refs[s] = target
+		return true
+	})
+
+	return err
+}
+
+// checkFormat checks whether the "format" keyword value of s is known.
+func (v *Validator) checkFormat(loc string, s *Schema) error {
+	if s.Format == "" {
+		return nil
+	}
+	if _, ok := v.formats.Lookup(s.Format); ok {
+		return nil
+	}
+
+	loc = appendLocation(loc, keyFormat)
+	switch v.unknownFormat {
+	case UnknownFormatAnnotate:
+		v.annotations = append(v.annotations, &Annotation{
+			KeywordLocation: loc,
+			Keyword:         keyFormat,
+			Message:         fmt.Sprintf("unknown format %q", s.Format),
+		})
+	case UnknownFormatFail:
+		return fmt.Errorf("%s: unknown format %q", loc, s.Format)
+	}
+
+	return nil
+}
+
+// compilePatterns compiles the "pattern" and "patternProperties" regular expressions of s with the RegexpEngine.
+func (v *Validator) compilePatterns(loc string, s *Schema) error {
+	compile := func(loc, expr string) error {
+		if _, ok := v.patterns[expr]; ok {
+			return nil
+		}
+		re, err := v.regexpEngine.compile(expr)
+		if err != nil {
+			return fmt.Errorf("%s: invalid %s pattern %q: %v", loc, v.regexpEngine, expr, err)
+		}
+		v.patterns[expr] = re
+		return nil
+	}
+
+	if s.Pattern != nil {
+		if err := compile(appendLocation(loc, keyPattern), s.Pattern.String()); err != nil {
+			return err
+		}
+	}
+	for expr := range s.PatternProperties {
+		if err := compile(appendLocation(loc, keyPatternProperties, expr), expr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// indexResources records the base URI of all schemas in s, and indexes the schemas identified by "$id".
+func (v *Validator) indexResources(s *Schema, base *url.URL, fn func(loc string, s *Schema) bool) {
+	var visit func(loc string, s *Schema, base *url.URL)
+	visit = func(loc string, s *Schema, base *url.URL) {
+		if _, ok := v.bases[s]; ok {
+			return
+		}
+		if s.ID != "" {
+			if id, err := url.Parse(s.ID); err == nil {
+				base = base.ResolveReference(id)
+				v.resources[base.String()] = s
+				if base.Fragment != "" {
+					// location-independent identifier, which does not change the base URI.
+					base = stripFragment(base)
+				}
+			}
+		}
+		v.bases[s] = base
+		if loc == "" {
+			v.resources[stripFragment(base).String()] = s
+		}
+		if !fn(loc, s) {
+			return
+		}
+		for _, sub := range s.subschemas() {
+			visit(appendLocation(loc, sub.tokens...), sub.schema, base)
+		}
+	}
+	visit("", s, base)
+}
+
+// stripFragment returns a copy of u without the fragment.
+func stripFragment(u *url.URL) *url.URL {
+	c := *u
+	c.Fragment = ""
+	c.RawFragment = ""
+
+	return &c
+}
+
+// resolveRef returns the Schema referenced by the "$ref" of s.
+func (v *Validator) resolveRef(s *Schema) (*Schema, error) {
+	ref, err := url.Parse(s.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q: %v", s.Ref, err)
+	}
+	base, ok := v.bases[s]
+	if !ok {
+		base = &url.URL{}
+	}
+	abs := base.ResolveReference(ref)
+
+	if abs.Fragment != "" && !strings.HasPrefix(abs.Fragment, "/") {
+		if target, ok := v.resources[abs.String()]; ok {
+			return target, nil
+		}
+		return nil, fmt.Errorf("unresolvable $ref %q", s.Ref)
+	}
+
+	doc, ok := v.resources[stripFragment(abs).String()]
+	if !ok {
+		return nil, fmt.Errorf("unresolvable $ref %q", s.Ref)
+	}
+	ptr, err := jsonpointer.Parse(abs.Fragment)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q: %v", s.Ref, err)
+	}
+	target, ok := doc.Lookup(ptr)
+	if !ok {
+		return nil, fmt.Errorf("unresolvable $ref %q", s.Ref)
+	}
+
+	return target, nil
+}
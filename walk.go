@@ -0,0 +1,224 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/zchee/go-jsonschema/pkg/jsonpointer"
+)
+
+// subschema represents a direct sub schema of a Schema and its location relative to the parent.
+type subschema struct {
+	tokens []string
+	schema *Schema
+}
+
+// subschemas returns the list of direct sub schemas of s in a deterministic order.
+func (s *Schema) subschemas() []subschema {
+	var subs []subschema
+	add := func(sub *Schema, tokens ...string) {
+		if sub != nil {
+			subs = append(subs, subschema{tokens: tokens, schema: sub})
+		}
+	}
+	addList := func(key string, list SchemaList) {
+		for i, sub := range list {
+			add(sub, key, strconv.Itoa(i))
+		}
+	}
+	addMap := func(key string, m map[string]*Schema) {
+		for _, k := range sortedKeys(m) {
+			add(m[k], key, k)
+		}
+	}
+
+	addMap(keyDefinitions, s.Definitions)
+	addMap(keyDefs, s.Defs)
+	if s.Items != nil {
+		if s.Items.HasMultiple {
+			addList(keyItems, s.Items.Schemas)
+		} else if len(s.Items.Schemas) > 0 {
+			add(s.Items.Schemas[0], keyItems)
+		}
+	}
+	if s.AdditionalItems != nil {
+		add(s.AdditionalItems.Schema, keyAdditionalItems)
+	}
+	if s.Contains != nil {
+		add(s.Contains.Schema, keyContains)
+	}
+	addMap(keyProperties, s.Properties)
+	if len(s.PatternProperties) > 0 {
+		m := make(map[string]*Schema, len(s.PatternProperties))
+		for expr, pp := range s.PatternProperties {
+			m[expr] = pp.Schema
+		}
+		addMap(keyPatternProperties, m)
+	}
+	if s.AdditionalProperties != nil {
+		add(s.AdditionalProperties.Schema, keyAdditionalProperties)
+	}
+	if s.Dependencies != nil {
+		addMap(keyDependencies, s.Dependencies.Schemas)
+	}
+	add(s.PropertyNames, keyPropertyNames)
+	add(s.If, keyIf)
+	add(s.Then, keyThen)
+	add(s.Else, keyElse)
+	addList(keyAllOf, s.AllOf)
+	addList(keyAnyOf, s.AnyOf)
+	addList(keyOneOf, s.OneOf)
+	add(s.Not, keyNot)
+
+	return subs
+}
+
+// mapSubschemas returns the shallow copy of s whose direct sub schemas are replaced by fn.
+//
+// The lists and maps of the sub schemas are copied, so s is not modified.
+func (s *Schema) mapSubschemas(fn func(sub *Schema) *Schema) *Schema {
+	c := *s
+	mapMap := func(m map[string]*Schema) map[string]*Schema {
+		if m == nil {
+			return nil
+		}
+		mm := make(map[string]*Schema, len(m))
+		for k, sub := range m {
+			mm[k] = fn(sub)
+		}
+		return mm
+	}
+	mapList := func(list SchemaList) SchemaList {
+		if list == nil {
+			return nil
+		}
+		l := make(SchemaList, len(list))
+		for i, sub := range list {
+			l[i] = fn(sub)
+		}
+		return l
+	}
+	mapOne := func(sub *Schema) *Schema {
+		if sub == nil {
+			return nil
+		}
+		return fn(sub)
+	}
+
+	c.Definitions = mapMap(s.Definitions)
+	c.Defs = mapMap(s.Defs)
+	if s.Items != nil {
+		c.Items = &Items{Schemas: mapList(s.Items.Schemas), HasMultiple: s.Items.HasMultiple}
+	}
+	if s.AdditionalItems != nil {
+		c.AdditionalItems = &AdditionalItems{Schema: mapOne(s.AdditionalItems.Schema)}
+	}
+	if s.Contains != nil {
+		c.Contains = &AdditionalProperties{Schema: mapOne(s.Contains.Schema)}
+	}
+	c.Properties = mapMap(s.Properties)
+	if s.PatternProperties != nil {
+		c.PatternProperties = make(PatternProperties, len(s.PatternProperties))
+		for expr, pp := range s.PatternProperties {
+			c.PatternProperties[expr] = &PatternProperty{Regexp: pp.Regexp, Schema: mapOne(pp.Schema)}
+		}
+	}
+	if s.AdditionalProperties != nil {
+		c.AdditionalProperties = &AdditionalProperties{Schema: mapOne(s.AdditionalProperties.Schema)}
+	}
+	if s.Dependencies != nil {
+		c.Dependencies = &DependencyMap{Names: s.Dependencies.Names, Schemas: mapMap(s.Dependencies.Schemas)}
+	}
+	c.PropertyNames = mapOne(s.PropertyNames)
+	c.If = mapOne(s.If)
+	c.Then = mapOne(s.Then)
+	c.Else = mapOne(s.Else)
+	c.AllOf = mapList(s.AllOf)
+	c.AnyOf = mapList(s.AnyOf)
+	c.OneOf = mapList(s.OneOf)
+	c.Not = mapOne(s.Not)
+
+	return &c
+}
+
+// sortedKeys returns the sorted keys of m.
+func sortedKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Walk calls fn for s and all sub schemas of s in the depth-first order, with the JSON Pointer location of each schema.
+//
+// Walk does not follow the "$ref", and visits each Schema only once even if it is shared.
+// If fn returns false, Walk skips the sub schemas of the current schema.
+func (s *Schema) Walk(fn func(loc string, s *Schema) bool) {
+	walk(s, fn)
+}
+
+// walk calls fn for s and all sub schemas of s in the depth-first order, with the JSON Pointer location of each schema.
+//
+// walk does not follow the "$ref", and visits each Schema only once even if it is shared.
+// If fn returns false, walk skips the sub schemas of the current schema.
+func walk(s *Schema, fn func(loc string, s *Schema) bool) {
+	seen := make(map[*Schema]bool)
+	var visit func(loc string, s *Schema)
+	visit = func(loc string, s *Schema) {
+		if seen[s] {
+			return
+		}
+		seen[s] = true
+		if !fn(loc, s) {
+			return
+		}
+		for _, sub := range s.subschemas() {
+			visit(appendLocation(loc, sub.tokens...), sub.schema)
+		}
+	}
+	visit("", s)
+}
+
+// appendLocation appends the escaped tokens to the loc JSON Pointer.
+func appendLocation(loc string, tokens ...string) string {
+	for _, tok := range tokens {
+		loc += "/" + jsonpointer.Escape(tok)
+	}
+
+	return loc
+}
+
+// Lookup returns the sub schema of s which is referenced by the ptr JSON Pointer.
+func (s *Schema) Lookup(ptr jsonpointer.Pointer) (*Schema, bool) {
+	cur := s
+next:
+	for len(ptr) > 0 {
+		for _, sub := range cur.subschemas() {
+			if len(sub.tokens) > len(ptr) {
+				continue
+			}
+			match := true
+			for i, tok := range sub.tokens {
+				if ptr[i] != tok {
+					match = false
+					break
+				}
+			}
+			if match {
+				cur = sub.schema
+				ptr = ptr[len(sub.tokens):]
+				continue next
+			}
+		}
+		return nil, false
+	}
+
+	return cur, true
+}
@@ -0,0 +1,76 @@
+// Copyright 2019 The go-jsonschema Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zchee/go-jsonschema/pkg/jsonpointer"
+)
+
+func TestWalk(t *testing.T) {
+	s := mustSchema(t, `{
+		"properties": {"b": {"items": [{}, {}]}, "a/b": {}},
+		"definitions": {"d": {"not": {}}},
+		"allOf": [{"$ref": "#"}]
+	}`)
+
+	var got []string
+	s.Walk(func(loc string, _ *Schema) bool {
+		got = append(got, loc)
+		return loc != "/definitions/d"
+	})
+	want := []string{
+		"",
+		"/definitions/d",
+		"/properties/a~1b",
+		"/properties/b",
+		"/properties/b/items/0",
+		"/properties/b/items/1",
+		"/allOf/0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk() visits %q, want %q", got, want)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	s := mustSchema(t, `{
+		"properties": {"a/b": {"title": "slash"}, "items": {"items": [{"title": "tuple"}]}},
+		"definitions": {"d": {"title": "definition"}}
+	}`)
+
+	tests := []struct {
+		ptr    string
+		want   string
+		wantOK bool
+	}{
+		{ptr: "/properties/a~1b", want: "slash", wantOK: true},
+		{ptr: "/properties/items/items/0", want: "tuple", wantOK: true},
+		{ptr: "/definitions/d", want: "definition", wantOK: true},
+		{ptr: "/definitions/missing"},
+		{ptr: "/properties"},
+		{ptr: "/title"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.ptr, func(t *testing.T) {
+			t.Parallel()
+
+			ptr, err := jsonpointer.Parse(tt.ptr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := s.Lookup(ptr)
+			if ok != tt.wantOK {
+				t.Fatalf("Lookup(%q) = _, %t, want %t", tt.ptr, ok, tt.wantOK)
+			}
+			if ok && got.Title != tt.want {
+				t.Errorf("Lookup(%q) = %q, want %q", tt.ptr, got.Title, tt.want)
+			}
+		})
+	}
+}